@@ -84,6 +84,15 @@ func (g *ClusterNodeGroups) ListClusterNode(filerGroup FilerGroupName) (nodes []
 	return
 }
 
+func (g *ClusterNodeGroups) countClusterNodes() (count int) {
+	g.RLock()
+	defer g.RUnlock()
+	for _, m := range g.groupMembers {
+		count += len(m.members)
+	}
+	return
+}
+
 func NewCluster() *Cluster {
 	return &Cluster{
 		filerGroups:  newClusterNodeGroups(),
@@ -138,6 +147,17 @@ func (cluster *Cluster) ListClusterNode(filerGroup FilerGroupName, nodeType stri
 	return
 }
 
+// CountClusterNodes counts the nodes of the type in all filer groups
+func (cluster *Cluster) CountClusterNodes(nodeType string) int {
+	switch nodeType {
+	case FilerType:
+		return cluster.filerGroups.countClusterNodes()
+	case BrokerType:
+		return cluster.brokerGroups.countClusterNodes()
+	}
+	return 0
+}
+
 func buildClusterNodeUpdateMessage(isAdd bool, filerGroup FilerGroupName, nodeType string, address pb.ServerAddress) (result []*master_pb.KeepConnectedResponse) {
 	result = append(result, &master_pb.KeepConnectedResponse{
 		ClusterNodeUpdate: &master_pb.ClusterNodeUpdate{
@@ -38,3 +38,17 @@ func TestConcurrentAddRemoveNodes(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func TestCountClusterNodes(t *testing.T) {
+	c := NewCluster()
+	c.AddClusterNode("", FilerType, "", "", pb.ServerAddress("filer1:8888"), "")
+	c.AddClusterNode("group2", FilerType, "", "", pb.ServerAddress("filer2:8888"), "")
+	c.AddClusterNode("", BrokerType, "", "", pb.ServerAddress("broker1:17777"), "")
+
+	if count := c.CountClusterNodes(FilerType); count != 2 {
+		t.Errorf("filers: %d, expected 2", count)
+	}
+	if count := c.CountClusterNodes(BrokerType); count != 1 {
+		t.Errorf("brokers: %d, expected 1", count)
+	}
+}
@@ -20,7 +20,9 @@ var Commands = []*Command{
 	cmdFilerBackup,
 	cmdFilerCat,
 	cmdFilerCopy,
+	cmdFilerExport,
 	cmdFilerMetaBackup,
+	cmdFilerMetaRestore,
 	cmdFilerMetaTail,
 	cmdFilerRemoteGateway,
 	cmdFilerRemoteSynchronize,
@@ -249,7 +249,9 @@ func writeFile(vid needle.VolumeId, n *needle.Needle) (err error) {
 		if util.IsGzippedContent(n.Data) && path.Ext(fileName) != ".gz" {
 			fileName = fileName + ".gz"
 		}
-		// TODO other compression method
+		if util.IsZstdContent(n.Data) && path.Ext(fileName) != ".zst" {
+			fileName = fileName + ".zst"
+		}
 	}
 
 	tarHeader.Name, tarHeader.Size = fileName, int64(len(n.Data))
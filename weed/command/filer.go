@@ -66,9 +66,26 @@ type FilerOptions struct {
 	localSocket             *string
 	showUIDirectoryDelete   *bool
 	downloadMaxMBps         *int
+	uploadMaxMB             *int
 	diskType                *string
 	allowedOrigins          *string
 	exposeDirectoryData     *bool
+	mirrorTo                *string
+	readCacheDir            *string
+	readCacheSizeMB         *int64
+	rateLimitRPM            *int
+	rateLimitBurst          *int
+	rateLimitWhiteList      *string
+	casDedupDir             *string
+	wormAdminWhiteList      *string
+	recompressOnRead        *bool
+	detectContentType       *bool
+	auditLog                *string
+	auditLogMaxMB           *int
+	gcInterval              *time.Duration
+	gcGracePeriod           *time.Duration
+	gcRateLimit             *int
+	quotaRecountInterval    *time.Duration
 	certProvider            certprovider.Provider
 }
 
@@ -100,9 +117,26 @@ func init() {
 	f.localSocket = cmdFiler.Flag.String("localSocket", "", "default to /tmp/seaweedfs-filer-<port>.sock")
 	f.showUIDirectoryDelete = cmdFiler.Flag.Bool("ui.deleteDir", true, "enable filer UI show delete directory button")
 	f.downloadMaxMBps = cmdFiler.Flag.Int("downloadMaxMBps", 0, "download max speed for each download request, in MB per second")
+	f.uploadMaxMB = cmdFiler.Flag.Int("uploadMaxMB", 0, "reject http uploads larger than this limit in MB, 0 for no limit")
 	f.diskType = cmdFiler.Flag.String("disk", "", "[hdd|ssd|<tag>] hard drive or solid state drive or any tag")
 	f.allowedOrigins = cmdFiler.Flag.String("allowedOrigins", "*", "comma separated list of allowed origins")
 	f.exposeDirectoryData = cmdFiler.Flag.Bool("exposeDirectoryData", true, "whether to return directory metadata and content in Filer UI")
+	f.readCacheDir = cmdFiler.Flag.String("readCacheDir", "", "local directory, preferably on an ssd, to cache the file chunks read from volume servers")
+	f.readCacheSizeMB = cmdFiler.Flag.Int64("readCacheSizeMB", 0, "read cache size in MB, evicting the least recently read chunks")
+	f.rateLimitRPM = cmdFiler.Flag.Int("rateLimit.rpm", 0, "limit http requests per minute from each client ip, 0 for no limit")
+	f.rateLimitBurst = cmdFiler.Flag.Int("rateLimit.burst", 500, "max http requests from each client ip in a burst, with -rateLimit.rpm")
+	f.rateLimitWhiteList = cmdFiler.Flag.String("rateLimit.whiteList", "", "comma separated ip addresses or CIDR ranges of the s3 gateways, mounts and other trusted clients. They are not rate limited, but the clients they forward in X-Forwarded-For are.")
+	f.casDedupDir = cmdFiler.Flag.String("casDedupDir", "", "local directory for the index to deduplicate the uploaded chunks by their sha256. Only for a single filer, since other filers would not see the shared chunks.")
+	f.wormAdminWhiteList = cmdFiler.Flag.String("worm.adminWhiteList", "", "comma separated ip addresses or CIDRs allowed to bypass the WORM retention by http requests with bypassWorm=true")
+	f.recompressOnRead = cmdFiler.Flag.Bool("recompressOnRead", false, "migrate the gzip chunks of the files to zstd in the background after the files are read")
+	f.auditLog = cmdFiler.Flag.String("auditLog", "", "file to log each http request as a json line, for audit")
+	f.auditLogMaxMB = cmdFiler.Flag.Int("auditLogMaxMB", 100, "rotate the audit log file after this size, keeping 5 rotated files")
+	f.gcInterval = cmdFiler.Flag.Duration("gc.interval", 0, "delete the chunks no entry refers to at this interval, 0 to disable. Not for volumes also written without this filer.")
+	f.gcGracePeriod = cmdFiler.Flag.Duration("gc.gracePeriod", 24*time.Hour, "only delete the unreferenced chunks written before this long ago")
+	f.gcRateLimit = cmdFiler.Flag.Int("gc.rateLimit", 100, "max chunks checked or deleted per second by the gc, 0 for no limit")
+	f.quotaRecountInterval = cmdFiler.Flag.Duration("quota.recountInterval", time.Hour, "recount the usage of the collections with quotas from all their files at this interval, 0 to disable. The usage is counted on each write and delete in between.")
+	f.detectContentType = cmdFiler.Flag.Bool("detectContentType", false, "detect the content type of the uploads without one, from the first 512 bytes or the file extension")
+	f.mirrorTo = cmdFiler.Flag.String("mirrorTo", "", "<host>:<port> of another filer to asynchronously replicate all changes to. Two filers mirroring to each other are active-active")
 
 	// start s3 on filer
 	filerStartS3 = cmdFiler.Flag.Bool("s3", false, "whether to start S3 gateway")
@@ -118,6 +152,7 @@ func init() {
 	filerS3Options.auditLogConfig = cmdFiler.Flag.String("s3.auditLogConfig", "", "path to the audit log config file")
 	filerS3Options.allowEmptyFolder = cmdFiler.Flag.Bool("s3.allowEmptyFolder", true, "allow empty folders")
 	filerS3Options.allowDeleteBucketNotEmpty = cmdFiler.Flag.Bool("s3.allowDeleteBucketNotEmpty", true, "allow recursive deleting all entries along with bucket")
+	filerS3Options.chunkSizeMB = cmdFiler.Flag.Int("s3.chunkSizeMB", 0, "split uploaded objects into chunks of this size, each uploaded to a volume server as soon as it is received. If 0, default to the -maxMB of the filer.")
 	filerS3Options.localSocket = cmdFiler.Flag.String("s3.localSocket", "", "default to /tmp/seaweedfs-s3-<port>.sock")
 
 	// start webdav on filer
@@ -131,6 +166,7 @@ func init() {
 	filerWebDavOptions.cacheDir = cmdFiler.Flag.String("webdav.cacheDir", os.TempDir(), "local cache directory for file chunks")
 	filerWebDavOptions.cacheSizeMB = cmdFiler.Flag.Int64("webdav.cacheCapacityMB", 0, "local cache capacity in MB")
 	filerWebDavOptions.filerRootPath = cmdFiler.Flag.String("webdav.filer.path", "/", "use this remote path from filer server")
+	filerWebDavOptions.lockTimeout = cmdFiler.Flag.Duration("webdav.lockTimeout", time.Hour, "the longest timeout of LOCK, also for locks asking for an infinite timeout")
 
 	// start iam on filer
 	filerStartIam = cmdFiler.Flag.Bool("iam", false, "whether to start IAM service")
@@ -155,6 +191,9 @@ func filerLongDesc() string {
 
 	The example filer.toml configuration file can be generated by "weed scaffold -config=filer"
 
+	With "-mirrorTo", all changes are replicated to another filer, the same as a one directional "weed filer.sync".
+	Two filers mirroring to each other accept writes on both sides, and the last write wins on conflicts.
+
 Supported Filer Stores:
 `
 
@@ -227,6 +266,15 @@ func runFiler(cmd *Command, args []string) bool {
 			time.Sleep(delay * time.Second)
 			filerIamOptions.startIamServer()
 		}(startDelay)
+		startDelay++
+	}
+
+	if *f.mirrorTo != "" {
+		grpcDialOption := security.LoadClientTLS(util.GetViper(), "grpc.client")
+		go func(delay time.Duration) {
+			time.Sleep(delay * time.Second)
+			startFilerMirror(grpcDialOption, pb.ServerAddress(filerAddress), pb.ServerAddress(*f.mirrorTo))
+		}(startDelay)
 	}
 
 	f.masters = pb.ServerAddresses(*f.mastersString).ToServiceDiscovery()
@@ -282,8 +330,24 @@ func (fo *FilerOptions) startFiler() {
 		ConcurrentUploadLimit: int64(*fo.concurrentUploadLimitMB) * 1024 * 1024,
 		ShowUIDirectoryDelete: *fo.showUIDirectoryDelete,
 		DownloadMaxBytesPs:    int64(*fo.downloadMaxMBps) * 1024 * 1024,
+		UploadMaxBytes:        int64(*fo.uploadMaxMB) * 1024 * 1024,
 		DiskType:              *fo.diskType,
 		AllowedOrigins:        strings.Split(*fo.allowedOrigins, ","),
+		ReadCacheDir:          util.ResolvePath(*fo.readCacheDir),
+		ReadCacheSizeMB:       *fo.readCacheSizeMB,
+		RateLimitRPM:          *fo.rateLimitRPM,
+		RateLimitBurst:        *fo.rateLimitBurst,
+		RateLimitWhiteList:    util.StringSplit(*fo.rateLimitWhiteList, ","),
+		CasDedupDir:           util.ResolvePath(*fo.casDedupDir),
+		WormAdminWhiteList:    util.StringSplit(*fo.wormAdminWhiteList, ","),
+		RecompressOnRead:      *fo.recompressOnRead,
+		DetectContentType:     *fo.detectContentType,
+		AuditLog:              util.ResolvePath(*fo.auditLog),
+		AuditLogMaxMB:         *fo.auditLogMaxMB,
+		GcInterval:            *fo.gcInterval,
+		GcGracePeriod:         *fo.gcGracePeriod,
+		GcRateLimit:           *fo.gcRateLimit,
+		QuotaRecountInterval:  *fo.quotaRecountInterval,
 	})
 	if nfs_err != nil {
 		glog.Fatalf("Filer startup error: %v", nfs_err)
@@ -332,6 +396,7 @@ func (fo *FilerOptions) startFiler() {
 		go grpcS.Serve(grpcLocalL)
 	}
 	go grpcS.Serve(grpcL)
+	pb.ServeInProcess(grpcS, util.JoinHostPort(*fo.ip, grpcPort))
 
 	httpS := &http.Server{Handler: defaultMux}
 	if runtime.GOOS != "windows" {
@@ -352,7 +417,9 @@ func (fo *FilerOptions) startFiler() {
 		}()
 	}
 
-	if viper.GetString("https.filer.key") != "" {
+	// the certificates of -tls-cert and -tls-key take precedence over [https.filer] in security.toml
+	httpS.TLSConfig = security.LoadServerTLSHTTP()
+	if httpS.TLSConfig == nil && viper.GetString("https.filer.key") != "" {
 		certFile := viper.GetString("https.filer.cert")
 		keyFile := viper.GetString("https.filer.key")
 		caCertFile := viper.GetString("https.filer.ca")
@@ -386,7 +453,9 @@ func (fo *FilerOptions) startFiler() {
 			ClientAuth:     clientAuth,
 			ClientCAs:      caCertPool,
 		}
+	}
 
+	if httpS.TLSConfig != nil {
 		if filerLocalListener != nil {
 			go func() {
 				if err := httpS.ServeTLS(filerLocalListener, "", ""); err != nil {
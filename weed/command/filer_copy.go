@@ -78,6 +78,12 @@ var cmdFilerCopy = &Command{
 
   If "maxMB" is set to a positive number, files larger than it would be split into chunks.
 
+  Pipes and devices are read once and uploaded chunk by chunk while reading,
+  so data of unknown size can be copied without storing it locally first:
+
+    mkfifo /tmp/backup.tar && tar cf /tmp/backup.tar /data &
+    weed filer.copy /tmp/backup.tar http://localhost:8888/backups/
+
 `,
 }
 
@@ -278,6 +284,11 @@ func (worker *FileCopyWorker) doEachCopy(task FileCopyTask) error {
 		return nil
 	}
 
+	// pipes and devices, e.g. /dev/stdin, have no known size and can only be read once
+	if task.fileMode&os.ModeDir == 0 && !task.fileMode.IsRegular() {
+		return worker.uploadFileStreaming(task, f)
+	}
+
 	// find the chunk count
 	chunkSize := int64(*worker.options.maxMB * 1024 * 1024)
 	chunkCount := 1
@@ -423,40 +434,14 @@ func (worker *FileCopyWorker) uploadFileInChunks(task FileCopyTask, f *os.File,
 				<-concurrentChunks
 			}()
 
-			fileId, uploadResult, err, _ := operation.UploadWithRetry(
-				worker,
-				&filer_pb.AssignVolumeRequest{
-					Count:       1,
-					Replication: *worker.options.replication,
-					Collection:  *worker.options.collection,
-					TtlSec:      worker.options.ttlSec,
-					DiskType:    *worker.options.diskType,
-					Path:        task.destinationUrlPath + fileName,
-				},
-				&operation.UploadOption{
-					Filename:          fileName + "-" + strconv.FormatInt(i+1, 10),
-					Cipher:            worker.options.cipher,
-					IsInputCompressed: false,
-					MimeType:          "",
-					PairMap:           nil,
-				},
-				func(host, fileId string) string {
-					return fmt.Sprintf("http://%s/%s", host, fileId)
-				},
-				io.NewSectionReader(f, i*chunkSize, chunkSize),
-			)
-
+			chunk, err := worker.uploadChunk(task, fileName, i, i*chunkSize, io.NewSectionReader(f, i*chunkSize, chunkSize))
 			if err != nil {
-				uploadError = fmt.Errorf("upload data %v: %v\n", fileName, err)
-				return
-			}
-			if uploadResult.Error != "" {
-				uploadError = fmt.Errorf("upload %v result: %v\n", fileName, uploadResult.Error)
+				uploadError = err
 				return
 			}
-			chunksChan <- uploadResult.ToPbFileChunk(fileId, i*chunkSize, time.Now().UnixNano())
+			chunksChan <- chunk
 
-			fmt.Printf("uploaded %s-%d [%d,%d)\n", fileName, i+1, i*chunkSize, i*chunkSize+int64(uploadResult.Size))
+			fmt.Printf("uploaded %s-%d [%d,%d)\n", fileName, i+1, i*chunkSize, i*chunkSize+int64(chunk.Size))
 		}(i)
 	}
 	wg.Wait()
@@ -478,6 +463,151 @@ func (worker *FileCopyWorker) uploadFileInChunks(task FileCopyTask, f *os.File,
 		return uploadError
 	}
 
+	if err := worker.createEntryWithChunks(task, fileName, mimeType, task.fileSize, uint32(task.fileMode), chunks); err != nil {
+		return err
+	}
+
+	fmt.Printf("copied %s => http://%s%s%s\n", f.Name(), worker.filerAddress.ToHttpAddress(), task.destinationUrlPath, fileName)
+
+	return nil
+}
+
+// uploadFileStreaming reads the source once from start to end, uploading each chunk
+// as soon as it is read, and creates the file entry after the last chunk.
+func (worker *FileCopyWorker) uploadFileStreaming(task FileCopyTask, f *os.File) error {
+
+	fileName := filepath.Base(f.Name())
+	chunkSize := int64(*worker.options.maxMB * 1024 * 1024)
+	if chunkSize <= 0 {
+		chunkSize = 4 * 1024 * 1024
+	}
+
+	if task.fileMode&os.ModeNamedPipe != 0 {
+		// the kernel may round up or cap the requested size, so check what it actually set
+		pipeSize, err := growPipeBuffer(f, int(chunkSize))
+		if *worker.options.verbose {
+			if err != nil {
+				fmt.Printf("grow pipe buffer of %s: %v\n", f.Name(), err)
+			} else if pipeSize < int(chunkSize) {
+				fmt.Printf("pipe buffer of %s is %d bytes, smaller than the %d bytes chunk size\n", f.Name(), pipeSize, chunkSize)
+			}
+		}
+	}
+
+	concurrentChunks := make(chan struct{}, *worker.options.concurrentChunks)
+	var wg sync.WaitGroup
+	var chunksLock sync.Mutex
+	var chunks []*filer_pb.FileChunk
+	var uploadError error
+
+	var mimeType string
+	var offset int64
+	fmt.Printf("uploading %s in streaming mode ...\n", fileName)
+	for i := int64(0); ; i++ {
+		data := make([]byte, chunkSize)
+		n, readErr := io.ReadFull(f, data)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			chunksLock.Lock()
+			uploadError = fmt.Errorf("read %v: %v", f.Name(), readErr)
+			chunksLock.Unlock()
+			break
+		}
+		if n == 0 {
+			break
+		}
+		data = data[:n]
+		if i == 0 {
+			if mimeType = http.DetectContentType(data); mimeType == "application/octet-stream" {
+				mimeType = ""
+			}
+		}
+
+		wg.Add(1)
+		concurrentChunks <- struct{}{}
+		go func(i int64, offset int64, data []byte) {
+			defer func() {
+				wg.Done()
+				<-concurrentChunks
+			}()
+
+			chunk, err := worker.uploadChunk(task, fileName, i, offset, util.NewBytesReader(data))
+
+			chunksLock.Lock()
+			defer chunksLock.Unlock()
+			if err != nil {
+				uploadError = err
+				return
+			}
+			chunks = append(chunks, chunk)
+
+			fmt.Printf("uploaded %s-%d [%d,%d)\n", fileName, i+1, offset, offset+int64(chunk.Size))
+		}(i, offset, data)
+
+		offset += int64(n)
+		chunksLock.Lock()
+		failed := uploadError != nil
+		chunksLock.Unlock()
+		if readErr != nil || failed {
+			break
+		}
+	}
+	wg.Wait()
+
+	if uploadError != nil {
+		var fileIds []string
+		for _, chunk := range chunks {
+			fileIds = append(fileIds, chunk.FileId)
+		}
+		operation.DeleteFiles(func(_ context.Context) pb.ServerAddress {
+			return pb.ServerAddress(copy.masters[0])
+		}, false, worker.options.grpcDialOption, fileIds)
+		return uploadError
+	}
+
+	if err := worker.createEntryWithChunks(task, fileName, mimeType, offset, uint32(task.fileMode.Perm()), chunks); err != nil {
+		return err
+	}
+
+	fmt.Printf("copied %s => http://%s%s%s\n", f.Name(), worker.filerAddress.ToHttpAddress(), task.destinationUrlPath, fileName)
+
+	return nil
+}
+
+// uploadChunk uploads the i-th chunk of the file, which starts at offset.
+func (worker *FileCopyWorker) uploadChunk(task FileCopyTask, fileName string, i int64, offset int64, reader io.Reader) (*filer_pb.FileChunk, error) {
+	fileId, uploadResult, err, _ := operation.UploadWithRetry(
+		worker,
+		&filer_pb.AssignVolumeRequest{
+			Count:       1,
+			Replication: *worker.options.replication,
+			Collection:  *worker.options.collection,
+			TtlSec:      worker.options.ttlSec,
+			DiskType:    *worker.options.diskType,
+			Path:        task.destinationUrlPath + fileName,
+		},
+		&operation.UploadOption{
+			Filename:          fileName + "-" + strconv.FormatInt(i+1, 10),
+			Cipher:            worker.options.cipher,
+			IsInputCompressed: false,
+			MimeType:          "",
+			PairMap:           nil,
+		},
+		func(host, fileId string) string {
+			return fmt.Sprintf("http://%s/%s", host, fileId)
+		},
+		reader,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("upload data %v: %v\n", fileName, err)
+	}
+	if uploadResult.Error != "" {
+		return nil, fmt.Errorf("upload %v result: %v\n", fileName, uploadResult.Error)
+	}
+	return uploadResult.ToPbFileChunk(fileId, offset, time.Now().UnixNano()), nil
+}
+
+func (worker *FileCopyWorker) createEntryWithChunks(task FileCopyTask, fileName string, mimeType string, fileSize int64, fileMode uint32, chunks []*filer_pb.FileChunk) error {
+
 	manifestedChunks, manifestErr := filer.MaybeManifestize(worker.saveDataAsChunk, chunks)
 	if manifestErr != nil {
 		return fmt.Errorf("create manifest: %v", manifestErr)
@@ -493,8 +623,8 @@ func (worker *FileCopyWorker) uploadFileInChunks(task FileCopyTask, f *os.File,
 					Mtime:    time.Now().Unix(),
 					Gid:      task.gid,
 					Uid:      task.uid,
-					FileSize: uint64(task.fileSize),
-					FileMode: uint32(task.fileMode),
+					FileSize: uint64(fileSize),
+					FileMode: fileMode,
 					Mime:     mimeType,
 					TtlSec:   worker.options.ttlSec,
 				},
@@ -510,8 +640,6 @@ func (worker *FileCopyWorker) uploadFileInChunks(task FileCopyTask, f *os.File,
 		return fmt.Errorf("upload data %v to http://%s%s%s: %v\n", fileName, worker.filerAddress.ToHttpAddress(), task.destinationUrlPath, fileName, err)
 	}
 
-	fmt.Printf("copied %s => http://%s%s%s\n", f.Name(), worker.filerAddress.ToHttpAddress(), task.destinationUrlPath, fileName)
-
 	return nil
 }
 
@@ -0,0 +1,34 @@
+//go:build linux
+// +build linux
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// growPipeBuffer asks the kernel for a pipe buffer of the given size, so the writer
+// can keep writing while a chunk is read. It returns the size the kernel actually set,
+// which can be smaller than requested, e.g. capped by /proc/sys/fs/pipe-max-size.
+func growPipeBuffer(f *os.File, size int) (int, error) {
+	// asking for more than the limit fails for unprivileged users
+	if data, err := os.ReadFile("/proc/sys/fs/pipe-max-size"); err == nil {
+		if maxSize, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil && size > maxSize {
+			size = maxSize
+		}
+	}
+	fd := int(f.Fd())
+	if _, err := unix.FcntlInt(uintptr(fd), unix.F_SETPIPE_SZ, size); err != nil {
+		return 0, fmt.Errorf("set pipe size %d: %v", size, err)
+	}
+	actual, err := unix.FcntlInt(uintptr(fd), unix.F_GETPIPE_SZ, 0)
+	if err != nil {
+		return 0, fmt.Errorf("get pipe size: %v", err)
+	}
+	return actual, nil
+}
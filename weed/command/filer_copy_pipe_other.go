@@ -0,0 +1,13 @@
+//go:build !linux
+// +build !linux
+
+package command
+
+import (
+	"fmt"
+	"os"
+)
+
+func growPipeBuffer(f *os.File, size int) (int, error) {
+	return 0, fmt.Errorf("pipe size is not supported on this platform")
+}
@@ -0,0 +1,266 @@
+package command
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/pb"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/security"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+	"github.com/seaweedfs/seaweedfs/weed/wdclient"
+)
+
+var (
+	filerExport FilerExportOptions
+)
+
+type FilerExportOptions struct {
+	filer    *string
+	dir      *string
+	output   *string
+	include  *string
+	exclude  *string
+	progress *bool
+
+	grpcDialOption grpc.DialOption
+	filerAddress   pb.ServerAddress
+	volumeUrls     map[string][]string
+	fileCount      int64
+	byteCount      int64
+}
+
+func init() {
+	cmdFilerExport.Run = runFilerExport // break init cycle
+	filerExport.filer = cmdFilerExport.Flag.String("filer", "localhost:8888", "filer server address")
+	filerExport.dir = cmdFilerExport.Flag.String("dir", "/", "the filer directory to export")
+	filerExport.output = cmdFilerExport.Flag.String("output", "", "the tar file to write, default to stdout")
+	filerExport.include = cmdFilerExport.Flag.String("include", "", "only export files with names matching the pattern, e.g., *.jpg")
+	filerExport.exclude = cmdFilerExport.Flag.String("exclude", "", "skip files and directories with names matching the pattern, e.g., *.tmp")
+	filerExport.progress = cmdFilerExport.Flag.Bool("progress", false, "print the exported files and bytes per second to stderr")
+}
+
+var cmdFilerExport = &Command{
+	UsageLine: "filer.export -filer=localhost:8888 -dir=/photos -output=photos.tar",
+	Short:     "export a filer directory as a tar archive",
+	Long: `export the files under a filer directory, with their data read from the volume servers, as a tar archive
+
+	weed filer.export -filer=localhost:8888 -dir=/photos -output=photos.tar
+	weed filer.export -dir=/photos -include=*.jpg -exclude=.thumbnails | tar -t
+
+  The names in the archive are relative to the exported directory.
+  The -include and -exclude patterns are matched against the file or directory name,
+  as in "filepath.Match".
+
+  `,
+}
+
+func runFilerExport(cmd *Command, args []string) bool {
+
+	util.LoadConfiguration("security", false)
+	filerExport.grpcDialOption = security.LoadClientTLS(util.GetViper(), "grpc.client")
+	filerExport.filerAddress = pb.ServerAddress(*filerExport.filer)
+	filerExport.volumeUrls = make(map[string][]string)
+
+	for _, pattern := range []string{*filerExport.include, *filerExport.exclude} {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid pattern %q: %v\n", pattern, err)
+			return false
+		}
+	}
+
+	var writer io.Writer = os.Stdout
+	if *filerExport.output != "" {
+		f, err := os.OpenFile(*filerExport.output, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "open file %s: %v\n", *filerExport.output, err)
+			return true
+		}
+		defer f.Close()
+		writer = f
+	}
+
+	if *filerExport.progress {
+		stop := make(chan struct{})
+		defer close(stop)
+		go filerExport.printProgress(stop)
+	}
+
+	startTime := time.Now()
+	tarWriter := tar.NewWriter(writer)
+	dir := util.FullPath(*filerExport.dir)
+	if dir != "/" {
+		dir = util.FullPath(strings.TrimSuffix(string(dir), "/"))
+	}
+	if err := filerExport.exportDirectory(tarWriter, dir, ""); err != nil {
+		fmt.Fprintf(os.Stderr, "export %s: %v\n", dir, err)
+		return true
+	}
+	if err := tarWriter.Close(); err != nil {
+		fmt.Fprintf(os.Stderr, "close tar archive: %v\n", err)
+		return true
+	}
+
+	if *filerExport.progress {
+		fmt.Fprintf(os.Stderr, "exported %d files, %s in %v\n",
+			atomic.LoadInt64(&filerExport.fileCount), util.BytesToHumanReadable(uint64(atomic.LoadInt64(&filerExport.byteCount))), time.Since(startTime).Round(time.Millisecond))
+	}
+	return true
+}
+
+func (exp *FilerExportOptions) exportDirectory(tarWriter *tar.Writer, dir util.FullPath, tarDir string) error {
+	return filer_pb.ReadDirAllEntries(exp, dir, "", func(entry *filer_pb.Entry, isLast bool) error {
+		if *exp.exclude != "" {
+			if matched, _ := filepath.Match(*exp.exclude, entry.Name); matched {
+				return nil
+			}
+		}
+		name := tarDir + entry.Name
+		if entry.IsDirectory {
+			if err := tarWriter.WriteHeader(exp.tarHeader(entry, name+"/", tar.TypeDir)); err != nil {
+				return err
+			}
+			return exp.exportDirectory(tarWriter, dir.Child(entry.Name), name+"/")
+		}
+		if *exp.include != "" {
+			if matched, _ := filepath.Match(*exp.include, entry.Name); !matched {
+				return nil
+			}
+		}
+		return exp.exportFile(tarWriter, entry, name)
+	})
+}
+
+func (exp *FilerExportOptions) exportFile(tarWriter *tar.Writer, entry *filer_pb.Entry, name string) error {
+	if entry.Attributes != nil && entry.Attributes.SymlinkTarget != "" {
+		header := exp.tarHeader(entry, name, tar.TypeSymlink)
+		header.Linkname = entry.Attributes.SymlinkTarget
+		return tarWriter.WriteHeader(header)
+	}
+
+	header := exp.tarHeader(entry, name, tar.TypeReg)
+	header.Size = int64(filer.FileSize(entry))
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+	writer := &exportCountingWriter{w: tarWriter, count: &exp.byteCount}
+	var err error
+	if len(entry.Content) > 0 {
+		_, err = writer.Write(entry.Content)
+	} else {
+		err = filer.StreamContent(exp, writer, entry.GetChunks(), 0, header.Size)
+	}
+	if err != nil {
+		return fmt.Errorf("read %s: %v", name, err)
+	}
+	atomic.AddInt64(&exp.fileCount, 1)
+	return nil
+}
+
+func (exp *FilerExportOptions) tarHeader(entry *filer_pb.Entry, name string, typeFlag byte) *tar.Header {
+	header := &tar.Header{
+		Typeflag: typeFlag,
+		Name:     name,
+		Mode:     0644,
+	}
+	if typeFlag == tar.TypeDir {
+		header.Mode = 0755
+	}
+	if attr := entry.Attributes; attr != nil {
+		if mode := os.FileMode(attr.FileMode).Perm(); mode != 0 {
+			header.Mode = int64(mode)
+		}
+		header.ModTime = time.Unix(attr.Mtime, 0)
+		header.Uid = int(attr.Uid)
+		header.Gid = int(attr.Gid)
+		header.Uname = attr.UserName
+		if len(attr.GroupName) > 0 {
+			header.Gname = attr.GroupName[0]
+		}
+	}
+	return header
+}
+
+func (exp *FilerExportOptions) printProgress(stop chan struct{}) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	var lastByteCount int64
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			byteCount := atomic.LoadInt64(&exp.byteCount)
+			fmt.Fprintf(os.Stderr, "exported %d files, %s, %s/s\n",
+				atomic.LoadInt64(&exp.fileCount), util.BytesToHumanReadable(uint64(byteCount)), util.BytesToHumanReadable(uint64(byteCount-lastByteCount)))
+			lastByteCount = byteCount
+		}
+	}
+}
+
+func (exp *FilerExportOptions) WithFilerClient(streamingMode bool, fn func(filer_pb.SeaweedFilerClient) error) error {
+	return pb.WithFilerClient(streamingMode, 0, exp.filerAddress, exp.grpcDialOption, fn)
+}
+
+func (exp *FilerExportOptions) AdjustedUrl(location *filer_pb.Location) string {
+	return location.Url
+}
+
+func (exp *FilerExportOptions) GetDataCenter() string {
+	return ""
+}
+
+func (exp *FilerExportOptions) GetLookupFileIdFunction() wdclient.LookupFileIdFunctionType {
+	return func(fileId string) (targetUrls []string, err error) {
+		vid := filer.VolumeId(fileId)
+		volumeUrls, found := exp.volumeUrls[vid]
+		if !found {
+			err = exp.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+				resp, err := client.LookupVolume(context.Background(), &filer_pb.LookupVolumeRequest{
+					VolumeIds: []string{vid},
+				})
+				if err != nil {
+					return err
+				}
+				if locations, found := resp.LocationsMap[vid]; found {
+					for _, loc := range locations.Locations {
+						volumeUrls = append(volumeUrls, loc.Url)
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			if len(volumeUrls) == 0 {
+				return nil, fmt.Errorf("volume %s not found", vid)
+			}
+			exp.volumeUrls[vid] = volumeUrls
+		}
+		for _, volumeUrl := range volumeUrls {
+			targetUrls = append(targetUrls, fmt.Sprintf("http://%s/%s", volumeUrl, fileId))
+		}
+		return
+	}
+}
+
+type exportCountingWriter struct {
+	w     io.Writer
+	count *int64
+}
+
+func (cw *exportCountingWriter) Write(p []byte) (n int, err error) {
+	n, err = cw.w.Write(p)
+	atomic.AddInt64(cw.count, int64(n))
+	return
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/seaweedfs/seaweedfs/weed/glog"
 	"github.com/spf13/viper"
 	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"os"
 	"reflect"
 	"strings"
 	"time"
@@ -27,6 +29,7 @@ type FilerMetaBackupOptions struct {
 	filerDirectory    *string
 	restart           *bool
 	backupFilerConfig *string
+	incremental       *string
 
 	store       filer.FilerStore
 	clientId    int32
@@ -39,11 +42,12 @@ func init() {
 	metaBackup.filerDirectory = cmdFilerMetaBackup.Flag.String("filerDir", "/", "a folder on the filer")
 	metaBackup.restart = cmdFilerMetaBackup.Flag.Bool("restart", false, "copy the full metadata before async incremental backup")
 	metaBackup.backupFilerConfig = cmdFilerMetaBackup.Flag.String("config", "", "path to filer.toml specifying backup filer store")
+	metaBackup.incremental = cmdFilerMetaBackup.Flag.String("incremental", "", "save the changes since the last incremental backup into this delta file, and exit")
 	metaBackup.clientId = util.RandomInt32()
 }
 
 var cmdFilerMetaBackup = &Command{
-	UsageLine: "filer.meta.backup [-filer=localhost:8888] [-filerDir=/] [-restart] -config=/path/to/backup_filer.toml",
+	UsageLine: "filer.meta.backup [-filer=localhost:8888] [-filerDir=/] [-restart] [-incremental=delta.meta] -config=/path/to/backup_filer.toml",
 	Short:     "continuously backup filer meta data changes to anther filer store specified in a backup_filer.toml",
 	Long: `continuously backup filer meta data changes. 
 The backup writes to another filer store specified in a backup_filer.toml.
@@ -51,6 +55,13 @@ The backup writes to another filer store specified in a backup_filer.toml.
 	weed filer.meta.backup -config=/path/to/backup_filer.toml -filer="localhost:8888"
 	weed filer.meta.backup -config=/path/to/backup_filer.toml -filer="localhost:8888" -restart
 
+With -incremental, the backup store is kept as the base snapshot, and only the changes since
+the previous incremental backup are read from the filer and saved into a delta file.
+The first run copies the full metadata into the backup store instead.
+The delta files can be applied on top of a copy of the base snapshot by "weed filer.meta.restore".
+
+	weed filer.meta.backup -config=/path/to/backup_filer.toml -filer="localhost:8888" -incremental=delta.1.meta
+
   `,
 }
 
@@ -69,13 +80,22 @@ func runFilerMetaBackup(cmd *Command, args []string) bool {
 			*metaBackup.backupFilerConfig, err, "backup_filer", "filer")
 	}
 
-	if err := metaBackup.initStore(v); err != nil {
+	store, err := loadBackupFilerStore(v)
+	if err != nil {
 		glog.V(0).Infof("init backup filer store: %v", err)
 		return true
 	}
+	metaBackup.store = store
+
+	if *metaBackup.incremental != "" {
+		if err := metaBackup.backupIncremental(*metaBackup.incremental); err != nil {
+			glog.Errorf("incremental meta backup from %s: %v", *metaBackup.filerAddress, err)
+		}
+		return true
+	}
 
 	missingPreviousBackup := false
-	_, err := metaBackup.getOffset()
+	_, err = metaBackup.getOffset()
 	if err != nil {
 		missingPreviousBackup = true
 	}
@@ -104,9 +124,8 @@ func runFilerMetaBackup(cmd *Command, args []string) bool {
 	return true
 }
 
-func (metaBackup *FilerMetaBackupOptions) initStore(v *viper.Viper) error {
+func loadBackupFilerStore(v *viper.Viper) (filer.FilerStore, error) {
 	// load configuration for default filer store
-	hasDefaultStoreConfigured := false
 	for _, store := range filer.Stores {
 		if v.GetBool(store.GetName() + ".enabled") {
 			store = reflect.New(reflect.ValueOf(store).Elem().Type()).Interface().(filer.FilerStore)
@@ -114,16 +133,10 @@ func (metaBackup *FilerMetaBackupOptions) initStore(v *viper.Viper) error {
 				glog.Fatalf("failed to initialize store for %s: %+v", store.GetName(), err)
 			}
 			glog.V(0).Infof("configured filer store to %s", store.GetName())
-			hasDefaultStoreConfigured = true
-			metaBackup.store = filer.NewFilerStoreWrapper(store)
-			break
+			return filer.NewFilerStoreWrapper(store), nil
 		}
 	}
-	if !hasDefaultStoreConfigured {
-		return fmt.Errorf("no filer store enabled in %s", v.ConfigFileUsed())
-	}
-
-	return nil
+	return nil, fmt.Errorf("no filer store enabled in %s", v.ConfigFileUsed())
 }
 
 func (metaBackup *FilerMetaBackupOptions) traverseMetadata() (err error) {
@@ -146,7 +159,8 @@ func (metaBackup *FilerMetaBackupOptions) traverseMetadata() (err error) {
 }
 
 var (
-	MetaBackupKey = []byte("metaBackup")
+	MetaBackupKey            = []byte("metaBackup")
+	MetaBackupIncrementalKey = []byte("metaBackupIncremental")
 )
 
 func (metaBackup *FilerMetaBackupOptions) streamMetadataBackup() error {
@@ -157,37 +171,8 @@ func (metaBackup *FilerMetaBackupOptions) streamMetadataBackup() error {
 	}
 	glog.V(0).Infof("streaming from %v", startTime)
 
-	store := metaBackup.store
-
 	eachEntryFunc := func(resp *filer_pb.SubscribeMetadataResponse) error {
-
-		ctx := context.Background()
-		message := resp.EventNotification
-
-		if filer_pb.IsEmpty(resp) {
-			return nil
-		} else if filer_pb.IsCreate(resp) {
-			println("+", util.FullPath(message.NewParentPath).Child(message.NewEntry.Name))
-			entry := filer.FromPbEntry(message.NewParentPath, message.NewEntry)
-			return store.InsertEntry(ctx, entry)
-		} else if filer_pb.IsDelete(resp) {
-			println("-", util.FullPath(resp.Directory).Child(message.OldEntry.Name))
-			return store.DeleteEntry(ctx, util.FullPath(resp.Directory).Child(message.OldEntry.Name))
-		} else if filer_pb.IsUpdate(resp) {
-			println("~", util.FullPath(message.NewParentPath).Child(message.NewEntry.Name))
-			entry := filer.FromPbEntry(message.NewParentPath, message.NewEntry)
-			return store.UpdateEntry(ctx, entry)
-		} else {
-			// renaming
-			println("-", util.FullPath(resp.Directory).Child(message.OldEntry.Name))
-			if err := store.DeleteEntry(ctx, util.FullPath(resp.Directory).Child(message.OldEntry.Name)); err != nil {
-				return err
-			}
-			println("+", util.FullPath(message.NewParentPath).Child(message.NewEntry.Name))
-			return store.InsertEntry(ctx, filer.FromPbEntry(message.NewParentPath, message.NewEntry))
-		}
-
-		return nil
+		return applyMetadataEvent(metaBackup.store, resp)
 	}
 
 	processEventFnWithOffset := pb.AddOffsetFunc(eachEntryFunc, 3*time.Second, func(counter int64, lastTsNs int64) error {
@@ -219,6 +204,126 @@ func (metaBackup *FilerMetaBackupOptions) streamMetadataBackup() error {
 
 }
 
+func applyMetadataEvent(store filer.FilerStore, resp *filer_pb.SubscribeMetadataResponse) error {
+
+	ctx := context.Background()
+	message := resp.EventNotification
+
+	if filer_pb.IsEmpty(resp) {
+		return nil
+	} else if filer_pb.IsCreate(resp) {
+		println("+", util.FullPath(message.NewParentPath).Child(message.NewEntry.Name))
+		entry := filer.FromPbEntry(message.NewParentPath, message.NewEntry)
+		return store.InsertEntry(ctx, entry)
+	} else if filer_pb.IsDelete(resp) {
+		println("-", util.FullPath(resp.Directory).Child(message.OldEntry.Name))
+		return store.DeleteEntry(ctx, util.FullPath(resp.Directory).Child(message.OldEntry.Name))
+	} else if filer_pb.IsUpdate(resp) {
+		println("~", util.FullPath(message.NewParentPath).Child(message.NewEntry.Name))
+		entry := filer.FromPbEntry(message.NewParentPath, message.NewEntry)
+		return store.UpdateEntry(ctx, entry)
+	} else {
+		// renaming
+		println("-", util.FullPath(resp.Directory).Child(message.OldEntry.Name))
+		if err := store.DeleteEntry(ctx, util.FullPath(resp.Directory).Child(message.OldEntry.Name)); err != nil {
+			return err
+		}
+		println("+", util.FullPath(message.NewParentPath).Child(message.NewEntry.Name))
+		return store.InsertEntry(ctx, filer.FromPbEntry(message.NewParentPath, message.NewEntry))
+	}
+}
+
+// backupIncremental saves the changes since the previous incremental backup into the delta file.
+// Without any previous backup, the full metadata is copied into the backup store as the base snapshot.
+func (metaBackup *FilerMetaBackupOptions) backupIncremental(deltaFile string) error {
+
+	startTime, err := metaBackup.getIncrementalOffset()
+	if err != nil {
+		glog.V(0).Infof("no previous backup, traversing metadata tree as the base snapshot...")
+		startTime = time.Now()
+		if err := metaBackup.traverseMetadata(); err != nil {
+			return fmt.Errorf("traverse meta data: %v", err)
+		}
+		if err := metaBackup.setOffset(startTime); err != nil {
+			return fmt.Errorf("save base snapshot offset: %v", err)
+		}
+		glog.V(0).Infof("base snapshot copied up to %v", startTime)
+		return metaBackup.setIncrementalOffset(startTime)
+	}
+
+	dst, err := os.OpenFile(deltaFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("create %s: %v", deltaFile, err)
+	}
+	defer dst.Close()
+
+	stopTime := time.Now()
+	var counter int64
+	var writeErr error
+	sizeBuf := make([]byte, 4)
+	eachEntryFunc := func(resp *filer_pb.SubscribeMetadataResponse) error {
+		if filer_pb.IsEmpty(resp) || writeErr != nil {
+			return nil
+		}
+		data, err := proto.Marshal(resp)
+		if err != nil {
+			writeErr = fmt.Errorf("marshal %+v: %v", resp, err)
+			return writeErr
+		}
+		util.Uint32toBytes(sizeBuf, uint32(len(data)))
+		if _, err = dst.Write(sizeBuf); err == nil {
+			_, err = dst.Write(data)
+		}
+		if err != nil {
+			writeErr = fmt.Errorf("write %s: %v", deltaFile, err)
+			return writeErr
+		}
+		counter++
+		return nil
+	}
+
+	prefix := *metaBackup.filerDirectory
+	if !strings.HasSuffix(prefix, "/") {
+		prefix = prefix + "/"
+	}
+	metadataFollowOption := &pb.MetadataFollowOption{
+		ClientName:     "meta_backup_incremental",
+		ClientId:       metaBackup.clientId,
+		ClientEpoch:    metaBackup.clientEpoch,
+		PathPrefix:     prefix,
+		StartTsNs:      startTime.UnixNano(),
+		StopTsNs:       stopTime.UnixNano(),
+		EventErrorType: pb.DontLogError,
+	}
+	if err = pb.FollowMetadata(pb.ServerAddress(*metaBackup.filerAddress), metaBackup.grpcDialOption, metadataFollowOption, eachEntryFunc); err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	if err = dst.Sync(); err != nil {
+		return fmt.Errorf("sync %s: %v", deltaFile, err)
+	}
+
+	glog.V(0).Infof("saved %d changes from %v to %v into %s", counter, startTime, stopTime, deltaFile)
+	return metaBackup.setIncrementalOffset(stopTime)
+}
+
+func (metaBackup *FilerMetaBackupOptions) getIncrementalOffset() (lastWriteTime time.Time, err error) {
+	value, err := metaBackup.store.KvGet(context.Background(), MetaBackupIncrementalKey)
+	if err != nil {
+		// the first incremental backup continues from the full backup
+		return metaBackup.getOffset()
+	}
+	return time.Unix(0, int64(util.BytesToUint64(value))), nil
+}
+
+func (metaBackup *FilerMetaBackupOptions) setIncrementalOffset(lastWriteTime time.Time) error {
+	valueBuf := make([]byte, 8)
+	util.Uint64toBytes(valueBuf, uint64(lastWriteTime.UnixNano()))
+	return metaBackup.store.KvPut(context.Background(), MetaBackupIncrementalKey, valueBuf)
+}
+
 func (metaBackup *FilerMetaBackupOptions) getOffset() (lastWriteTime time.Time, err error) {
 	value, err := metaBackup.store.KvGet(context.Background(), MetaBackupKey)
 	if err != nil {
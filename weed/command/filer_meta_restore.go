@@ -0,0 +1,130 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+var (
+	metaRestore FilerMetaRestoreOptions
+)
+
+type FilerMetaRestoreOptions struct {
+	backupFilerConfig *string
+	incremental       *string
+}
+
+func init() {
+	cmdFilerMetaRestore.Run = runFilerMetaRestore // break init cycle
+	metaRestore.backupFilerConfig = cmdFilerMetaRestore.Flag.String("config", "", "path to filer.toml specifying the filer store with the base snapshot")
+	metaRestore.incremental = cmdFilerMetaRestore.Flag.String("incremental", "", "comma separated delta files created by \"weed filer.meta.backup -incremental\", in the order of creation")
+}
+
+var cmdFilerMetaRestore = &Command{
+	UsageLine: "filer.meta.restore -config=/path/to/backup_filer.toml -incremental=delta.1.meta,delta.2.meta",
+	Short:     "apply incremental filer meta data backups on top of a base snapshot",
+	Long: `apply the delta files created by "weed filer.meta.backup -incremental" to a filer store.
+
+The filer store specified in the backup_filer.toml should contain the base snapshot,
+usually a copy of the backup store used by "weed filer.meta.backup".
+Changes older than the point the store was last restored or backed up to are skipped,
+so applying the same delta file twice is harmless.
+
+	weed filer.meta.restore -config=/path/to/backup_filer.toml -incremental=delta.1.meta,delta.2.meta
+
+  `,
+}
+
+func runFilerMetaRestore(cmd *Command, args []string) bool {
+
+	if *metaRestore.incremental == "" {
+		fmt.Fprintf(os.Stderr, "missing -incremental delta files\n")
+		return false
+	}
+
+	v := viper.New()
+	v.SetConfigFile(*metaRestore.backupFilerConfig)
+	if err := v.ReadInConfig(); err != nil {
+		glog.Fatalf("Failed to load %s file: %v", *metaRestore.backupFilerConfig, err)
+	}
+
+	store, err := loadBackupFilerStore(v)
+	if err != nil {
+		glog.V(0).Infof("init backup filer store: %v", err)
+		return true
+	}
+	defer store.Shutdown()
+
+	for _, deltaFile := range strings.Split(*metaRestore.incremental, ",") {
+		if err := restoreMetadataDelta(store, deltaFile); err != nil {
+			glog.Errorf("restore %s: %v", deltaFile, err)
+			return true
+		}
+	}
+
+	return true
+}
+
+func restoreMetadataDelta(store filer.FilerStore, deltaFile string) error {
+
+	var restoredTsNs int64
+	if value, err := store.KvGet(context.Background(), MetaBackupKey); err == nil {
+		restoredTsNs = int64(util.BytesToUint64(value))
+	}
+
+	src, err := os.Open(deltaFile)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	reader := bufio.NewReader(src)
+
+	var applied, skipped int64
+	sizeBuf := make([]byte, 4)
+	for {
+		if _, err = io.ReadFull(reader, sizeBuf); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return fmt.Errorf("read size: %v", err)
+		}
+		data := make([]byte, util.BytesToUint32(sizeBuf))
+		if _, err = io.ReadFull(reader, data); err != nil {
+			return fmt.Errorf("read event: %v", err)
+		}
+		resp := &filer_pb.SubscribeMetadataResponse{}
+		if err = proto.Unmarshal(data, resp); err != nil {
+			return fmt.Errorf("unmarshal event: %v", err)
+		}
+		if resp.TsNs <= restoredTsNs {
+			skipped++
+			continue
+		}
+		if err = applyMetadataEvent(store, resp); err != nil {
+			return fmt.Errorf("apply %+v: %v", resp, err)
+		}
+		restoredTsNs = resp.TsNs
+		applied++
+	}
+
+	valueBuf := make([]byte, 8)
+	util.Uint64toBytes(valueBuf, uint64(restoredTsNs))
+	if err = store.KvPut(context.Background(), MetaBackupKey, valueBuf); err != nil {
+		return fmt.Errorf("save restored offset: %v", err)
+	}
+
+	glog.V(0).Infof("applied %d changes from %s, skipped %d older changes", applied, deltaFile, skipped)
+	return nil
+}
@@ -0,0 +1,60 @@
+package command
+
+import (
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb"
+	"github.com/seaweedfs/seaweedfs/weed/replication"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+// startFilerMirror replicates all metadata changes and new file chunks of the source filer to the target filer,
+// the same as a one directional "weed filer.sync". Two filers mirroring to each other are active-active,
+// and conflicting changes are resolved by the last write.
+func startFilerMirror(grpcDialOption grpc.DialOption, sourceFiler, targetFiler pb.ServerAddress) {
+
+	var sourceFilerSignature, targetFilerSignature int32
+	for {
+		var sourceErr, targetErr error
+		sourceFilerSignature, sourceErr = replication.ReadFilerSignature(grpcDialOption, sourceFiler)
+		targetFilerSignature, targetErr = replication.ReadFilerSignature(grpcDialOption, targetFiler)
+		if sourceErr == nil && targetErr == nil {
+			break
+		}
+		glog.Warningf("mirror %s to %s: read filer signatures: %v, %v", sourceFiler, targetFiler, sourceErr, targetErr)
+		time.Sleep(1747 * time.Millisecond)
+	}
+
+	clientId := util.RandomInt32()
+	var clientEpoch atomic.Int32
+	for {
+		err := doSubscribeFilerMetaChanges(
+			clientId,
+			clientEpoch.Add(1),
+			grpcDialOption,
+			sourceFiler,
+			"/",
+			nil,
+			false,
+			targetFiler,
+			"/",
+			"",
+			"",
+			0,
+			false,
+			"",
+			false,
+			DefaultConcurrencyLimit,
+			true,
+			sourceFilerSignature,
+			targetFilerSignature)
+		if err != nil {
+			glog.Errorf("mirror %s to %s: %v", sourceFiler, targetFiler, err)
+			time.Sleep(1747 * time.Millisecond)
+		}
+	}
+}
@@ -145,6 +145,19 @@ func runFuse(cmd *Command, args []string) bool {
 			} else {
 				panic(fmt.Errorf("concurrentWriters: %s", err))
 			}
+		case "writeRetryCount":
+			if parsed, err := strconv.ParseInt(parameter.value, 0, 32); err == nil {
+				intValue := int(parsed)
+				mountOptions.writeRetryCount = &intValue
+			} else {
+				panic(fmt.Errorf("writeRetryCount: %s", err))
+			}
+		case "writeRetryDelay":
+			if parsed, err := time.ParseDuration(parameter.value); err == nil {
+				mountOptions.writeRetryDelay = &parsed
+			} else {
+				panic(fmt.Errorf("writeRetryDelay: %s", err))
+			}
 		case "cacheDir":
 			mountOptions.cacheDirForRead = &parameter.value
 		case "cacheCapacityMB":
@@ -2,6 +2,7 @@ package command
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"os"
@@ -58,6 +59,10 @@ type MasterOptions struct {
 	electionTimeout    *time.Duration
 	raftHashicorp      *bool
 	raftBootstrap      *bool
+	electionBackend    *string
+	etcdEndpoints      *string
+	etcdKeyPrefix      *string
+	topologyConfig     *string
 }
 
 func init() {
@@ -84,6 +89,10 @@ func init() {
 	m.electionTimeout = cmdMaster.Flag.Duration("electionTimeout", 10*time.Second, "election timeout of master servers")
 	m.raftHashicorp = cmdMaster.Flag.Bool("raftHashicorp", false, "use hashicorp raft")
 	m.raftBootstrap = cmdMaster.Flag.Bool("raftBootstrap", false, "Whether to bootstrap the Raft cluster")
+	m.electionBackend = cmdMaster.Flag.String("electionBackend", "raft", "leader election backend, raft or etcd")
+	m.etcdEndpoints = cmdMaster.Flag.String("etcd.endpoints", "localhost:2379", "comma separated etcd endpoints, for -electionBackend=etcd")
+	m.etcdKeyPrefix = cmdMaster.Flag.String("etcd.keyPrefix", "/seaweedfs/master", "etcd key prefix of the leader election and max volume id, for -electionBackend=etcd")
+	m.topologyConfig = cmdMaster.Flag.String("topology", "", "path to topology.toml assigning volume servers to data centers and racks, reloaded on SIGHUP")
 }
 
 var cmdMaster = &Command{
@@ -172,14 +181,24 @@ func startMaster(masterOption MasterOptions, masterWhiteList []string) {
 		ServerAddr:        myMasterAddress,
 		DataDir:           util.ResolvePath(metaDir),
 		Topo:              ms.Topo,
+		Cluster:           ms.Cluster,
 		RaftResumeState:   *masterOption.raftResumeState,
 		HeartbeatInterval: *masterOption.heartbeatInterval,
 		ElectionTimeout:   *masterOption.electionTimeout,
 		RaftBootstrap:     *masterOption.raftBootstrap,
+		EtcdEndpoints:     util.StringSplit(*masterOption.etcdEndpoints, ","),
+		EtcdKeyPrefix:     *masterOption.etcdKeyPrefix,
 	}
 	var raftServer *weed_server.RaftServer
 	var err error
-	if *masterOption.raftHashicorp {
+	isEtcdElection := *masterOption.electionBackend == "etcd"
+	if isEtcdElection {
+		if raftServer, err = weed_server.NewEtcdRaftServer(raftServerOption); err != nil {
+			glog.Fatalf("NewEtcdRaftServer: %s", err)
+		}
+	} else if *masterOption.electionBackend != "raft" {
+		glog.Fatalf("unknown -electionBackend %s, expecting raft or etcd", *masterOption.electionBackend)
+	} else if *masterOption.raftHashicorp {
 		if raftServer, err = weed_server.NewHashicorpRaftServer(raftServerOption); err != nil {
 			glog.Fatalf("NewHashicorpRaftServer: %s", err)
 		}
@@ -203,7 +222,9 @@ func startMaster(masterOption MasterOptions, masterWhiteList []string) {
 	}
 	grpcS := pb.NewGrpcServer(security.LoadServerTLS(util.GetViper(), "grpc.master"))
 	master_pb.RegisterSeaweedServer(grpcS, ms)
-	if *masterOption.raftHashicorp {
+	if isEtcdElection {
+		// no raft rpc between masters
+	} else if *masterOption.raftHashicorp {
 		raftServer.TransportManager.Register(grpcS)
 	} else {
 		protobuf.RegisterRaftServer(grpcS, raftServer)
@@ -214,9 +235,10 @@ func startMaster(masterOption MasterOptions, masterWhiteList []string) {
 		go grpcS.Serve(grpcLocalL)
 	}
 	go grpcS.Serve(grpcL)
+	pb.ServeInProcess(grpcS, util.JoinHostPort(*masterOption.ip, grpcPort))
 
 	timeSleep := 1500 * time.Millisecond
-	if !*masterOption.raftHashicorp {
+	if !*masterOption.raftHashicorp && !isEtcdElection {
 		go func() {
 			time.Sleep(timeSleep)
 
@@ -252,6 +274,15 @@ func startMaster(masterOption MasterOptions, masterWhiteList []string) {
 	}
 
 	httpS := &http.Server{Handler: r}
+	// the certificates of -tls-cert and -tls-key take precedence over [https.master] in security.toml
+	if tlsConfig := security.LoadServerTLSHTTP(); tlsConfig != nil {
+		httpS.TLSConfig = tlsConfig
+		useTLS, useMTLS = true, false
+		certFile, keyFile = "", ""
+		if masterLocalListener != nil {
+			masterLocalListener = tls.NewListener(masterLocalListener, tlsConfig)
+		}
+	}
 	if masterLocalListener != nil {
 		go httpS.Serve(masterLocalListener)
 	}
@@ -268,6 +299,7 @@ func startMaster(masterOption MasterOptions, masterWhiteList []string) {
 
 	grace.OnInterrupt(ms.Shutdown)
 	grace.OnInterrupt(grpcS.Stop)
+	grace.OnReload(ms.ReloadTopologyConfig)
 	grace.OnReload(func() {
 		if ms.Topo.HashicorpRaft != nil && ms.Topo.HashicorpRaft.State() == hashicorpRaft.Leader {
 			ms.Topo.HashicorpRaft.LeadershipTransfer()
@@ -322,5 +354,6 @@ func (m *MasterOptions) toMasterOption(whiteList []string) *weed_server.MasterOp
 		DisableHttp:             *m.disableHttp,
 		MetricsAddress:          *m.metricsAddress,
 		MetricsIntervalSec:      *m.metricsIntervalSec,
+		TopologyConfig:          *m.topologyConfig,
 	}
 }
@@ -2,6 +2,7 @@ package command
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"time"
@@ -144,6 +145,12 @@ func startMasterFollower(masterOptions MasterOptions) {
 
 	// start http server
 	httpS := &http.Server{Handler: r}
+	if tlsConfig := security.LoadServerTLSHTTP(); tlsConfig != nil {
+		if masterLocalListener != nil {
+			masterLocalListener = tls.NewListener(masterLocalListener, tlsConfig)
+		}
+		masterListener = tls.NewListener(masterListener, tlsConfig)
+	}
 	if masterLocalListener != nil {
 		go httpS.Serve(masterLocalListener)
 	}
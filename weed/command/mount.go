@@ -17,6 +17,8 @@ type MountOptions struct {
 	ttlSec             *int
 	chunkSizeLimitMB   *int
 	concurrentWriters  *int
+	writeRetryCount    *int
+	writeRetryDelay    *time.Duration
 	cacheDirForRead    *string
 	cacheDirForWrite   *string
 	cacheSizeMBForRead *int64
@@ -32,6 +34,8 @@ type MountOptions struct {
 	debugPort          *int
 	localSocket        *string
 	disableXAttr       *bool
+	attrCacheTTL       *time.Duration
+	entryCacheTTL      *time.Duration
 	extraOptions       []string
 }
 
@@ -55,6 +59,8 @@ func init() {
 	mountOptions.ttlSec = cmdMount.Flag.Int("ttl", 0, "file ttl in seconds")
 	mountOptions.chunkSizeLimitMB = cmdMount.Flag.Int("chunkSizeLimitMB", 2, "local write buffer size, also chunk large files")
 	mountOptions.concurrentWriters = cmdMount.Flag.Int("concurrentWriters", 32, "limit concurrent goroutine writers")
+	mountOptions.writeRetryCount = cmdMount.Flag.Int("writeRetryCount", 3, "retry uploading flushed data this many times on volume server errors")
+	mountOptions.writeRetryDelay = cmdMount.Flag.Duration("writeRetryDelay", 500*time.Millisecond, "wait time before the first write retry, doubled on each retry")
 	mountOptions.cacheDirForRead = cmdMount.Flag.String("cacheDir", os.TempDir(), "local cache directory for file chunks and meta data")
 	mountOptions.cacheSizeMBForRead = cmdMount.Flag.Int64("cacheCapacityMB", 0, "file chunk read cache capacity in MB")
 	mountOptions.cacheDirForWrite = cmdMount.Flag.String("cacheDirWrite", os.TempDir(), "buffer writes mostly for large files")
@@ -70,6 +76,8 @@ func init() {
 	mountOptions.debugPort = cmdMount.Flag.Int("debug.port", 6061, "http port for debugging")
 	mountOptions.localSocket = cmdMount.Flag.String("localSocket", "", "default to /tmp/seaweedfs-mount-<mount_dir_hash>.sock")
 	mountOptions.disableXAttr = cmdMount.Flag.Bool("disableXAttr", false, "disable xattr")
+	mountOptions.attrCacheTTL = cmdMount.Flag.Duration("attrCacheTTL", time.Second, "how long the kernel caches file attributes. Longer values save getattr calls, but changes from other clients may show up later")
+	mountOptions.entryCacheTTL = cmdMount.Flag.Duration("entryCacheTTL", time.Second, "how long the kernel and the mount metadata cache keep file name lookups. Longer values save lookup calls, but changes from other clients may show up later")
 
 	mountCpuProfile = cmdMount.Flag.String("cpuprofile", "", "cpu profile output file")
 	mountMemProfile = cmdMount.Flag.String("memprofile", "", "memory profile output file")
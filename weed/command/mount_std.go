@@ -227,6 +227,8 @@ func RunMount(option *MountOptions, umask os.FileMode) bool {
 		DiskType:           types.ToDiskType(*option.diskType),
 		ChunkSizeLimit:     int64(chunkSizeLimitMB) * 1024 * 1024,
 		ConcurrentWriters:  *option.concurrentWriters,
+		WriteRetryCount:    *option.writeRetryCount,
+		WriteRetryDelay:    *option.writeRetryDelay,
 		CacheDirForRead:    *option.cacheDirForRead,
 		CacheSizeMBForRead: *option.cacheSizeMBForRead,
 		CacheDirForWrite:   *option.cacheDirForWrite,
@@ -242,6 +244,8 @@ func RunMount(option *MountOptions, umask os.FileMode) bool {
 		Cipher:             cipher,
 		UidGidMapper:       uidGidMapper,
 		DisableXAttr:       *option.disableXAttr,
+		AttrCacheTTL:       *option.attrCacheTTL,
+		EntryCacheTTL:      *option.entryCacheTTL,
 	})
 
 	// create mount root
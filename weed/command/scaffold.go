@@ -13,7 +13,7 @@ func init() {
 }
 
 var cmdScaffold = &Command{
-	UsageLine: "scaffold -config=[filer|notification|replication|security|master]",
+	UsageLine: "scaffold -config=[filer|notification|replication|security|master|compose]",
 	Short:     "generate basic configuration files",
 	Long: `Generate filer.toml with all possible configurations for you to customize.
 
@@ -25,12 +25,18 @@ var cmdScaffold = &Command{
 		* Uppercase the rest of the variable name.
 		* Replace '.' with '_'.
 
+	Generate a docker-compose.yml for a replication policy, e.g. "020" with 3 racks in one data center.
+	It has 1 master, or 3 masters if the data is replicated,
+	and enough volume servers on different data centers and racks for the replication.
+		weed scaffold -config=compose -replication=020 -output=.
+
   `,
 }
 
 var (
-	outputPath = cmdScaffold.Flag.String("output", "", "if not empty, save the configuration file to this directory")
-	config     = cmdScaffold.Flag.String("config", "filer", "[filer|notification|replication|security|master] the configuration file to generate")
+	outputPath          = cmdScaffold.Flag.String("output", "", "if not empty, save the configuration file to this directory")
+	config              = cmdScaffold.Flag.String("config", "filer", "[filer|notification|replication|security|master|compose] the configuration file to generate")
+	scaffoldReplication = cmdScaffold.Flag.String("replication", "000", "replication policy of the generated docker-compose.yml, only for -config=compose")
 )
 
 func runScaffold(cmd *Command, args []string) bool {
@@ -49,6 +55,12 @@ func runScaffold(cmd *Command, args []string) bool {
 		content = scaffold.Master
 	case "shell":
 		content = scaffold.Shell
+	case "compose":
+		var err error
+		if content, err = generateCompose(*scaffoldReplication); err != nil {
+			println(err.Error())
+			return false
+		}
 	}
 	if content == "" {
 		println("need a valid -config option")
@@ -56,7 +68,11 @@ func runScaffold(cmd *Command, args []string) bool {
 	}
 
 	if *outputPath != "" {
-		util.WriteFile(filepath.Join(*outputPath, *config+".toml"), []byte(content), 0644)
+		fileName := *config + ".toml"
+		if *config == "compose" {
+			fileName = "docker-compose.yml"
+		}
+		util.WriteFile(filepath.Join(*outputPath, fileName), []byte(content), 0644)
 	} else {
 		fmt.Println(content)
 	}
@@ -0,0 +1,80 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/storage/super_block"
+)
+
+const scaffoldComposeImage = "chrislusf/seaweedfs"
+
+// generateCompose creates a docker-compose.yml with enough data centers, racks and volume servers
+// for the replication, so that any data center can hold the first copy of a volume.
+func generateCompose(replication string) (string, error) {
+	rp, err := super_block.NewReplicaPlacementFromString(replication)
+	if err != nil {
+		return "", err
+	}
+	replication = rp.String()
+
+	masterCount := 1
+	if rp.GetCopyCount() > 1 {
+		// an odd number of masters to keep a raft quorum
+		masterCount = 3
+	}
+	var masters, masterServices []string
+	for i := 0; i < masterCount; i++ {
+		masters = append(masters, fmt.Sprintf("master%d:%d", i, 9333+i))
+		masterServices = append(masterServices, fmt.Sprintf("master%d", i))
+	}
+	mserver := strings.Join(masters, ",")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# generated by \"weed scaffold -config=compose -replication=%s\"\n", replication)
+	fmt.Fprintf(&b, "# %d data center(s), %d rack(s) per data center, %d volume server(s) per rack\n",
+		rp.DiffDataCenterCount+1, rp.DiffRackCount+1, rp.SameRackCount+1)
+	b.WriteString("version: '3.9'\n\nservices:\n")
+
+	for i := 0; i < masterCount; i++ {
+		port := 9333 + i
+		fmt.Fprintf(&b, "  master%d:\n", i)
+		fmt.Fprintf(&b, "    image: %s\n", scaffoldComposeImage)
+		fmt.Fprintf(&b, "    ports:\n      - %d:%d\n      - %d:%d\n", port, port, port+10000, port+10000)
+		fmt.Fprintf(&b, "    command: 'master -ip=master%d -ip.bind=0.0.0.0 -port=%d -peers=%s -defaultReplication=%s -mdir=/data'\n",
+			i, port, mserver, replication)
+	}
+
+	var volumeServices []string
+	port := 8080
+	for dc := 1; dc <= rp.DiffDataCenterCount+1; dc++ {
+		for rack := 1; rack <= rp.DiffRackCount+1; rack++ {
+			for server := 1; server <= rp.SameRackCount+1; server++ {
+				name := fmt.Sprintf("volume%d", len(volumeServices)+1)
+				volumeServices = append(volumeServices, name)
+				fmt.Fprintf(&b, "  %s:\n", name)
+				fmt.Fprintf(&b, "    image: %s\n", scaffoldComposeImage)
+				fmt.Fprintf(&b, "    ports:\n      - %d:%d\n      - %d:%d\n", port, port, port+10000, port+10000)
+				fmt.Fprintf(&b, "    command: 'volume -dataCenter=dc%d -rack=rack%d -mserver=\"%s\" -ip=%s -ip.bind=0.0.0.0 -port=%d -publicUrl=localhost:%d -dir=/data'\n",
+					dc, rack, mserver, name, port, port)
+				writeComposeDependsOn(&b, masterServices)
+				port++
+			}
+		}
+	}
+
+	b.WriteString("  filer:\n")
+	fmt.Fprintf(&b, "    image: %s\n", scaffoldComposeImage)
+	b.WriteString("    ports:\n      - 8888:8888\n      - 18888:18888\n")
+	fmt.Fprintf(&b, "    command: 'filer -master=\"%s\" -ip=filer -ip.bind=0.0.0.0 -defaultReplicaPlacement=%s'\n", mserver, replication)
+	writeComposeDependsOn(&b, append(masterServices, volumeServices...))
+
+	return b.String(), nil
+}
+
+func writeComposeDependsOn(b *strings.Builder, services []string) {
+	b.WriteString("    depends_on:\n")
+	for _, service := range services {
+		fmt.Fprintf(b, "      - %s\n", service)
+	}
+}
@@ -3,6 +3,7 @@ package command
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/spf13/viper"
@@ -42,3 +43,26 @@ enabled = true
 
 	fmt.Printf("alpha ip is %v\n", alpha.GetString("ip"))
 }
+
+func TestGenerateCompose(t *testing.T) {
+	content, err := generateCompose("110")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"-peers=master0:9333,master1:9334,master2:9335",
+		"-dataCenter=dc2 -rack=rack2",
+		"-defaultReplicaPlacement=110",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("missing %q in:\n%s", expected, content)
+		}
+	}
+	if count := strings.Count(content, "command: 'volume "); count != 4 {
+		t.Errorf("expected 4 volume servers, got %d", count)
+	}
+
+	if _, err = generateCompose("030"); err == nil {
+		t.Errorf("expected error for replication 030")
+	}
+}
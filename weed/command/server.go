@@ -50,6 +50,10 @@ var cmdServer = &Command{
   Optionally, a filer server can be started.
   Also optionally, a S3 gateway can be started.
 
+  With -allInOne, the grpc calls between the servers started here, e.g. heartbeats,
+  volume assignments and metadata subscriptions, go through in-memory connections.
+  The servers still listen on their ports for other clients.
+
   `,
 }
 
@@ -76,6 +80,7 @@ var (
 	isStartingIam          = cmdServer.Flag.Bool("iam", false, "whether to start IAM service")
 	isStartingWebDav       = cmdServer.Flag.Bool("webdav", false, "whether to start WebDAV gateway")
 	isStartingMqBroker     = cmdServer.Flag.Bool("mq.broker", false, "whether to start message queue broker")
+	serverAllInOne         = cmdServer.Flag.Bool("allInOne", false, "connect the master, volume server and filer of this process with in-memory grpc connections, instead of through the network")
 
 	False = false
 )
@@ -99,8 +104,12 @@ func init() {
 	masterOptions.raftResumeState = cmdServer.Flag.Bool("master.resumeState", false, "resume previous state on start master server")
 	masterOptions.raftHashicorp = cmdServer.Flag.Bool("master.raftHashicorp", false, "use hashicorp raft")
 	masterOptions.raftBootstrap = cmdServer.Flag.Bool("master.raftBootstrap", false, "Whether to bootstrap the Raft cluster")
+	masterOptions.electionBackend = cmdServer.Flag.String("master.electionBackend", "raft", "leader election backend, raft or etcd")
+	masterOptions.etcdEndpoints = cmdServer.Flag.String("master.etcd.endpoints", "localhost:2379", "comma separated etcd endpoints, for -master.electionBackend=etcd")
+	masterOptions.etcdKeyPrefix = cmdServer.Flag.String("master.etcd.keyPrefix", "/seaweedfs/master", "etcd key prefix of the leader election and max volume id, for -master.electionBackend=etcd")
 	masterOptions.heartbeatInterval = cmdServer.Flag.Duration("master.heartbeatInterval", 300*time.Millisecond, "heartbeat interval of master servers, and will be randomly multiplied by [1, 1.25)")
 	masterOptions.electionTimeout = cmdServer.Flag.Duration("master.electionTimeout", 10*time.Second, "election timeout of master servers")
+	masterOptions.topologyConfig = cmdServer.Flag.String("master.topology", "", "path to topology.toml assigning volume servers to data centers and racks, reloaded on SIGHUP")
 
 	filerOptions.filerGroup = cmdServer.Flag.String("filer.filerGroup", "", "share metadata with other filers in the same filerGroup")
 	filerOptions.collection = cmdServer.Flag.String("filer.collection", "", "all data will be stored in this collection")
@@ -118,7 +127,23 @@ func init() {
 	filerOptions.localSocket = cmdServer.Flag.String("filer.localSocket", "", "default to /tmp/seaweedfs-filer-<port>.sock")
 	filerOptions.showUIDirectoryDelete = cmdServer.Flag.Bool("filer.ui.deleteDir", true, "enable filer UI show delete directory button")
 	filerOptions.downloadMaxMBps = cmdServer.Flag.Int("filer.downloadMaxMBps", 0, "download max speed for each download request, in MB per second")
+	filerOptions.uploadMaxMB = cmdServer.Flag.Int("filer.uploadMaxMB", 0, "reject http uploads larger than this limit in MB, 0 for no limit")
 	filerOptions.diskType = cmdServer.Flag.String("filer.disk", "", "[hdd|ssd|<tag>] hard drive or solid state drive or any tag")
+	filerOptions.readCacheDir = cmdServer.Flag.String("filer.readCacheDir", "", "local directory, preferably on an ssd, to cache the file chunks read from volume servers")
+	filerOptions.readCacheSizeMB = cmdServer.Flag.Int64("filer.readCacheSizeMB", 0, "read cache size in MB, evicting the least recently read chunks")
+	filerOptions.rateLimitRPM = cmdServer.Flag.Int("filer.rateLimit.rpm", 0, "limit http requests per minute from each client ip, 0 for no limit")
+	filerOptions.rateLimitBurst = cmdServer.Flag.Int("filer.rateLimit.burst", 500, "max http requests from each client ip in a burst, with -filer.rateLimit.rpm")
+	filerOptions.rateLimitWhiteList = cmdServer.Flag.String("filer.rateLimit.whiteList", "", "comma separated ip addresses or CIDR ranges of the s3 gateways, mounts and other trusted clients. They are not rate limited, but the clients they forward in X-Forwarded-For are.")
+	filerOptions.casDedupDir = cmdServer.Flag.String("filer.casDedupDir", "", "local directory for the index to deduplicate the uploaded chunks by their sha256. Only for a single filer, since other filers would not see the shared chunks.")
+	filerOptions.wormAdminWhiteList = cmdServer.Flag.String("filer.worm.adminWhiteList", "", "comma separated ip addresses or CIDRs allowed to bypass the WORM retention by http requests with bypassWorm=true")
+	filerOptions.recompressOnRead = cmdServer.Flag.Bool("filer.recompressOnRead", false, "migrate the gzip chunks of the files to zstd in the background after the files are read")
+	filerOptions.auditLog = cmdServer.Flag.String("filer.auditLog", "", "file to log each http request as a json line, for audit")
+	filerOptions.auditLogMaxMB = cmdServer.Flag.Int("filer.auditLogMaxMB", 100, "rotate the audit log file after this size, keeping 5 rotated files")
+	filerOptions.gcInterval = cmdServer.Flag.Duration("filer.gc.interval", 0, "delete the chunks no entry refers to at this interval, 0 to disable. Not for volumes also written without this filer.")
+	filerOptions.gcGracePeriod = cmdServer.Flag.Duration("filer.gc.gracePeriod", 24*time.Hour, "only delete the unreferenced chunks written before this long ago")
+	filerOptions.gcRateLimit = cmdServer.Flag.Int("filer.gc.rateLimit", 100, "max chunks checked or deleted per second by the gc, 0 for no limit")
+	filerOptions.quotaRecountInterval = cmdServer.Flag.Duration("filer.quota.recountInterval", time.Hour, "recount the usage of the collections with quotas from all their files at this interval, 0 to disable. The usage is counted on each write and delete in between.")
+	filerOptions.detectContentType = cmdServer.Flag.Bool("filer.detectContentType", false, "detect the content type of the uploads without one, from the first 512 bytes or the file extension")
 	filerOptions.exposeDirectoryData = cmdServer.Flag.Bool("filer.exposeDirectoryData", true, "expose directory data via filer. If false, filer UI will be innaccessible.")
 
 	serverOptions.v.port = cmdServer.Flag.Int("volume.port", 8080, "volume server http listen port")
@@ -126,10 +151,20 @@ func init() {
 	serverOptions.v.publicPort = cmdServer.Flag.Int("volume.port.public", 0, "volume server public port")
 	serverOptions.v.indexType = cmdServer.Flag.String("volume.index", "memory", "Choose [memory|leveldb|leveldbMedium|leveldbLarge] mode for memory~performance balance.")
 	serverOptions.v.diskType = cmdServer.Flag.String("volume.disk", "", "[hdd|ssd|<tag>] hard drive or solid state drive or any tag")
+	serverOptions.v.tags = cmdServer.Flag.String("volume.tags", "", "comma separated key=value tags, assign requests with tags only write to volumes on matching volume servers")
 	serverOptions.v.fixJpgOrientation = cmdServer.Flag.Bool("volume.images.fix.orientation", false, "Adjust jpg orientation when uploading.")
 	serverOptions.v.readMode = cmdServer.Flag.String("volume.readMode", "proxy", "[local|proxy|redirect] how to deal with non-local volume: 'not found|read in remote node|redirect volume location'.")
 	serverOptions.v.compactionMBPerSecond = cmdServer.Flag.Int("volume.compactionMBps", 0, "limit compaction speed in mega bytes per second")
+	serverOptions.v.compression = cmdServer.Flag.String("volume.compression", "gzip", "[gzip|zstd] how to compress the compressible uploads which are not compressed by the clients, unless the upload asks for another one with ?compression=zstd&compressionLevel=19")
+	serverOptions.v.compressionLevel = cmdServer.Flag.Int("volume.compression.level", 0, "zstd compression level from 1 to 22, 0 for the zstd default")
+	serverOptions.v.compressionLevels = cmdServer.Flag.String("volume.compression.collectionLevels", "", "comma separated zstd compression levels by collection, like realtime:1,archive:19")
 	serverOptions.v.fileSizeLimitMB = cmdServer.Flag.Int("volume.fileSizeLimitMB", 256, "limit file size to avoid out of memory")
+	serverOptions.v.autoCompaction = cmdServer.Flag.Bool("volume.compaction.auto", false, "compact volumes on the volume server, while the master vacuum is disabled with volume.vacuum.disable")
+	serverOptions.v.compactionGarbage = cmdServer.Flag.Float64("volume.compaction.garbageThreshold", 0.3, "auto compact volumes with more than this ratio of garbage")
+	serverOptions.v.compactionIdle = cmdServer.Flag.Duration("volume.compaction.idle", 5*time.Minute, "auto compact volumes without any writes or deletes for this long")
+	serverOptions.v.readCacheSizeMB = cmdServer.Flag.Int("volume.readCache.sizeMB", 0, "cache recently read small files in memory, up to this size in total")
+	serverOptions.v.readCacheMaxFileSizeKB = cmdServer.Flag.Int("volume.readCache.maxFileSizeKB", 64, "only cache files up to this size in the read cache")
+	serverOptions.v.maxVolumeSize = cmdServer.Flag.String("volume.maxVolumeSize", "", "stop writing to volumes over this size, like 1GiB, if smaller than the volume size limit of the master")
 	serverOptions.v.ldbTimeout = cmdServer.Flag.Int64("volume.index.leveldbTimeout", 0, "alive time for leveldb (default to 0). If leveldb of volume is not accessed in ldbTimeout hours, it will be off loaded to reduce opened files and memory consumption.")
 	serverOptions.v.concurrentUploadLimitMB = cmdServer.Flag.Int("volume.concurrentUploadLimitMB", 64, "limit total concurrent upload size")
 	serverOptions.v.concurrentDownloadLimitMB = cmdServer.Flag.Int("volume.concurrentDownloadLimitMB", 64, "limit total concurrent download size")
@@ -139,6 +174,8 @@ func init() {
 	serverOptions.v.idxFolder = cmdServer.Flag.String("volume.dir.idx", "", "directory to store .idx files")
 	serverOptions.v.inflightUploadDataTimeout = cmdServer.Flag.Duration("volume.inflightUploadDataTimeout", 60*time.Second, "inflight upload data wait timeout of volume servers")
 	serverOptions.v.hasSlowRead = cmdServer.Flag.Bool("volume.hasSlowRead", true, "<experimental> if true, this prevents slow reads from blocking other requests, but large file read P99 latency will increase.")
+	serverOptions.v.tierBackend = cmdServer.Flag.String("volume.tier.backend", "", "move the unreplicated volumes without writes for -volume.tier.quietFor to this storage backend configured on the master, like s3.default, and move them back on writes")
+	serverOptions.v.tierQuietFor = cmdServer.Flag.Duration("volume.tier.quietFor", 24*time.Hour, "move volumes to -volume.tier.backend without any writes or deletes for this long")
 	serverOptions.v.readBufferSizeMB = cmdServer.Flag.Int("volume.readBufferSizeMB", 4, "<experimental> larger values can optimize query performance but will increase some memory usage,Use with hasSlowRead normally")
 
 	s3Options.port = cmdServer.Flag.Int("s3.port", 8333, "s3 server http listen port")
@@ -154,6 +191,7 @@ func init() {
 	s3Options.auditLogConfig = cmdServer.Flag.String("s3.auditLogConfig", "", "path to the audit log config file")
 	s3Options.allowEmptyFolder = cmdServer.Flag.Bool("s3.allowEmptyFolder", true, "allow empty folders")
 	s3Options.allowDeleteBucketNotEmpty = cmdServer.Flag.Bool("s3.allowDeleteBucketNotEmpty", true, "allow recursive deleting all entries along with bucket")
+	s3Options.chunkSizeMB = cmdServer.Flag.Int("s3.chunkSizeMB", 0, "split uploaded objects into chunks of this size, each uploaded to a volume server as soon as it is received. If 0, default to the -maxMB of the filer.")
 	s3Options.localSocket = cmdServer.Flag.String("s3.localSocket", "", "default to /tmp/seaweedfs-s3-<port>.sock")
 
 	iamOptions.port = cmdServer.Flag.Int("iam.port", 8111, "iam server http listen port")
@@ -168,6 +206,7 @@ func init() {
 	webdavOptions.cacheSizeMB = cmdServer.Flag.Int64("webdav.cacheCapacityMB", 0, "local cache capacity in MB")
 	webdavOptions.maxMB = cmdServer.Flag.Int("webdav.maxMB", 4, "split files larger than the limit")
 	webdavOptions.filerRootPath = cmdServer.Flag.String("webdav.filer.path", "/", "use this remote path from filer server")
+	webdavOptions.lockTimeout = cmdServer.Flag.Duration("webdav.lockTimeout", time.Hour, "the longest timeout of LOCK, also for locks asking for an infinite timeout")
 
 	mqBrokerOptions.port = cmdServer.Flag.Int("mq.broker.port", 17777, "message queue broker gRPC listen port")
 
@@ -184,6 +223,10 @@ func runServer(cmd *Command, args []string) bool {
 
 	grace.SetupProfiling(*serverOptions.cpuprofile, *serverOptions.memprofile)
 
+	if *serverAllInOne {
+		pb.EnableInProcessGrpc()
+	}
+
 	if *isStartingS3 {
 		*isStartingFiler = true
 	}
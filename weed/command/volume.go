@@ -1,6 +1,7 @@
 package command
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	httppprof "net/http/pprof"
@@ -10,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
 	"github.com/seaweedfs/seaweedfs/weed/storage/types"
 
 	"github.com/spf13/viper"
@@ -51,6 +53,7 @@ type VolumeServerOptions struct {
 	idleConnectionTimeout     *int
 	dataCenter                *string
 	rack                      *string
+	tags                      *string
 	whiteList                 []string
 	indexType                 *string
 	diskType                  *string
@@ -71,6 +74,17 @@ type VolumeServerOptions struct {
 	hasSlowRead               *bool
 	readBufferSizeMB          *int
 	ldbTimeout                *int64
+	autoCompaction            *bool
+	compactionGarbage         *float64
+	compactionIdle            *time.Duration
+	readCacheSizeMB           *int
+	readCacheMaxFileSizeKB    *int
+	maxVolumeSize             *string
+	compression               *string
+	compressionLevel          *int
+	compressionLevels         *string
+	tierBackend               *string
+	tierQuietFor              *time.Duration
 }
 
 func init() {
@@ -87,6 +101,7 @@ func init() {
 	v.idleConnectionTimeout = cmdVolume.Flag.Int("idleTimeout", 30, "connection idle seconds")
 	v.dataCenter = cmdVolume.Flag.String("dataCenter", "", "current volume server's data center name")
 	v.rack = cmdVolume.Flag.String("rack", "", "current volume server's rack name")
+	v.tags = cmdVolume.Flag.String("tags", "", "comma separated key=value tags, assign requests with tags only write to volumes on matching volume servers")
 	v.indexType = cmdVolume.Flag.String("index", "memory", "Choose [memory|leveldb|leveldbMedium|leveldbLarge] mode for memory~performance balance.")
 	v.diskType = cmdVolume.Flag.String("disk", "", "[hdd|ssd|<tag>] hard drive or solid state drive or any tag")
 	v.fixJpgOrientation = cmdVolume.Flag.Bool("images.fix.orientation", false, "Adjust jpg orientation when uploading.")
@@ -94,7 +109,16 @@ func init() {
 	v.cpuProfile = cmdVolume.Flag.String("cpuprofile", "", "cpu profile output file")
 	v.memProfile = cmdVolume.Flag.String("memprofile", "", "memory profile output file")
 	v.compactionMBPerSecond = cmdVolume.Flag.Int("compactionMBps", 0, "limit background compaction or copying speed in mega bytes per second")
+	v.autoCompaction = cmdVolume.Flag.Bool("compaction.auto", false, "compact volumes on this server, while the master vacuum is disabled with volume.vacuum.disable")
+	v.compactionGarbage = cmdVolume.Flag.Float64("compaction.garbageThreshold", 0.3, "auto compact volumes with more than this ratio of garbage")
+	v.compactionIdle = cmdVolume.Flag.Duration("compaction.idle", 5*time.Minute, "auto compact volumes without any writes or deletes for this long")
+	v.readCacheSizeMB = cmdVolume.Flag.Int("readCache.sizeMB", 0, "cache recently read small files in memory, up to this size in total")
+	v.readCacheMaxFileSizeKB = cmdVolume.Flag.Int("readCache.maxFileSizeKB", 64, "only cache files up to this size in the read cache")
+	v.compression = cmdVolume.Flag.String("compression", "gzip", "[gzip|zstd] how to compress the compressible uploads which are not compressed by the clients, unless the upload asks for another one with ?compression=zstd&compressionLevel=19")
+	v.compressionLevel = cmdVolume.Flag.Int("compression.level", 0, "zstd compression level from 1 to 22, 0 for the zstd default")
+	v.compressionLevels = cmdVolume.Flag.String("compression.collectionLevels", "", "comma separated zstd compression levels by collection, like realtime:1,archive:19")
 	v.fileSizeLimitMB = cmdVolume.Flag.Int("fileSizeLimitMB", 256, "limit file size to avoid out of memory")
+	v.maxVolumeSize = cmdVolume.Flag.String("maxVolumeSize", "", "stop writing to volumes over this size, like 1GiB, if smaller than the volume size limit of the master")
 	v.ldbTimeout = cmdVolume.Flag.Int64("index.leveldbTimeout", 0, "alive time for leveldb (default to 0). If leveldb of volume is not accessed in ldbTimeout hours, it will be off loaded to reduce opened files and memory consumption.")
 	v.concurrentUploadLimitMB = cmdVolume.Flag.Int("concurrentUploadLimitMB", 256, "limit total concurrent upload size")
 	v.concurrentDownloadLimitMB = cmdVolume.Flag.Int("concurrentDownloadLimitMB", 256, "limit total concurrent download size")
@@ -104,6 +128,8 @@ func init() {
 	v.idxFolder = cmdVolume.Flag.String("dir.idx", "", "directory to store .idx files")
 	v.inflightUploadDataTimeout = cmdVolume.Flag.Duration("inflightUploadDataTimeout", 60*time.Second, "inflight upload data wait timeout of volume servers")
 	v.hasSlowRead = cmdVolume.Flag.Bool("hasSlowRead", true, "<experimental> if true, this prevents slow reads from blocking other requests, but large file read P99 latency will increase.")
+	v.tierBackend = cmdVolume.Flag.String("tier.backend", "", "move the unreplicated volumes without writes for -tier.quietFor to this storage backend configured on the master, like s3.default, and move them back on writes")
+	v.tierQuietFor = cmdVolume.Flag.Duration("tier.quietFor", 24*time.Hour, "move volumes to -tier.backend without any writes or deletes for this long")
 	v.readBufferSizeMB = cmdVolume.Flag.Int("readBufferSizeMB", 4, "<experimental> larger values can optimize query performance but will increase some memory usage,Use with hasSlowRead normally.")
 }
 
@@ -187,6 +213,22 @@ func (v VolumeServerOptions) startVolumeServer(volumeFolders, maxVolumeCounts, v
 		glog.Fatalf("%d directories by -dir, but only %d minFreeSpacePercent is set by -minFreeSpacePercent", len(v.folders), len(minFreeSpaces))
 	}
 
+	var maxVolumeSize uint64
+	if *v.maxVolumeSize != "" {
+		var err error
+		if maxVolumeSize, err = util.ParseBytes(*v.maxVolumeSize); err != nil {
+			glog.Fatalf("The -maxVolumeSize %s is not a valid size: %v", *v.maxVolumeSize, err)
+		}
+		if maxVolumeSize > types.MaxPossibleVolumeSize {
+			glog.Fatalf("The -maxVolumeSize %s is over the max possible volume size %d of this build", *v.maxVolumeSize, types.MaxPossibleVolumeSize)
+		}
+	}
+
+	compression, err := needle.ParseCompression(*v.compression, *v.compressionLevel, *v.compressionLevels)
+	if err != nil {
+		glog.Fatalf("invalid -compression options: %v", err)
+	}
+
 	// set disk types
 	var diskTypes []types.DiskType
 	diskTypeStrings := strings.Split(*v.diskType, ",")
@@ -247,12 +289,17 @@ func (v VolumeServerOptions) startVolumeServer(volumeFolders, maxVolumeCounts, v
 		volumeNeedleMapKind = storage.NeedleMapLevelDbLarge
 	}
 
+	tags, err := util.ParseTags(*v.tags)
+	if err != nil {
+		glog.Fatalf("The tags specified in -tags are not valid: %v", err)
+	}
+
 	volumeServer := weed_server.NewVolumeServer(volumeMux, publicVolumeMux,
 		*v.ip, *v.port, *v.portGrpc, *v.publicUrl,
 		v.folders, v.folderMaxLimits, minFreeSpaces, diskTypes,
 		*v.idxFolder,
 		volumeNeedleMapKind,
-		v.masters, constants.VolumePulseSeconds, *v.dataCenter, *v.rack,
+		v.masters, constants.VolumePulseSeconds, *v.dataCenter, *v.rack, tags,
 		v.whiteList,
 		*v.fixJpgOrientation, *v.readMode,
 		*v.compactionMBPerSecond,
@@ -263,6 +310,19 @@ func (v VolumeServerOptions) startVolumeServer(volumeFolders, maxVolumeCounts, v
 		*v.hasSlowRead,
 		*v.readBufferSizeMB,
 		*v.ldbTimeout,
+		weed_server.AutoCompactionOption{
+			Enabled:          *v.autoCompaction,
+			GarbageThreshold: *v.compactionGarbage,
+			Idle:             *v.compactionIdle,
+		},
+		*v.readCacheSizeMB,
+		*v.readCacheMaxFileSizeKB,
+		maxVolumeSize,
+		compression,
+		weed_server.AutoTieringOption{
+			Backend:  *v.tierBackend,
+			QuietFor: *v.tierQuietFor,
+		},
 	)
 	// starting grpc server
 	grpcS := v.startGrpcService(volumeServer)
@@ -345,6 +405,7 @@ func (v VolumeServerOptions) startGrpcService(vs volume_server_pb.VolumeServerSe
 			glog.Fatalf("start gRPC service failed, %s", err)
 		}
 	}()
+	pb.ServeInProcess(grpcS, util.JoinHostPort(*v.ip, grpcPort))
 	return grpcS
 }
 
@@ -356,6 +417,10 @@ func (v VolumeServerOptions) startPublicHttpService(handler http.Handler) httpdo
 		glog.Fatalf("Volume server listener error:%v", e)
 	}
 
+	if tlsConfig := security.LoadServerTLSHTTP(); tlsConfig != nil {
+		publicListener = tls.NewListener(publicListener, tlsConfig)
+	}
+
 	pubHttp := httpdown.HTTP{StopTimeout: 5 * time.Minute, KillTimeout: 5 * time.Minute}
 	publicHttpDown := pubHttp.Serve(&http.Server{Handler: handler}, publicListener)
 	go func() {
@@ -395,6 +460,13 @@ func (v VolumeServerOptions) startClusterHttpService(handler http.Handler) httpd
 		httpS.TLSConfig = security.LoadClientTLSHTTP(clientCertFile)
 	}
 
+	// the certificates of -tls-cert and -tls-key are reloaded, so they are not served by file names
+	if tlsConfig := security.LoadServerTLSHTTP(); tlsConfig != nil {
+		httpDown.CertFile, httpDown.KeyFile = "", ""
+		httpS.TLSConfig = tlsConfig
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
 	clusterHttpServer := httpDown.Serve(httpS, listener)
 	go func() {
 		if e := clusterHttpServer.Wait(); e != nil {
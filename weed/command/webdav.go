@@ -33,6 +33,7 @@ type WebDavOption struct {
 	cacheDir       *string
 	cacheSizeMB    *int64
 	maxMB          *int
+	lockTimeout    *time.Duration
 }
 
 func init() {
@@ -48,6 +49,7 @@ func init() {
 	webDavStandaloneOptions.cacheSizeMB = cmdWebDav.Flag.Int64("cacheCapacityMB", 0, "local cache capacity in MB")
 	webDavStandaloneOptions.maxMB = cmdWebDav.Flag.Int("maxMB", 4, "split files larger than the limit")
 	webDavStandaloneOptions.filerRootPath = cmdWebDav.Flag.String("filer.path", "/", "use this remote path from filer server")
+	webDavStandaloneOptions.lockTimeout = cmdWebDav.Flag.Duration("lockTimeout", time.Hour, "the longest timeout of LOCK, also for locks asking for an infinite timeout")
 }
 
 var cmdWebDav = &Command{
@@ -119,6 +121,7 @@ func (wo *WebDavOption) startWebDav() bool {
 		CacheDir:       util.ResolvePath(*wo.cacheDir),
 		CacheSizeMB:    *wo.cacheSizeMB,
 		MaxMB:          *wo.maxMB,
+		LockTimeout:    *wo.lockTimeout,
 	})
 	if webdavServer_err != nil {
 		glog.Fatalf("WebDav Server startup error: %v", webdavServer_err)
@@ -24,6 +24,14 @@ func (f *Filer) LoadConfiguration(config *util.ViperProxy) (isFresh bool) {
 			if err := store.Initialize(config, store.GetName()+"."); err != nil {
 				glog.Fatalf("failed to initialize store for %s: %+v", store.GetName(), err)
 			}
+			if walDir := config.GetString("filer.options.wal_dir"); walDir != "" {
+				config.SetDefault("filer.options.wal_max_size_mb", 64)
+				wal, err := NewFilerStoreWal(store, walDir, int64(config.GetInt("filer.options.wal_max_size_mb"))*1024*1024)
+				if err != nil {
+					glog.Fatalf("failed to open wal for %s: %+v", store.GetName(), err)
+				}
+				store = wal
+			}
 			isFresh = f.SetStore(store)
 			glog.V(0).Infof("configured filer store to %s", store.GetName())
 			hasDefaultStoreConfigured = true
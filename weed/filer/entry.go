@@ -43,6 +43,7 @@ type Entry struct {
 	Content         []byte
 	Remote          *filer_pb.RemoteEntry
 	Quota           int64
+	SnapshotId      uint64
 }
 
 func (entry *Entry) Size() uint64 {
@@ -71,6 +72,7 @@ func (entry *Entry) ShallowClone() *Entry {
 	newEntry.Content = entry.Content
 	newEntry.Remote = entry.Remote
 	newEntry.Quota = entry.Quota
+	newEntry.SnapshotId = entry.SnapshotId
 
 	return newEntry
 }
@@ -98,6 +100,7 @@ func (entry *Entry) ToExistingProtoEntry(message *filer_pb.Entry) {
 	message.Content = entry.Content
 	message.RemoteEntry = entry.Remote
 	message.Quota = entry.Quota
+	message.SnapshotId = entry.SnapshotId
 }
 
 func FromPbEntryToExistingEntry(message *filer_pb.Entry, fsEntry *Entry) {
@@ -109,6 +112,7 @@ func FromPbEntryToExistingEntry(message *filer_pb.Entry, fsEntry *Entry) {
 	fsEntry.Content = message.Content
 	fsEntry.Remote = message.RemoteEntry
 	fsEntry.Quota = message.Quota
+	fsEntry.SnapshotId = message.SnapshotId
 	fsEntry.FileSize = FileSize(message)
 }
 
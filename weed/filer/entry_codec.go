@@ -47,6 +47,7 @@ func EntryAttributeToPb(entry *Entry) *filer_pb.FuseAttributes {
 		FileSize:      entry.Attr.FileSize,
 		Rdev:          entry.Attr.Rdev,
 		Inode:         entry.Attr.Inode,
+		MtimeNs:       int64(entry.Attr.Mtime.Nanosecond()),
 	}
 }
 
@@ -59,7 +60,7 @@ func PbToEntryAttribute(attr *filer_pb.FuseAttributes) Attr {
 	}
 
 	t.Crtime = time.Unix(attr.Crtime, 0)
-	t.Mtime = time.Unix(attr.Mtime, 0)
+	t.Mtime = time.Unix(attr.Mtime, attr.MtimeNs)
 	t.Mode = os.FileMode(attr.FileMode)
 	t.Uid = attr.Uid
 	t.Gid = attr.Gid
@@ -119,6 +120,9 @@ func EqualEntry(a, b *Entry) bool {
 	if a.Quota != b.Quota {
 		return false
 	}
+	if a.SnapshotId != b.SnapshotId {
+		return false
+	}
 	return true
 }
 
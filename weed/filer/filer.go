@@ -19,6 +19,7 @@ import (
 	"github.com/seaweedfs/seaweedfs/weed/glog"
 	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
 	"github.com/seaweedfs/seaweedfs/weed/util"
+	"github.com/seaweedfs/seaweedfs/weed/util/chunk_cache"
 	"github.com/seaweedfs/seaweedfs/weed/util/log_buffer"
 	"github.com/seaweedfs/seaweedfs/weed/wdclient"
 )
@@ -40,6 +41,7 @@ type Filer struct {
 	Store               VirtualFilerStore
 	MasterClient        *wdclient.MasterClient
 	fileIdDeletionQueue *util.UnboundedQueue
+	ChunkDedup          *ChunkDedup
 	GrpcDialOption      grpc.DialOption
 	DirBucketsPath      string
 	Cipher              bool
@@ -49,9 +51,12 @@ type Filer struct {
 	MetaAggregator      *MetaAggregator
 	Signature           int32
 	FilerConf           *FilerConf
+	Quotas              *FilerQuotas
+	ReadCache           *chunk_cache.FlatFileChunkCache
 	RemoteStorage       *FilerRemoteStorage
 	Dlm                 *lock_manager.DistributedLockManager
 	MaxFilenameLength   uint32
+	entryLocks          *util.LockTable[util.FullPath]
 }
 
 func NewFiler(masters pb.ServerDiscovery, grpcDialOption grpc.DialOption, filerHost pb.ServerAddress, filerGroup string, collection string, replication string, dataCenter string, maxFilenameLength uint32, notifyFn func()) *Filer {
@@ -60,10 +65,12 @@ func NewFiler(masters pb.ServerDiscovery, grpcDialOption grpc.DialOption, filerH
 		fileIdDeletionQueue: util.NewUnboundedQueue(),
 		GrpcDialOption:      grpcDialOption,
 		FilerConf:           NewFilerConf(),
+		Quotas:              NewFilerQuotas(),
 		RemoteStorage:       NewFilerRemoteStorage(),
 		UniqueFilerId:       util.RandomInt32(),
 		Dlm:                 lock_manager.NewDistributedLockManager(filerHost),
 		MaxFilenameLength:   maxFilenameLength,
+		entryLocks:          util.NewLockTable[util.FullPath](),
 	}
 	if f.UniqueFilerId < 0 {
 		f.UniqueFilerId = -f.UniqueFilerId
@@ -185,12 +192,52 @@ func (f *Filer) RollbackTransaction(ctx context.Context) error {
 	return f.Store.RollbackTransaction(ctx)
 }
 
+// LockEntry serializes the changes to the entry of the path made through this filer, and returns the unlock function.
+// CreateEntry, UpdateEntryIf and DeleteEntryMetaAndData lock the path themselves, so they must not be called with the lock held.
+func (f *Filer) LockEntry(p util.FullPath, intention string) (unlock func()) {
+	if f.entryLocks == nil {
+		return func() {}
+	}
+	lock := f.entryLocks.AcquireLock(intention, p, util.ExclusiveLock)
+	return func() {
+		f.entryLocks.ReleaseLock(p, lock)
+	}
+}
+
 func (f *Filer) CreateEntry(ctx context.Context, entry *Entry, o_excl bool, isFromOtherCluster bool, signatures []int32, skipCreateParentDir bool, maxFilenameLength uint32) error {
 
 	if string(entry.FullPath) == "/" {
 		return nil
 	}
 
+	defer f.LockEntry(entry.FullPath, "CreateEntry")()
+	return f.createEntry(ctx, entry, o_excl, isFromOtherCluster, signatures, skipCreateParentDir, maxFilenameLength)
+}
+
+// UpdateEntryIf changes the current entry of the path with fn, and saves it like CreateEntry.
+// The entry is kept if it is not found, or if fn returns false.
+// Finding, changing and saving the entry are done with the path locked, so no other change through this filer gets in between.
+func (f *Filer) UpdateEntryIf(ctx context.Context, p util.FullPath, fn func(entry *Entry) bool) (updated bool, err error) {
+	if string(p) == "/" {
+		return false, nil
+	}
+
+	defer f.LockEntry(p, "UpdateEntryIf")()
+	entry, err := f.FindEntry(ctx, p)
+	if err == filer_pb.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !fn(entry) {
+		return false, nil
+	}
+	return true, f.createEntry(ctx, entry, false, false, nil, true, f.MaxFilenameLength)
+}
+
+func (f *Filer) createEntry(ctx context.Context, entry *Entry, o_excl bool, isFromOtherCluster bool, signatures []int32, skipCreateParentDir bool, maxFilenameLength uint32) error {
+
 	if entry.FullPath.IsLongerFileName(maxFilenameLength) {
 		return fmt.Errorf("entry name too long")
 	}
@@ -215,10 +262,12 @@ func (f *Filer) CreateEntry(ctx context.Context, entry *Entry, o_excl bool, isFr
 		}
 
 		glog.V(4).Infof("InsertEntry %s: new entry: %v", entry.FullPath, entry.Name())
+		f.lockWormContent(nil, entry)
 		if err := f.Store.InsertEntry(ctx, entry); err != nil {
 			glog.Errorf("insert entry %s: %v", entry.FullPath, err)
 			return fmt.Errorf("insert entry %s: %v", entry.FullPath, err)
 		}
+		f.countQuotaUsage(nil, entry)
 	} else {
 		if o_excl {
 			glog.V(3).Infof("EEXIST: entry %s already exists", entry.FullPath)
@@ -227,7 +276,7 @@ func (f *Filer) CreateEntry(ctx context.Context, entry *Entry, o_excl bool, isFr
 		glog.V(4).Infof("UpdateEntry %s: old entry: %v", entry.FullPath, oldEntry.Name())
 		if err := f.UpdateEntry(ctx, oldEntry, entry); err != nil {
 			glog.Errorf("update entry %s: %v", entry.FullPath, err)
-			return fmt.Errorf("update entry %s: %v", entry.FullPath, err)
+			return fmt.Errorf("update entry %s: %w", entry.FullPath, err)
 		}
 	}
 
@@ -309,8 +358,22 @@ func (f *Filer) UpdateEntry(ctx context.Context, oldEntry, entry *Entry) (err er
 			glog.Errorf("existing %s is a file", oldEntry.FullPath)
 			return fmt.Errorf("existing %s is a file", oldEntry.FullPath)
 		}
+		if !isSameFileContent(oldEntry, entry) {
+			if err = f.CheckWormRetention(ctx, oldEntry, "overwrite"); err != nil {
+				return err
+			}
+		}
+		f.lockWormContent(oldEntry, entry)
+		if entry.SnapshotId < oldEntry.SnapshotId {
+			// keep the chunks shared with the snapshot protected
+			entry.SnapshotId = oldEntry.SnapshotId
+		}
 	}
-	return f.Store.UpdateEntry(ctx, entry)
+	if err = f.Store.UpdateEntry(ctx, entry); err != nil {
+		return err
+	}
+	f.countQuotaUsage(oldEntry, entry)
+	return nil
 }
 
 var (
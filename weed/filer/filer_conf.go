@@ -8,6 +8,7 @@ import (
 	"github.com/seaweedfs/seaweedfs/weed/wdclient"
 	"google.golang.org/grpc"
 	"io"
+	"strings"
 
 	"github.com/seaweedfs/seaweedfs/weed/glog"
 	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
@@ -153,6 +154,22 @@ func (fc *FilerConf) GetCollectionTtls(collection string) (ttls map[string]strin
 	return ttls
 }
 
+// HasWormRetention checks whether any file under the directory can be retained by WORM
+func (fc *FilerConf) HasWormRetention(dir string) (found bool) {
+	if fc.MatchStorageRule(dir).WormRetentionDays > 0 {
+		return true
+	}
+	prefix := string(util.FullPath(dir).Child(""))
+	fc.rules.Walk(func(key []byte, value *filer_pb.FilerConf_PathConf) bool {
+		if value.WormRetentionDays > 0 && strings.HasPrefix(string(key), prefix) {
+			found = true
+			return false
+		}
+		return true
+	})
+	return
+}
+
 // merge if values in b is not empty, merge them into a
 func mergePathConf(a, b *filer_pb.FilerConf_PathConf) {
 	a.Collection = util.Nvl(b.Collection, a.Collection)
@@ -170,6 +187,10 @@ func mergePathConf(a, b *filer_pb.FilerConf_PathConf) {
 	a.DataCenter = util.Nvl(b.DataCenter, a.DataCenter)
 	a.Rack = util.Nvl(b.Rack, a.Rack)
 	a.DataNode = util.Nvl(b.DataNode, a.DataNode)
+	// the retention of a sub directory can not be shorter
+	if b.WormRetentionDays > a.WormRetentionDays {
+		a.WormRetentionDays = b.WormRetentionDays
+	}
 }
 
 func (fc *FilerConf) ToProto() *filer_pb.FilerConf {
@@ -0,0 +1,227 @@
+package filer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	leveldb_errors "github.com/syndtr/goleveldb/leveldb/errors"
+	"github.com/syndtr/goleveldb/leveldb/filter"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+var (
+	dedupChunkPrefix     = []byte("c:") // content key -> chunk
+	dedupReferencePrefix = []byte("r:") // file id -> reference count and content key
+)
+
+// ChunkDedup is a local index of the uploaded chunks by their content.
+// The chunks found in the index are reused by the new uploads, and reference counted,
+// so that a chunk is only deleted when the last entry using it is gone.
+type ChunkDedup struct {
+	sync.Mutex
+	db *leveldb.DB
+}
+
+func NewChunkDedup(dir string) (*ChunkDedup, error) {
+	glog.V(0).Infof("chunk dedup index dir: %s", dir)
+	os.MkdirAll(dir, 0755)
+	if err := util.TestFolderWritable(dir); err != nil {
+		return nil, fmt.Errorf("check chunk dedup folder %s writable: %v", dir, err)
+	}
+	opts := &opt.Options{
+		Filter: filter.NewBloomFilter(10),
+	}
+	db, err := leveldb.OpenFile(dir, opts)
+	if leveldb_errors.IsCorrupted(err) {
+		db, err = leveldb.RecoverFile(dir, opts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open chunk dedup index %s: %v", dir, err)
+	}
+	return &ChunkDedup{db: db}, nil
+}
+
+// DedupContentKey identifies the chunk content together with where it is stored,
+// so that the chunks are only shared within the same collection, replication and disk type.
+func DedupContentKey(data []byte, collection, replication, diskType string) []byte {
+	hash := sha256.Sum256(data)
+	return []byte(fmt.Sprintf("%s:%s:%s:%s", hex.EncodeToString(hash[:]), collection, replication, diskType))
+}
+
+// Acquire returns a reference to the chunk with the content key, or nil if not found.
+// The isValid function can reject a chunk, e.g., when its volume is not found, so that the content is uploaded again.
+func (d *ChunkDedup) Acquire(contentKey []byte, isValid func(chunk *filer_pb.FileChunk) bool) *filer_pb.FileChunk {
+	d.Lock()
+	defer d.Unlock()
+
+	key := dedupKey(dedupChunkPrefix, contentKey)
+	data, err := d.db.Get(key, nil)
+	if err != nil {
+		return nil
+	}
+	chunk := &filer_pb.FileChunk{}
+	if err = proto.Unmarshal(data, chunk); err != nil {
+		glog.Errorf("chunk dedup unmarshal %s: %v", contentKey, err)
+		return nil
+	}
+	if !isValid(chunk) {
+		// keep the reference count, in case the chunk is still there
+		if err = d.db.Delete(key, nil); err != nil {
+			glog.Errorf("chunk dedup drop %s: %v", chunk.GetFileIdString(), err)
+		}
+		return nil
+	}
+	count, _ := d.getReference(chunk.GetFileIdString())
+	if err = d.putReference(chunk.GetFileIdString(), count+1, contentKey); err != nil {
+		glog.Errorf("chunk dedup reference %s: %v", chunk.GetFileIdString(), err)
+		return nil
+	}
+	return chunk
+}
+
+// Register adds a newly uploaded chunk, with one reference, to the index.
+func (d *ChunkDedup) Register(contentKey []byte, chunk *filer_pb.FileChunk) {
+	indexed := &filer_pb.FileChunk{
+		FileId:       chunk.GetFileIdString(),
+		Size:         chunk.Size,
+		ETag:         chunk.ETag,
+		CipherKey:    chunk.CipherKey,
+		IsCompressed: chunk.IsCompressed,
+	}
+	data, err := proto.Marshal(indexed)
+	if err != nil {
+		glog.Errorf("chunk dedup marshal %s: %v", indexed.FileId, err)
+		return
+	}
+
+	d.Lock()
+	defer d.Unlock()
+	key := dedupKey(dedupChunkPrefix, contentKey)
+	// the chunk and its reference are written together, so that Forget always finds the chunk by its file id
+	batch := new(leveldb.Batch)
+	// a concurrent upload of the same content may have been registered first
+	if found, _ := d.db.Has(key, nil); !found {
+		batch.Put(key, data)
+	}
+	batch.Put(dedupKey(dedupReferencePrefix, []byte(indexed.FileId)), referenceValue(1, contentKey))
+	if err = d.db.Write(batch, nil); err != nil {
+		glog.Errorf("chunk dedup register %s: %v", indexed.FileId, err)
+	}
+}
+
+// Forget drops the chunk from the index regardless of its references, e.g. when the chunk is deleted
+// because no entry refers to it, so that it is not handed out to the new uploads any more.
+func (d *ChunkDedup) Forget(fileId string) {
+	d.Lock()
+	defer d.Unlock()
+
+	_, contentKey := d.getReference(fileId)
+	d.deleteChunk(contentKey, fileId)
+}
+
+// Release drops one reference to the chunk, and returns true if the chunk is not used any more and can be deleted.
+// The chunks not in the index are not shared and always can be deleted.
+func (d *ChunkDedup) Release(fileId string) bool {
+	d.Lock()
+	defer d.Unlock()
+
+	count, contentKey := d.getReference(fileId)
+	if count == 0 {
+		return true
+	}
+	if count > 1 {
+		if err := d.putReference(fileId, count-1, contentKey); err != nil {
+			glog.Errorf("chunk dedup release %s: %v", fileId, err)
+		}
+		return false
+	}
+	d.deleteChunk(contentKey, fileId)
+	return true
+}
+
+func (d *ChunkDedup) deleteChunk(contentKey []byte, fileId string) {
+	key := dedupKey(dedupChunkPrefix, contentKey)
+	batch := new(leveldb.Batch)
+	if data, err := d.db.Get(key, nil); err == nil {
+		chunk := &filer_pb.FileChunk{}
+		if proto.Unmarshal(data, chunk) == nil && chunk.GetFileIdString() == fileId {
+			batch.Delete(key)
+		}
+	}
+	batch.Delete(dedupKey(dedupReferencePrefix, []byte(fileId)))
+	if err := d.db.Write(batch, nil); err != nil {
+		glog.Errorf("chunk dedup delete %s: %v", fileId, err)
+	}
+}
+
+func (d *ChunkDedup) getReference(fileId string) (count uint64, contentKey []byte) {
+	data, err := d.db.Get(dedupKey(dedupReferencePrefix, []byte(fileId)), nil)
+	if err != nil || len(data) < 8 {
+		return 0, nil
+	}
+	return binary.BigEndian.Uint64(data), data[8:]
+}
+
+func (d *ChunkDedup) putReference(fileId string, count uint64, contentKey []byte) error {
+	return d.db.Put(dedupKey(dedupReferencePrefix, []byte(fileId)), referenceValue(count, contentKey), nil)
+}
+
+func referenceValue(count uint64, contentKey []byte) []byte {
+	data := make([]byte, 8+len(contentKey))
+	binary.BigEndian.PutUint64(data, count)
+	copy(data[8:], contentKey)
+	return data
+}
+
+func dedupKey(prefix, key []byte) []byte {
+	return append(append(make([]byte, 0, len(prefix)+len(key)), prefix...), key...)
+}
+
+func (d *ChunkDedup) Close() {
+	d.db.Close()
+}
+
+// isChunkReleased tells whether the chunk can be deleted from the volume servers.
+func (f *Filer) isChunkReleased(fileId string) bool {
+	return f.ChunkDedup == nil || f.ChunkDedup.Release(fileId)
+}
+
+// ReleaseOverwrittenChunks drops the references of the overwritten entry to the chunks reused by the upload replacing it.
+// The upload has taken its own references to them, while deleting the old chunks skips the file ids
+// found in both the old and the new entry, so the old references would never be released.
+func (f *Filer) ReleaseOverwrittenChunks(ctx context.Context, oldEntry *Entry, uploaded []*filer_pb.FileChunk) {
+	if f.ChunkDedup == nil || oldEntry == nil || len(uploaded) == 0 {
+		return
+	}
+	uploadedFileIds := make(map[string]bool)
+	for _, chunk := range uploaded {
+		uploadedFileIds[chunk.GetFileIdString()] = true
+	}
+	oldChunks, _, err := ResolveChunkManifest(f.MasterClient.GetLookupFileIdFunction(), oldEntry.GetChunks(), 0, math.MaxInt64)
+	if err != nil {
+		glog.Errorf("chunk dedup resolve overwritten %s: %v", oldEntry.FullPath, err)
+		return
+	}
+	var kept []*filer_pb.FileChunk
+	for _, chunk := range oldChunks {
+		if uploadedFileIds[chunk.GetFileIdString()] {
+			kept = append(kept, chunk)
+		}
+	}
+	// the references of the chunks in a snapshot are held by the snapshot
+	for _, chunk := range f.ChunksNotInSnapshot(ctx, oldEntry, kept) {
+		f.ChunkDedup.Release(chunk.GetFileIdString())
+	}
+}
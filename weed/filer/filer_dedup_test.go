@@ -0,0 +1,120 @@
+package filer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+)
+
+func TestChunkDedup(t *testing.T) {
+	dedup, err := NewChunkDedup(t.TempDir())
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer dedup.Close()
+
+	valid := func(chunk *filer_pb.FileChunk) bool { return true }
+	key := DedupContentKey([]byte("some data"), "c1", "000", "")
+	if chunk := dedup.Acquire(key, valid); chunk != nil {
+		t.Fatalf("acquired %s from an empty index", chunk.FileId)
+	}
+
+	dedup.Register(key, &filer_pb.FileChunk{FileId: "1,01637037d6", Size: 9, ETag: "etag"})
+	chunk := dedup.Acquire(key, valid)
+	if chunk == nil || chunk.FileId != "1,01637037d6" || chunk.Size != 9 || chunk.ETag != "etag" {
+		t.Fatalf("acquired %+v", chunk)
+	}
+	if otherCollection := DedupContentKey([]byte("some data"), "c2", "000", ""); dedup.Acquire(otherCollection, valid) != nil {
+		t.Errorf("chunk shared across collections")
+	}
+
+	if dedup.Release("1,01637037d6") {
+		t.Errorf("chunk with one more reference released")
+	}
+	if !dedup.Release("1,01637037d6") {
+		t.Errorf("chunk with the last reference not released")
+	}
+	if chunk = dedup.Acquire(key, valid); chunk != nil {
+		t.Errorf("acquired released chunk %s", chunk.FileId)
+	}
+	if !dedup.Release("2,02a8b7c6d5") {
+		t.Errorf("chunk not in the index should be released")
+	}
+}
+
+func TestChunkDedupInvalidChunk(t *testing.T) {
+	dedup, err := NewChunkDedup(t.TempDir())
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer dedup.Close()
+
+	key := DedupContentKey([]byte("some data"), "", "", "")
+	dedup.Register(key, &filer_pb.FileChunk{FileId: "1,01637037d6", Size: 9})
+	dedup.Acquire(key, func(chunk *filer_pb.FileChunk) bool { return true })
+
+	if chunk := dedup.Acquire(key, func(chunk *filer_pb.FileChunk) bool { return false }); chunk != nil {
+		t.Fatalf("acquired invalid chunk %s", chunk.FileId)
+	}
+	dedup.Register(key, &filer_pb.FileChunk{FileId: "3,03637037d6", Size: 9})
+	if chunk := dedup.Acquire(key, func(chunk *filer_pb.FileChunk) bool { return true }); chunk == nil || chunk.FileId != "3,03637037d6" {
+		t.Fatalf("acquired %+v after the content was uploaded again", chunk)
+	}
+	// the references to the invalid chunk are still counted
+	if dedup.Release("1,01637037d6") {
+		t.Errorf("chunk with one more reference released")
+	}
+}
+
+func TestChunkDedupForget(t *testing.T) {
+	dedup, err := NewChunkDedup(t.TempDir())
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer dedup.Close()
+
+	valid := func(chunk *filer_pb.FileChunk) bool { return true }
+	key := DedupContentKey([]byte("some data"), "", "", "")
+	dedup.Register(key, &filer_pb.FileChunk{FileId: "1,01637037d6", Size: 9})
+	dedup.Acquire(key, valid)
+
+	// the file id of the needle as the garbage collection deletes it
+	fileId := gcNeedle{key: 0x01}.fileId(1, 0x637037d6)
+	dedup.Forget(fileId)
+	if chunk := dedup.Acquire(key, valid); chunk != nil {
+		t.Fatalf("acquired forgotten chunk %s", chunk.FileId)
+	}
+	if !dedup.Release(fileId) {
+		t.Errorf("forgotten chunk is still reference counted")
+	}
+}
+
+func TestReleaseOverwrittenChunks(t *testing.T) {
+	dedup, err := NewChunkDedup(t.TempDir())
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer dedup.Close()
+	f := &Filer{ChunkDedup: dedup}
+
+	valid := func(chunk *filer_pb.FileChunk) bool { return true }
+	key := DedupContentKey([]byte("some data"), "", "", "")
+	dedup.Register(key, &filer_pb.FileChunk{FileId: "1,01637037d6", Size: 9})
+	oldEntry := &Entry{
+		FullPath: "/dir/file",
+		Chunks: []*filer_pb.FileChunk{
+			{FileId: "1,01637037d6", Size: 9},
+			{FileId: "2,02a8b7c6d5", Offset: 9, Size: 9},
+		},
+	}
+
+	// overwriting the file with the same content reuses the chunk
+	uploaded := []*filer_pb.FileChunk{dedup.Acquire(key, valid)}
+	f.ReleaseOverwrittenChunks(context.Background(), oldEntry, uploaded)
+
+	// only the new entry refers to the chunk now
+	if !dedup.Release("1,01637037d6") {
+		t.Errorf("chunk of the new entry still has the reference of the overwritten entry")
+	}
+}
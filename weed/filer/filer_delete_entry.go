@@ -22,10 +22,17 @@ func (f *Filer) DeleteEntryMetaAndData(ctx context.Context, p util.FullPath, isR
 		return nil
 	}
 
+	defer f.LockEntry(p, "DeleteEntry")()
+
 	entry, findErr := f.FindEntry(ctx, p)
 	if findErr != nil {
 		return findErr
 	}
+	if isRecursive || !entry.IsDirectory() {
+		if err = f.CheckWormRetentionRecursively(ctx, entry, "delete"); err != nil {
+			return err
+		}
+	}
 	isDeleteCollection := f.isBucket(entry)
 	if entry.IsDirectory() {
 		// delete the folder children, not including the folder itself
@@ -50,12 +57,14 @@ func (f *Filer) DeleteEntryMetaAndData(ctx context.Context, p util.FullPath, isR
 	}
 
 	if shouldDeleteChunks && !isDeleteCollection {
-		f.DirectDeleteChunks(entry.GetChunks())
+		f.DirectDeleteChunks(f.chunksOfDeletedEntry(ctx, entry))
 	}
 
 	if isDeleteCollection {
 		collectionName := entry.Name()
 		f.DoDeleteCollection(collectionName)
+		// the files of a dropped bucket are not listed, nor counted one by one
+		go f.refreshQuotaUsage()
 	}
 
 	return nil
@@ -87,12 +96,13 @@ func (f *Filer) doBatchDeleteFolderMetaAndData(ctx context.Context, entry *Entry
 					err = f.doBatchDeleteFolderMetaAndData(ctx, sub, isRecursive, ignoreRecursiveError, shouldDeleteChunks, subIsDeletingBucket, false, nil, onHardLinkIdsFn)
 				} else {
 					f.NotifyUpdateEvent(ctx, sub, nil, shouldDeleteChunks, isFromOtherCluster, nil)
+					f.countQuotaUsage(sub, nil)
 					if len(sub.HardLinkId) != 0 {
 						// hard link chunk data are deleted separately
 						err = onHardLinkIdsFn([]HardLinkId{sub.HardLinkId})
 					} else {
 						if shouldDeleteChunks {
-							chunksToDelete = append(chunksToDelete, sub.GetChunks()...)
+							chunksToDelete = append(chunksToDelete, f.chunksOfDeletedEntry(ctx, sub)...)
 						}
 					}
 				}
@@ -128,6 +138,7 @@ func (f *Filer) doDeleteEntryMetaAndData(ctx context.Context, entry *Entry, shou
 	}
 	if !entry.IsDirectory() {
 		f.NotifyUpdateEvent(ctx, entry, nil, shouldDeleteChunks, isFromOtherCluster, signatures)
+		f.countQuotaUsage(entry, nil)
 	}
 
 	return nil
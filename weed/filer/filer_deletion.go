@@ -1,6 +1,7 @@
 package filer
 
 import (
+	"context"
 	"github.com/seaweedfs/seaweedfs/weed/storage"
 	"github.com/seaweedfs/seaweedfs/weed/util"
 	"strings"
@@ -53,6 +54,7 @@ func (f *Filer) loopProcessingDeletion() {
 					fileIds = fileIds[:0]
 				}
 				deletionCount = len(toDeleteFileIds)
+				f.invalidateReadCache(toDeleteFileIds)
 				_, err := operation.DeleteFilesWithLookupVolumeId(f.GrpcDialOption, toDeleteFileIds, lookupFunc)
 				if err != nil {
 					if !strings.Contains(err.Error(), storage.ErrorDeleted.Error()) {
@@ -85,6 +87,7 @@ func (f *Filer) doDeleteFileIds(fileIds []string) {
 			fileIds = fileIds[:0]
 		}
 		deletionCount := len(toDeleteFileIds)
+		f.invalidateReadCache(toDeleteFileIds)
 		_, err := operation.DeleteFilesWithLookupVolumeId(f.GrpcDialOption, toDeleteFileIds, lookupFunc)
 		if err != nil {
 			if !strings.Contains(err.Error(), storage.ErrorDeleted.Error()) {
@@ -94,11 +97,22 @@ func (f *Filer) doDeleteFileIds(fileIds []string) {
 	}
 }
 
+func (f *Filer) invalidateReadCache(fileIds []string) {
+	if f.ReadCache == nil {
+		return
+	}
+	for _, fileId := range fileIds {
+		f.ReadCache.InvalidateChunk(fileId)
+	}
+}
+
 func (f *Filer) DirectDeleteChunks(chunks []*filer_pb.FileChunk) {
 	var fileIdsToDelete []string
 	for _, chunk := range chunks {
 		if !chunk.IsChunkManifest {
-			fileIdsToDelete = append(fileIdsToDelete, chunk.GetFileIdString())
+			if f.isChunkReleased(chunk.GetFileIdString()) {
+				fileIdsToDelete = append(fileIdsToDelete, chunk.GetFileIdString())
+			}
 			continue
 		}
 		dataChunks, manifestResolveErr := ResolveOneChunkManifest(f.MasterClient.LookupFileId, chunk)
@@ -106,7 +120,9 @@ func (f *Filer) DirectDeleteChunks(chunks []*filer_pb.FileChunk) {
 			glog.V(0).Infof("failed to resolve manifest %s: %v", chunk.FileId, manifestResolveErr)
 		}
 		for _, dChunk := range dataChunks {
-			fileIdsToDelete = append(fileIdsToDelete, dChunk.GetFileIdString())
+			if f.isChunkReleased(dChunk.GetFileIdString()) {
+				fileIdsToDelete = append(fileIdsToDelete, dChunk.GetFileIdString())
+			}
 		}
 		fileIdsToDelete = append(fileIdsToDelete, chunk.GetFileIdString())
 	}
@@ -129,7 +145,9 @@ func (f *Filer) DeleteChunks(fullpath util.FullPath, chunks []*filer_pb.FileChun
 func (f *Filer) doDeleteChunks(chunks []*filer_pb.FileChunk) {
 	for _, chunk := range chunks {
 		if !chunk.IsChunkManifest {
-			f.fileIdDeletionQueue.EnQueue(chunk.GetFileIdString())
+			if f.isChunkReleased(chunk.GetFileIdString()) {
+				f.fileIdDeletionQueue.EnQueue(chunk.GetFileIdString())
+			}
 			continue
 		}
 		dataChunks, manifestResolveErr := ResolveOneChunkManifest(f.MasterClient.LookupFileId, chunk)
@@ -137,7 +155,9 @@ func (f *Filer) doDeleteChunks(chunks []*filer_pb.FileChunk) {
 			glog.V(0).Infof("failed to resolve manifest %s: %v", chunk.FileId, manifestResolveErr)
 		}
 		for _, dChunk := range dataChunks {
-			f.fileIdDeletionQueue.EnQueue(dChunk.GetFileIdString())
+			if f.isChunkReleased(dChunk.GetFileIdString()) {
+				f.fileIdDeletionQueue.EnQueue(dChunk.GetFileIdString())
+			}
 		}
 		f.fileIdDeletionQueue.EnQueue(chunk.GetFileIdString())
 	}
@@ -145,7 +165,9 @@ func (f *Filer) doDeleteChunks(chunks []*filer_pb.FileChunk) {
 
 func (f *Filer) DeleteChunksNotRecursive(chunks []*filer_pb.FileChunk) {
 	for _, chunk := range chunks {
-		f.fileIdDeletionQueue.EnQueue(chunk.GetFileIdString())
+		if f.isChunkReleased(chunk.GetFileIdString()) {
+			f.fileIdDeletionQueue.EnQueue(chunk.GetFileIdString())
+		}
 	}
 }
 
@@ -163,5 +185,5 @@ func (f *Filer) deleteChunksIfNotNew(oldEntry, newEntry *Entry) {
 		glog.Errorf("Failed to resolve old entry chunks when delete old entry chunks. new: %s, old: %s", newChunks, oldChunks)
 		return
 	}
-	f.DeleteChunksNotRecursive(toDelete)
+	f.DeleteChunksNotRecursive(f.ChunksNotInSnapshot(context.Background(), oldEntry, toDelete))
 }
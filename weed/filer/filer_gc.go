@@ -0,0 +1,266 @@
+package filer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/operation"
+	"github.com/seaweedfs/seaweedfs/weed/pb/volume_server_pb"
+	"github.com/seaweedfs/seaweedfs/weed/storage/idx"
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
+	"github.com/seaweedfs/seaweedfs/weed/storage/types"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+// GcOption configures the background removal of the chunks no entry refers to.
+type GcOption struct {
+	Interval    time.Duration // time between two passes
+	GracePeriod time.Duration // only needles written before this long ago are removed
+	RateLimit   int           // needles checked or deleted per second on the volume servers
+}
+
+// gcNeedle is an index entry of a needle the filer metadata does not refer to.
+type gcNeedle struct {
+	key    types.NeedleId
+	offset types.Offset
+	size   types.Size
+}
+
+// fileId is the file id of the needle with its cookie, as the filer entries and the chunk dedup index refer to it.
+func (n gcNeedle) fileId(vid uint32, cookie uint32) string {
+	return needle.NewFileId(needle.VolumeId(vid), uint64(n.key), cookie).String()
+}
+
+// LoopGarbageCollection removes the orphan chunks, e.g. left by a crash between
+// an entry update and the deletion of its previous chunks.
+// Each pass collects all chunk references from the filer metadata into memory,
+// and compares them with the needle index of each volume that has at least one referenced chunk.
+// A needle is only deleted when it was also unreferenced in the previous pass, so that
+// the entries moved during a walk are not mistaken as gone, and when it is older than the grace period,
+// so that the chunks of uploads still in progress are kept.
+// The unreferenced needles are dropped from the chunk dedup index when first found, so the entries
+// reusing them before that are seen by the next pass, and no entry reuses them afterwards.
+// Volumes shared with clients writing directly to the volume servers must not be garbage collected.
+func (f *Filer) LoopGarbageCollection(option GcOption) {
+	limiter := rate.NewLimiter(rate.Inf, 1)
+	if option.RateLimit > 0 {
+		limiter = rate.NewLimiter(rate.Limit(option.RateLimit), option.RateLimit)
+	}
+	suspects := make(map[string]struct{})
+	for {
+		time.Sleep(option.Interval)
+		var err error
+		if suspects, err = f.collectGarbage(context.Background(), option, limiter, suspects); err != nil {
+			glog.Warningf("filer gc: %v", err)
+		}
+	}
+}
+
+func (f *Filer) collectGarbage(ctx context.Context, option GcOption, limiter *rate.Limiter, suspects map[string]struct{}) (map[string]struct{}, error) {
+
+	for _, location := range f.FilerConf.ToProto().Locations {
+		if location.DisableChunkDeletion {
+			return suspects, fmt.Errorf("skipped, chunk deletion is disabled for %s", location.LocationPrefix)
+		}
+	}
+
+	startTime := time.Now()
+	cutoffNs := uint64(startTime.Add(-option.GracePeriod).UnixNano())
+
+	references, err := f.collectChunkReferences(ctx, "/")
+	if err != nil {
+		return suspects, fmt.Errorf("collect chunk references: %v", err)
+	}
+
+	var orphanCount, deletedCount int
+	nextSuspects := make(map[string]struct{})
+	for vid, referenced := range references {
+		orphans, err := f.findOrphanNeedles(ctx, vid, referenced, cutoffNs, limiter)
+		if err != nil {
+			glog.V(0).Infof("filer gc volume %d: %v", vid, err)
+			continue
+		}
+		var fileIds []string
+		for _, fileId := range orphans {
+			if f.ChunkDedup != nil {
+				// a crash between registering an upload and creating its entry leaves the chunk in the dedup index,
+				// which must not hand it out to new uploads while it is waiting to be deleted
+				f.ChunkDedup.Forget(fileId)
+			}
+			if _, found := suspects[fileId]; found {
+				fileIds = append(fileIds, fileId)
+			} else {
+				nextSuspects[fileId] = struct{}{}
+			}
+		}
+		orphanCount += len(orphans)
+		batchSize := limiter.Burst()
+		if limiter.Limit() == rate.Inf {
+			batchSize = len(fileIds)
+		}
+		for len(fileIds) > 0 {
+			batch := fileIds
+			if len(batch) > batchSize {
+				batch = fileIds[:batchSize]
+			}
+			fileIds = fileIds[len(batch):]
+			if err := limiter.WaitN(ctx, len(batch)); err != nil {
+				return nextSuspects, err
+			}
+			f.doDeleteFileIds(batch)
+			deletedCount += len(batch)
+		}
+	}
+
+	glog.V(0).Infof("filer gc: %d volumes, %d orphan needles, %d deleted, took %v", len(references), orphanCount, deletedCount, time.Since(startTime))
+	return nextSuspects, nil
+}
+
+// collectChunkReferences walks the directory tree, and returns the referenced needles by volume id.
+// Chunk manifests are resolved, and both the manifests and their data chunks count as referenced.
+func (f *Filer) collectChunkReferences(ctx context.Context, dir util.FullPath) (map[uint32]map[types.NeedleId]struct{}, error) {
+	references := make(map[uint32]map[types.NeedleId]struct{})
+	lookupFn := f.MasterClient.GetLookupFileIdFunction()
+
+	var walk func(dir util.FullPath) error
+	walk = func(dir util.FullPath) error {
+		var subDirs []util.FullPath
+		var walkErr error
+		lastFileName := ""
+		for {
+			count := 0
+			var err error
+			lastFileName, err = f.StreamListDirectoryEntries(ctx, dir, lastFileName, false, PaginationSize, "", "", "", func(entry *Entry) bool {
+				count++
+				if entry.IsDirectory() {
+					subDirs = append(subDirs, entry.FullPath)
+					return true
+				}
+				dataChunks, manifestChunks, resolveErr := ResolveChunkManifest(lookupFn, entry.GetChunks(), 0, math.MaxInt64)
+				if resolveErr != nil {
+					walkErr = fmt.Errorf("resolve chunks of %s: %v", entry.FullPath, resolveErr)
+					return false
+				}
+				for _, chunk := range append(dataChunks, manifestChunks...) {
+					fid, parseErr := needle.ParseFileIdFromString(chunk.GetFileIdString())
+					if parseErr != nil {
+						walkErr = fmt.Errorf("parse chunk %s of %s: %v", chunk.GetFileIdString(), entry.FullPath, parseErr)
+						return false
+					}
+					vid := uint32(fid.VolumeId)
+					if _, found := references[vid]; !found {
+						references[vid] = make(map[types.NeedleId]struct{})
+					}
+					references[vid][fid.Key] = struct{}{}
+				}
+				return true
+			})
+			if err != nil {
+				return fmt.Errorf("list %s: %v", dir, err)
+			}
+			if walkErr != nil {
+				return walkErr
+			}
+			if count < PaginationSize {
+				break
+			}
+		}
+		for _, subDir := range subDirs {
+			if err := walk(subDir); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return references, walk(dir)
+}
+
+// findOrphanNeedles reads the needle index of the volume, and returns the file ids of
+// the needles that are not referenced, and were written before the cutoff time.
+func (f *Filer) findOrphanNeedles(ctx context.Context, vid uint32, referenced map[types.NeedleId]struct{}, cutoffNs uint64, limiter *rate.Limiter) (fileIds []string, err error) {
+	locations, found := f.MasterClient.GetLocations(vid)
+	if !found || len(locations) == 0 {
+		return nil, fmt.Errorf("volume not found")
+	}
+	server := locations[0].ServerAddress()
+
+	err = operation.WithVolumeServerClient(false, server, f.GrpcDialOption, func(client volume_server_pb.VolumeServerClient) error {
+		copyFileClient, err := client.CopyFile(ctx, &volume_server_pb.CopyFileRequest{
+			VolumeId:           vid,
+			Ext:                ".idx",
+			CompactionRevision: math.MaxUint32,
+			StopOffset:         math.MaxInt64,
+		})
+		if err != nil {
+			return fmt.Errorf("copy index from %s: %v", server, err)
+		}
+		var buf bytes.Buffer
+		for {
+			resp, err := copyFileClient.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("copy index from %s: %v", server, err)
+			}
+			buf.Write(resp.FileContent)
+		}
+
+		orphans, err := unreferencedNeedles(buf.Bytes(), referenced)
+		if err != nil {
+			return fmt.Errorf("read index from %s: %v", server, err)
+		}
+		for _, orphan := range orphans {
+			if err := limiter.Wait(ctx); err != nil {
+				return err
+			}
+			resp, err := client.ReadNeedleMeta(ctx, &volume_server_pb.ReadNeedleMetaRequest{
+				VolumeId: vid,
+				NeedleId: uint64(orphan.key),
+				Offset:   orphan.offset.ToActualOffset(),
+				Size:     int32(orphan.size),
+			})
+			if err != nil {
+				// deleted or compacted since the index was copied
+				continue
+			}
+			if resp.AppendAtNs < cutoffNs {
+				fileIds = append(fileIds, orphan.fileId(vid, resp.Cookie))
+			}
+		}
+		return nil
+	})
+	return
+}
+
+// unreferencedNeedles returns the live needles of the index that are not referenced.
+func unreferencedNeedles(indexData []byte, referenced map[types.NeedleId]struct{}) ([]gcNeedle, error) {
+	live := make(map[types.NeedleId]gcNeedle)
+	err := idx.WalkIndexFile(bytes.NewReader(indexData), 0, func(key types.NeedleId, offset types.Offset, size types.Size) error {
+		if offset.IsZero() || size.IsDeleted() {
+			delete(live, key)
+		} else {
+			live[key] = gcNeedle{key: key, offset: offset, size: size}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var orphans []gcNeedle
+	for key, n := range live {
+		if _, found := referenced[key]; !found {
+			orphans = append(orphans, n)
+		}
+	}
+	return orphans, nil
+}
@@ -0,0 +1,39 @@
+package filer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle_map"
+	"github.com/seaweedfs/seaweedfs/weed/storage/types"
+)
+
+func TestUnreferencedNeedles(t *testing.T) {
+	var index bytes.Buffer
+	index.Write(needle_map.ToBytes(1, types.ToOffset(8), 100))
+	index.Write(needle_map.ToBytes(2, types.ToOffset(128), 100))
+	index.Write(needle_map.ToBytes(3, types.ToOffset(256), 100))
+	index.Write(needle_map.ToBytes(4, types.ToOffset(384), 100))
+	// needle 3 is deleted, needle 4 is overwritten
+	index.Write(needle_map.ToBytes(3, types.Offset{}, types.TombstoneFileSize))
+	index.Write(needle_map.ToBytes(4, types.ToOffset(512), 200))
+
+	referenced := map[types.NeedleId]struct{}{1: {}}
+	orphans, err := unreferencedNeedles(index.Bytes(), referenced)
+	if err != nil {
+		t.Fatalf("unreferenced needles: %v", err)
+	}
+	found := make(map[types.NeedleId]gcNeedle)
+	for _, n := range orphans {
+		found[n.key] = n
+	}
+	if len(found) != 2 {
+		t.Fatalf("orphans %+v, expected needles 2 and 4", orphans)
+	}
+	if n, ok := found[2]; !ok || n.offset.ToActualOffset() != 128 {
+		t.Errorf("needle 2: %+v", n)
+	}
+	if n, ok := found[4]; !ok || n.offset.ToActualOffset() != 512 || n.size != 200 {
+		t.Errorf("needle 4 should be at its latest offset: %+v", n)
+	}
+}
@@ -0,0 +1,327 @@
+package filer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+	"github.com/seaweedfs/seaweedfs/weed/wdclient"
+)
+
+// SnapshotsRoot keeps one metadata copy of the snapshotted tree per snapshot id.
+// The copies share chunks with the live entries, no file content is duplicated.
+const SnapshotsRoot = "/.snapshots"
+
+func SnapshotPath(snapshotId uint64, p util.FullPath) util.FullPath {
+	snapshotDir := util.FullPath(SnapshotsRoot + "/" + strconv.FormatUint(snapshotId, 10))
+	if p == "/" {
+		return snapshotDir
+	}
+	return snapshotDir.Child(string(p))
+}
+
+func isSnapshotPath(p util.FullPath) bool {
+	return p == SnapshotsRoot || strings.HasPrefix(string(p), SnapshotsRoot+"/")
+}
+
+// CreateSnapshot records the current state of the directory tree under SnapshotsRoot,
+// and marks the live files with the snapshot id, so that later writes branch off
+// the chunk list instead of deleting the chunks the snapshot still refers to.
+// The walk is not atomic with concurrent writes to the same tree.
+func (f *Filer) CreateSnapshot(ctx context.Context, dir util.FullPath) (snapshotId uint64, err error) {
+
+	if isSnapshotPath(dir) {
+		return 0, fmt.Errorf("can not snapshot %s", dir)
+	}
+
+	entry, err := f.FindEntry(ctx, dir)
+	if err != nil {
+		return 0, fmt.Errorf("find %s: %v", dir, err)
+	}
+	if !entry.IsDirectory() {
+		return 0, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	snapshotId = uint64(time.Now().UnixNano())
+
+	if err = f.snapshotEntry(ctx, entry, snapshotId, false); err != nil {
+		return 0, err
+	}
+
+	glog.V(0).Infof("created snapshot %d of %s", snapshotId, dir)
+	return snapshotId, nil
+}
+
+func (f *Filer) snapshotEntry(ctx context.Context, entry *Entry, snapshotId uint64, skipCreateParentDir bool) error {
+
+	snapshotEntry := entry.ShallowClone()
+	snapshotEntry.FullPath = SnapshotPath(snapshotId, entry.FullPath)
+	snapshotEntry.SnapshotId = snapshotId
+	// the copy points to the resolved chunks, not to the hard link meta data
+	snapshotEntry.HardLinkId = nil
+	snapshotEntry.HardLinkCounter = 0
+	if err := f.CreateEntry(ctx, snapshotEntry, true, false, nil, skipCreateParentDir, 0); err != nil {
+		return fmt.Errorf("snapshot %s: %v", entry.FullPath, err)
+	}
+
+	if !entry.IsDirectory() {
+		if len(entry.GetChunks()) == 0 {
+			return nil
+		}
+		entry.SnapshotId = snapshotId
+		if err := f.Store.UpdateEntry(ctx, entry); err != nil {
+			return fmt.Errorf("mark %s with snapshot %d: %v", entry.FullPath, snapshotId, err)
+		}
+		return nil
+	}
+
+	lastFileName := ""
+	for {
+		entries, _, err := f.ListDirectoryEntries(ctx, entry.FullPath, lastFileName, false, PaginationSize, "", "", "")
+		if err != nil {
+			return fmt.Errorf("list folder %s: %v", entry.FullPath, err)
+		}
+		for _, sub := range entries {
+			lastFileName = sub.Name()
+			if isSnapshotPath(sub.FullPath) {
+				continue
+			}
+			if err = f.snapshotEntry(ctx, sub, snapshotId, true); err != nil {
+				return err
+			}
+		}
+		if len(entries) < PaginationSize {
+			break
+		}
+	}
+	return nil
+}
+
+// ChunksNotInSnapshot filters the garbage chunks of an entry update, keeping the chunks that the latest snapshot
+// of the entry still refers to. The snapshot side is resolved, so a data chunk the snapshot reaches through
+// a chunk manifest is kept. The garbage side is taken as is, since the data chunks of a garbage manifest
+// may still be used by the updated entry, and the returned manifest chunks are plain chunks,
+// so that deleting them does not delete their data chunks.
+// When the snapshot copy can not be found, e.g. the entry was renamed afterwards, all chunks are kept.
+func (f *Filer) ChunksNotInSnapshot(ctx context.Context, entry *Entry, chunks []*filer_pb.FileChunk) []*filer_pb.FileChunk {
+	if entry == nil || entry.SnapshotId == 0 || len(chunks) == 0 {
+		return chunks
+	}
+	if isSnapshotPath(entry.FullPath) {
+		// updating a copy in a snapshot never releases its chunks, only deleting it does
+		return nil
+	}
+
+	snapshotEntry, err := f.FindEntry(ctx, SnapshotPath(entry.SnapshotId, entry.FullPath))
+	if err != nil {
+		glog.V(1).Infof("keep chunks of %s for snapshot %d: %v", entry.FullPath, entry.SnapshotId, err)
+		return nil
+	}
+
+	references, err := resolveAllChunks(f.MasterClient.GetLookupFileIdFunction(), snapshotEntry.GetChunks())
+	if err != nil {
+		glog.V(0).Infof("keep chunks of %s for snapshot %d: %v", entry.FullPath, entry.SnapshotId, err)
+		return nil
+	}
+	return plainChunks(unreferencedChunks(chunks, references))
+}
+
+// chunksOfDeletedEntry returns the chunks to delete together with the file entry.
+// Deleting a live file keeps the chunks shared with its latest snapshot, and marks all its snapshot copies,
+// so that deleting the last copy later releases them.
+// Deleting a snapshot copy releases the chunks that neither the live file nor the other snapshots refer to.
+func (f *Filer) chunksOfDeletedEntry(ctx context.Context, entry *Entry) []*filer_pb.FileChunk {
+	if isSnapshotPath(entry.FullPath) {
+		return f.chunksOfDeletedSnapshotCopy(ctx, entry)
+	}
+	if entry.SnapshotId == 0 || len(entry.GetChunks()) == 0 {
+		return entry.GetChunks()
+	}
+
+	copies, err := f.snapshotCopies(ctx, entry.FullPath)
+	if err != nil {
+		glog.V(0).Infof("keep chunks of deleted %s: %v", entry.FullPath, err)
+		return nil
+	}
+	var references []*filer_pb.FileChunk
+	for _, snapshotEntry := range copies {
+		references = append(references, snapshotEntry.GetChunks()...)
+		if snapshotEntry.Extended == nil {
+			snapshotEntry.Extended = make(map[string][]byte)
+		}
+		snapshotEntry.Extended[snapshotLiveDeletedKey] = []byte("true")
+		if err := f.Store.UpdateEntry(ctx, snapshotEntry); err != nil {
+			glog.V(0).Infof("mark snapshot copy %s of deleted %s: %v", snapshotEntry.FullPath, entry.FullPath, err)
+		}
+	}
+	if len(copies) == 0 {
+		glog.V(1).Infof("keep chunks of deleted %s, no copy found for snapshot %d", entry.FullPath, entry.SnapshotId)
+		return nil
+	}
+
+	chunks, err := f.chunksNotReferenced(entry.GetChunks(), references)
+	if err != nil {
+		glog.V(0).Infof("keep chunks of deleted %s: %v", entry.FullPath, err)
+		return nil
+	}
+	return chunks
+}
+
+// snapshotLiveDeletedKey marks the snapshot copies whose live file was deleted.
+// Without it, a missing live file may also have been renamed, and still refer to the chunks.
+const snapshotLiveDeletedKey = "Seaweed-Snapshot-Live-Deleted"
+
+func (f *Filer) chunksOfDeletedSnapshotCopy(ctx context.Context, snapshotEntry *Entry) []*filer_pb.FileChunk {
+	snapshotId, livePath, ok := parseSnapshotPath(snapshotEntry.FullPath)
+	if !ok || len(snapshotEntry.GetChunks()) == 0 {
+		return nil
+	}
+
+	_, liveDeleted := snapshotEntry.Extended[snapshotLiveDeletedKey]
+	var references []*filer_pb.FileChunk
+	liveEntry, err := f.FindEntry(ctx, livePath)
+	if err == nil {
+		references = append(references, liveEntry.GetChunks()...)
+		// a file created at the same path after the snapshot is not the one the copy was taken from
+		liveDeleted = liveDeleted || liveEntry.SnapshotId < snapshotId
+	} else if err != filer_pb.ErrNotFound {
+		glog.V(0).Infof("keep chunks of snapshot copy %s: %v", snapshotEntry.FullPath, err)
+		return nil
+	}
+	copies, err := f.snapshotCopies(ctx, livePath)
+	if err != nil {
+		glog.V(0).Infof("keep chunks of snapshot copy %s: %v", snapshotEntry.FullPath, err)
+		return nil
+	}
+	for _, other := range copies {
+		if other.FullPath == snapshotEntry.FullPath {
+			continue
+		}
+		references = append(references, other.GetChunks()...)
+		if _, found := other.Extended[snapshotLiveDeletedKey]; found {
+			liveDeleted = true
+		}
+	}
+	if liveEntry == nil && !liveDeleted {
+		glog.V(1).Infof("keep chunks of snapshot copy %s, %s may have been renamed", snapshotEntry.FullPath, livePath)
+		return nil
+	}
+
+	unreferenced, err := f.chunksNotReferenced(snapshotEntry.GetChunks(), references)
+	if err != nil {
+		glog.V(0).Infof("keep chunks of snapshot copy %s: %v", snapshotEntry.FullPath, err)
+		return nil
+	}
+	return unreferenced
+}
+
+// snapshotCopies returns the copies of the live path in all snapshots.
+func (f *Filer) snapshotCopies(ctx context.Context, livePath util.FullPath) (copies []*Entry, err error) {
+	lastFileName := ""
+	for {
+		snapshotDirs, _, err := f.ListDirectoryEntries(ctx, SnapshotsRoot, lastFileName, false, PaginationSize, "", "", "")
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %v", SnapshotsRoot, err)
+		}
+		for _, snapshotDir := range snapshotDirs {
+			lastFileName = snapshotDir.Name()
+			snapshotId, err := strconv.ParseUint(snapshotDir.Name(), 10, 64)
+			if err != nil {
+				continue
+			}
+			snapshotEntry, err := f.FindEntry(ctx, SnapshotPath(snapshotId, livePath))
+			if err == filer_pb.ErrNotFound {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("find %s: %v", SnapshotPath(snapshotId, livePath), err)
+			}
+			copies = append(copies, snapshotEntry)
+		}
+		if len(snapshotDirs) < PaginationSize {
+			break
+		}
+	}
+	return copies, nil
+}
+
+// parseSnapshotPath returns the snapshot id and the live path of a path under SnapshotsRoot.
+func parseSnapshotPath(p util.FullPath) (snapshotId uint64, livePath util.FullPath, ok bool) {
+	rest, found := strings.CutPrefix(string(p), SnapshotsRoot+"/")
+	if !found {
+		return 0, "", false
+	}
+	id, live, _ := strings.Cut(rest, "/")
+	snapshotId, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return snapshotId, util.FullPath("/" + live), true
+}
+
+func (f *Filer) chunksNotReferenced(chunks, references []*filer_pb.FileChunk) ([]*filer_pb.FileChunk, error) {
+	return chunksNotReferenced(f.MasterClient.GetLookupFileIdFunction(), chunks, references)
+}
+
+// chunksNotReferenced resolves the chunk manifests on both sides, and returns the chunks
+// not found in the references, with the manifest chunks as plain chunks.
+func chunksNotReferenced(lookupFileIdFn wdclient.LookupFileIdFunctionType, chunks, references []*filer_pb.FileChunk) ([]*filer_pb.FileChunk, error) {
+	resolvedChunks, err := resolveAllChunks(lookupFileIdFn, chunks)
+	if err != nil {
+		return nil, err
+	}
+	resolvedReferences, err := resolveAllChunks(lookupFileIdFn, references)
+	if err != nil {
+		return nil, err
+	}
+	return unreferencedChunks(resolvedChunks, resolvedReferences), nil
+}
+
+func resolveAllChunks(lookupFileIdFn wdclient.LookupFileIdFunctionType, chunks []*filer_pb.FileChunk) ([]*filer_pb.FileChunk, error) {
+	if !HasChunkManifest(chunks) {
+		return chunks, nil
+	}
+	dataChunks, manifestChunks, err := ResolveChunkManifest(lookupFileIdFn, chunks, 0, math.MaxInt64)
+	if err != nil {
+		return nil, err
+	}
+	return append(dataChunks, plainChunks(manifestChunks)...), nil
+}
+
+// plainChunks turns the manifest chunks into plain chunks, so that only the manifests themselves are deleted.
+func plainChunks(chunks []*filer_pb.FileChunk) []*filer_pb.FileChunk {
+	if !HasChunkManifest(chunks) {
+		return chunks
+	}
+	plain := make([]*filer_pb.FileChunk, 0, len(chunks))
+	for _, chunk := range chunks {
+		if chunk.IsChunkManifest {
+			chunk = &filer_pb.FileChunk{
+				FileId: chunk.GetFileIdString(),
+				Offset: chunk.Offset,
+				Size:   chunk.Size,
+			}
+		}
+		plain = append(plain, chunk)
+	}
+	return plain
+}
+
+func unreferencedChunks(chunks, references []*filer_pb.FileChunk) (unreferenced []*filer_pb.FileChunk) {
+	referenced := make(map[string]struct{})
+	for _, chunk := range references {
+		referenced[chunk.GetFileIdString()] = struct{}{}
+	}
+	for _, chunk := range chunks {
+		if _, found := referenced[chunk.GetFileIdString()]; !found {
+			unreferenced = append(unreferenced, chunk)
+		}
+	}
+	return
+}
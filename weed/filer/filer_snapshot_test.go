@@ -0,0 +1,88 @@
+package filer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotPath(t *testing.T) {
+	assert.Equal(t, util.FullPath("/.snapshots/12"), SnapshotPath(12, "/"))
+	assert.Equal(t, util.FullPath("/.snapshots/12/a/b.txt"), SnapshotPath(12, "/a/b.txt"))
+	assert.Equal(t, util.FullPath("/.snapshots/12/a/"), SnapshotPath(12, "/a/"))
+
+	assert.True(t, isSnapshotPath(SnapshotPath(12, "/a")))
+	assert.True(t, isSnapshotPath(SnapshotsRoot))
+	assert.False(t, isSnapshotPath("/.snapshots_old/a"))
+}
+
+func TestChunksNotInSnapshotOfSnapshotCopy(t *testing.T) {
+	f := &Filer{}
+	chunks := []*filer_pb.FileChunk{{FileId: "1,01"}}
+
+	assert.Equal(t, chunks, f.ChunksNotInSnapshot(context.Background(), &Entry{FullPath: "/a"}, chunks))
+	assert.Empty(t, f.ChunksNotInSnapshot(context.Background(), &Entry{FullPath: SnapshotPath(12, "/a"), SnapshotId: 12}, chunks))
+}
+
+func TestParseSnapshotPath(t *testing.T) {
+	snapshotId, livePath, ok := parseSnapshotPath(SnapshotPath(12, "/a/b.txt"))
+	assert.True(t, ok)
+	assert.Equal(t, uint64(12), snapshotId)
+	assert.Equal(t, util.FullPath("/a/b.txt"), livePath)
+
+	snapshotId, livePath, ok = parseSnapshotPath(SnapshotPath(12, "/"))
+	assert.True(t, ok)
+	assert.Equal(t, uint64(12), snapshotId)
+	assert.Equal(t, util.FullPath("/"), livePath)
+
+	_, _, ok = parseSnapshotPath("/a/b.txt")
+	assert.False(t, ok)
+	_, _, ok = parseSnapshotPath("/.snapshots/latest/a")
+	assert.False(t, ok)
+}
+
+func TestChunksNotReferencedWithoutManifest(t *testing.T) {
+	f := &Filer{}
+	chunks := []*filer_pb.FileChunk{{FileId: "1,01"}, {FileId: "1,02"}, {FileId: "2,03"}}
+	references := []*filer_pb.FileChunk{{FileId: "1,02"}, {FileId: "3,04"}}
+
+	unreferenced, err := f.chunksNotReferenced(chunks, references)
+	assert.NoError(t, err)
+	assert.Equal(t, []*filer_pb.FileChunk{chunks[0], chunks[2]}, unreferenced)
+}
+
+func TestChunksNotReferencedThroughManifest(t *testing.T) {
+	manifest, err := proto.Marshal(&filer_pb.FileChunkManifest{
+		Chunks: []*filer_pb.FileChunk{{FileId: "1,01", Size: 4}, {FileId: "1,02", Offset: 4, Size: 4}},
+	})
+	assert.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(manifest)
+	}))
+	defer server.Close()
+	lookupFn := func(fileId string) ([]string, error) {
+		return []string{server.URL + "/" + fileId}, nil
+	}
+
+	// the snapshot reaches 1,01 and 1,02 only through its manifest chunk 3,05
+	references := []*filer_pb.FileChunk{{FileId: "3,05", Size: 8, IsChunkManifest: true}}
+	garbage := []*filer_pb.FileChunk{{FileId: "1,01"}, {FileId: "1,02"}, {FileId: "1,03"}}
+	unreferenced, err := chunksNotReferenced(lookupFn, garbage, references)
+	assert.NoError(t, err)
+	assert.Equal(t, []*filer_pb.FileChunk{garbage[2]}, unreferenced)
+
+	// deleting the manifest itself returns it as a plain chunk, so its data chunks are not deleted again
+	unreferenced, err = chunksNotReferenced(lookupFn, references, []*filer_pb.FileChunk{{FileId: "1,01"}})
+	assert.NoError(t, err)
+	assert.Len(t, unreferenced, 2)
+	assert.Equal(t, "1,02", unreferenced[0].GetFileIdString())
+	assert.Equal(t, "3,05", unreferenced[1].GetFileIdString())
+	assert.False(t, unreferenced[1].IsChunkManifest)
+}
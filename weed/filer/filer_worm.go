@@ -0,0 +1,122 @@
+package filer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+)
+
+// ErrWormRetained is returned when overwriting or deleting a file before the WORM retention of its location expires
+var ErrWormRetained = errors.New("retained by WORM")
+
+// wormLockedKey records the server time when the file content was written in a WORM location.
+// The retention is counted from it, since the clients can set the mtime.
+const wormLockedKey = "Seaweed-Worm-Locked"
+
+type wormBypassKey struct{}
+
+// WithWormBypass lets the privileged operations by the admin change the retained files.
+// Each bypassed retention is logged for auditing.
+func WithWormBypass(ctx context.Context, admin string) context.Context {
+	return context.WithValue(ctx, wormBypassKey{}, admin)
+}
+
+// CheckWormRetention rejects the operation on a file written less than the WORM retention days of its location ago
+func (f *Filer) CheckWormRetention(ctx context.Context, entry *Entry, op string) error {
+	if entry == nil || entry.IsDirectory() {
+		return nil
+	}
+	rule := f.FilerConf.MatchStorageRule(string(entry.FullPath))
+	if rule.WormRetentionDays == 0 {
+		return nil
+	}
+	retainUntil := wormLockedTime(entry).Add(time.Duration(rule.WormRetentionDays) * 24 * time.Hour)
+	if !time.Now().Before(retainUntil) {
+		return nil
+	}
+	if admin, found := ctx.Value(wormBypassKey{}).(string); found {
+		glog.Warningf("audit: %s bypassed WORM to %s %s retained until %s", admin, op, entry.FullPath, retainUntil.UTC().Format(time.RFC3339))
+		return nil
+	}
+	return fmt.Errorf("can not %s %s: %w until %s", op, entry.FullPath, ErrWormRetained, retainUntil.UTC().Format(time.RFC3339))
+}
+
+// CheckWormRetentionRecursively checks the entry, and all the files under it if it is a directory
+func (f *Filer) CheckWormRetentionRecursively(ctx context.Context, entry *Entry, op string) error {
+	if !entry.IsDirectory() {
+		return f.CheckWormRetention(ctx, entry, op)
+	}
+	if !f.FilerConf.HasWormRetention(string(entry.FullPath)) {
+		return nil
+	}
+	lastFileName := ""
+	for {
+		entries, hasMore, err := f.ListDirectoryEntries(ctx, entry.FullPath, lastFileName, false, PaginationSize, "", "", "")
+		if err != nil {
+			return fmt.Errorf("list folder %s: %v", entry.FullPath, err)
+		}
+		for _, sub := range entries {
+			lastFileName = sub.Name()
+			if err = f.CheckWormRetentionRecursively(ctx, sub, op); err != nil {
+				return err
+			}
+		}
+		if !hasMore {
+			return nil
+		}
+	}
+}
+
+// lockWormContent records the current time as the lock time of a file whose content is written in a WORM location.
+// An update keeping the content keeps the lock time of the old entry, whatever the client sent.
+func (f *Filer) lockWormContent(oldEntry, entry *Entry) {
+	if entry.IsDirectory() {
+		return
+	}
+	delete(entry.Extended, wormLockedKey)
+	var locked []byte
+	if oldEntry != nil && isSameFileContent(oldEntry, entry) {
+		locked = oldEntry.Extended[wormLockedKey]
+	} else if f.FilerConf != nil && f.FilerConf.MatchStorageRule(string(entry.FullPath)).WormRetentionDays > 0 {
+		locked = []byte(time.Now().UTC().Format(time.RFC3339Nano))
+	}
+	if locked == nil {
+		return
+	}
+	if entry.Extended == nil {
+		entry.Extended = make(map[string][]byte)
+	}
+	entry.Extended[wormLockedKey] = locked
+}
+
+// wormLockedTime returns when the file content was written in its WORM location.
+// The files written before the location had a WORM retention fall back to their mtime.
+func wormLockedTime(entry *Entry) time.Time {
+	if locked, found := entry.Extended[wormLockedKey]; found {
+		if t, err := time.Parse(time.RFC3339Nano, string(locked)); err == nil {
+			return t
+		}
+	}
+	return entry.Attr.Mtime
+}
+
+// isSameFileContent checks whether an update keeps the file content and modification time
+func isSameFileContent(oldEntry, entry *Entry) bool {
+	if !oldEntry.Attr.Mtime.Equal(entry.Attr.Mtime) || oldEntry.Attr.FileSize != entry.Attr.FileSize || !bytes.Equal(oldEntry.Content, entry.Content) {
+		return false
+	}
+	oldChunks, chunks := oldEntry.GetChunks(), entry.GetChunks()
+	if len(oldChunks) != len(chunks) {
+		return false
+	}
+	for i, chunk := range chunks {
+		if chunk.GetFileIdString() != oldChunks[i].GetFileIdString() || chunk.Offset != oldChunks[i].Offset || chunk.Size != oldChunks[i].Size {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,106 @@
+package filer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckWormRetention(t *testing.T) {
+	f := &Filer{FilerConf: NewFilerConf()}
+	f.FilerConf.doLoadConf(&filer_pb.FilerConf{Locations: []*filer_pb.FilerConf_PathConf{
+		{LocationPrefix: "/records/", WormRetentionDays: 30},
+		{LocationPrefix: "/records/short/", WormRetentionDays: 1},
+	}})
+
+	newFile := func(path string, age time.Duration) *Entry {
+		return &Entry{FullPath: util.FullPath(path), Attr: Attr{Mtime: time.Now().Add(-age), Mode: 0644}}
+	}
+	ctx := context.Background()
+
+	err := f.CheckWormRetention(ctx, newFile("/records/a.pdf", time.Hour), "delete")
+	assert.True(t, errors.Is(err, ErrWormRetained), "recent file should be retained: %v", err)
+	assert.NoError(t, f.CheckWormRetention(ctx, newFile("/records/a.pdf", 31*24*time.Hour), "delete"))
+	assert.NoError(t, f.CheckWormRetention(ctx, newFile("/other/a.pdf", time.Hour), "delete"))
+	assert.NoError(t, f.CheckWormRetention(WithWormBypass(ctx, "admin"), newFile("/records/a.pdf", time.Hour), "delete"))
+
+	// a sub directory can not shorten the retention
+	err = f.CheckWormRetention(ctx, newFile("/records/short/a.pdf", 2*24*time.Hour), "delete")
+	assert.True(t, errors.Is(err, ErrWormRetained), "sub directory should keep the retention: %v", err)
+
+	dir := &Entry{FullPath: "/records/dir", Attr: Attr{Mtime: time.Now(), Mode: os.ModeDir | 0755}}
+	assert.NoError(t, f.CheckWormRetention(ctx, dir, "delete"))
+
+	assert.True(t, f.FilerConf.HasWormRetention("/records"))
+	assert.True(t, f.FilerConf.HasWormRetention("/"))
+	assert.True(t, f.FilerConf.HasWormRetention("/records/2024"))
+	assert.False(t, f.FilerConf.HasWormRetention("/other"))
+}
+
+func TestWormRetentionFromLockTime(t *testing.T) {
+	f := &Filer{FilerConf: NewFilerConf()}
+	f.FilerConf.doLoadConf(&filer_pb.FilerConf{Locations: []*filer_pb.FilerConf_PathConf{
+		{LocationPrefix: "/records/", WormRetentionDays: 30},
+	}})
+	ctx := context.Background()
+
+	// a new file is locked when written, even if the client backdates its mtime
+	backdated := time.Now().Add(-31 * 24 * time.Hour)
+	entry := &Entry{
+		FullPath: "/records/a.pdf",
+		Attr:     Attr{Mtime: backdated, Mode: 0644, FileSize: 3},
+		Chunks:   []*filer_pb.FileChunk{{FileId: "3,01637037d6", Size: 3}},
+		Extended: map[string][]byte{wormLockedKey: []byte(backdated.Format(time.RFC3339Nano))},
+	}
+	f.lockWormContent(nil, entry)
+	err := f.CheckWormRetention(ctx, entry, "delete")
+	assert.True(t, errors.Is(err, ErrWormRetained), "backdated file should be retained: %v", err)
+
+	// updating the metadata keeps the lock time of the old entry
+	updated := entry.ShallowClone()
+	updated.Extended = map[string][]byte{"tag": []byte("x"), wormLockedKey: []byte(backdated.Format(time.RFC3339Nano))}
+	f.lockWormContent(entry, updated)
+	assert.Equal(t, string(entry.Extended[wormLockedKey]), string(updated.Extended[wormLockedKey]))
+
+	// the files outside of WORM locations are not locked
+	other := &Entry{FullPath: "/other/a.pdf", Attr: Attr{Mtime: time.Now(), Mode: 0644}}
+	f.lockWormContent(nil, other)
+	_, found := other.Extended[wormLockedKey]
+	assert.False(t, found)
+}
+
+func TestIsSameFileContent(t *testing.T) {
+	mtime := time.Now()
+	oldEntry := &Entry{
+		FullPath: "/records/a.pdf",
+		Attr:     Attr{Mtime: mtime, FileSize: 3},
+		Chunks:   []*filer_pb.FileChunk{{FileId: "3,01637037d6", Size: 3}},
+	}
+	withXattr := &Entry{
+		FullPath: "/records/a.pdf",
+		Attr:     Attr{Mtime: mtime, FileSize: 3},
+		Chunks:   []*filer_pb.FileChunk{{FileId: "3,01637037d6", Size: 3}},
+		Extended: map[string][]byte{"tag": []byte("x")},
+	}
+	assert.True(t, isSameFileContent(oldEntry, withXattr))
+
+	overwritten := &Entry{
+		FullPath: "/records/a.pdf",
+		Attr:     Attr{Mtime: mtime, FileSize: 3},
+		Chunks:   []*filer_pb.FileChunk{{FileId: "4,01637037d7", Size: 3}},
+	}
+	assert.False(t, isSameFileContent(oldEntry, overwritten))
+
+	touched := &Entry{
+		FullPath: "/records/a.pdf",
+		Attr:     Attr{Mtime: mtime.Add(-time.Hour), FileSize: 3},
+		Chunks:   []*filer_pb.FileChunk{{FileId: "3,01637037d6", Size: 3}},
+	}
+	assert.False(t, isSameFileContent(oldEntry, touched))
+}
@@ -0,0 +1,333 @@
+package filer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+const (
+	// FilerQuotasName is the json file under /etc/seaweedfs with the per collection limits, e.g.
+	// {"tenant1": {"softLimitBytes": 900000000000, "hardLimitBytes": 1000000000000}}
+	FilerQuotasName = "_quotas"
+)
+
+type CollectionQuota struct {
+	SoftLimitBytes int64 `json:"softLimitBytes,omitempty"`
+	HardLimitBytes int64 `json:"hardLimitBytes,omitempty"`
+}
+
+type collectionUsage struct {
+	usedBytes   int64
+	softWarning int32 // 1 after logging the soft limit warning
+}
+
+// FilerQuotas tracks the bytes used by each collection with limits.
+// The usage is refreshed from the sizes of the files in the collection,
+// and counted on each write and delete in between.
+type FilerQuotas struct {
+	sync.RWMutex
+	limits map[string]CollectionQuota
+	usages map[string]*collectionUsage
+}
+
+func NewFilerQuotas() *FilerQuotas {
+	return &FilerQuotas{
+		limits: make(map[string]CollectionQuota),
+		usages: make(map[string]*collectionUsage),
+	}
+}
+
+func (q *FilerQuotas) LoadFromBytes(data []byte) error {
+	limits := make(map[string]CollectionQuota)
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &limits); err != nil {
+			return err
+		}
+	}
+	q.Lock()
+	defer q.Unlock()
+	q.limits = limits
+	for collection := range limits {
+		if _, found := q.usages[collection]; !found {
+			q.usages[collection] = &collectionUsage{}
+		}
+	}
+	return nil
+}
+
+func (q *FilerQuotas) HasLimits() bool {
+	q.RLock()
+	defer q.RUnlock()
+	return len(q.limits) > 0
+}
+
+// Collections returns the collections with limits.
+func (q *FilerQuotas) Collections() (collections []string) {
+	q.RLock()
+	defer q.RUnlock()
+	for collection := range q.limits {
+		collections = append(collections, collection)
+	}
+	return
+}
+
+func (q *FilerQuotas) usage(collection string) (*collectionUsage, CollectionQuota, bool) {
+	q.RLock()
+	defer q.RUnlock()
+	limit, found := q.limits[collection]
+	if !found {
+		return nil, limit, false
+	}
+	return q.usages[collection], limit, true
+}
+
+// AddUsage counts the bytes written to, or removed from, the collection.
+func (q *FilerQuotas) AddUsage(collection string, delta int64) {
+	if delta == 0 {
+		return
+	}
+	usage, limit, found := q.usage(collection)
+	if !found {
+		return
+	}
+	q.checkSoftLimit(collection, usage, limit, atomic.AddInt64(&usage.usedBytes, delta))
+}
+
+// SetUsage resets the bytes used by the collection.
+func (q *FilerQuotas) SetUsage(collection string, usedBytes int64) {
+	usage, limit, found := q.usage(collection)
+	if !found {
+		return
+	}
+	atomic.StoreInt64(&usage.usedBytes, usedBytes)
+	q.checkSoftLimit(collection, usage, limit, usedBytes)
+}
+
+func (q *FilerQuotas) checkSoftLimit(collection string, usage *collectionUsage, limit CollectionQuota, usedBytes int64) {
+	if limit.SoftLimitBytes <= 0 {
+		return
+	}
+	if usedBytes > limit.SoftLimitBytes {
+		if atomic.CompareAndSwapInt32(&usage.softWarning, 0, 1) {
+			glog.Warningf("collection %s uses %d bytes, over the soft limit %d bytes", collection, usedBytes, limit.SoftLimitBytes)
+		}
+	} else {
+		atomic.StoreInt32(&usage.softWarning, 0)
+	}
+}
+
+// CheckHardLimit returns an ENOSPC error if the collection is over its hard limit.
+func (q *FilerQuotas) CheckHardLimit(collection string) error {
+	usage, limit, found := q.usage(collection)
+	if !found || limit.HardLimitBytes <= 0 {
+		return nil
+	}
+	if usedBytes := atomic.LoadInt64(&usage.usedBytes); usedBytes >= limit.HardLimitBytes {
+		return fmt.Errorf("collection %s uses %d bytes, over the hard limit %d bytes: %w", collection, usedBytes, limit.HardLimitBytes, syscall.ENOSPC)
+	}
+	return nil
+}
+
+// Usage returns the bytes used by a collection with limits.
+func (q *FilerQuotas) Usage(collection string) (usedBytes int64, limit CollectionQuota, found bool) {
+	usage, limit, found := q.usage(collection)
+	if !found {
+		return 0, limit, false
+	}
+	return atomic.LoadInt64(&usage.usedBytes), limit, true
+}
+
+// CollectionDetector finds the collection of a path, the same way as the filer picks the collection of a write.
+type CollectionDetector struct {
+	FilerConf         *FilerConf
+	DirBucketsPath    string
+	DefaultCollection string
+}
+
+// Detect returns the collection of the path, from the path specific configuration,
+// the bucket, or the default collection of the filer.
+func (d *CollectionDetector) Detect(p util.FullPath) string {
+	rule := d.FilerConf.MatchStorageRule(string(p))
+	var bucketCollection string
+	if d.DirBucketsPath != "" && strings.HasPrefix(string(p), d.DirBucketsPath+"/") {
+		bucketAndObjectKey := string(p)[len(d.DirBucketsPath)+1:]
+		bucketCollection, _, _ = strings.Cut(bucketAndObjectKey, "/")
+	}
+	return util.Nvl(rule.Collection, bucketCollection, d.DefaultCollection)
+}
+
+// UsageRoots returns the directories with the files of the collection.
+// The files under them can still be in other collections, and are checked with Detect.
+func (d *CollectionDetector) UsageRoots(collection string) (roots []util.FullPath) {
+	if collection == util.Nvl(d.FilerConf.MatchStorageRule("/").Collection, d.DefaultCollection) {
+		return []util.FullPath{"/"}
+	}
+	if d.DirBucketsPath != "" {
+		roots = append(roots, util.NewFullPath(d.DirBucketsPath, collection))
+	}
+	for _, locationConf := range d.FilerConf.ToProto().Locations {
+		if locationConf.Collection != collection {
+			continue
+		}
+		// the location prefix also matches the names starting with its last part
+		if prefix := locationConf.LocationPrefix; strings.HasSuffix(prefix, "/") {
+			roots = append(roots, util.FullPath(strings.TrimSuffix(prefix, "/")))
+		} else {
+			dir, _ := util.FullPath(prefix).DirAndName()
+			roots = append(roots, util.FullPath(dir))
+		}
+	}
+
+	// skip the roots under another root
+	slices.Sort(roots)
+	var uniqueRoots []util.FullPath
+	for _, root := range roots {
+		if root == "" {
+			root = "/"
+		}
+		if n := len(uniqueRoots); n > 0 && (uniqueRoots[n-1] == "/" || root == uniqueRoots[n-1] || strings.HasPrefix(string(root), string(uniqueRoots[n-1])+"/")) {
+			continue
+		}
+		uniqueRoots = append(uniqueRoots, root)
+	}
+	return uniqueRoots
+}
+
+// LoadFilerQuotas loads the collection limits, and recounts the usage of the collections at the recount interval, unless it is 0.
+func (f *Filer) LoadFilerQuotas(recountInterval time.Duration) {
+	quotasPath := util.NewFullPath(DirectoryEtcSeaweedFS, FilerQuotasName)
+	entry, err := f.FindEntry(context.Background(), quotasPath)
+	if err != nil {
+		if err != filer_pb.ErrNotFound {
+			glog.Errorf("read filer quotas %s: %v", quotasPath, err)
+		}
+	} else {
+		f.reloadFilerQuotas(entry.ToProtoEntry())
+	}
+	if recountInterval > 0 {
+		go f.loopRefreshQuotaUsage(recountInterval)
+	}
+}
+
+func (f *Filer) reloadFilerQuotas(entry *filer_pb.Entry) {
+	data := entry.Content
+	if len(data) == 0 && len(entry.GetChunks()) > 0 {
+		var err error
+		if data, err = f.readEntry(entry.GetChunks(), FileSize(entry)); err != nil {
+			glog.Errorf("read filer quotas chunks: %v", err)
+			return
+		}
+	}
+	if err := f.Quotas.LoadFromBytes(data); err != nil {
+		glog.Errorf("parse filer quotas: %v", err)
+		return
+	}
+	go f.refreshQuotaUsage()
+}
+
+func (f *Filer) loopRefreshQuotaUsage(interval time.Duration) {
+	for {
+		time.Sleep(interval)
+		f.refreshQuotaUsage()
+	}
+}
+
+// refreshQuotaUsage sums up the sizes of the files in each collection with limits.
+// It corrects the usage missed by the counting on each write, like the expired files and the dropped buckets.
+func (f *Filer) refreshQuotaUsage() {
+	if !f.Quotas.HasLimits() {
+		return
+	}
+	detector := f.collectionDetector()
+	for _, collection := range f.Quotas.Collections() {
+		var usedBytes int64
+		var err error
+		for _, root := range detector.UsageRoots(collection) {
+			if err = f.walkQuotaUsage(context.Background(), detector, collection, root, &usedBytes); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			glog.V(0).Infof("refresh collection %s usage: %v", collection, err)
+			continue
+		}
+		f.Quotas.SetUsage(collection, usedBytes)
+	}
+}
+
+func (f *Filer) walkQuotaUsage(ctx context.Context, detector *CollectionDetector, collection string, dir util.FullPath, usedBytes *int64) error {
+	lastFileName := ""
+	for {
+		var subDirs []util.FullPath
+		count := 0
+		var err error
+		lastFileName, err = f.Store.ListDirectoryEntries(ctx, dir, lastFileName, false, PaginationSize, func(entry *Entry) bool {
+			count++
+			if entry.IsDirectory() {
+				subDirs = append(subDirs, entry.FullPath)
+			} else if detector.Detect(entry.FullPath) == collection {
+				*usedBytes += int64(entry.Size())
+			}
+			return true
+		})
+		if err != nil {
+			return fmt.Errorf("list %s: %v", dir, err)
+		}
+		for _, subDir := range subDirs {
+			if err = f.walkQuotaUsage(ctx, detector, collection, subDir, usedBytes); err != nil {
+				return err
+			}
+		}
+		if count < PaginationSize {
+			return nil
+		}
+	}
+}
+
+// countQuotaUsage counts the size change of a file in the collection of its path.
+// A nil oldEntry is a new file, and a nil newEntry is a deleted file.
+func (f *Filer) countQuotaUsage(oldEntry, newEntry *Entry) {
+	if f.Quotas == nil || !f.Quotas.HasLimits() {
+		return
+	}
+	var delta int64
+	var p util.FullPath
+	if oldEntry != nil && !oldEntry.IsDirectory() {
+		delta -= int64(oldEntry.Size())
+		p = oldEntry.FullPath
+	}
+	if newEntry != nil && !newEntry.IsDirectory() {
+		delta += int64(newEntry.Size())
+		p = newEntry.FullPath
+	}
+	if p == "" {
+		return
+	}
+	f.Quotas.AddUsage(f.DetectCollection(p), delta)
+}
+
+func (f *Filer) collectionDetector() *CollectionDetector {
+	return &CollectionDetector{
+		FilerConf:         f.FilerConf,
+		DirBucketsPath:    f.DirBucketsPath,
+		DefaultCollection: f.metaLogCollection,
+	}
+}
+
+// DetectCollection returns the collection of the path, from the path specific configuration,
+// the bucket, or the default collection of the filer.
+func (f *Filer) DetectCollection(p util.FullPath) string {
+	return f.collectionDetector().Detect(p)
+}
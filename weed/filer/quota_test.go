@@ -0,0 +1,83 @@
+package filer
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func TestFilerQuotas(t *testing.T) {
+	q := NewFilerQuotas()
+	if err := q.LoadFromBytes([]byte(`{"tenant1": {"softLimitBytes": 100, "hardLimitBytes": 200}}`)); err != nil {
+		t.Fatalf("load quotas: %v", err)
+	}
+
+	q.SetUsage("tenant1", 150)
+	q.AddUsage("tenant1", 40)
+	q.AddUsage("other", 1000)
+	if used, limit, found := q.Usage("tenant1"); !found || used != 190 || limit.HardLimitBytes != 200 {
+		t.Errorf("usage %d, limit %+v, found %v", used, limit, found)
+	}
+	if _, _, found := q.Usage("other"); found {
+		t.Errorf("collection without limits should not be tracked")
+	}
+	if err := q.CheckHardLimit("tenant1"); err != nil {
+		t.Errorf("under hard limit: %v", err)
+	}
+
+	q.AddUsage("tenant1", 10)
+	if err := q.CheckHardLimit("tenant1"); !errors.Is(err, syscall.ENOSPC) {
+		t.Errorf("expected ENOSPC, got %v", err)
+	}
+	if err := q.CheckHardLimit("other"); err != nil {
+		t.Errorf("collection without limits: %v", err)
+	}
+
+	// the usage is kept when the limits are reloaded
+	if err := q.LoadFromBytes([]byte(`{"tenant1": {"hardLimitBytes": 300}}`)); err != nil {
+		t.Fatalf("reload quotas: %v", err)
+	}
+	if err := q.CheckHardLimit("tenant1"); err != nil {
+		t.Errorf("under the new hard limit: %v", err)
+	}
+}
+
+func TestCollectionDetectorUsageRoots(t *testing.T) {
+	fc := NewFilerConf()
+	for _, locationConf := range []*filer_pb.FilerConf_PathConf{
+		{LocationPrefix: "/data/tenant1/", Collection: "tenant1"},
+		{LocationPrefix: "/data/tenant1/archive/", Collection: "archive"},
+		{LocationPrefix: "/buckets/tenant1/logs", Collection: "tenant1"},
+		{LocationPrefix: "/home/t1", Collection: "tenant1"},
+	} {
+		if err := fc.AddLocationConf(locationConf); err != nil {
+			t.Fatalf("add location conf: %v", err)
+		}
+	}
+	d := &CollectionDetector{FilerConf: fc, DirBucketsPath: "/buckets", DefaultCollection: "default"}
+
+	roots := d.UsageRoots("tenant1")
+	if expected := []util.FullPath{"/buckets/tenant1", "/data/tenant1", "/home"}; !slices.Equal(roots, expected) {
+		t.Errorf("usage roots %v, expected %v", roots, expected)
+	}
+	if roots := d.UsageRoots("default"); !slices.Equal(roots, []util.FullPath{"/"}) {
+		t.Errorf("usage roots of the default collection %v", roots)
+	}
+
+	for p, collection := range map[util.FullPath]string{
+		"/data/tenant1/a.txt":         "tenant1",
+		"/data/tenant1/archive/b.txt": "archive",
+		"/buckets/tenant2/c.txt":      "tenant2",
+		"/home/t1x/d.txt":             "tenant1",
+		"/home/other/e.txt":           "default",
+	} {
+		if detected := d.Detect(p); detected != collection {
+			t.Errorf("collection of %s is %s, expected %s", p, detected, collection)
+		}
+	}
+}
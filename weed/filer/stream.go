@@ -15,6 +15,7 @@ import (
 	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
 	"github.com/seaweedfs/seaweedfs/weed/stats"
 	"github.com/seaweedfs/seaweedfs/weed/util"
+	"github.com/seaweedfs/seaweedfs/weed/util/chunk_cache"
 	"github.com/seaweedfs/seaweedfs/weed/wdclient"
 )
 
@@ -80,6 +81,12 @@ func noJwtFunc(string) string {
 }
 
 func PrepareStreamContentWithThrottler(masterClient wdclient.HasLookupFileIdFunction, jwtFunc VolumeServerJwtFunction, chunks []*filer_pb.FileChunk, offset int64, size int64, downloadMaxBytesPs int64) (DoStreamContent, error) {
+	return PrepareStreamContentWithCache(masterClient, jwtFunc, chunks, offset, size, downloadMaxBytesPs, nil)
+}
+
+// PrepareStreamContentWithCache reads the chunks from the local read cache if found,
+// and adds the chunks fetched from volume servers to the read cache.
+func PrepareStreamContentWithCache(masterClient wdclient.HasLookupFileIdFunction, jwtFunc VolumeServerJwtFunction, chunks []*filer_pb.FileChunk, offset int64, size int64, downloadMaxBytesPs int64, readCache *chunk_cache.FlatFileChunkCache) (DoStreamContent, error) {
 	glog.V(4).Infof("prepare to stream content for chunks: %d", len(chunks))
 	chunkViews := ViewFromChunks(masterClient.GetLookupFileIdFunction(), chunks, offset, size)
 
@@ -126,7 +133,12 @@ func PrepareStreamContentWithThrottler(masterClient wdclient.HasLookupFileIdFunc
 			urlStrings := fileId2Url[chunkView.FileId]
 			start := time.Now()
 			jwt := jwtFunc(chunkView.FileId)
-			err := retriedStreamFetchChunkData(writer, urlStrings, jwt, chunkView.CipherKey, chunkView.IsGzipped, chunkView.IsFullChunk(), chunkView.OffsetInChunk, int(chunkView.ViewSize))
+			var err error
+			if readCache != nil && int64(chunkView.ChunkSize) <= readCache.MaxChunkSize() {
+				err = streamChunkViewWithCache(writer, readCache, urlStrings, jwt, chunkView)
+			} else {
+				err = retriedStreamFetchChunkData(writer, urlStrings, jwt, chunkView.CipherKey, chunkView.IsGzipped, chunkView.IsFullChunk(), chunkView.OffsetInChunk, int(chunkView.ViewSize))
+			}
 			offset += int64(chunkView.ViewSize)
 			remaining -= int64(chunkView.ViewSize)
 			stats.FilerRequestHistogram.WithLabelValues("chunkDownload").Observe(time.Since(start).Seconds())
@@ -149,6 +161,27 @@ func PrepareStreamContentWithThrottler(masterClient wdclient.HasLookupFileIdFunc
 	}, nil
 }
 
+func streamChunkViewWithCache(writer io.Writer, readCache *chunk_cache.FlatFileChunkCache, urlStrings []string, jwt string, chunkView *ChunkView) error {
+	data := make([]byte, chunkView.ViewSize)
+	if n, err := readCache.ReadChunkAt(data, chunkView.FileId, uint64(chunkView.OffsetInChunk)); err == nil && n == len(data) {
+		stats.FilerHandlerCounter.WithLabelValues("chunkReadCacheHit").Inc()
+		_, err = writer.Write(data)
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := retriedStreamFetchChunkData(&buf, urlStrings, jwt, chunkView.CipherKey, chunkView.IsGzipped, true, 0, 0); err != nil {
+		return err
+	}
+	chunkData := buf.Bytes()
+	if uint64(len(chunkData)) < uint64(chunkView.OffsetInChunk)+chunkView.ViewSize {
+		return fmt.Errorf("chunk %s size %d, expected at least %d", chunkView.FileId, len(chunkData), uint64(chunkView.OffsetInChunk)+chunkView.ViewSize)
+	}
+	readCache.SetChunk(chunkView.FileId, chunkData)
+	_, err := writer.Write(chunkData[chunkView.OffsetInChunk : uint64(chunkView.OffsetInChunk)+chunkView.ViewSize])
+	return err
+}
+
 func StreamContent(masterClient wdclient.HasLookupFileIdFunction, writer io.Writer, chunks []*filer_pb.FileChunk, offset int64, size int64) error {
 	streamFn, err := PrepareStreamContent(masterClient, noJwtFunc, chunks, offset, size)
 	if err != nil {
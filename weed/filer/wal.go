@@ -0,0 +1,467 @@
+package filer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+const (
+	walFileName = "filer.wal"
+
+	walOpInsert               = "insert"
+	walOpUpdate               = "update"
+	walOpDelete               = "delete"
+	walOpDeleteFolderChildren = "deleteFolderChildren"
+	walOpAbort                = "abort"
+	walOpBegin                = "begin"
+	walOpCommit               = "commit"
+
+	// the operations in a transaction are logged as "<op>@<transaction ts>"
+	walTxSeparator = "@"
+)
+
+type walTxKey struct{}
+
+var (
+	_ = FilerStore(&FilerStoreWal{})
+	_ = BucketAware(&FilerStoreWal{})
+)
+
+// FilerStoreWal appends each metadata mutation to a write-ahead log before applying it to the actual store.
+// Each record is a 4 byte size followed by a filer_pb.LogEntry, the same framing as the filer meta logs.
+// The log key is the operation, and the log data is the filer_pb.FullEntry it applies to.
+// Records are replayed on startup, so a crash in the middle of a store write does not leave it half applied.
+// The operations of a transaction are replayed only if its commit was logged.
+// The mutations are applied one at a time, in the order of the log.
+// The log is truncated whenever no mutation or transaction is in flight, and writes wait for that once it reaches maxSize.
+type FilerStoreWal struct {
+	actualStore FilerStore
+	maxSize     int64
+
+	applyLock    sync.Mutex
+	walLock      sync.Mutex
+	walCond      *sync.Cond
+	walFile      *os.File
+	walSize      int64
+	inFlight     int
+	transactions map[int64]struct{}
+	lastTsNs     int64
+	sizeBuf      []byte
+}
+
+func NewFilerStoreWal(store FilerStore, dir string, maxSize int64) (*FilerStoreWal, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create wal dir %s: %v", dir, err)
+	}
+	walFile, err := os.OpenFile(filepath.Join(dir, walFileName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open wal in %s: %v", dir, err)
+	}
+	wal := &FilerStoreWal{
+		actualStore:  store,
+		maxSize:      maxSize,
+		walFile:      walFile,
+		transactions: make(map[int64]struct{}),
+		sizeBuf:      make([]byte, 4),
+	}
+	wal.walCond = sync.NewCond(&wal.walLock)
+
+	if err = wal.replay(); err != nil {
+		walFile.Close()
+		return nil, err
+	}
+	return wal, nil
+}
+
+// replay applies the records left by a previous run, and then checkpoints the log.
+// The operations are idempotent, so records already applied to the store are applied again harmlessly.
+// A torn or corrupt record ends the log, since the records after it were never acknowledged.
+func (wal *FilerStoreWal) replay() error {
+
+	fi, err := wal.walFile.Stat()
+	if err != nil {
+		return fmt.Errorf("stat wal: %v", err)
+	}
+
+	var logEntries []*filer_pb.LogEntry
+	aborted := make(map[int64]bool)
+	committed := make(map[int64]bool)
+
+	reader := bufio.NewReader(wal.walFile)
+	var offset int64
+	for {
+		logEntry, size, err := readWalRecord(reader, fi.Size()-offset)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			glog.Warningf("truncate wal at offset %d of %d: %v", offset, fi.Size(), err)
+			break
+		}
+		offset += size
+		switch string(logEntry.Key) {
+		case walOpAbort:
+			aborted[logEntry.TsNs] = true
+		case walOpCommit:
+			committed[logEntry.TsNs] = true
+		case walOpBegin:
+		default:
+			logEntries = append(logEntries, logEntry)
+		}
+	}
+
+	replayed := 0
+	for _, logEntry := range logEntries {
+		if aborted[logEntry.TsNs] {
+			continue
+		}
+		op, tx, inTx := strings.Cut(string(logEntry.Key), walTxSeparator)
+		if inTx {
+			txTsNs, err := strconv.ParseInt(tx, 10, 64)
+			if err != nil {
+				return fmt.Errorf("replay wal %s at %d: %v", string(logEntry.Key), logEntry.TsNs, err)
+			}
+			if !committed[txTsNs] || aborted[txTsNs] {
+				continue
+			}
+		}
+		if err := wal.applyRecord(context.Background(), op, logEntry); err != nil {
+			return fmt.Errorf("replay wal %s at %d: %v", op, logEntry.TsNs, err)
+		}
+		replayed++
+	}
+	if replayed > 0 {
+		glog.V(0).Infof("replayed %d filer store mutations from wal", replayed)
+	}
+
+	return wal.checkpoint()
+}
+
+// readWalRecord reads the next record, and its size in the log.
+// It returns io.EOF at the end of the log, and an error for a record not fully written.
+func readWalRecord(reader io.Reader, remaining int64) (*filer_pb.LogEntry, int64, error) {
+	sizeBuf := make([]byte, 4)
+	if _, err := io.ReadFull(reader, sizeBuf); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, 0, fmt.Errorf("torn record size")
+		}
+		return nil, 0, err
+	}
+	size := int64(util.BytesToUint32(sizeBuf))
+	if 4+size > remaining {
+		return nil, 0, fmt.Errorf("record size %d exceeds the rest of the log", size)
+	}
+	data := make([]byte, size)
+	if _, err := io.ReadFull(reader, data); err != nil {
+		return nil, 0, fmt.Errorf("read record: %v", err)
+	}
+	logEntry := &filer_pb.LogEntry{}
+	if err := proto.Unmarshal(data, logEntry); err != nil {
+		return nil, 0, fmt.Errorf("decode record: %v", err)
+	}
+	return logEntry, 4 + size, nil
+}
+
+func (wal *FilerStoreWal) applyRecord(ctx context.Context, op string, logEntry *filer_pb.LogEntry) error {
+	fullEntry := &filer_pb.FullEntry{}
+	if err := proto.Unmarshal(logEntry.Data, fullEntry); err != nil {
+		return err
+	}
+	switch op {
+	case walOpInsert:
+		return wal.actualStore.InsertEntry(ctx, FromPbEntry(fullEntry.Dir, fullEntry.Entry))
+	case walOpUpdate:
+		return wal.actualStore.UpdateEntry(ctx, FromPbEntry(fullEntry.Dir, fullEntry.Entry))
+	case walOpDelete:
+		err := wal.actualStore.DeleteEntry(ctx, util.NewFullPath(fullEntry.Dir, fullEntry.Entry.Name))
+		if err == filer_pb.ErrNotFound {
+			return nil
+		}
+		return err
+	case walOpDeleteFolderChildren:
+		return wal.actualStore.DeleteFolderChildren(ctx, util.NewFullPath(fullEntry.Dir, fullEntry.Entry.Name))
+	}
+	return fmt.Errorf("unknown wal operation %s", op)
+}
+
+// checkpoint truncates the log. The caller must make sure no mutation is in flight.
+func (wal *FilerStoreWal) checkpoint() error {
+	if err := wal.walFile.Truncate(0); err != nil {
+		return fmt.Errorf("truncate wal: %v", err)
+	}
+	if _, err := wal.walFile.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("seek wal: %v", err)
+	}
+	wal.walSize = 0
+	return nil
+}
+
+// waitForRoom waits for the in flight mutations to finish and the log to be truncated, once it reaches maxSize.
+// The operations of an open transaction do not wait, since the transaction is in flight itself.
+func (wal *FilerStoreWal) waitForRoom() {
+	wal.walLock.Lock()
+	defer wal.walLock.Unlock()
+
+	for wal.maxSize > 0 && wal.walSize >= wal.maxSize && wal.inFlight > 0 {
+		wal.walCond.Wait()
+	}
+}
+
+func (wal *FilerStoreWal) nextTsNs() int64 {
+	tsNs := time.Now().UnixNano()
+	if tsNs <= wal.lastTsNs {
+		tsNs = wal.lastTsNs + 1
+	}
+	wal.lastTsNs = tsNs
+	return tsNs
+}
+
+// appendRecord durably logs the operation, and returns the record timestamp used to abort it.
+// The operations in a transaction are logged with the transaction timestamp.
+func (wal *FilerStoreWal) appendRecord(ctx context.Context, op string, fp util.FullPath, entry *Entry) (tsNs int64, err error) {
+
+	wal.walLock.Lock()
+	defer wal.walLock.Unlock()
+
+	tsNs = wal.nextTsNs()
+
+	dir, name := fp.DirAndName()
+	fullEntry := &filer_pb.FullEntry{Dir: dir, Entry: &filer_pb.Entry{Name: name}}
+	if entry != nil {
+		fullEntry.Entry = entry.ToProtoEntry()
+	}
+	data, err := proto.Marshal(fullEntry)
+	if err != nil {
+		return 0, err
+	}
+
+	if txTsNs, found := ctx.Value(walTxKey{}).(int64); found {
+		op += walTxSeparator + strconv.FormatInt(txTsNs, 10)
+	}
+	if err = wal.writeRecord(&filer_pb.LogEntry{TsNs: tsNs, Key: []byte(op), Data: data}); err != nil {
+		return 0, err
+	}
+	wal.inFlight++
+	return tsNs, nil
+}
+
+func (wal *FilerStoreWal) writeRecord(logEntry *filer_pb.LogEntry) error {
+	data, err := proto.Marshal(logEntry)
+	if err != nil {
+		return err
+	}
+	util.Uint32toBytes(wal.sizeBuf, uint32(len(data)))
+	if _, err = wal.walFile.Write(append(wal.sizeBuf, data...)); err != nil {
+		return fmt.Errorf("append wal: %v", err)
+	}
+	if err = wal.walFile.Sync(); err != nil {
+		return fmt.Errorf("sync wal: %v", err)
+	}
+	wal.walSize += int64(len(data) + 4)
+	return nil
+}
+
+// finishRecord marks the logged operation as done, aborting it if the store rejected it.
+func (wal *FilerStoreWal) finishRecord(tsNs int64, applyErr error) {
+	wal.walLock.Lock()
+	defer wal.walLock.Unlock()
+
+	if applyErr != nil {
+		if err := wal.writeRecord(&filer_pb.LogEntry{TsNs: tsNs, Key: []byte(walOpAbort)}); err != nil {
+			glog.Errorf("abort wal record %d: %v", tsNs, err)
+		}
+	}
+	wal.doneInFlight()
+}
+
+func (wal *FilerStoreWal) doneInFlight() {
+	wal.inFlight--
+	if wal.inFlight == 0 {
+		if err := wal.checkpoint(); err != nil {
+			glog.Errorf("checkpoint wal: %v", err)
+		}
+		wal.walCond.Broadcast()
+	}
+}
+
+// logAndApply logs the operation and applies it under one lock,
+// so the store applies the mutations in the order they are replayed.
+func (wal *FilerStoreWal) logAndApply(ctx context.Context, op string, fp util.FullPath, entry *Entry, fn func() error) error {
+	if _, inTx := ctx.Value(walTxKey{}).(int64); !inTx {
+		wal.waitForRoom()
+	}
+
+	wal.applyLock.Lock()
+	defer wal.applyLock.Unlock()
+
+	tsNs, err := wal.appendRecord(ctx, op, fp, entry)
+	if err != nil {
+		return err
+	}
+	err = fn()
+	wal.finishRecord(tsNs, err)
+	return err
+}
+
+func (wal *FilerStoreWal) GetName() string {
+	return wal.actualStore.GetName()
+}
+
+func (wal *FilerStoreWal) Initialize(configuration util.Configuration, prefix string) error {
+	return wal.actualStore.Initialize(configuration, prefix)
+}
+
+func (wal *FilerStoreWal) InsertEntry(ctx context.Context, entry *Entry) error {
+	return wal.logAndApply(ctx, walOpInsert, entry.FullPath, entry, func() error {
+		return wal.actualStore.InsertEntry(ctx, entry)
+	})
+}
+
+func (wal *FilerStoreWal) UpdateEntry(ctx context.Context, entry *Entry) error {
+	return wal.logAndApply(ctx, walOpUpdate, entry.FullPath, entry, func() error {
+		return wal.actualStore.UpdateEntry(ctx, entry)
+	})
+}
+
+func (wal *FilerStoreWal) FindEntry(ctx context.Context, fp util.FullPath) (entry *Entry, err error) {
+	return wal.actualStore.FindEntry(ctx, fp)
+}
+
+func (wal *FilerStoreWal) DeleteEntry(ctx context.Context, fp util.FullPath) (err error) {
+	return wal.logAndApply(ctx, walOpDelete, fp, nil, func() error {
+		return wal.actualStore.DeleteEntry(ctx, fp)
+	})
+}
+
+func (wal *FilerStoreWal) DeleteFolderChildren(ctx context.Context, fp util.FullPath) (err error) {
+	return wal.logAndApply(ctx, walOpDeleteFolderChildren, fp, nil, func() error {
+		return wal.actualStore.DeleteFolderChildren(ctx, fp)
+	})
+}
+
+func (wal *FilerStoreWal) ListDirectoryEntries(ctx context.Context, dirPath util.FullPath, startFileName string, includeStartFile bool, limit int64, eachEntryFunc ListEachEntryFunc) (string, error) {
+	return wal.actualStore.ListDirectoryEntries(ctx, dirPath, startFileName, includeStartFile, limit, eachEntryFunc)
+}
+
+func (wal *FilerStoreWal) ListDirectoryPrefixedEntries(ctx context.Context, dirPath util.FullPath, startFileName string, includeStartFile bool, limit int64, prefix string, eachEntryFunc ListEachEntryFunc) (string, error) {
+	return wal.actualStore.ListDirectoryPrefixedEntries(ctx, dirPath, startFileName, includeStartFile, limit, prefix, eachEntryFunc)
+}
+
+// BeginTransaction logs the start of the transaction, which stays in flight until it is committed or rolled back.
+func (wal *FilerStoreWal) BeginTransaction(ctx context.Context) (context.Context, error) {
+	wal.waitForRoom()
+
+	txCtx, err := wal.actualStore.BeginTransaction(ctx)
+	if err != nil {
+		return ctx, err
+	}
+
+	wal.walLock.Lock()
+	defer wal.walLock.Unlock()
+	txTsNs := wal.nextTsNs()
+	if err = wal.writeRecord(&filer_pb.LogEntry{TsNs: txTsNs, Key: []byte(walOpBegin)}); err != nil {
+		wal.actualStore.RollbackTransaction(txCtx)
+		return ctx, err
+	}
+	wal.transactions[txTsNs] = struct{}{}
+	wal.inFlight++
+	return context.WithValue(txCtx, walTxKey{}, txTsNs), nil
+}
+
+// CommitTransaction logs the commit before committing the store, so the replay applies the committed transaction again.
+func (wal *FilerStoreWal) CommitTransaction(ctx context.Context) error {
+	txTsNs, found := ctx.Value(walTxKey{}).(int64)
+	if !found {
+		return wal.actualStore.CommitTransaction(ctx)
+	}
+
+	wal.walLock.Lock()
+	if _, open := wal.transactions[txTsNs]; !open {
+		wal.walLock.Unlock()
+		return wal.actualStore.CommitTransaction(ctx)
+	}
+	err := wal.writeRecord(&filer_pb.LogEntry{TsNs: txTsNs, Key: []byte(walOpCommit)})
+	wal.walLock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	err = wal.actualStore.CommitTransaction(ctx)
+	wal.finishTransaction(txTsNs, err)
+	return err
+}
+
+func (wal *FilerStoreWal) RollbackTransaction(ctx context.Context) error {
+	err := wal.actualStore.RollbackTransaction(ctx)
+	if txTsNs, found := ctx.Value(walTxKey{}).(int64); found {
+		wal.finishTransaction(txTsNs, fmt.Errorf("rollback"))
+	}
+	return err
+}
+
+// finishTransaction marks the transaction as done, aborting it if it was not committed.
+func (wal *FilerStoreWal) finishTransaction(txTsNs int64, commitErr error) {
+	wal.walLock.Lock()
+	defer wal.walLock.Unlock()
+
+	if _, open := wal.transactions[txTsNs]; !open {
+		return
+	}
+	delete(wal.transactions, txTsNs)
+	if commitErr != nil {
+		if err := wal.writeRecord(&filer_pb.LogEntry{TsNs: txTsNs, Key: []byte(walOpAbort)}); err != nil {
+			glog.Errorf("abort wal transaction %d: %v", txTsNs, err)
+		}
+	}
+	wal.doneInFlight()
+}
+
+func (wal *FilerStoreWal) Shutdown() {
+	wal.actualStore.Shutdown()
+	wal.walLock.Lock()
+	wal.walFile.Close()
+	wal.walLock.Unlock()
+}
+
+func (wal *FilerStoreWal) KvPut(ctx context.Context, key []byte, value []byte) (err error) {
+	return wal.actualStore.KvPut(ctx, key, value)
+}
+func (wal *FilerStoreWal) KvGet(ctx context.Context, key []byte) (value []byte, err error) {
+	return wal.actualStore.KvGet(ctx, key)
+}
+func (wal *FilerStoreWal) KvDelete(ctx context.Context, key []byte) (err error) {
+	return wal.actualStore.KvDelete(ctx, key)
+}
+
+func (wal *FilerStoreWal) CanDropWholeBucket() bool {
+	if ba, ok := wal.actualStore.(BucketAware); ok {
+		return ba.CanDropWholeBucket()
+	}
+	return false
+}
+
+func (wal *FilerStoreWal) OnBucketCreation(bucket string) {
+	if ba, ok := wal.actualStore.(BucketAware); ok {
+		ba.OnBucketCreation(bucket)
+	}
+}
+
+func (wal *FilerStoreWal) OnBucketDeletion(bucket string) {
+	if ba, ok := wal.actualStore.(BucketAware); ok {
+		ba.OnBucketDeletion(bucket)
+	}
+}
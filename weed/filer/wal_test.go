@@ -0,0 +1,153 @@
+package filer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+type walTestStore struct {
+	FilerStore
+	entries map[util.FullPath]*Entry
+}
+
+func (s *walTestStore) GetName() string {
+	return "walTest"
+}
+
+func (s *walTestStore) InsertEntry(ctx context.Context, entry *Entry) error {
+	s.entries[entry.FullPath] = entry
+	return nil
+}
+
+func (s *walTestStore) UpdateEntry(ctx context.Context, entry *Entry) error {
+	s.entries[entry.FullPath] = entry
+	return nil
+}
+
+func (s *walTestStore) DeleteEntry(ctx context.Context, fp util.FullPath) error {
+	delete(s.entries, fp)
+	return nil
+}
+
+func (s *walTestStore) DeleteFolderChildren(ctx context.Context, fp util.FullPath) error {
+	for p := range s.entries {
+		if dir, _ := p.DirAndName(); dir == string(fp) {
+			delete(s.entries, p)
+		}
+	}
+	return nil
+}
+
+func (s *walTestStore) BeginTransaction(ctx context.Context) (context.Context, error) {
+	return ctx, nil
+}
+
+func (s *walTestStore) CommitTransaction(ctx context.Context) error {
+	return nil
+}
+
+func (s *walTestStore) RollbackTransaction(ctx context.Context) error {
+	return nil
+}
+
+func TestFilerStoreWalCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	store := &walTestStore{entries: make(map[util.FullPath]*Entry)}
+	wal, err := NewFilerStoreWal(store, dir, 1024)
+	assert.NoError(t, err)
+
+	assert.NoError(t, wal.InsertEntry(context.Background(), &Entry{FullPath: "/a/b"}))
+	assert.NoError(t, wal.DeleteEntry(context.Background(), "/a/b"))
+	assert.Empty(t, store.entries)
+
+	fi, err := os.Stat(filepath.Join(dir, walFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), fi.Size(), "wal should be checkpointed after each applied mutation")
+}
+
+func TestFilerStoreWalReplay(t *testing.T) {
+	dir := t.TempDir()
+	store := &walTestStore{entries: make(map[util.FullPath]*Entry)}
+	wal, err := NewFilerStoreWal(store, dir, 0)
+	assert.NoError(t, err)
+
+	// simulate a crash after logging, but before the mutations reach the store
+	_, err = wal.appendRecord(context.Background(), walOpInsert, "/a/b", &Entry{FullPath: "/a/b", Attr: Attr{Mime: "text/plain"}})
+	assert.NoError(t, err)
+	_, err = wal.appendRecord(context.Background(), walOpInsert, "/a/c", &Entry{FullPath: "/a/c"})
+	assert.NoError(t, err)
+	_, err = wal.appendRecord(context.Background(), walOpDelete, "/a/c", nil)
+	assert.NoError(t, err)
+	aborted, err := wal.appendRecord(context.Background(), walOpInsert, "/a/d", &Entry{FullPath: "/a/d"})
+	assert.NoError(t, err)
+	wal.finishRecord(aborted, os.ErrInvalid)
+	// a torn record at the end of the log
+	_, err = wal.walFile.Write([]byte{0, 0, 1})
+	assert.NoError(t, err)
+	wal.walFile.Close()
+	assert.Empty(t, store.entries)
+
+	wal, err = NewFilerStoreWal(store, dir, 0)
+	assert.NoError(t, err)
+	defer wal.walFile.Close()
+
+	assert.Equal(t, 1, len(store.entries))
+	if entry, found := store.entries["/a/b"]; assert.True(t, found) {
+		assert.Equal(t, "text/plain", entry.Mime)
+	}
+}
+
+func TestFilerStoreWalReplayTransactions(t *testing.T) {
+	dir := t.TempDir()
+	store := &walTestStore{entries: make(map[util.FullPath]*Entry)}
+	wal, err := NewFilerStoreWal(store, dir, 0)
+	assert.NoError(t, err)
+
+	// simulate a crash after logging the commit, but before the store commits
+	committed, err := wal.BeginTransaction(context.Background())
+	assert.NoError(t, err)
+	_, err = wal.appendRecord(committed, walOpInsert, "/a/committed", &Entry{FullPath: "/a/committed"})
+	assert.NoError(t, err)
+	txTsNs := committed.Value(walTxKey{}).(int64)
+	assert.NoError(t, wal.writeRecord(&filer_pb.LogEntry{TsNs: txTsNs, Key: []byte(walOpCommit)}))
+
+	// a transaction still open at the crash
+	open, err := wal.BeginTransaction(context.Background())
+	assert.NoError(t, err)
+	_, err = wal.appendRecord(open, walOpInsert, "/a/open", &Entry{FullPath: "/a/open"})
+	assert.NoError(t, err)
+
+	// a rolled back transaction
+	rolledBack, err := wal.BeginTransaction(context.Background())
+	assert.NoError(t, err)
+	_, err = wal.appendRecord(rolledBack, walOpInsert, "/a/rolledBack", &Entry{FullPath: "/a/rolledBack"})
+	assert.NoError(t, err)
+	assert.NoError(t, wal.RollbackTransaction(rolledBack))
+
+	// a corrupt record, and a record after it
+	_, err = wal.walFile.Write([]byte{0, 0, 0, 5, 0xff, 0xff, 0xff, 0xff, 0xff})
+	assert.NoError(t, err)
+	_, err = wal.appendRecord(context.Background(), walOpInsert, "/a/after", &Entry{FullPath: "/a/after"})
+	assert.NoError(t, err)
+	wal.walFile.Close()
+	assert.Empty(t, store.entries)
+
+	wal, err = NewFilerStoreWal(store, dir, 0)
+	assert.NoError(t, err, "a corrupt record should not fail the startup")
+	defer wal.walFile.Close()
+
+	assert.Equal(t, 1, len(store.entries), "only the committed transaction should be replayed")
+	_, found := store.entries["/a/committed"]
+	assert.True(t, found)
+
+	fi, err := os.Stat(filepath.Join(dir, walFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), fi.Size(), "wal should be truncated after the replay")
+}
@@ -3,7 +3,9 @@ package meta_cache
 import (
 	"context"
 	"os"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/seaweedfs/seaweedfs/weed/filer"
 	"github.com/seaweedfs/seaweedfs/weed/filer/leveldb"
@@ -15,17 +17,22 @@ import (
 // need to have logic similar to FilerStoreWrapper
 // e.g. fill fileId field for chunks
 
+// cachedAtKey keeps in the local store when an entry was cached,
+// so FindEntry can tell whether the entry is older than the entry TTL.
+const cachedAtKey = "Seaweed-Mount-Cached-At"
+
 type MetaCache struct {
 	root       util.FullPath
 	localStore filer.VirtualFilerStore
 	sync.RWMutex
 	uidGidMapper   *UidGidMapper
+	entryTTL       time.Duration
 	markCachedFn   func(fullpath util.FullPath)
 	isCachedFn     func(fullpath util.FullPath) bool
 	invalidateFunc func(fullpath util.FullPath, entry *filer_pb.Entry)
 }
 
-func NewMetaCache(dbFolder string, uidGidMapper *UidGidMapper, root util.FullPath,
+func NewMetaCache(dbFolder string, uidGidMapper *UidGidMapper, root util.FullPath, entryTTL time.Duration,
 	markCachedFn func(path util.FullPath), isCachedFn func(path util.FullPath) bool, invalidateFunc func(util.FullPath, *filer_pb.Entry)) *MetaCache {
 	return &MetaCache{
 		root:         root,
@@ -33,6 +40,7 @@ func NewMetaCache(dbFolder string, uidGidMapper *UidGidMapper, root util.FullPat
 		markCachedFn: markCachedFn,
 		isCachedFn:   isCachedFn,
 		uidGidMapper: uidGidMapper,
+		entryTTL:     entryTTL,
 		invalidateFunc: func(fullpath util.FullPath, entry *filer_pb.Entry) {
 			invalidateFunc(fullpath, entry)
 		},
@@ -64,7 +72,7 @@ func (mc *MetaCache) InsertEntry(ctx context.Context, entry *filer.Entry) error
 }
 
 func (mc *MetaCache) doInsertEntry(ctx context.Context, entry *filer.Entry) error {
-	return mc.localStore.InsertEntry(ctx, entry)
+	return mc.localStore.InsertEntry(ctx, withCachedAt(entry))
 }
 
 func (mc *MetaCache) AtomicUpdateEntryFromFiler(ctx context.Context, oldPath util.FullPath, newEntry *filer.Entry) error {
@@ -97,7 +105,7 @@ func (mc *MetaCache) AtomicUpdateEntryFromFiler(ctx context.Context, oldPath uti
 		newDir, _ := newEntry.DirAndName()
 		if mc.isCachedFn(util.FullPath(newDir)) {
 			glog.V(3).Infof("InsertEntry %s/%s", newDir, newEntry.Name())
-			if err := mc.localStore.InsertEntry(ctx, newEntry); err != nil {
+			if err := mc.localStore.InsertEntry(ctx, withCachedAt(newEntry)); err != nil {
 				return err
 			}
 		}
@@ -108,7 +116,7 @@ func (mc *MetaCache) AtomicUpdateEntryFromFiler(ctx context.Context, oldPath uti
 func (mc *MetaCache) UpdateEntry(ctx context.Context, entry *filer.Entry) error {
 	mc.Lock()
 	defer mc.Unlock()
-	return mc.localStore.UpdateEntry(ctx, entry)
+	return mc.localStore.UpdateEntry(ctx, withCachedAt(entry))
 }
 
 func (mc *MetaCache) FindEntry(ctx context.Context, fp util.FullPath) (entry *filer.Entry, err error) {
@@ -118,6 +126,11 @@ func (mc *MetaCache) FindEntry(ctx context.Context, fp util.FullPath) (entry *fi
 	if err != nil {
 		return nil, err
 	}
+	// an entry older than the TTL is a cache miss, and the caller reads it from the filer again
+	if cachedAt := takeCachedAt(entry); time.Since(cachedAt) > mc.entryTTL {
+		glog.V(4).Infof("meta cache entry %s expired, cached at %v", fp, cachedAt)
+		return nil, nil
+	}
 	mc.mapIdFromFilerToLocal(entry)
 	return
 }
@@ -143,6 +156,7 @@ func (mc *MetaCache) ListDirectoryEntries(ctx context.Context, dirPath util.Full
 	}
 
 	_, err := mc.localStore.ListDirectoryEntries(ctx, dirPath, startFileName, includeStartFile, limit, func(entry *filer.Entry) bool {
+		takeCachedAt(entry)
 		mc.mapIdFromFilerToLocal(entry)
 		return eachEntryFunc(entry)
 	})
@@ -162,6 +176,29 @@ func (mc *MetaCache) mapIdFromFilerToLocal(entry *filer.Entry) {
 	entry.Attr.Uid, entry.Attr.Gid = mc.uidGidMapper.FilerToLocal(entry.Attr.Uid, entry.Attr.Gid)
 }
 
+// withCachedAt returns a copy of the entry stamped with the current time.
+// The Extended map is copied, since the caller may still hold the original.
+func withCachedAt(entry *filer.Entry) *filer.Entry {
+	stamped := entry.ShallowClone()
+	stamped.Extended = make(map[string][]byte, len(entry.Extended)+1)
+	for k, v := range entry.Extended {
+		stamped.Extended[k] = v
+	}
+	stamped.Extended[cachedAtKey] = []byte(strconv.FormatInt(time.Now().UnixNano(), 10))
+	return stamped
+}
+
+// takeCachedAt removes the cache time from an entry read from the local store and returns it.
+func takeCachedAt(entry *filer.Entry) time.Time {
+	value, found := entry.Extended[cachedAtKey]
+	if !found {
+		return time.Time{}
+	}
+	delete(entry.Extended, cachedAtKey)
+	cachedAt, _ := strconv.ParseInt(string(value), 10, 64)
+	return time.Unix(0, cachedAt)
+}
+
 func (mc *MetaCache) Debug() {
 	if debuggable, ok := mc.localStore.(filer.Debuggable); ok {
 		println("start debugging")
@@ -0,0 +1,75 @@
+package meta_cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func newTestMetaCache(t *testing.T, entryTTL time.Duration) *MetaCache {
+	uidGidMapper, err := NewUidGidMapper("", "")
+	if err != nil {
+		t.Fatalf("uid gid mapper: %v", err)
+	}
+	mc := NewMetaCache(t.TempDir(), uidGidMapper, "/", entryTTL,
+		func(path util.FullPath) {},
+		func(path util.FullPath) bool { return true },
+		func(path util.FullPath, entry *filer_pb.Entry) {})
+	t.Cleanup(mc.Shutdown)
+	return mc
+}
+
+func TestFindEntryHonorsTTL(t *testing.T) {
+	entry := &filer.Entry{
+		FullPath: "/dir/file",
+		Attr:     filer.Attr{Mode: 0644, Mtime: time.Now()},
+		Extended: map[string][]byte{"user.k": []byte("v")},
+	}
+
+	mc := newTestMetaCache(t, time.Hour)
+	if err := mc.InsertEntry(context.Background(), entry); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if _, found := entry.Extended[cachedAtKey]; found {
+		t.Errorf("insert changed the caller's entry")
+	}
+	found, err := mc.FindEntry(context.Background(), entry.FullPath)
+	if err != nil || found == nil {
+		t.Fatalf("find fresh entry: %v, %v", found, err)
+	}
+	if _, ok := found.Extended[cachedAtKey]; ok {
+		t.Errorf("cache time leaked into the entry")
+	}
+	if string(found.Extended["user.k"]) != "v" {
+		t.Errorf("extended attributes: %v", found.Extended)
+	}
+
+	mc = newTestMetaCache(t, time.Millisecond)
+	if err := mc.InsertEntry(context.Background(), entry); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	found, err = mc.FindEntry(context.Background(), entry.FullPath)
+	if err != nil || found != nil {
+		t.Errorf("expired entry should be a cache miss, got %v, %v", found, err)
+	}
+
+	if err := mc.InsertEntry(context.Background(), entry); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	var listed []string
+	mc.ListDirectoryEntries(context.Background(), "/dir", "", false, 10, func(entry *filer.Entry) bool {
+		if _, ok := entry.Extended[cachedAtKey]; ok {
+			t.Errorf("cache time leaked into listed entry %s", entry.FullPath)
+		}
+		listed = append(listed, entry.Name())
+		return true
+	})
+	if len(listed) != 1 || listed[0] != "file" {
+		t.Errorf("listed %v", listed)
+	}
+}
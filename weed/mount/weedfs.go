@@ -13,6 +13,7 @@ import (
 	"google.golang.org/grpc"
 
 	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
 	"github.com/seaweedfs/seaweedfs/weed/mount/meta_cache"
 	"github.com/seaweedfs/seaweedfs/weed/pb"
 	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
@@ -38,6 +39,8 @@ type Option struct {
 	DiskType           types.DiskType
 	ChunkSizeLimit     int64
 	ConcurrentWriters  int
+	WriteRetryCount    int
+	WriteRetryDelay    time.Duration
 	CacheDirForRead    string
 	CacheSizeMBForRead int64
 	CacheDirForWrite   string
@@ -45,6 +48,8 @@ type Option struct {
 	Umask              os.FileMode
 	Quota              int64
 	DisableXAttr       bool
+	AttrCacheTTL       time.Duration // how long the kernel caches the attributes
+	EntryCacheTTL      time.Duration // how long the kernel caches the name lookups
 
 	MountUid         uint32
 	MountGid         uint32
@@ -99,7 +104,7 @@ func NewSeaweedFileSystem(option *Option) *WFS {
 	}
 
 	wfs.metaCache = meta_cache.NewMetaCache(path.Join(option.getUniqueCacheDirForRead(), "meta"), option.UidGidMapper,
-		util.FullPath(option.FilerMountRootPath),
+		util.FullPath(option.FilerMountRootPath), option.EntryCacheTTL,
 		func(path util.FullPath) {
 			wfs.inodeToPath.MarkChildrenCached(path)
 		}, func(path util.FullPath) bool {
@@ -199,6 +204,21 @@ func (wfs *WFS) maybeLoadEntry(fullpath util.FullPath) (*filer_pb.Entry, fuse.St
 	if cacheErr == filer_pb.ErrNotFound {
 		return nil, fuse.ENOENT
 	}
+	if cachedEntry == nil {
+		// the cached entry expired, read it from the filer again
+		entry, err := filer_pb.GetEntry(wfs, fullpath)
+		if err != nil {
+			glog.V(1).Infof("GetEntry %s: %v", fullpath, err)
+			if err == filer_pb.ErrNotFound {
+				wfs.metaCache.DeleteEntry(context.Background(), fullpath)
+			}
+			return nil, fuse.ENOENT
+		}
+		if err := wfs.metaCache.InsertEntry(context.Background(), filer.FromPbEntry(dir, entry)); err != nil {
+			glog.Warningf("cache entry %s: %v", fullpath, err)
+		}
+		return entry, fuse.OK
+	}
 	return cachedEntry.ToProtoEntry(), fuse.OK
 }
 
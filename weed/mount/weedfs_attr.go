@@ -19,12 +19,12 @@ func (wfs *WFS) GetAttr(cancel <-chan struct{}, input *fuse.GetAttrIn, out *fuse
 	inode := input.NodeId
 	_, _, entry, status := wfs.maybeReadEntry(inode)
 	if status == fuse.OK {
-		out.AttrValid = 1
+		out.SetTimeout(wfs.option.AttrCacheTTL)
 		wfs.setAttrByPbEntry(&out.Attr, inode, entry, true)
 		return status
 	} else {
 		if fh, found := wfs.fhmap.FindFileHandle(inode); found {
-			out.AttrValid = 1
+			out.SetTimeout(wfs.option.AttrCacheTTL)
 			wfs.setAttrByPbEntry(&out.Attr, inode, fh.entry.GetEntry(), true)
 			out.Nlink = 0
 			return fuse.OK
@@ -113,7 +113,7 @@ func (wfs *WFS) SetAttr(cancel <-chan struct{}, input *fuse.SetAttrIn, out *fuse
 		entry.Attributes.Mtime = mtime.Unix()
 	}
 
-	out.AttrValid = 1
+	out.SetTimeout(wfs.option.AttrCacheTTL)
 	size, includeSize := input.GetSize()
 	if includeSize {
 		out.Attr.Size = size
@@ -198,16 +198,16 @@ func (wfs *WFS) setAttrByFilerEntry(out *fuse.Attr, inode uint64, entry *filer.E
 func (wfs *WFS) outputPbEntry(out *fuse.EntryOut, inode uint64, entry *filer_pb.Entry) {
 	out.NodeId = inode
 	out.Generation = 1
-	out.EntryValid = 1
-	out.AttrValid = 1
+	out.SetEntryTimeout(wfs.option.EntryCacheTTL)
+	out.SetAttrTimeout(wfs.option.AttrCacheTTL)
 	wfs.setAttrByPbEntry(&out.Attr, inode, entry, true)
 }
 
 func (wfs *WFS) outputFilerEntry(out *fuse.EntryOut, inode uint64, entry *filer.Entry) {
 	out.NodeId = inode
 	out.Generation = 1
-	out.EntryValid = 1
-	out.AttrValid = 1
+	out.SetEntryTimeout(wfs.option.EntryCacheTTL)
+	out.SetAttrTimeout(wfs.option.AttrCacheTTL)
 	wfs.setAttrByFilerEntry(&out.Attr, inode, entry)
 }
 
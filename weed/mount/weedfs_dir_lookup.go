@@ -44,9 +44,15 @@ func (wfs *WFS) Lookup(cancel <-chan struct{}, header *fuse.InHeader, name strin
 		entry, err := filer_pb.GetEntry(wfs, fullFilePath)
 		if err != nil {
 			glog.V(1).Infof("dir GetEntry %s: %v", fullFilePath, err)
+			if err == filer_pb.ErrNotFound {
+				wfs.metaCache.DeleteEntry(context.Background(), fullFilePath)
+			}
 			return fuse.ENOENT
 		}
 		localEntry = filer.FromPbEntry(string(dirPath), entry)
+		if err := wfs.metaCache.InsertEntry(context.Background(), localEntry); err != nil {
+			glog.Warningf("cache entry %s: %v", fullFilePath, err)
+		}
 	} else {
 		glog.V(4).Infof("dir Lookup cache hit %s", fullFilePath)
 	}
@@ -0,0 +1,62 @@
+package mount
+
+import (
+	"syscall"
+	"time"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+// FALLOC_FL_KEEP_SIZE keeps the file size unchanged, see https://man7.org/linux/man-pages/man2/fallocate.2.html
+const FALLOC_FL_KEEP_SIZE uint32 = 0x01
+
+/**
+ * Allocates space for an open file
+ *
+ * This function ensures that required space is allocated for specified
+ * file.  If this function returns success then any subsequent write
+ * request to specified range is guaranteed not to fail because of lack
+ * of space on the file system media.
+ */
+func (wfs *WFS) Fallocate(cancel <-chan struct{}, in *fuse.FallocateIn) (code fuse.Status) {
+
+	if wfs.IsOverQuota {
+		return fuse.Status(syscall.ENOSPC)
+	}
+
+	if in.Mode&^FALLOC_FL_KEEP_SIZE != 0 {
+		return fuse.Status(syscall.EOPNOTSUPP)
+	}
+
+	fh := wfs.GetHandle(FileHandleId(in.Fh))
+	if fh == nil {
+		return fuse.EBADF
+	}
+
+	fhActiveLock := fh.wfs.fhLockTable.AcquireLock("Fallocate", fh.fh, util.ExclusiveLock)
+	defer fh.wfs.fhLockTable.ReleaseLock(fh.fh, fhActiveLock)
+
+	entry := fh.GetEntry()
+	if entry == nil {
+		return fuse.OK
+	}
+
+	glog.V(4).Infof("Fallocate %s fh %d [%d,%d) mode %d", fh.FullPath(), fh.fh, in.Offset, in.Offset+in.Length, in.Mode)
+
+	// needles are written once and never updated in place, so a pre-allocated needle could not be
+	// filled by later writes. Only the file size is extended, and the range reads as zeros until written.
+	if in.Mode&FALLOC_FL_KEEP_SIZE != 0 {
+		return fuse.OK
+	}
+
+	if newSize := in.Offset + in.Length; newSize > entry.Attributes.FileSize {
+		entry.Attributes.FileSize = newSize
+		entry.Attributes.Mtime = time.Now().Unix()
+		fh.dirtyMetadata = true
+	}
+
+	return fuse.OK
+}
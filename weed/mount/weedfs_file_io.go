@@ -2,6 +2,8 @@ package mount
 
 import (
 	"github.com/hanwen/go-fuse/v2/fuse"
+
+	"github.com/seaweedfs/seaweedfs/weed/util"
 )
 
 /**
@@ -62,6 +64,9 @@ import (
 */
 func (wfs *WFS) Open(cancel <-chan struct{}, in *fuse.OpenIn, out *fuse.OpenOut) (status fuse.Status) {
 	var fileHandle *FileHandle
+	path, _ := wfs.inodeToPath.GetPath(in.NodeId)
+	span := startSpan("Open", path)
+	defer func() { endSpan(span, status) }()
 	fileHandle, status = wfs.AcquireHandle(in.NodeId, in.Uid, in.Gid)
 	if status == fuse.OK {
 		out.Fh = uint64(fileHandle.fh)
@@ -96,5 +101,11 @@ func (wfs *WFS) Open(cancel <-chan struct{}, in *fuse.OpenIn, out *fuse.OpenOut)
  * @param fi file information
  */
 func (wfs *WFS) Release(cancel <-chan struct{}, in *fuse.ReleaseIn) {
+	var path util.FullPath
+	if fh := wfs.GetHandle(FileHandleId(in.Fh)); fh != nil {
+		path = fh.FullPath()
+	}
+	span := startSpan("Release", path)
+	defer endSpan(span, fuse.OK)
 	wfs.ReleaseHandle(FileHandleId(in.Fh))
 }
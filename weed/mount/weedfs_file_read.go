@@ -7,6 +7,7 @@ import (
 	"io"
 
 	"github.com/hanwen/go-fuse/v2/fuse"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/seaweedfs/seaweedfs/weed/glog"
 )
@@ -36,17 +37,21 @@ import (
  * @param off offset to read from
  * @param fi file information
  */
-func (wfs *WFS) Read(cancel <-chan struct{}, in *fuse.ReadIn, buff []byte) (fuse.ReadResult, fuse.Status) {
+func (wfs *WFS) Read(cancel <-chan struct{}, in *fuse.ReadIn, buff []byte) (result fuse.ReadResult, code fuse.Status) {
 	fh := wfs.GetHandle(FileHandleId(in.Fh))
 	if fh == nil {
 		return nil, fuse.ENOENT
 	}
 
+	span := startSpan("Read", fh.FullPath(), attribute.Int64("offset", int64(in.Offset)), attribute.Int("size", len(buff)))
+	defer func() { endSpan(span, code) }()
+
 	fhActiveLock := fh.wfs.fhLockTable.AcquireLock("Read", fh.fh, util.SharedLock)
 	defer fh.wfs.fhLockTable.ReleaseLock(fh.fh, fhActiveLock)
 
 	offset := int64(in.Offset)
 	totalRead, err := readDataByFileHandle(buff, fh, offset)
+	span.SetAttributes(attribute.Int64("bytes", totalRead))
 	if err != nil {
 		glog.Warningf("file handle read %s %d: %v", fh.FullPath(), totalRead, err)
 		return nil, fuse.EIO
@@ -8,6 +8,7 @@ import (
 	"github.com/seaweedfs/seaweedfs/weed/glog"
 	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
 	"github.com/seaweedfs/seaweedfs/weed/util"
+	"go.opentelemetry.io/otel/attribute"
 	"syscall"
 	"time"
 )
@@ -89,11 +90,13 @@ func (wfs *WFS) Fsync(cancel <-chan struct{}, in *fuse.FsyncIn) (code fuse.Statu
 
 }
 
-func (wfs *WFS) doFlush(fh *FileHandle, uid, gid uint32) fuse.Status {
+func (wfs *WFS) doFlush(fh *FileHandle, uid, gid uint32) (code fuse.Status) {
 
 	// flush works at fh level
 	fileFullPath := fh.FullPath()
 	dir, name := fileFullPath.DirAndName()
+	span := startSpan("Flush", fileFullPath)
+	defer func() { endSpan(span, code) }()
 	// send the data to the OS
 	glog.V(4).Infof("doFlush %s fh %d", fileFullPath, fh.fh)
 
@@ -160,6 +163,7 @@ func (wfs *WFS) doFlush(fh *FileHandle, uid, gid uint32) fuse.Status {
 			glog.V(0).Infof("MaybeManifestize: %v", manifestErr)
 		}
 		entry.Chunks = append(chunks, manifestChunks...)
+		span.SetAttributes(attribute.Int("chunks", len(entry.Chunks)))
 
 		wfs.mapPbIdFromLocalToFiler(request.Entry)
 		defer wfs.mapPbIdFromFilerToLocal(request.Entry)
@@ -3,6 +3,7 @@ package mount
 import (
 	"github.com/hanwen/go-fuse/v2/fuse"
 	"github.com/seaweedfs/seaweedfs/weed/util"
+	"go.opentelemetry.io/otel/attribute"
 	"net/http"
 	"syscall"
 	"time"
@@ -45,6 +46,9 @@ func (wfs *WFS) Write(cancel <-chan struct{}, in *fuse.WriteIn, data []byte) (wr
 		return 0, fuse.ENOENT
 	}
 
+	span := startSpan("Write", fh.FullPath(), attribute.Int64("offset", int64(in.Offset)), attribute.Int("bytes", len(data)))
+	defer func() { endSpan(span, code) }()
+
 	fh.dirtyPages.writerPattern.MonitorWriteAt(int64(in.Offset), int(in.Size))
 
 	tsNs := time.Now().UnixNano()
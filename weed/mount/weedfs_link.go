@@ -48,6 +48,9 @@ func (wfs *WFS) Link(cancel <-chan struct{}, in *fuse.LinkIn, name string, out *
 	if status != fuse.OK {
 		return status
 	}
+	if oldEntry.IsDirectory {
+		return fuse.EPERM
+	}
 
 	// update old file to hardlink mode
 	if len(oldEntry.HardLinkId) == 0 {
@@ -0,0 +1,31 @@
+package mount
+
+import (
+	"context"
+
+	"github.com/hanwen/go-fuse/v2/fuse"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+// the global tracer provider is a no-op, until a program embedding the mount sets one with otel.SetTracerProvider
+var tracer = otel.Tracer("github.com/seaweedfs/seaweedfs/weed/mount")
+
+func startSpan(name string, path util.FullPath, attributes ...attribute.KeyValue) trace.Span {
+	_, span := tracer.Start(context.Background(), "fuse."+name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attribute.String("file.path", string(path))),
+		trace.WithAttributes(attributes...))
+	return span
+}
+
+func endSpan(span trace.Span, status fuse.Status) {
+	if !status.Ok() {
+		span.SetStatus(codes.Error, status.String())
+	}
+	span.End()
+}
@@ -32,6 +32,8 @@ func (wfs *WFS) saveDataAsChunk(fullPath util.FullPath) filer.SaveDataAsChunkFun
 				IsInputCompressed: false,
 				MimeType:          "",
 				PairMap:           nil,
+				RetryCount:        wfs.option.WriteRetryCount,
+				RetryDelay:        wfs.option.WriteRetryDelay,
 			},
 			func(host, fileId string) string {
 				fileUrl := fmt.Sprintf("http://%s/%s", host, fileId)
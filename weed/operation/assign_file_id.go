@@ -22,6 +22,7 @@ type VolumeAssignRequest struct {
 	Rack                string
 	DataNode            string
 	WritableVolumeCount uint32
+	Tags                map[string]string
 }
 
 type AssignResult struct {
@@ -103,6 +104,7 @@ func (ap *singleThreadAssignProxy) doAssign(grpcConnection *grpc.ClientConn, pri
 			Rack:                request.Rack,
 			DataNode:            request.DataNode,
 			WritableVolumeCount: request.WritableVolumeCount,
+			Tags:                request.Tags,
 		}
 		if err = ap.assignClient.Send(req); err != nil {
 			return nil, fmt.Errorf("StreamAssignSend: %v", err)
@@ -164,6 +166,7 @@ func Assign(masterFn GetMasterFn, grpcDialOption grpc.DialOption, primaryRequest
 				Rack:                request.Rack,
 				DataNode:            request.DataNode,
 				WritableVolumeCount: request.WritableVolumeCount,
+				Tags:                request.Tags,
 			}
 			resp, grpcErr := masterClient.Assign(context.Background(), req)
 			if grpcErr != nil {
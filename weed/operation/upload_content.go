@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"github.com/valyala/bytebufferpool"
 	"io"
+	"math/rand"
 	"mime"
 	"mime/multipart"
 	"net"
@@ -32,8 +33,11 @@ type UploadOption struct {
 	PairMap           map[string]string
 	Jwt               security.EncodedJwt
 	RetryForever      bool
+	RetryCount        int           // retry UploadWithRetry on any error this many times, instead of only on transport errors
+	RetryDelay        time.Duration // the delay before the first retry, doubled on each retry
 	Md5               string
 	BytesBuffer       *bytes.Buffer
+	ContentEncoding   string // the compression of the compressed input, gzip if empty
 }
 
 type UploadResult struct {
@@ -72,14 +76,14 @@ var (
 )
 
 func init() {
-	HttpClient = &http.Client{Transport: &http.Transport{
+	HttpClient = &http.Client{Transport: util.NewInternalTransport(&http.Transport{
 		DialContext: (&net.Dialer{
 			Timeout:   10 * time.Second,
 			KeepAlive: 10 * time.Second,
 		}).DialContext,
 		MaxIdleConns:        1024,
 		MaxIdleConnsPerHost: 1024,
-	}}
+	})}
 }
 
 // UploadWithRetry will retry both assigning volume request and uploading content
@@ -117,7 +121,23 @@ func UploadWithRetry(filerClient filer_pb.FilerClient, assignRequest *filer_pb.A
 		uploadResult, uploadErr, data = doUpload(reader, uploadOption)
 		return uploadErr
 	}
-	if uploadOption.RetryForever {
+	if uploadOption.RetryCount > 0 {
+		// keep the data to upload it again on retries
+		if _, ok := reader.(*util.BytesReader); !ok {
+			if data, err = io.ReadAll(reader); err != nil {
+				return "", nil, fmt.Errorf("read input: %v", err), nil
+			}
+			reader = util.NewBytesReader(data)
+		}
+		for attempt := 1; ; attempt++ {
+			if err = doUploadFunc(); err == nil || attempt > uploadOption.RetryCount {
+				break
+			}
+			delay := uploadRetryDelay(uploadOption.RetryDelay, attempt)
+			glog.V(0).Infof("upload %s attempt %d/%d failed, retry in %v: %v", uploadOption.Filename, attempt, uploadOption.RetryCount+1, delay, err)
+			time.Sleep(delay)
+		}
+	} else if uploadOption.RetryForever {
 		util.RetryUntil("uploadWithRetryForever", doUploadFunc, func(err error) (shouldContinue bool) {
 			glog.V(0).Infof("upload content: %v", err)
 			return true
@@ -130,6 +150,12 @@ func UploadWithRetry(filerClient filer_pb.FilerClient, assignRequest *filer_pb.A
 	return
 }
 
+// uploadRetryDelay doubles the base delay on each attempt, with up to 50% jitter.
+func uploadRetryDelay(base time.Duration, attempt int) time.Duration {
+	delay := base << (attempt - 1)
+	return delay + time.Duration(rand.Int63n(int64(delay)/2+1))
+}
+
 var fileNameEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", "")
 
 // Upload sends a POST request to a volume server to upload the content with adjustable compression level
@@ -259,6 +285,7 @@ func doUploadData(data []byte, option *UploadOption) (uploadResult *UploadResult
 			Filename:          option.Filename,
 			Cipher:            false,
 			IsInputCompressed: contentIsGzipped,
+			ContentEncoding:   option.ContentEncoding,
 			MimeType:          option.MimeType,
 			PairMap:           option.PairMap,
 			Jwt:               option.Jwt,
@@ -300,7 +327,7 @@ func upload_content(fillBufferFunction func(w io.Writer) error, originalDataSize
 		h.Set("Content-Type", option.MimeType)
 	}
 	if option.IsInputCompressed {
-		h.Set("Content-Encoding", "gzip")
+		h.Set("Content-Encoding", util.Nvl(option.ContentEncoding, "gzip"))
 	}
 	if option.Md5 != "" {
 		h.Set("Content-MD5", option.Md5)
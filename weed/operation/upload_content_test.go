@@ -0,0 +1,17 @@
+package operation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUploadRetryDelay(t *testing.T) {
+	base := 100 * time.Millisecond
+	for attempt, expected := range []time.Duration{100, 200, 400, 800} {
+		expected *= time.Millisecond
+		delay := uploadRetryDelay(base, attempt+1)
+		if delay < expected || delay > expected+expected/2 {
+			t.Errorf("attempt %d: delay %v, expected between %v and %v", attempt+1, delay, expected, expected+expected/2)
+		}
+	}
+}
@@ -89,6 +89,9 @@ func GrpcDial(ctx context.Context, address string, waitForReady bool, opts ...gr
 			options = append(options, opt)
 		}
 	}
+	if dialer := inProcessDialer(address); dialer != nil {
+		options = append(options, dialer)
+	}
 	return grpc.DialContext(ctx, address, options...)
 }
 
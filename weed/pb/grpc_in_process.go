@@ -0,0 +1,54 @@
+package pb
+
+import (
+	"context"
+	"net"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+)
+
+const inProcessBufferSize = 1 << 20
+
+var (
+	inProcessEnabled   bool
+	inProcessListeners = make(map[string]*bufconn.Listener)
+	inProcessLock      sync.RWMutex
+)
+
+// EnableInProcessGrpc lets ServeInProcess serve the grpc servers of this process in memory,
+// e.g. when "weed server" runs the master, volume server and filer together.
+func EnableInProcessGrpc() {
+	inProcessLock.Lock()
+	defer inProcessLock.Unlock()
+	inProcessEnabled = true
+}
+
+// ServeInProcess also serves the grpc server through in-memory connections, if enabled.
+// GrpcDial in this process then connects to the grpc address through memory instead of the network.
+func ServeInProcess(grpcServer *grpc.Server, grpcAddress string) {
+	inProcessLock.Lock()
+	defer inProcessLock.Unlock()
+	if !inProcessEnabled {
+		return
+	}
+	l := bufconn.Listen(inProcessBufferSize)
+	inProcessListeners[grpcAddress] = l
+	glog.V(0).Infof("serve grpc %s in process", grpcAddress)
+	go grpcServer.Serve(l)
+}
+
+func inProcessDialer(grpcAddress string) grpc.DialOption {
+	inProcessLock.RLock()
+	defer inProcessLock.RUnlock()
+	l, found := inProcessListeners[grpcAddress]
+	if !found {
+		return nil
+	}
+	return grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return l.DialContext(ctx)
+	})
+}
@@ -0,0 +1,42 @@
+package pb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestServeInProcess(t *testing.T) {
+	grpcS := NewGrpcServer()
+	grpc_health_v1.RegisterHealthServer(grpcS, health.NewServer())
+	defer grpcS.Stop()
+
+	// nothing listens on this address
+	address := "127.0.0.1:1"
+	ServeInProcess(grpcS, address)
+	if inProcessDialer(address) != nil {
+		t.Fatalf("served in process before it is enabled")
+	}
+	EnableInProcessGrpc()
+	ServeInProcess(grpcS, address)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := GrpcDial(ctx, address, true, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial %s: %v", address, err)
+	}
+	defer conn.Close()
+	resp, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("check %s: %v", address, err)
+	}
+	if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		t.Errorf("status %v", resp.Status)
+	}
+}
@@ -41,6 +41,7 @@ const (
 	Seaweed_RaftListClusterServers_FullMethodName = "/master_pb.Seaweed/RaftListClusterServers"
 	Seaweed_RaftAddServer_FullMethodName          = "/master_pb.Seaweed/RaftAddServer"
 	Seaweed_RaftRemoveServer_FullMethodName       = "/master_pb.Seaweed/RaftRemoveServer"
+	Seaweed_WatchVolumeAssignments_FullMethodName = "/master_pb.Seaweed/WatchVolumeAssignments"
 )
 
 // SeaweedClient is the client API for Seaweed service.
@@ -69,6 +70,7 @@ type SeaweedClient interface {
 	RaftListClusterServers(ctx context.Context, in *RaftListClusterServersRequest, opts ...grpc.CallOption) (*RaftListClusterServersResponse, error)
 	RaftAddServer(ctx context.Context, in *RaftAddServerRequest, opts ...grpc.CallOption) (*RaftAddServerResponse, error)
 	RaftRemoveServer(ctx context.Context, in *RaftRemoveServerRequest, opts ...grpc.CallOption) (*RaftRemoveServerResponse, error)
+	WatchVolumeAssignments(ctx context.Context, in *WatchVolumeAssignmentsRequest, opts ...grpc.CallOption) (Seaweed_WatchVolumeAssignmentsClient, error)
 }
 
 type seaweedClient struct {
@@ -343,6 +345,38 @@ func (c *seaweedClient) RaftRemoveServer(ctx context.Context, in *RaftRemoveServ
 	return out, nil
 }
 
+func (c *seaweedClient) WatchVolumeAssignments(ctx context.Context, in *WatchVolumeAssignmentsRequest, opts ...grpc.CallOption) (Seaweed_WatchVolumeAssignmentsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Seaweed_ServiceDesc.Streams[3], Seaweed_WatchVolumeAssignments_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &seaweedWatchVolumeAssignmentsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Seaweed_WatchVolumeAssignmentsClient interface {
+	Recv() (*VolumeAssignmentEvent, error)
+	grpc.ClientStream
+}
+
+type seaweedWatchVolumeAssignmentsClient struct {
+	grpc.ClientStream
+}
+
+func (x *seaweedWatchVolumeAssignmentsClient) Recv() (*VolumeAssignmentEvent, error) {
+	m := new(VolumeAssignmentEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // SeaweedServer is the server API for Seaweed service.
 // All implementations must embed UnimplementedSeaweedServer
 // for forward compatibility
@@ -369,6 +403,7 @@ type SeaweedServer interface {
 	RaftListClusterServers(context.Context, *RaftListClusterServersRequest) (*RaftListClusterServersResponse, error)
 	RaftAddServer(context.Context, *RaftAddServerRequest) (*RaftAddServerResponse, error)
 	RaftRemoveServer(context.Context, *RaftRemoveServerRequest) (*RaftRemoveServerResponse, error)
+	WatchVolumeAssignments(*WatchVolumeAssignmentsRequest, Seaweed_WatchVolumeAssignmentsServer) error
 	mustEmbedUnimplementedSeaweedServer()
 }
 
@@ -442,6 +477,9 @@ func (UnimplementedSeaweedServer) RaftAddServer(context.Context, *RaftAddServerR
 func (UnimplementedSeaweedServer) RaftRemoveServer(context.Context, *RaftRemoveServerRequest) (*RaftRemoveServerResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RaftRemoveServer not implemented")
 }
+func (UnimplementedSeaweedServer) WatchVolumeAssignments(*WatchVolumeAssignmentsRequest, Seaweed_WatchVolumeAssignmentsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchVolumeAssignments not implemented")
+}
 func (UnimplementedSeaweedServer) mustEmbedUnimplementedSeaweedServer() {}
 
 // UnsafeSeaweedServer may be embedded to opt out of forward compatibility for this service.
@@ -875,6 +913,27 @@ func _Seaweed_RaftRemoveServer_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Seaweed_WatchVolumeAssignments_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchVolumeAssignmentsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SeaweedServer).WatchVolumeAssignments(m, &seaweedWatchVolumeAssignmentsServer{stream})
+}
+
+type Seaweed_WatchVolumeAssignmentsServer interface {
+	Send(*VolumeAssignmentEvent) error
+	grpc.ServerStream
+}
+
+type seaweedWatchVolumeAssignmentsServer struct {
+	grpc.ServerStream
+}
+
+func (x *seaweedWatchVolumeAssignmentsServer) Send(m *VolumeAssignmentEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 // Seaweed_ServiceDesc is the grpc.ServiceDesc for Seaweed service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -978,6 +1037,11 @@ var Seaweed_ServiceDesc = grpc.ServiceDesc{
 			ServerStreams: true,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "WatchVolumeAssignments",
+			Handler:       _Seaweed_WatchVolumeAssignments_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "master.proto",
 }
@@ -34,6 +34,13 @@ func uploadReaderAtToBlockBlob(ctx context.Context, reader io.ReaderAt, readerSi
 			// StageBlock will be called with blockSize blocks and a Parallelism of (BufferSize / BlockSize).
 		}
 	}
+	// a block blob can only commit up to BlockBlobMaxBlocks blocks, use larger blocks for large files
+	if o.BlockSize*BlockBlobMaxBlocks < readerSize {
+		o.BlockSize = (readerSize-1)/BlockBlobMaxBlocks + 1
+		if o.BlockSize > BlockBlobMaxStageBlockBytes {
+			return nil, fmt.Errorf("size %d is too large to upload to a block blob", readerSize)
+		}
+	}
 
 	if readerSize <= BlockBlobMaxUploadBlobBytes {
 		// If the size can fit in 1 Upload call, do it this way
@@ -0,0 +1,93 @@
+package azure
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Azure/azure-storage-blob-go/azblob"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+)
+
+// the instance metadata service of azure virtual machines, see
+// https://learn.microsoft.com/en-us/entra/identity/managed-identities-azure-resources/how-to-use-vm-token
+var msiTokenEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+
+const (
+	msiStorageResource = "https://storage.azure.com/"
+	msiRetryInterval   = 10 * time.Second
+	msiRefreshAhead    = 5 * time.Minute
+)
+
+type msiToken struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   string `json:"expires_in"`
+}
+
+func fetchMsiToken(clientId string) (token string, expiresIn time.Duration, err error) {
+	query := url.Values{}
+	query.Set("api-version", "2018-02-01")
+	query.Set("resource", msiStorageResource)
+	if clientId != "" {
+		query.Set("client_id", clientId)
+	}
+	req, err := http.NewRequest(http.MethodGet, msiTokenEndpoint+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", 0, err
+	}
+	req.Header.Set("Metadata", "true")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("request managed identity token: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("request managed identity token: %s", resp.Status)
+	}
+
+	var t msiToken
+	if err = json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return "", 0, fmt.Errorf("decode managed identity token: %v", err)
+	}
+	seconds, err := strconv.ParseInt(t.ExpiresIn, 10, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("parse managed identity token expires_in %q: %v", t.ExpiresIn, err)
+	}
+	return t.AccessToken, time.Duration(seconds) * time.Second, nil
+}
+
+// newMsiCredential returns a credential of the managed service identity,
+// refreshing the token a few minutes before it expires.
+func newMsiCredential(clientId string) (azblob.Credential, error) {
+	token, expiresIn, err := fetchMsiToken(clientId)
+	if err != nil {
+		return nil, err
+	}
+	fetched := true
+	// the refresher is called right away, and then after each returned duration
+	return azblob.NewTokenCredential(token, func(credential azblob.TokenCredential) time.Duration {
+		if !fetched {
+			newToken, newExpiresIn, err := fetchMsiToken(clientId)
+			if err != nil {
+				glog.Errorf("refresh azure managed identity token: %v", err)
+				return msiRetryInterval
+			}
+			credential.SetToken(newToken)
+			expiresIn = newExpiresIn
+		}
+		fetched = false
+		return msiRefreshAfter(expiresIn)
+	}), nil
+}
+
+func msiRefreshAfter(expiresIn time.Duration) time.Duration {
+	if expiresIn > 2*msiRefreshAhead {
+		return expiresIn - msiRefreshAhead
+	}
+	return expiresIn / 2
+}
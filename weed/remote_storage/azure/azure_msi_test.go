@@ -0,0 +1,46 @@
+package azure
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFetchMsiToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata") != "true" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.URL.Query().Get("resource") != msiStorageResource || r.URL.Query().Get("client_id") != "abc" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"access_token":"token1","expires_in":"3599","token_type":"Bearer"}`))
+	}))
+	defer server.Close()
+
+	defer func(endpoint string) { msiTokenEndpoint = endpoint }(msiTokenEndpoint)
+	msiTokenEndpoint = server.URL
+
+	token, expiresIn, err := fetchMsiToken("abc")
+	if err != nil {
+		t.Fatalf("fetch token: %v", err)
+	}
+	if token != "token1" || expiresIn != 3599*time.Second {
+		t.Errorf("token %s expires in %v", token, expiresIn)
+	}
+	if _, _, err = fetchMsiToken("other"); err == nil {
+		t.Errorf("expected error for unknown client id")
+	}
+}
+
+func TestMsiRefreshAfter(t *testing.T) {
+	if d := msiRefreshAfter(time.Hour); d != 55*time.Minute {
+		t.Errorf("refresh after %v", d)
+	}
+	if d := msiRefreshAfter(4 * time.Minute); d != 2*time.Minute {
+		t.Errorf("refresh after %v", d)
+	}
+}
@@ -34,25 +34,49 @@ func (s azureRemoteStorageMaker) Make(conf *remote_pb.RemoteConf) (remote_storag
 		conf: conf,
 	}
 
-	accountName, accountKey := conf.AzureAccountName, conf.AzureAccountKey
-	if len(accountName) == 0 || len(accountKey) == 0 {
-		accountName, accountKey = os.Getenv("AZURE_STORAGE_ACCOUNT"), os.Getenv("AZURE_STORAGE_ACCESS_KEY")
-		if len(accountName) == 0 || len(accountKey) == 0 {
-			return nil, fmt.Errorf("either AZURE_STORAGE_ACCOUNT or AZURE_STORAGE_ACCESS_KEY environment variable is not set")
-		}
+	accountName := conf.AzureAccountName
+	if len(accountName) == 0 {
+		accountName = os.Getenv("AZURE_STORAGE_ACCOUNT")
 	}
-
-	// Use your Storage account's name and key to create a credential object.
-	credential, err := azblob.NewSharedKeyCredential(accountName, accountKey)
-	if err != nil {
-		return nil, fmt.Errorf("invalid Azure credential with account name:%s: %v", accountName, err)
+	if len(accountName) == 0 && (len(conf.AzureSasToken) > 0 || conf.AzureUseMsi) {
+		return nil, fmt.Errorf("neither azure account name nor AZURE_STORAGE_ACCOUNT environment variable is set")
+	}
+	serviceUrl := fmt.Sprintf("https://%s.blob.core.windows.net", accountName)
+
+	var credential azblob.Credential
+	var err error
+	switch {
+	case len(conf.AzureSasToken) > 0:
+		// the shared access signature is sent as the query of each request
+		credential = azblob.NewAnonymousCredential()
+		serviceUrl += "?" + strings.TrimPrefix(conf.AzureSasToken, "?")
+	case conf.AzureUseMsi:
+		if credential, err = newMsiCredential(conf.AzureMsiClientId); err != nil {
+			return nil, fmt.Errorf("azure managed identity credential with account name:%s: %v", accountName, err)
+		}
+	default:
+		accountKey := conf.AzureAccountKey
+		if len(conf.AzureAccountName) == 0 || len(accountKey) == 0 {
+			accountName, accountKey = os.Getenv("AZURE_STORAGE_ACCOUNT"), os.Getenv("AZURE_STORAGE_ACCESS_KEY")
+			if len(accountName) == 0 || len(accountKey) == 0 {
+				return nil, fmt.Errorf("either AZURE_STORAGE_ACCOUNT or AZURE_STORAGE_ACCESS_KEY environment variable is not set")
+			}
+			serviceUrl = fmt.Sprintf("https://%s.blob.core.windows.net", accountName)
+		}
+		// Use your Storage account's name and key to create a credential object.
+		if credential, err = azblob.NewSharedKeyCredential(accountName, accountKey); err != nil {
+			return nil, fmt.Errorf("invalid Azure credential with account name:%s: %v", accountName, err)
+		}
 	}
-
 	// Create a request pipeline that is used to process HTTP(S) requests and responses.
 	p := azblob.NewPipeline(credential, azblob.PipelineOptions{})
 
 	// Create an ServiceURL object that wraps the service URL and a request pipeline.
-	u, _ := url.Parse(fmt.Sprintf("https://%s.blob.core.windows.net", accountName))
+	u, err := url.Parse(serviceUrl)
+	if err != nil {
+		// the url may have the shared access signature, so do not print it
+		return nil, fmt.Errorf("invalid azure service url of account name:%s", accountName)
+	}
 	client.serviceURL = azblob.NewServiceURL(*u, p)
 
 	return client, nil
@@ -120,6 +120,11 @@ func (fs *FilerSink) CreateEntry(key string, entry *filer_pb.Entry, signatures [
 				glog.V(3).Infof("already replicated %s", key)
 				return nil
 			}
+			if isNewerEntry(resp.Entry, entry) {
+				// the last write wins when both clusters write to the same file
+				glog.V(2).Infof("skip older %s", key)
+				return nil
+			}
 		}
 
 		replicatedChunks, err := fs.replicateChunks(entry.GetChunks(), key)
@@ -188,7 +193,7 @@ func (fs *FilerSink) UpdateEntry(key string, oldEntry *filer_pb.Entry, newParent
 
 	glog.V(4).Infof("oldEntry %+v, newEntry %+v, existingEntry: %+v", oldEntry, newEntry, existingEntry)
 
-	if existingEntry.Attributes.Mtime > newEntry.Attributes.Mtime {
+	if isNewerEntry(existingEntry, newEntry) {
 		// skip if already changed
 		// this usually happens when the messages are not ordered
 		glog.V(2).Infof("late updates %s", key)
@@ -256,3 +261,11 @@ func compareChunks(lookupFileIdFn wdclient.LookupFileIdFunctionType, oldEntry, n
 
 	return
 }
+
+// isNewerEntry tells whether entry a is modified later than entry b, up to nanoseconds
+func isNewerEntry(a, b *filer_pb.Entry) bool {
+	if a.GetAttributes().GetMtime() != b.GetAttributes().GetMtime() {
+		return a.GetAttributes().GetMtime() > b.GetAttributes().GetMtime()
+	}
+	return a.GetAttributes().GetMtimeNs() > b.GetAttributes().GetMtimeNs()
+}
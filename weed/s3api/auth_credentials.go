@@ -35,6 +35,7 @@ type IdentityAccessManagement struct {
 	hashMu            sync.RWMutex
 	domain            string
 	isAuthEnabled     bool
+	bucketPolicy      func(bucket string) *BucketPolicy
 }
 
 type Identity struct {
@@ -367,6 +368,14 @@ func (iam *IdentityAccessManagement) authRequest(r *http.Request, action Action)
 		authType = "Anonymous"
 		if identity, found = iam.lookupAnonymous(); !found {
 			r.Header.Set(s3_constants.AmzAuthType, authType)
+			bucket, object := s3_constants.GetBucketAndObject(r)
+			if iam.checkBucketPolicy(r, nil, bucket, object) == policyAllowed {
+				if errCode := iam.checkCopySource(r, nil); errCode != s3err.ErrNone {
+					return identity, errCode
+				}
+				r.Header.Set(s3_constants.AmzAccountId, s3_constants.AccountAnonymousId)
+				return identity, s3err.ErrNone
+			}
 			return identity, s3err.ErrAccessDenied
 		}
 	default:
@@ -384,8 +393,16 @@ func (iam *IdentityAccessManagement) authRequest(r *http.Request, action Action)
 
 	bucket, object := s3_constants.GetBucketAndObject(r)
 
-	if !identity.canDo(action, bucket, object) {
+	switch iam.checkBucketPolicy(r, identity, bucket, object) {
+	case policyDenied:
 		return identity, s3err.ErrAccessDenied
+	case policyNotMatched:
+		if !identity.canDo(action, bucket, object) {
+			return identity, s3err.ErrAccessDenied
+		}
+	}
+	if errCode := iam.checkCopySource(r, identity); errCode != s3err.ErrNone {
+		return identity, errCode
 	}
 
 	r.Header.Set(s3_constants.AmzAccountId, identity.Account.Id)
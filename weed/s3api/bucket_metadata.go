@@ -38,6 +38,12 @@ type BucketMetaData struct {
 
 	// A list of grants for access controls.
 	Acl []*s3.Grant `locationName:"AccessControlList" locationNameList:"Grant" type:"list"`
+
+	// Versioning status, "Enabled", "Suspended", or empty if versioning was never enabled
+	Versioning string
+
+	// The bucket policy, or nil if the bucket has no policy
+	Policy *BucketPolicy
 }
 
 type BucketRegistry struct {
@@ -132,6 +138,19 @@ func buildBucketMetadata(accountManager AccountManager, entry *filer_pb.Entry) *
 				glog.Warningf("Unmarshal ACP grants: %s(%v), bucket: %s", string(acpGrantsBytes), err, bucketMetadata.Name)
 			}
 		}
+
+		//versioning
+		bucketMetadata.Versioning = string(entry.Extended[s3_constants.ExtVersioningKey])
+
+		//bucket policy
+		if policyJson, ok := entry.Extended[s3_constants.ExtBucketPolicyKey]; ok && len(policyJson) > 0 {
+			policy, errCode := parseBucketPolicy(entry.Name, policyJson)
+			if errCode == s3err.ErrNone {
+				bucketMetadata.Policy = policy
+			} else {
+				glog.Warningf("Invalid bucket policy: %s, bucket: %s", string(policyJson), bucketMetadata.Name)
+			}
+		}
 	}
 	return bucketMetadata
 }
@@ -166,6 +185,15 @@ func (r *BucketRegistry) GetBucketMetadata(bucketName string) (*BucketMetaData,
 	return bucketMetadata, s3err.ErrNone
 }
 
+// GetBucketPolicy returns the policy of the bucket, or nil if the bucket has no policy or does not exist.
+func (r *BucketRegistry) GetBucketPolicy(bucketName string) *BucketPolicy {
+	bucketMetadata, errCode := r.GetBucketMetadata(bucketName)
+	if errCode != s3err.ErrNone {
+		return nil
+	}
+	return bucketMetadata.Policy
+}
+
 func (r *BucketRegistry) LoadBucketMetadataFromFiler(bucketName string) (*BucketMetaData, s3err.ErrorCode) {
 	r.notFoundLock.Lock()
 	defer r.notFoundLock.Unlock()
@@ -27,6 +27,7 @@ import (
 	"hash"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
@@ -55,10 +56,10 @@ func (iam *IdentityAccessManagement) calculateSeedSignature(r *http.Request) (cr
 	}
 
 	// Payload streaming.
-	payload := streamingContentSHA256
+	payload := req.Header.Get(s3_constants.AmzContentSha256)
 
-	// Payload for STREAMING signature should be 'STREAMING-AWS4-HMAC-SHA256-PAYLOAD'
-	if payload != req.Header.Get("X-Amz-Content-Sha256") {
+	// Payload for STREAMING signature should be 'STREAMING-AWS4-HMAC-SHA256-PAYLOAD', optionally followed by trailers
+	if payload != streamingContentSHA256 && payload != streamingContentSHA256Trailer {
 		return nil, "", "", time.Time{}, s3err.ErrContentSHA256Mismatch
 	}
 
@@ -139,7 +140,8 @@ func (iam *IdentityAccessManagement) newSignV4ChunkedReader(req *http.Request) (
 	if errCode != s3err.ErrNone {
 		return nil, errCode
 	}
-	return &s3ChunkedReader{
+	removeAwsChunkedEncoding(req.Header)
+	cr := &s3ChunkedReader{
 		cred:              ident,
 		reader:            bufio.NewReader(req.Body),
 		seedSignature:     seedSignature,
@@ -148,7 +150,43 @@ func (iam *IdentityAccessManagement) newSignV4ChunkedReader(req *http.Request) (
 		chunkSHA256Writer: sha256.New(),
 		state:             readChunkHeader,
 		iam:               iam,
-	}, s3err.ErrNone
+	}
+	if req.Header.Get(s3_constants.AmzContentSha256) == streamingContentSHA256Trailer {
+		cr.trailers = make(http.Header)
+	}
+	return cr, s3err.ErrNone
+}
+
+// newUnsignedChunkedReader decodes the aws-chunked body of a STREAMING-UNSIGNED-PAYLOAD-TRAILER request,
+// which has no chunk signatures. Its trailers are only available after the io.EOF.
+func newUnsignedChunkedReader(req *http.Request) io.ReadCloser {
+	removeAwsChunkedEncoding(req.Header)
+	return &s3ChunkedReader{
+		reader:   bufio.NewReader(req.Body),
+		state:    readChunkHeader,
+		unsigned: true,
+		trailers: make(http.Header),
+	}
+}
+
+// removeAwsChunkedEncoding keeps only the content encodings of the decoded body, which are saved with the object.
+func removeAwsChunkedEncoding(h http.Header) {
+	var encodings []string
+	for _, encoding := range strings.Split(h.Get("Content-Encoding"), ",") {
+		if encoding = strings.TrimSpace(encoding); encoding != "" && encoding != "aws-chunked" {
+			encodings = append(encodings, encoding)
+		}
+	}
+	if len(encodings) == 0 {
+		h.Del("Content-Encoding")
+	} else {
+		h.Set("Content-Encoding", strings.Join(encodings, ","))
+	}
+}
+
+// isUnsignedChunkedRequest tells whether the request body is an aws-chunked body without chunk signatures.
+func isUnsignedChunkedRequest(r *http.Request) bool {
+	return r.Header.Get(s3_constants.AmzContentSha256) == streamingUnsignedPayloadTrailer
 }
 
 // Represents the overall state that is required for decoding a
@@ -166,6 +204,8 @@ type s3ChunkedReader struct {
 	n                 uint64    // Unread bytes in chunk
 	err               error
 	iam               *IdentityAccessManagement
+	unsigned          bool        // the chunks have no signatures
+	trailers          http.Header // the trailing headers after the last chunk, nil if none are expected
 }
 
 // Read chunk reads the chunk token signature portion.
@@ -195,6 +235,7 @@ const (
 	readChunkTrailer
 	readChunk
 	verifyChunk
+	readTrailers
 	eofChunk
 )
 
@@ -209,6 +250,8 @@ func (cs chunkState) String() string {
 		stateString = "readChunk"
 	case verifyChunk:
 		stateString = "verifyChunk"
+	case readTrailers:
+		stateString = "readTrailers"
 	case eofChunk:
 		stateString = "eofChunk"
 
@@ -230,6 +273,10 @@ func (cr *s3ChunkedReader) Read(buf []byte) (n int, err error) {
 			// If we're at the end of a chunk.
 			if cr.n == 0 && cr.err == io.EOF {
 				cr.state = readChunkTrailer
+				if cr.trailers != nil {
+					// the trailing headers follow the last chunk header
+					cr.state = verifyChunk
+				}
 				cr.lastChunk = true
 				continue
 			}
@@ -265,7 +312,9 @@ func (cr *s3ChunkedReader) Read(buf []byte) (n int, err error) {
 			}
 
 			// Calculate sha256.
-			cr.chunkSHA256Writer.Write(rbuf[:n0])
+			if !cr.unsigned {
+				cr.chunkSHA256Writer.Write(rbuf[:n0])
+			}
 
 			// Update the bytes read into request buffer so far.
 			n += n0
@@ -279,30 +328,79 @@ func (cr *s3ChunkedReader) Read(buf []byte) (n int, err error) {
 				continue
 			}
 		case verifyChunk:
-			// Calculate the hashed chunk.
-			hashedChunk := hex.EncodeToString(cr.chunkSHA256Writer.Sum(nil))
-			// Calculate the chunk signature.
-			newSignature := cr.getChunkSignature(hashedChunk)
-			if !compareSignatureV4(cr.chunkSignature, newSignature) {
-				// Chunk signature doesn't match we return signature does not match.
-				cr.err = errors.New("chunk signature does not match")
-				return 0, cr.err
+			if !cr.unsigned {
+				// Calculate the hashed chunk.
+				hashedChunk := hex.EncodeToString(cr.chunkSHA256Writer.Sum(nil))
+				// Calculate the chunk signature.
+				newSignature := cr.getChunkSignature(hashedChunk)
+				if !compareSignatureV4(cr.chunkSignature, newSignature) {
+					// Chunk signature doesn't match we return signature does not match.
+					cr.err = errors.New("chunk signature does not match")
+					return 0, cr.err
+				}
+				// Newly calculated signature becomes the seed for the next chunk
+				// this follows the chaining.
+				cr.seedSignature = newSignature
+				cr.chunkSHA256Writer.Reset()
 			}
-			// Newly calculated signature becomes the seed for the next chunk
-			// this follows the chaining.
-			cr.seedSignature = newSignature
-			cr.chunkSHA256Writer.Reset()
-			if cr.lastChunk {
+			if cr.lastChunk && cr.trailers != nil {
+				cr.state = readTrailers
+			} else if cr.lastChunk {
 				cr.state = eofChunk
 			} else {
 				cr.state = readChunkHeader
 			}
+		case readTrailers:
+			if cr.err = cr.readS3ChunkTrailers(); cr.err != nil {
+				return 0, cr.err
+			}
+			cr.state = eofChunk
 		case eofChunk:
 			return n, io.EOF
 		}
 	}
 }
 
+// readS3ChunkTrailers reads the "name:value" trailing headers up to the empty line.
+// In a signed body, the trailers end with the x-amz-trailer-signature of the other trailers.
+func (cr *s3ChunkedReader) readS3ChunkTrailers() error {
+	var signed []byte
+	var signature string
+	for {
+		line, err := cr.reader.ReadSlice('\n')
+		if err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			} else if err == bufio.ErrBufferFull {
+				err = errLineTooLong
+			}
+			return err
+		}
+		line = trimTrailingWhitespace(line)
+		if len(line) == 0 {
+			break
+		}
+		name, value, found := bytes.Cut(line, []byte(":"))
+		if !found {
+			return errMalformedEncoding
+		}
+		if http.CanonicalHeaderKey(string(name)) == s3_constants.AmzTrailerSignature {
+			signature = string(bytes.TrimSpace(value))
+			continue
+		}
+		cr.trailers.Add(string(name), string(bytes.TrimSpace(value)))
+		signed = append(append(signed, line...), '\n')
+	}
+	if cr.unsigned {
+		return nil
+	}
+	cr.chunkSHA256Writer.Write(signed)
+	if !compareSignatureV4(signature, cr.getTrailerSignature(hex.EncodeToString(cr.chunkSHA256Writer.Sum(nil)))) {
+		return errors.New("trailer signature does not match")
+	}
+	return nil
+}
+
 // getChunkSignature - get chunk signature.
 func (cr *s3ChunkedReader) getChunkSignature(hashedChunk string) string {
 	// Calculate string to sign.
@@ -323,6 +421,23 @@ func (cr *s3ChunkedReader) getChunkSignature(hashedChunk string) string {
 	)
 }
 
+// getTrailerSignature - get the signature of the trailing headers, chained to the last chunk signature.
+func (cr *s3ChunkedReader) getTrailerSignature(hashedTrailers string) string {
+	stringToSign := signV4ChunkedAlgorithmTrailer + "\n" +
+		cr.seedDate.Format(iso8601Format) + "\n" +
+		getScope(cr.seedDate, cr.region) + "\n" +
+		cr.seedSignature + "\n" +
+		hashedTrailers
+
+	return cr.iam.getSignature(
+		cr.cred.SecretKey,
+		cr.seedDate,
+		cr.region,
+		"s3",
+		stringToSign,
+	)
+}
+
 // readCRLF - check if reader only has '\r\n' CRLF character.
 // returns malformed encoding if it doesn't.
 func readCRLF(reader io.Reader) error {
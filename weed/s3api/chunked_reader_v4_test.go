@@ -0,0 +1,102 @@
+package s3api
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+)
+
+func TestSignedChunkedReaderTrailer(t *testing.T) {
+	iam := &IdentityAccessManagement{
+		hashes:       make(map[string]*sync.Pool),
+		hashCounters: make(map[string]*int32),
+	}
+	date := time.Date(2013, 5, 24, 0, 0, 0, 0, time.UTC)
+	region, secretKey, seedSignature := "us-east-1", "secret", "seed"
+	signingKey := getSigningKey(secretKey, date.Format(yyyymmdd), region, "s3")
+	sign := func(algorithm, previous, hashed string) string {
+		h := hmac.New(sha256.New, signingKey)
+		h.Write([]byte(algorithm + "\n" + date.Format(iso8601Format) + "\n" + getScope(date, region) + "\n" + previous + "\n" + hashed))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	signedBody := func(trailerValue string) string {
+		var body strings.Builder
+		previous := seedSignature
+		for _, chunk := range []string{"hello ", "trailer", ""} {
+			hashed := sha256.Sum256([]byte(chunk))
+			previous = sign(signV4ChunkedAlgorithm, previous, emptySHA256+"\n"+hex.EncodeToString(hashed[:]))
+			fmt.Fprintf(&body, "%x;chunk-signature=%s\r\n", len(chunk), previous)
+			if chunk != "" {
+				body.WriteString(chunk + "\r\n")
+			}
+		}
+		hashed := sha256.Sum256([]byte("x-amz-checksum-crc32:AAAAAA==\n"))
+		fmt.Fprintf(&body, "x-amz-checksum-crc32:%s\r\n", trailerValue)
+		fmt.Fprintf(&body, "x-amz-trailer-signature:%s\r\n\r\n", sign(signV4ChunkedAlgorithmTrailer, previous, hex.EncodeToString(hashed[:])))
+		return body.String()
+	}
+	newReader := func(body string) *s3ChunkedReader {
+		return &s3ChunkedReader{
+			cred:              &Credential{SecretKey: secretKey},
+			reader:            bufio.NewReader(strings.NewReader(body)),
+			seedSignature:     seedSignature,
+			seedDate:          date,
+			region:            region,
+			chunkSHA256Writer: sha256.New(),
+			state:             readChunkHeader,
+			iam:               iam,
+			trailers:          make(http.Header),
+		}
+	}
+
+	cr := newReader(signedBody("AAAAAA=="))
+	data, err := io.ReadAll(cr)
+	if err != nil || string(data) != "hello trailer" {
+		t.Fatalf("read %q: %v", data, err)
+	}
+	if got := cr.trailers.Get(s3_constants.AmzChecksumCRC32); got != "AAAAAA==" {
+		t.Errorf("trailer %q", got)
+	}
+
+	if _, err = io.ReadAll(newReader(signedBody("BBBBBB=="))); err == nil {
+		t.Errorf("read a modified trailer")
+	}
+}
+
+func TestUnsignedChunkedReader(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPut, "/bucket1/obj", strings.NewReader("6\r\nhello \r\n7\r\ntrailer\r\n0\r\nx-amz-checksum-crc32:AAAAAA==\r\n\r\n"))
+	r.Header.Set("Content-Encoding", "aws-chunked,gzip")
+	r.Header.Set(s3_constants.AmzContentSha256, streamingUnsignedPayloadTrailer)
+	if !isUnsignedChunkedRequest(r) {
+		t.Fatalf("not an unsigned aws-chunked request")
+	}
+
+	reader := newUnsignedChunkedReader(r)
+	data, err := io.ReadAll(reader)
+	if err != nil || string(data) != "hello trailer" {
+		t.Fatalf("read %q: %v", data, err)
+	}
+	if got := reader.(*s3ChunkedReader).trailers.Get(s3_constants.AmzChecksumCRC32); got != "AAAAAA==" {
+		t.Errorf("trailer %q", got)
+	}
+	if got := r.Header.Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("content encoding %q", got)
+	}
+
+	r = httptest.NewRequest(http.MethodPut, "/bucket1/obj", strings.NewReader("6\r\nhello \r\n0\r\nx-amz-checksum-crc32:AAAAAA==\r\n"))
+	if _, err = io.ReadAll(newUnsignedChunkedReader(r)); err == nil {
+		t.Errorf("read a body without the end of the trailers")
+	}
+}
@@ -73,6 +73,8 @@ func (s3a *S3ApiServer) createMultipartUpload(input *s3.CreateMultipartUploadInp
 type CompleteMultipartUploadResult struct {
 	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ CompleteMultipartUploadResult"`
 	s3.CompleteMultipartUploadOutput
+	// VersionId is returned in the x-amz-version-id header, not in the xml body
+	VersionId *string `xml:"-"`
 }
 
 func (s3a *S3ApiServer) completeMultipartUpload(input *s3.CompleteMultipartUploadInput, parts *CompleteMultipartUpload) (output *CompleteMultipartUploadResult, code s3err.ErrorCode) {
@@ -218,16 +220,29 @@ func (s3a *S3ApiServer) completeMultipartUpload(input *s3.CompleteMultipartUploa
 	}
 
 	entryName, dirName := s3a.getEntryNameAndDir(input)
+	object := strings.TrimPrefix(dirName+"/"+entryName, fmt.Sprintf("%s/%s", s3a.option.BucketsPath, *input.Bucket))
+	unlock := s3a.lockObject(*input.Bucket, object)
+	defer unlock()
+	versionId, err := s3a.prepareNewVersion(*input.Bucket, object)
+	if err != nil {
+		glog.Errorf("completeMultipartUpload prepare new version of %s/%s: %v", dirName, entryName, err)
+		return nil, s3err.ErrInternalError
+	}
 	err = s3a.mkFile(dirName, entryName, finalParts, func(entry *filer_pb.Entry) {
 		if entry.Extended == nil {
 			entry.Extended = make(map[string][]byte)
 		}
 		entry.Extended[s3_constants.SeaweedFSUploadId] = []byte(*input.UploadId)
 		for k, v := range pentry.Extended {
-			if k != "key" {
+			if k != "key" && k != s3_constants.ExtVersionIdKey && k != s3_constants.ExtDeleteMarkerKey {
 				entry.Extended[k] = v
 			}
 		}
+		if versionId != "" {
+			entry.Extended[s3_constants.ExtVersionIdKey] = []byte(versionId)
+		}
+		now := time.Now()
+		entry.Attributes.Mtime, entry.Attributes.MtimeNs = now.Unix(), int64(now.Nanosecond())
 		if pentry.Attributes.Mime != "" {
 			entry.Attributes.Mime = pentry.Attributes.Mime
 		} else if mime != "" {
@@ -238,6 +253,7 @@ func (s3a *S3ApiServer) completeMultipartUpload(input *s3.CompleteMultipartUploa
 
 	if err != nil {
 		glog.Errorf("completeMultipartUpload %s/%s error: %v", dirName, entryName, err)
+		s3a.restoreLatestVersion(*input.Bucket, object)
 		return nil, s3err.ErrInternalError
 	}
 
@@ -249,6 +265,9 @@ func (s3a *S3ApiServer) completeMultipartUpload(input *s3.CompleteMultipartUploa
 			Key:      objectKey(input.Key),
 		},
 	}
+	if versionId != "" {
+		output.VersionId = aws.String(versionId)
+	}
 
 	for _, deleteEntry := range deleteEntries {
 		//delete unused part data
@@ -302,9 +321,10 @@ func (s3a *S3ApiServer) abortMultipartUpload(input *s3.AbortMultipartUploadInput
 		glog.V(1).Infof("bucket %s abort upload %s: %v", *input.Bucket, *input.UploadId, err)
 		return nil, s3err.ErrNoSuchUpload
 	}
-	if exists {
-		err = s3a.rm(s3a.genUploadsFolder(*input.Bucket), *input.UploadId, true, true)
+	if !exists {
+		return nil, s3err.ErrNoSuchUpload
 	}
+	err = s3a.rm(s3a.genUploadsFolder(*input.Bucket), *input.UploadId, true, true)
 	if err != nil {
 		glog.V(1).Infof("bucket %s remove upload %s: %v", *input.Bucket, *input.UploadId, err)
 		return nil, s3err.ErrInternalError
@@ -361,10 +381,14 @@ func (s3a *S3ApiServer) listMultipartUploads(input *s3.ListMultipartUploadsInput
 			if *input.Prefix != "" && !strings.HasPrefix(key, *input.Prefix) {
 				continue
 			}
-			output.Upload = append(output.Upload, &s3.MultipartUpload{
+			upload := &s3.MultipartUpload{
 				Key:      objectKey(aws.String(key)),
 				UploadId: aws.String(entry.Name),
-			})
+			}
+			if entry.Attributes != nil && entry.Attributes.Crtime > 0 {
+				upload.Initiated = aws.Time(time.Unix(entry.Attributes.Crtime, 0).UTC())
+			}
+			output.Upload = append(output.Upload, upload)
 			uploadsCount += 1
 		}
 		if uploadsCount >= *input.MaxUploads {
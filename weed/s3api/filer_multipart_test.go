@@ -74,3 +74,25 @@ func Test_parsePartNumber(t *testing.T) {
 		})
 	}
 }
+
+func TestListMultipartUploadsResult(t *testing.T) {
+
+	expected := `<?xml version="1.0" encoding="UTF-8"?>
+<ListMultipartUploadsResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><Bucket>example-bucket</Bucket><Upload><Initiated>2024-01-02T03:04:05Z</Initiated><Key>example-object</Key><UploadId>example-upload-id</UploadId></Upload></ListMultipartUploadsResult>`
+	response := &ListMultipartUploadsResult{
+		Bucket: aws.String("example-bucket"),
+		Upload: []*s3.MultipartUpload{
+			{
+				Initiated: aws.Time(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+				Key:       aws.String("example-object"),
+				UploadId:  aws.String("example-upload-id"),
+			},
+		},
+	}
+
+	encoded := string(s3err.EncodeXMLResponse(response))
+	if encoded != expected {
+		t.Errorf("unexpected output: %s\nexpecting:%s", encoded, expected)
+	}
+
+}
@@ -4,4 +4,17 @@ const (
 	ExtAmzOwnerKey  = "Seaweed-X-Amz-Owner"
 	ExtAmzAclKey    = "Seaweed-X-Amz-Acl"
 	ExtOwnershipKey = "Seaweed-X-Amz-Ownership"
+
+	ExtVersioningKey   = "Seaweed-X-Amz-Versioning"
+	ExtVersionIdKey    = "Seaweed-X-Amz-Version-Id"
+	ExtDeleteMarkerKey = "Seaweed-X-Amz-Delete-Marker"
+
+	ExtLifecycleKey    = "Seaweed-X-Amz-Lifecycle"
+	ExtBucketPolicyKey = "Seaweed-X-Amz-Bucket-Policy"
+)
+
+// bucket versioning status
+const (
+	VersioningEnabled   = "Enabled"
+	VersioningSuspended = "Suspended"
 )
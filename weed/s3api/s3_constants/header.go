@@ -51,6 +51,31 @@ const (
 	AmzAclWriteAcp    = "X-Amz-Grant-Write-Acp"
 
 	AmzMpPartsCount = "X-Amz-Mp-Parts-Count"
+
+	// S3 versioning
+	AmzVersionId    = "X-Amz-Version-Id"
+	AmzDeleteMarker = "X-Amz-Delete-Marker"
+
+	// S3 server side encryption with customer provided keys
+	AmzServerSideEncryptionCustomerAlgorithm = "X-Amz-Server-Side-Encryption-Customer-Algorithm"
+	AmzServerSideEncryptionCustomerKey       = "X-Amz-Server-Side-Encryption-Customer-Key"
+	AmzServerSideEncryptionCustomerKeyMD5    = "X-Amz-Server-Side-Encryption-Customer-Key-Md5"
+
+	// saved by the filer in the entry extended attributes, the customer key itself is never saved
+	SeaweedFSSSECustomerAlgorithm = "Seaweed-Sse-C-Algorithm"
+	SeaweedFSSSECustomerKeyMD5    = "Seaweed-Sse-C-Key-Md5"
+	SeaweedFSSSECustomerIV        = "Seaweed-Sse-C-Iv"
+
+	// S3 additional checksums, the base64 encoded digest of the object data
+	AmzChecksumCRC32  = "X-Amz-Checksum-Crc32"
+	AmzChecksumCRC32C = "X-Amz-Checksum-Crc32c"
+	AmzChecksumSHA1   = "X-Amz-Checksum-Sha1"
+	AmzChecksumSHA256 = "X-Amz-Checksum-Sha256"
+	// the checksum headers sent after an aws-chunked body
+	AmzTrailer          = "X-Amz-Trailer"
+	AmzTrailerSignature = "X-Amz-Trailer-Signature"
+	// the sha256 of the payload, or how the payload is sent, e.g. as an aws-chunked body
+	AmzContentSha256 = "X-Amz-Content-Sha256"
 )
 
 // Non-Standard S3 HTTP request constants
@@ -0,0 +1,133 @@
+package s3api
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+const (
+	lifecycleScanInterval         = time.Hour
+	maxLifecycleConfigurationSize = 1 << 20
+)
+
+// validateLifecycle only accepts expiration by days, optionally filtered by a prefix.
+func validateLifecycle(lifecycle *Lifecycle) s3err.ErrorCode {
+	for _, rule := range lifecycle.Rules {
+		if rule.Status != Enabled && rule.Status != Disabled {
+			return s3err.ErrMalformedXML
+		}
+		if rule.Transition.Days > 0 || !rule.Transition.Date.IsZero() || rule.Transition.StorageClass != "" {
+			return s3err.ErrNotImplemented
+		}
+		if !rule.Expiration.Date.IsZero() {
+			return s3err.ErrNotImplemented
+		}
+		if rule.Filter.Tag.Key != "" || len(rule.Filter.And.Tags) > 0 {
+			return s3err.ErrNotImplemented
+		}
+		if rule.Expiration.Days < 0 {
+			return s3err.ErrInvalidRequest
+		}
+	}
+	return s3err.ErrNone
+}
+
+func lifecycleRulePrefix(rule Rule) string {
+	switch {
+	case rule.Filter.Prefix.set:
+		return rule.Filter.Prefix.val
+	case rule.Filter.And.Prefix.set:
+		return rule.Filter.And.Prefix.val
+	default:
+		return rule.Prefix.val
+	}
+}
+
+// loopExpireObjects deletes the objects past the expiration days of the bucket lifecycle rules.
+// Every s3 gateway runs the scan, deleting an object twice does no harm.
+func (s3a *S3ApiServer) loopExpireObjects() {
+	for {
+		time.Sleep(lifecycleScanInterval)
+		s3a.expireObjects(time.Now())
+	}
+}
+
+func (s3a *S3ApiServer) expireObjects(now time.Time) {
+	err := filer_pb.ReadDirAllEntries(s3a, util.FullPath(s3a.option.BucketsPath), "", func(entry *filer_pb.Entry, isLast bool) error {
+		if !entry.IsDirectory || len(entry.Extended[s3_constants.ExtLifecycleKey]) == 0 {
+			return nil
+		}
+		lifecycle := Lifecycle{}
+		if err := xmlDecoder(bytes.NewReader(entry.Extended[s3_constants.ExtLifecycleKey]), &lifecycle, 0); err != nil {
+			glog.Errorf("parse lifecycle configuration of bucket %s: %v", entry.Name, err)
+			return nil
+		}
+		versioned := string(entry.Extended[s3_constants.ExtVersioningKey]) != ""
+		for _, rule := range lifecycle.Rules {
+			if rule.Status != Enabled || rule.Expiration.Days <= 0 {
+				continue
+			}
+			cutoff := now.Add(-time.Duration(rule.Expiration.Days) * 24 * time.Hour)
+			if err := s3a.expireBucketObjects(entry.Name, lifecycleRulePrefix(rule), cutoff, versioned); err != nil {
+				glog.Errorf("expire objects in bucket %s rule %s: %v", entry.Name, rule.ID, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		glog.Errorf("list buckets to expire objects: %v", err)
+	}
+}
+
+// expireBucketObjects deletes the objects under the prefix last modified before the cutoff.
+// In versioned buckets, a delete marker is added instead, as a DeleteObject call would do.
+func (s3a *S3ApiServer) expireBucketObjects(bucket, prefix string, cutoff time.Time, versioned bool) error {
+	bucketDir := fmt.Sprintf("%s/%s", s3a.option.BucketsPath, bucket)
+	prefixDir, namePrefix := "", prefix
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		prefixDir, namePrefix = "/"+prefix[:i], prefix[i+1:]
+	}
+
+	var expire func(dir, namePrefix string) error
+	expire = func(dir, namePrefix string) error {
+		var expired []string
+		err := filer_pb.ReadDirAllEntries(s3a, util.FullPath(bucketDir+dir), namePrefix, func(entry *filer_pb.Entry, isLast bool) error {
+			if entry.IsDirectory {
+				if dir == "" && (entry.Name == s3_constants.MultipartUploadsFolder || entry.Name == s3_constants.VersionsFolder) {
+					return nil
+				}
+				return expire(dir+"/"+entry.Name, "")
+			}
+			if entry.Attributes == nil || time.Unix(entry.Attributes.Mtime, 0).After(cutoff) || isDeleteMarker(entry) {
+				return nil
+			}
+			expired = append(expired, entry.Name)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, name := range expired {
+			object := dir + "/" + name
+			glog.V(2).Infof("lifecycle expires %s%s", bucket, object)
+			if versioned {
+				_, _, err = s3a.deleteVersionedObject(bucket, object, "")
+			} else {
+				err = s3a.rm(bucketDir+dir, name, true, false)
+			}
+			if err != nil {
+				return fmt.Errorf("delete %s%s: %v", bucket, object, err)
+			}
+		}
+		return nil
+	}
+	return expire(prefixDir, namePrefix)
+}
@@ -0,0 +1,72 @@
+package s3api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+)
+
+func TestValidateLifecycle(t *testing.T) {
+	tests := []struct {
+		name       string
+		xml        string
+		wantCode   s3err.ErrorCode
+		wantPrefix string
+	}{
+		{
+			name:       "expiration days with filter prefix",
+			xml:        `<LifecycleConfiguration><Rule><ID>logs</ID><Status>Enabled</Status><Filter><Prefix>logs/</Prefix></Filter><Expiration><Days>7</Days></Expiration></Rule></LifecycleConfiguration>`,
+			wantCode:   s3err.ErrNone,
+			wantPrefix: "logs/",
+		},
+		{
+			name:       "expiration days with and prefix",
+			xml:        `<LifecycleConfiguration><Rule><Status>Enabled</Status><Filter><And><Prefix>tmp/</Prefix></And></Filter><Expiration><Days>1</Days></Expiration></Rule></LifecycleConfiguration>`,
+			wantCode:   s3err.ErrNone,
+			wantPrefix: "tmp/",
+		},
+		{
+			name:       "expiration days with rule prefix",
+			xml:        `<LifecycleConfiguration><Rule><Status>Disabled</Status><Prefix>old</Prefix><Expiration><Days>30</Days></Expiration></Rule></LifecycleConfiguration>`,
+			wantCode:   s3err.ErrNone,
+			wantPrefix: "old",
+		},
+		{
+			name:     "transition",
+			xml:      `<LifecycleConfiguration><Rule><Status>Enabled</Status><Filter><Prefix></Prefix></Filter><Transition><Days>30</Days><StorageClass>GLACIER</StorageClass></Transition></Rule></LifecycleConfiguration>`,
+			wantCode: s3err.ErrNotImplemented,
+		},
+		{
+			name:     "expiration date",
+			xml:      `<LifecycleConfiguration><Rule><Status>Enabled</Status><Filter><Prefix>a/</Prefix></Filter><Expiration><Date>2030-01-01T00:00:00Z</Date></Expiration></Rule></LifecycleConfiguration>`,
+			wantCode: s3err.ErrNotImplemented,
+		},
+		{
+			name:     "tag filter",
+			xml:      `<LifecycleConfiguration><Rule><Status>Enabled</Status><Filter><Tag><Key>k</Key><Value>v</Value></Tag></Filter><Expiration><Days>1</Days></Expiration></Rule></LifecycleConfiguration>`,
+			wantCode: s3err.ErrNotImplemented,
+		},
+		{
+			name:     "unknown status",
+			xml:      `<LifecycleConfiguration><Rule><Status>On</Status><Expiration><Days>1</Days></Expiration></Rule></LifecycleConfiguration>`,
+			wantCode: s3err.ErrMalformedXML,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lifecycle := Lifecycle{}
+			if err := xmlDecoder(strings.NewReader(tt.xml), &lifecycle, 0); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if code := validateLifecycle(&lifecycle); code != tt.wantCode {
+				t.Fatalf("validateLifecycle = %v, want %v", code, tt.wantCode)
+			}
+			if tt.wantCode == s3err.ErrNone {
+				if prefix := lifecycleRulePrefix(lifecycle.Rules[0]); prefix != tt.wantPrefix {
+					t.Errorf("lifecycleRulePrefix = %q, want %q", prefix, tt.wantPrefix)
+				}
+			}
+		})
+	}
+}
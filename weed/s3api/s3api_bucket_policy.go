@@ -0,0 +1,391 @@
+package s3api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+)
+
+// the same limit as AWS S3
+const maxBucketPolicySize = 20 * 1024
+
+const (
+	policyEffectAllow = "Allow"
+	policyEffectDeny  = "Deny"
+
+	policyActionAll          = "s3:*"
+	policyActionGetObject    = "s3:GetObject"
+	policyActionPutObject    = "s3:PutObject"
+	policyActionDeleteObject = "s3:DeleteObject"
+
+	policyResourcePrefix = "arn:aws:s3:::"
+)
+
+type policyDecision int
+
+const (
+	policyNotMatched policyDecision = iota
+	policyAllowed
+	policyDenied
+)
+
+// BucketPolicy is an IAM style JSON policy document attached to a bucket.
+type BucketPolicy struct {
+	Version   string                  `json:"Version"`
+	Id        string                  `json:"Id,omitempty"`
+	Statement []BucketPolicyStatement `json:"Statement"`
+}
+
+type BucketPolicyStatement struct {
+	Sid       string           `json:"Sid,omitempty"`
+	Effect    string           `json:"Effect"`
+	Principal *policyPrincipal `json:"Principal"`
+	Action    policyStrings    `json:"Action"`
+	Resource  policyStrings    `json:"Resource"`
+
+	// not supported, only decoded to reject the policies using them
+	NotPrincipal json.RawMessage `json:"NotPrincipal,omitempty"`
+	NotAction    json.RawMessage `json:"NotAction,omitempty"`
+	NotResource  json.RawMessage `json:"NotResource,omitempty"`
+	Condition    json.RawMessage `json:"Condition,omitempty"`
+}
+
+// policyStrings is a policy element which can be either a string or a list of strings.
+type policyStrings []string
+
+func (s *policyStrings) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*s = policyStrings{one}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// policyPrincipal is either "*" or {"AWS": "..."} with one or a list of principals.
+type policyPrincipal struct {
+	AWS policyStrings `json:"AWS"`
+}
+
+func (p *policyPrincipal) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		if one != "*" {
+			return fmt.Errorf("invalid principal %q", one)
+		}
+		p.AWS = policyStrings{"*"}
+		return nil
+	}
+	type principal policyPrincipal
+	return json.Unmarshal(data, (*principal)(p))
+}
+
+// matches tells whether the principal includes the identity, with a nil identity for anonymous requests.
+// Besides "*", an identity is matched by its name, its account id or an ARN ending with ":user/<name>".
+func (p *policyPrincipal) matches(identity *Identity) bool {
+	for _, principal := range p.AWS {
+		if principal == "*" {
+			return true
+		}
+		if identity == nil || identity.isAnonymous() {
+			continue
+		}
+		if principal == identity.Name || strings.HasSuffix(principal, ":user/"+identity.Name) {
+			return true
+		}
+		if identity.Account != nil && (principal == identity.Account.Id || principal == "arn:aws:iam::"+identity.Account.Id+":root") {
+			return true
+		}
+	}
+	return false
+}
+
+func parseBucketPolicy(bucket string, data []byte) (*BucketPolicy, s3err.ErrorCode) {
+	policy := &BucketPolicy{}
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(policy); err != nil {
+		glog.V(3).Infof("bucket %s policy decode: %v", bucket, err)
+		return nil, s3err.ErrMalformedPolicy
+	}
+	return policy, validateBucketPolicy(bucket, policy)
+}
+
+func validateBucketPolicy(bucket string, policy *BucketPolicy) s3err.ErrorCode {
+	if policy.Version != "2012-10-17" && policy.Version != "2008-10-17" {
+		return s3err.ErrMalformedPolicy
+	}
+	if len(policy.Statement) == 0 {
+		return s3err.ErrMalformedPolicy
+	}
+	for _, statement := range policy.Statement {
+		if statement.NotPrincipal != nil || statement.NotAction != nil || statement.NotResource != nil || statement.Condition != nil {
+			return s3err.ErrNotImplemented
+		}
+		if statement.Effect != policyEffectAllow && statement.Effect != policyEffectDeny {
+			return s3err.ErrMalformedPolicy
+		}
+		if statement.Principal == nil || len(statement.Principal.AWS) == 0 {
+			return s3err.ErrMalformedPolicy
+		}
+		if len(statement.Action) == 0 || len(statement.Resource) == 0 {
+			return s3err.ErrMalformedPolicy
+		}
+		for _, action := range statement.Action {
+			switch action {
+			case policyActionAll, policyActionGetObject, policyActionPutObject, policyActionDeleteObject:
+			default:
+				if !strings.HasPrefix(action, "s3:") {
+					return s3err.ErrMalformedPolicy
+				}
+				return s3err.ErrNotImplemented
+			}
+		}
+		for _, resource := range statement.Resource {
+			if resource != policyResourcePrefix+bucket && !strings.HasPrefix(resource, policyResourcePrefix+bucket+"/") {
+				return s3err.ErrMalformedPolicy
+			}
+		}
+	}
+	return s3err.ErrNone
+}
+
+// evaluate checks the statements for the action on the resource. An explicit deny wins over any allow.
+func (policy *BucketPolicy) evaluate(identity *Identity, action, resource string) policyDecision {
+	decision := policyNotMatched
+	for _, statement := range policy.Statement {
+		if !statement.Principal.matches(identity) || !statement.matchesAction(action) || !statement.matchesResource(resource) {
+			continue
+		}
+		if statement.Effect == policyEffectDeny {
+			return policyDenied
+		}
+		decision = policyAllowed
+	}
+	return decision
+}
+
+func (statement *BucketPolicyStatement) matchesAction(action string) bool {
+	for _, a := range statement.Action {
+		if a == policyActionAll || a == action {
+			return true
+		}
+	}
+	return false
+}
+
+func (statement *BucketPolicyStatement) matchesResource(resource string) bool {
+	for _, pattern := range statement.Resource {
+		if policyWildcardMatch(pattern, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// policyWildcardMatch matches the ARN wildcards, where "*" matches any characters including "/", and "?" any one character.
+func policyWildcardMatch(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(s); i >= 0; i-- {
+				if policyWildcardMatch(pattern[1:], s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+		default:
+			if len(s) == 0 || pattern[0] != s[0] {
+				return false
+			}
+		}
+		pattern, s = pattern[1:], s[1:]
+	}
+	return len(s) == 0
+}
+
+// objectSubResources are the query parameters of the object requests not covered by the supported policy actions
+var objectSubResources = []string{"acl", "tagging", "retention", "legal-hold", "object-lock", "versionId", "attributes", "torrent"}
+
+// bucketPolicyAction maps an object request to the policy action, or returns "" if the request is not covered.
+func bucketPolicyAction(r *http.Request, object string) string {
+	if object == "" || object == "/" {
+		return ""
+	}
+	query := r.URL.Query()
+	for _, subResource := range objectSubResources {
+		if query.Has(subResource) {
+			return ""
+		}
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		if query.Has("uploadId") {
+			return ""
+		}
+		return policyActionGetObject
+	case http.MethodPut:
+		return policyActionPutObject
+	case http.MethodPost:
+		if query.Has("uploads") || query.Has("uploadId") {
+			return policyActionPutObject
+		}
+		if query.Has("select") {
+			return policyActionGetObject
+		}
+	case http.MethodDelete:
+		if query.Has("uploadId") {
+			return ""
+		}
+		return policyActionDeleteObject
+	}
+	return ""
+}
+
+// checkBucketPolicy evaluates the bucket policy, if any, for the request by the identity, which is nil for anonymous requests.
+func (iam *IdentityAccessManagement) checkBucketPolicy(r *http.Request, identity *Identity, bucket, object string) policyDecision {
+	if iam.bucketPolicy == nil || bucket == "" {
+		return policyNotMatched
+	}
+	action := bucketPolicyAction(r, object)
+	if action == "" {
+		return policyNotMatched
+	}
+	policy := iam.bucketPolicy(bucket)
+	if policy == nil {
+		return policyNotMatched
+	}
+	return policy.evaluate(identity, action, policyResourcePrefix+bucket+object)
+}
+
+// checkCopySource requires the read access to the source object of CopyObject and UploadPartCopy.
+// The copy is authorized as a write to the destination, which alone would allow reading any bucket through a copy.
+func (iam *IdentityAccessManagement) checkCopySource(r *http.Request, identity *Identity) s3err.ErrorCode {
+	copySource := r.Header.Get("X-Amz-Copy-Source")
+	if r.Method != http.MethodPut || copySource == "" {
+		return s3err.ErrNone
+	}
+	if unescaped, err := url.QueryUnescape(copySource); err == nil {
+		copySource = unescaped
+	}
+	srcBucket, srcObject := pathToBucketAndObject(copySource)
+	if iam.bucketPolicy != nil && srcBucket != "" {
+		if policy := iam.bucketPolicy(srcBucket); policy != nil {
+			switch policy.evaluate(identity, policyActionGetObject, policyResourcePrefix+srcBucket+srcObject) {
+			case policyDenied:
+				return s3err.ErrAccessDenied
+			case policyAllowed:
+				return s3err.ErrNone
+			}
+		}
+	}
+	if identity == nil || !identity.canDo(s3_constants.ACTION_READ, srcBucket, srcObject) {
+		return s3err.ErrAccessDenied
+	}
+	return s3err.ErrNone
+}
+
+// GetBucketPolicyHandler Get bucket Policy
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_GetBucketPolicy.html
+func (s3a *S3ApiServer) GetBucketPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, _ := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("GetBucketPolicyHandler %s", bucket)
+
+	if err := s3a.checkBucket(r, bucket); err != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, err)
+		return
+	}
+	bucketEntry, err := s3a.getEntry(s3a.option.BucketsPath, bucket)
+	if err != nil {
+		glog.Errorf("GetBucketPolicyHandler %s: %v", bucket, err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+	policy, found := bucketEntry.Extended[s3_constants.ExtBucketPolicyKey]
+	if !found {
+		s3err.WriteErrorResponse(w, r, s3err.ErrNoSuchBucketPolicy)
+		return
+	}
+	s3err.WriteResponse(w, r, http.StatusOK, policy, s3err.MimeJSON)
+}
+
+// PutBucketPolicyHandler Put bucket Policy
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutBucketPolicy.html
+func (s3a *S3ApiServer) PutBucketPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, _ := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("PutBucketPolicyHandler %s", bucket)
+
+	if err := s3a.checkBucket(r, bucket); err != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, err)
+		return
+	}
+
+	policyJson, err := io.ReadAll(io.LimitReader(r.Body, maxBucketPolicySize+1))
+	if err != nil || len(policyJson) > maxBucketPolicySize {
+		s3err.WriteErrorResponse(w, r, s3err.ErrMalformedPolicy)
+		return
+	}
+	if _, errCode := parseBucketPolicy(bucket, policyJson); errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+
+	bucketEntry, err := s3a.getEntry(s3a.option.BucketsPath, bucket)
+	if err != nil {
+		glog.Errorf("PutBucketPolicyHandler %s: %v", bucket, err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+	if bucketEntry.Extended == nil {
+		bucketEntry.Extended = make(map[string][]byte)
+	}
+	bucketEntry.Extended[s3_constants.ExtBucketPolicyKey] = policyJson
+	if err = s3a.updateEntry(s3a.option.BucketsPath, bucketEntry); err != nil {
+		glog.Errorf("PutBucketPolicyHandler %s: %v", bucket, err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+	s3err.WriteEmptyResponse(w, r, http.StatusNoContent)
+}
+
+// DeleteBucketPolicyHandler Delete bucket Policy
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_DeleteBucketPolicy.html
+func (s3a *S3ApiServer) DeleteBucketPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	bucket, _ := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("DeleteBucketPolicyHandler %s", bucket)
+
+	if err := s3a.checkBucket(r, bucket); err != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, err)
+		return
+	}
+	bucketEntry, err := s3a.getEntry(s3a.option.BucketsPath, bucket)
+	if err != nil {
+		glog.Errorf("DeleteBucketPolicyHandler %s: %v", bucket, err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+	if _, found := bucketEntry.Extended[s3_constants.ExtBucketPolicyKey]; found {
+		delete(bucketEntry.Extended, s3_constants.ExtBucketPolicyKey)
+		if err = s3a.updateEntry(s3a.option.BucketsPath, bucketEntry); err != nil {
+			glog.Errorf("DeleteBucketPolicyHandler %s: %v", bucket, err)
+			s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+			return
+		}
+	}
+	s3err.WriteEmptyResponse(w, r, http.StatusNoContent)
+}
@@ -0,0 +1,211 @@
+package s3api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/iam_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+)
+
+const testPublicReadPolicy = `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {"Effect": "Allow", "Principal": "*", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::bucket1/public/*"},
+    {"Effect": "Allow", "Principal": {"AWS": ["writer"]}, "Action": ["s3:PutObject", "s3:DeleteObject"], "Resource": ["arn:aws:s3:::bucket1/*"]},
+    {"Effect": "Deny", "Principal": {"AWS": "*"}, "Action": "s3:*", "Resource": "arn:aws:s3:::bucket1/public/secret?"}
+  ]
+}`
+
+func TestValidateBucketPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   string
+		wantCode s3err.ErrorCode
+	}{
+		{"valid", testPublicReadPolicy, s3err.ErrNone},
+		{"not json", `{"Version": `, s3err.ErrMalformedPolicy},
+		{"bad version", `{"Version": "2020-01-01", "Statement": [{"Effect": "Allow", "Principal": "*", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::bucket1/*"}]}`, s3err.ErrMalformedPolicy},
+		{"no statement", `{"Version": "2012-10-17", "Statement": []}`, s3err.ErrMalformedPolicy},
+		{"bad effect", `{"Version": "2012-10-17", "Statement": [{"Effect": "Maybe", "Principal": "*", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::bucket1/*"}]}`, s3err.ErrMalformedPolicy},
+		{"no principal", `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::bucket1/*"}]}`, s3err.ErrMalformedPolicy},
+		{"other bucket", `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Principal": "*", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::bucket2/*"}]}`, s3err.ErrMalformedPolicy},
+		{"bucket name prefix", `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Principal": "*", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::bucket10/*"}]}`, s3err.ErrMalformedPolicy},
+		{"unsupported action", `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Principal": "*", "Action": "s3:ListBucket", "Resource": "arn:aws:s3:::bucket1"}]}`, s3err.ErrNotImplemented},
+		{"condition", `{"Version": "2012-10-17", "Statement": [{"Effect": "Allow", "Principal": "*", "Action": "s3:GetObject", "Resource": "arn:aws:s3:::bucket1/*", "Condition": {"IpAddress": {"aws:SourceIp": "10.0.0.0/8"}}}]}`, s3err.ErrNotImplemented},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, code := parseBucketPolicy("bucket1", []byte(tt.policy)); code != tt.wantCode {
+				t.Errorf("parseBucketPolicy = %v, want %v", code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestBucketPolicyEvaluate(t *testing.T) {
+	policy, code := parseBucketPolicy("bucket1", []byte(testPublicReadPolicy))
+	if code != s3err.ErrNone {
+		t.Fatalf("parseBucketPolicy: %v", code)
+	}
+	writer := &Identity{Name: "writer", Account: &AccountAdmin}
+	reader := &Identity{Name: "reader", Account: &Account{Id: "reader-account"}}
+
+	tests := []struct {
+		name     string
+		identity *Identity
+		action   string
+		resource string
+		want     policyDecision
+	}{
+		{"anonymous read public", nil, policyActionGetObject, "arn:aws:s3:::bucket1/public/a/b.txt", policyAllowed},
+		{"anonymous read private", nil, policyActionGetObject, "arn:aws:s3:::bucket1/private/a.txt", policyNotMatched},
+		{"anonymous write public", nil, policyActionPutObject, "arn:aws:s3:::bucket1/public/a.txt", policyNotMatched},
+		{"writer write", writer, policyActionPutObject, "arn:aws:s3:::bucket1/a.txt", policyAllowed},
+		{"writer delete", writer, policyActionDeleteObject, "arn:aws:s3:::bucket1/public/a.txt", policyAllowed},
+		{"reader write", reader, policyActionPutObject, "arn:aws:s3:::bucket1/a.txt", policyNotMatched},
+		{"explicit deny", writer, policyActionPutObject, "arn:aws:s3:::bucket1/public/secret1", policyDenied},
+		{"explicit deny wildcard length", writer, policyActionPutObject, "arn:aws:s3:::bucket1/public/secret12", policyAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.evaluate(tt.identity, tt.action, tt.resource); got != tt.want {
+				t.Errorf("evaluate = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthRequestWithBucketPolicy(t *testing.T) {
+	policy, _ := parseBucketPolicy("bucket1", []byte(testPublicReadPolicy))
+	iam := &IdentityAccessManagement{
+		isAuthEnabled: true,
+		bucketPolicy: func(bucket string) *BucketPolicy {
+			if bucket == "bucket1" {
+				return policy
+			}
+			return nil
+		},
+	}
+
+	tests := []struct {
+		method string
+		bucket string
+		object string
+		query  string
+		want   s3err.ErrorCode
+	}{
+		{http.MethodGet, "bucket1", "public/a.txt", "", s3err.ErrNone},
+		{http.MethodHead, "bucket1", "public/a.txt", "", s3err.ErrNone},
+		{http.MethodGet, "bucket1", "public/a.txt", "?tagging", s3err.ErrAccessDenied},
+		{http.MethodGet, "bucket1", "private/a.txt", "", s3err.ErrAccessDenied},
+		{http.MethodPut, "bucket1", "public/a.txt", "", s3err.ErrAccessDenied},
+		{http.MethodGet, "bucket2", "public/a.txt", "", s3err.ErrAccessDenied},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, "/"+tt.bucket+"/"+tt.object+tt.query, nil)
+		r = mux.SetURLVars(r, map[string]string{"bucket": tt.bucket, "object": tt.object})
+		_, code := iam.authRequest(r, s3_constants.ACTION_READ)
+		if code != tt.want {
+			t.Errorf("%s %s/%s%s: %v, want %v", tt.method, tt.bucket, tt.object, tt.query, code, tt.want)
+		}
+	}
+}
+
+func TestAuthRequestWithBucketPolicyCopySource(t *testing.T) {
+	readPolicy, _ := parseBucketPolicy("bucket1", []byte(testPublicReadPolicy))
+	writePolicy, _ := parseBucketPolicy("dropbox", []byte(`{
+  "Version": "2012-10-17",
+  "Statement": [
+    {"Effect": "Allow", "Principal": "*", "Action": "s3:PutObject", "Resource": "arn:aws:s3:::dropbox/*"}
+  ]
+}`))
+	iam := &IdentityAccessManagement{
+		isAuthEnabled: true,
+		bucketPolicy: func(bucket string) *BucketPolicy {
+			switch bucket {
+			case "bucket1":
+				return readPolicy
+			case "dropbox":
+				return writePolicy
+			}
+			return nil
+		},
+	}
+
+	tests := []struct {
+		copySource string
+		query      string
+		want       s3err.ErrorCode
+	}{
+		{"", "", s3err.ErrNone},
+		{"/bucket1/public/a.txt", "", s3err.ErrNone},
+		{"bucket1/public%2Fa.txt", "", s3err.ErrNone},
+		{"/bucket1/private/a.txt", "", s3err.ErrAccessDenied},
+		{"/bucket1/private/a.txt", "?partNumber=1&uploadId=abc", s3err.ErrAccessDenied},
+		{"/bucket1/public/secret1", "", s3err.ErrAccessDenied},
+		{"/bucket2/a.txt", "", s3err.ErrAccessDenied},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodPut, "/dropbox/a.txt"+tt.query, nil)
+		r = mux.SetURLVars(r, map[string]string{"bucket": "dropbox", "object": "a.txt"})
+		if tt.copySource != "" {
+			r.Header.Set("X-Amz-Copy-Source", tt.copySource)
+		}
+		_, code := iam.authRequest(r, s3_constants.ACTION_WRITE)
+		if code != tt.want {
+			t.Errorf("copy %q%s: %v, want %v", tt.copySource, tt.query, code, tt.want)
+		}
+	}
+}
+
+func TestBucketPolicyRequiresAdmin(t *testing.T) {
+	iam := &IdentityAccessManagement{
+		hashes:       make(map[string]*sync.Pool),
+		hashCounters: make(map[string]*int32),
+	}
+	_ = iam.loadS3ApiConfiguration(&iam_pb.S3ApiConfiguration{
+		Identities: []*iam_pb.Identity{
+			{
+				Name: "writer",
+				Credentials: []*iam_pb.Credential{
+					{
+						AccessKey: "access_key_1",
+						SecretKey: "secret_key_1",
+					},
+				},
+				Actions: []string{s3_constants.ACTION_WRITE},
+			},
+		},
+	})
+	s3a := &S3ApiServer{
+		option: &S3ApiServerOption{BucketsPath: "/buckets"},
+		iam:    iam,
+		cb: &CircuitBreaker{
+			counters:    make(map[string]*int64),
+			limitations: make(map[string]int64),
+		},
+	}
+	router := mux.NewRouter()
+	s3a.registerRouter(router)
+
+	for _, method := range []string{http.MethodPut, http.MethodDelete} {
+		var body io.ReadSeeker
+		if method == http.MethodPut {
+			body = strings.NewReader(testPublicReadPolicy)
+		}
+		r := mustNewSignedRequest(method, "http://127.0.0.1:9000/bucket1?policy", 0, body, t)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "AccessDenied") {
+			t.Errorf("%s ?policy by a write only identity: %d %s, want AccessDenied", method, w.Code, w.Body.String())
+		}
+	}
+}
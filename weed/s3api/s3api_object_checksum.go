@@ -0,0 +1,128 @@
+package s3api
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"hash"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+)
+
+var checksumAlgorithms = []struct {
+	header  string
+	newHash func() hash.Hash
+}{
+	{s3_constants.AmzChecksumCRC32, func() hash.Hash { return crc32.NewIEEE() }},
+	{s3_constants.AmzChecksumCRC32C, func() hash.Hash { return crc32.New(crc32.MakeTable(crc32.Castagnoli)) }},
+	{s3_constants.AmzChecksumSHA1, sha1.New},
+	{s3_constants.AmzChecksumSHA256, sha256.New},
+}
+
+var errBadDigest = errors.New("checksum mismatch")
+
+// checksumReader verifies the x-amz-checksum-* and Content-Md5 headers of an upload against the received bytes.
+// The x-amz-checksum-* value may also be sent as a trailer after an aws-chunked body.
+// A mismatch fails the last read, so the filer does not create the entry.
+type checksumReader struct {
+	io.ReadCloser
+	header     string
+	expected   string
+	hash       hash.Hash
+	trailers   http.Header // the decoded trailers with the expected checksum, complete at io.EOF
+	contentMd5 []byte
+	md5        hash.Hash
+	mismatch   bool
+}
+
+// newChecksumReader returns nil if the request has neither a checksum nor a Content-Md5 header.
+func newChecksumReader(h http.Header, reader io.ReadCloser) (*checksumReader, s3err.ErrorCode) {
+	trailerNames := make(map[string]bool)
+	for _, name := range strings.Split(h.Get(s3_constants.AmzTrailer), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			trailerNames[http.CanonicalHeaderKey(name)] = true
+		}
+	}
+	c := &checksumReader{ReadCloser: reader}
+	for _, algorithm := range checksumAlgorithms {
+		value := h.Get(algorithm.header)
+		isTrailer := trailerNames[algorithm.header]
+		if value == "" && !isTrailer {
+			continue
+		}
+		if c.hash != nil {
+			return nil, s3err.ErrInvalidDigest
+		}
+		hash := algorithm.newHash()
+		if isTrailer {
+			chunked, ok := reader.(*s3ChunkedReader)
+			if !ok || chunked.trailers == nil {
+				// the trailer is only sent after an aws-chunked body
+				return nil, s3err.ErrInvalidRequest
+			}
+			c.trailers, value = chunked.trailers, ""
+		} else if digest, err := base64.StdEncoding.DecodeString(value); err != nil || len(digest) != hash.Size() {
+			return nil, s3err.ErrInvalidDigest
+		}
+		c.header, c.expected, c.hash = algorithm.header, value, hash
+	}
+	if value := h.Get("Content-Md5"); value != "" {
+		digest, err := base64.StdEncoding.DecodeString(value)
+		if err != nil || len(digest) != md5.Size {
+			return nil, s3err.ErrInvalidDigest
+		}
+		c.contentMd5, c.md5 = digest, md5.New()
+	}
+	if c.hash == nil && c.md5 == nil {
+		return nil, s3err.ErrNone
+	}
+	return c, s3err.ErrNone
+}
+
+func (c *checksumReader) Read(p []byte) (n int, err error) {
+	n, err = c.ReadCloser.Read(p)
+	if c.hash != nil {
+		c.hash.Write(p[:n])
+	}
+	if c.md5 != nil {
+		c.md5.Write(p[:n])
+	}
+	if err == io.EOF && c.trailers != nil {
+		c.expected = c.trailers.Get(c.header)
+	}
+	if err == io.EOF && !c.matches() {
+		c.mismatch = true
+		err = errBadDigest
+	}
+	return
+}
+
+func (c *checksumReader) matches() bool {
+	if c.hash != nil && base64.StdEncoding.EncodeToString(c.hash.Sum(nil)) != c.expected {
+		return false
+	}
+	return c.md5 == nil || bytes.Equal(c.md5.Sum(nil), c.contentMd5)
+}
+
+// checkUpload turns the failed upload into BadDigest if it was caused by a checksum mismatch.
+func (c *checksumReader) checkUpload(errCode s3err.ErrorCode) s3err.ErrorCode {
+	if c != nil && errCode != s3err.ErrNone && c.mismatch {
+		return s3err.ErrBadDigest
+	}
+	return errCode
+}
+
+// setResponseHeader returns the verified checksum, like S3 does.
+func (c *checksumReader) setResponseHeader(w http.ResponseWriter) {
+	if c != nil && c.header != "" {
+		w.Header().Set(c.header, c.expected)
+	}
+}
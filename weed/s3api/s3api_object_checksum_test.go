@@ -0,0 +1,211 @@
+package s3api
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/security"
+	weed_server "github.com/seaweedfs/seaweedfs/weed/server"
+)
+
+func TestChecksumReader(t *testing.T) {
+	data := "hello checksum"
+	sha := sha256.Sum256([]byte(data))
+	crc := crc32.Checksum([]byte(data), crc32.MakeTable(crc32.Castagnoli))
+	crcBytes := []byte{byte(crc >> 24), byte(crc >> 16), byte(crc >> 8), byte(crc)}
+	md5sum := md5.Sum([]byte(data))
+
+	tests := []struct {
+		name     string
+		header   string
+		value    string
+		body     string
+		errCode  s3err.ErrorCode
+		mismatch bool
+	}{
+		{"sha256", s3_constants.AmzChecksumSHA256, base64.StdEncoding.EncodeToString(sha[:]), data, s3err.ErrNone, false},
+		{"crc32c", s3_constants.AmzChecksumCRC32C, base64.StdEncoding.EncodeToString(crcBytes), data, s3err.ErrNone, false},
+		{"corrupted", s3_constants.AmzChecksumSHA256, base64.StdEncoding.EncodeToString(sha[:]), data + "!", s3err.ErrNone, true},
+		{"invalid", s3_constants.AmzChecksumSHA1, "not base64", data, s3err.ErrInvalidDigest, false},
+		{"wrong size", s3_constants.AmzChecksumCRC32, base64.StdEncoding.EncodeToString(sha[:]), data, s3err.ErrInvalidDigest, false},
+		{"content md5", "Content-Md5", base64.StdEncoding.EncodeToString(md5sum[:]), data, s3err.ErrNone, false},
+		{"corrupted content md5", "Content-Md5", base64.StdEncoding.EncodeToString(md5sum[:]), data + "!", s3err.ErrNone, true},
+		{"wrong size content md5", "Content-Md5", base64.StdEncoding.EncodeToString(sha[:]), data, s3err.ErrInvalidDigest, false},
+	}
+	for _, tt := range tests {
+		h := http.Header{}
+		h.Set(tt.header, tt.value)
+		checksum, errCode := newChecksumReader(h, io.NopCloser(strings.NewReader(tt.body)))
+		if errCode != tt.errCode {
+			t.Errorf("%s: error code %v, expected %v", tt.name, errCode, tt.errCode)
+			continue
+		}
+		if errCode != s3err.ErrNone {
+			continue
+		}
+		_, err := io.ReadAll(checksum)
+		if tt.mismatch != (err != nil) {
+			t.Errorf("%s: read error %v", tt.name, err)
+		}
+		if got := checksum.checkUpload(s3err.ErrInternalError); tt.mismatch != (got == s3err.ErrBadDigest) {
+			t.Errorf("%s: upload error code %v", tt.name, got)
+		}
+	}
+
+	if checksum, errCode := newChecksumReader(http.Header{}, io.NopCloser(strings.NewReader(data))); checksum != nil || errCode != s3err.ErrNone {
+		t.Errorf("no checksum header: %v %v", checksum, errCode)
+	}
+	h := http.Header{}
+	h.Set(s3_constants.AmzChecksumSHA256, base64.StdEncoding.EncodeToString(sha[:]))
+	h.Set(s3_constants.AmzChecksumCRC32C, base64.StdEncoding.EncodeToString(crcBytes))
+	if _, errCode := newChecksumReader(h, io.NopCloser(strings.NewReader(data))); errCode != s3err.ErrInvalidDigest {
+		t.Errorf("two checksum headers: %v", errCode)
+	}
+}
+
+func TestChecksumUploadHandlers(t *testing.T) {
+	// the filer only creates the entry after reading the whole body
+	var created atomic.Int32
+	filerServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		created.Add(1)
+		json.NewEncoder(w).Encode(weed_server.FilerPostResult{Name: path.Base(r.URL.Path), Size: int64(len(data))})
+	}))
+	defer filerServer.Close()
+
+	s3a := &S3ApiServer{
+		option: &S3ApiServerOption{
+			Filer:       pb.ServerAddress(strings.TrimPrefix(filerServer.URL, "http://")),
+			BucketsPath: "/buckets",
+		},
+		iam:        &IdentityAccessManagement{},
+		filerGuard: security.NewGuard(nil, "", 0, "", 0),
+		client:     &http.Client{},
+	}
+	s3a.bucketRegistry = &BucketRegistry{
+		metadataCache: map[string]*BucketMetaData{"bucket1": {Name: "bucket1"}},
+		notFound:      make(map[string]struct{}),
+		s3a:           s3a,
+	}
+
+	data := strings.Repeat("hello checksum ", 10000)
+	sha := sha256.Sum256([]byte(data))
+	wrongSha := sha256.Sum256([]byte("something else"))
+	md5sum := md5.Sum([]byte(data))
+	wrongMd5 := md5.Sum([]byte("something else"))
+	uploadId := s3a.generateUploadID("/obj")
+
+	tests := []struct {
+		name    string
+		query   string
+		header  string
+		value   string
+		handler http.HandlerFunc
+		status  int
+		code    string
+		created bool
+		sseC    bool
+	}{
+		{"put", "", s3_constants.AmzChecksumSHA256, base64.StdEncoding.EncodeToString(sha[:]), s3a.PutObjectHandler, http.StatusOK, "", true, false},
+		{"put wrong checksum", "", s3_constants.AmzChecksumSHA256, base64.StdEncoding.EncodeToString(wrongSha[:]), s3a.PutObjectHandler, http.StatusBadRequest, "BadDigest", false, false},
+		{"put trailer without aws-chunked body", "", s3_constants.AmzTrailer, s3_constants.AmzChecksumSHA256, s3a.PutObjectHandler, http.StatusBadRequest, "InvalidRequest", false, false},
+		{"put content md5", "", "Content-Md5", base64.StdEncoding.EncodeToString(md5sum[:]), s3a.PutObjectHandler, http.StatusOK, "", true, false},
+		{"put wrong content md5", "", "Content-Md5", base64.StdEncoding.EncodeToString(wrongMd5[:]), s3a.PutObjectHandler, http.StatusBadRequest, "BadDigest", false, false},
+		{"put sse-c content md5", "", "Content-Md5", base64.StdEncoding.EncodeToString(md5sum[:]), s3a.PutObjectHandler, http.StatusOK, "", true, true},
+		{"put sse-c wrong content md5", "", "Content-Md5", base64.StdEncoding.EncodeToString(wrongMd5[:]), s3a.PutObjectHandler, http.StatusBadRequest, "BadDigest", false, true},
+		{"upload part", "?partNumber=1&uploadId=" + uploadId, s3_constants.AmzChecksumSHA256, base64.StdEncoding.EncodeToString(sha[:]), s3a.PutObjectPartHandler, http.StatusOK, "", true, false},
+		{"upload part wrong checksum", "?partNumber=1&uploadId=" + uploadId, s3_constants.AmzChecksumSHA256, base64.StdEncoding.EncodeToString(wrongSha[:]), s3a.PutObjectPartHandler, http.StatusBadRequest, "BadDigest", false, false},
+		{"upload part wrong content md5", "?partNumber=1&uploadId=" + uploadId, "Content-Md5", base64.StdEncoding.EncodeToString(wrongMd5[:]), s3a.PutObjectPartHandler, http.StatusBadRequest, "BadDigest", false, false},
+	}
+	for _, tt := range tests {
+		created.Store(0)
+		r := httptest.NewRequest(http.MethodPut, "/bucket1/obj"+tt.query, strings.NewReader(data))
+		if tt.sseC {
+			r.Header = sseCustomerKeyHeader(bytes.Repeat([]byte{7}, 32), "AES256")
+		}
+		r.Header.Set(tt.header, tt.value)
+		r = mux.SetURLVars(r, map[string]string{"bucket": "bucket1", "object": "obj"})
+		w := httptest.NewRecorder()
+		tt.handler(w, r)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: status %d, expected %d: %s", tt.name, w.Code, tt.status, w.Body.String())
+		}
+		if tt.code != "" && !strings.Contains(w.Body.String(), "<Code>"+tt.code+"</Code>") {
+			t.Errorf("%s: expected %s: %s", tt.name, tt.code, w.Body.String())
+		}
+		if isCreated := created.Load() > 0; isCreated != tt.created {
+			t.Errorf("%s: created %v, expected %v", tt.name, isCreated, tt.created)
+		}
+	}
+
+	// the checksum in the trailer of an unsigned aws-chunked body, as the AWS SDKs send by default
+	crc := crc32.ChecksumIEEE([]byte(data))
+	crcBase64 := base64.StdEncoding.EncodeToString([]byte{byte(crc >> 24), byte(crc >> 16), byte(crc >> 8), byte(crc)})
+	dataMd5 := md5.Sum([]byte(data))
+	trailerTests := []struct {
+		name    string
+		handler http.HandlerFunc
+		query   string
+		trailer string
+		status  int
+		code    string
+		created bool
+	}{
+		{"put trailing checksum", s3a.PutObjectHandler, "", crcBase64, http.StatusOK, "", true},
+		{"put wrong trailing checksum", s3a.PutObjectHandler, "", "AAAAAA==", http.StatusBadRequest, "BadDigest", false},
+		{"upload part trailing checksum", s3a.PutObjectPartHandler, "?partNumber=1&uploadId=" + uploadId, crcBase64, http.StatusOK, "", true},
+		{"upload part wrong trailing checksum", s3a.PutObjectPartHandler, "?partNumber=1&uploadId=" + uploadId, "AAAAAA==", http.StatusBadRequest, "BadDigest", false},
+	}
+	for _, tt := range trailerTests {
+		created.Store(0)
+		body := fmt.Sprintf("%x\r\n%s\r\n0\r\nx-amz-checksum-crc32:%s\r\n\r\n", len(data), data, tt.trailer)
+		r := httptest.NewRequest(http.MethodPut, "/bucket1/obj"+tt.query, strings.NewReader(body))
+		r.Header.Set("Content-Encoding", "aws-chunked")
+		r.Header.Set(s3_constants.AmzContentSha256, streamingUnsignedPayloadTrailer)
+		r.Header.Set(s3_constants.AmzTrailer, "x-amz-checksum-crc32")
+		r = mux.SetURLVars(r, map[string]string{"bucket": "bucket1", "object": "obj"})
+		w := httptest.NewRecorder()
+		tt.handler(w, r)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: status %d, expected %d: %s", tt.name, w.Code, tt.status, w.Body.String())
+		}
+		if tt.code != "" && !strings.Contains(w.Body.String(), "<Code>"+tt.code+"</Code>") {
+			t.Errorf("%s: expected %s: %s", tt.name, tt.code, w.Body.String())
+		}
+		if isCreated := created.Load() > 0; isCreated != tt.created {
+			t.Errorf("%s: created %v, expected %v", tt.name, isCreated, tt.created)
+		}
+		if tt.created {
+			// the etag is the md5 of the decoded body
+			if etag := w.Header()["ETag"]; len(etag) != 1 || etag[0] != fmt.Sprintf("\"%x\"", dataMd5) {
+				t.Errorf("%s: etag %s", tt.name, etag)
+			}
+			if got := w.Header().Get(s3_constants.AmzChecksumCRC32); got != crcBase64 {
+				t.Errorf("%s: checksum %s", tt.name, got)
+			}
+		}
+	}
+}
@@ -120,9 +120,23 @@ func (s3a *S3ApiServer) GetObjectHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	sseKey, errCode := parseSSECustomerKey(r.Header)
+	if errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+	removeSSECustomerKeyHeaders(r.Header)
+
+	if versionId := r.URL.Query().Get("versionId"); versionId != "" {
+		if object, errCode = s3a.resolveObjectVersion(bucket, object, versionId); errCode != s3err.ErrNone {
+			s3err.WriteErrorResponse(w, r, errCode)
+			return
+		}
+	}
+
 	destUrl := s3a.toFilerUrl(bucket, object)
 
-	s3a.proxyToFiler(w, r, destUrl, false, passThroughResponse)
+	s3a.proxyToFiler(w, r, destUrl, false, passThroughSSECustomerResponse(r, sseKey))
 }
 
 func (s3a *S3ApiServer) HeadObjectHandler(w http.ResponseWriter, r *http.Request) {
@@ -130,9 +144,23 @@ func (s3a *S3ApiServer) HeadObjectHandler(w http.ResponseWriter, r *http.Request
 	bucket, object := s3_constants.GetBucketAndObject(r)
 	glog.V(3).Infof("HeadObjectHandler %s %s", bucket, object)
 
+	sseKey, errCode := parseSSECustomerKey(r.Header)
+	if errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+	removeSSECustomerKeyHeaders(r.Header)
+
+	if versionId := r.URL.Query().Get("versionId"); versionId != "" {
+		if object, errCode = s3a.resolveObjectVersion(bucket, object, versionId); errCode != s3err.ErrNone {
+			s3err.WriteErrorResponse(w, r, errCode)
+			return
+		}
+	}
+
 	destUrl := s3a.toFilerUrl(bucket, object)
 
-	s3a.proxyToFiler(w, r, destUrl, false, passThroughResponse)
+	s3a.proxyToFiler(w, r, destUrl, false, passThroughSSECustomerResponse(r, sseKey))
 }
 
 func (s3a *S3ApiServer) proxyToFiler(w http.ResponseWriter, r *http.Request, destUrl string, isWrite bool, responseFn func(proxyResponse *http.Response, w http.ResponseWriter) (statusCode int)) {
@@ -196,6 +224,16 @@ func (s3a *S3ApiServer) proxyToFiler(w http.ResponseWriter, r *http.Request, des
 		return
 	}
 
+	if resp.Header.Get(s3_constants.ExtDeleteMarkerKey) == "true" {
+		w.Header().Set(s3_constants.AmzDeleteMarker, "true")
+		setVersionIdResponseHeader(w, resp.Header.Get(s3_constants.ExtVersionIdKey))
+		s3err.WriteErrorResponse(w, r, s3err.ErrNoSuchKey)
+		return
+	}
+	if versionId := resp.Header.Get(s3_constants.ExtVersionIdKey); versionId != "" {
+		resp.Header.Set(s3_constants.AmzVersionId, versionId)
+	}
+
 	TimeToFirstByte(r.Method, start, r)
 	if resp.Header.Get(s3_constants.SeaweedFSIsDirectoryKey) == "true" {
 		responseStatusCode := responseFn(resp, w)
@@ -1,24 +1,42 @@
 package s3api
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"modernc.org/strutil"
 
+	"google.golang.org/protobuf/proto"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
 	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/operation"
+	"github.com/seaweedfs/seaweedfs/weed/pb"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/pb/volume_server_pb"
 	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
 	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
 	"github.com/seaweedfs/seaweedfs/weed/util"
+	"github.com/seaweedfs/seaweedfs/weed/wdclient"
 )
 
 const (
 	DirectiveCopy    = "COPY"
 	DirectiveReplace = "REPLACE"
+
+	copyChunkConcurrency = 8
+	copyChunkBatchSize   = 16
 )
 
 func (s3a *S3ApiServer) CopyObjectHandler(w http.ResponseWriter, r *http.Request) {
@@ -72,7 +90,8 @@ func (s3a *S3ApiServer) CopyObjectHandler(w http.ResponseWriter, r *http.Request
 	}
 	srcPath := util.FullPath(fmt.Sprintf("%s/%s%s", s3a.option.BucketsPath, srcBucket, srcObject))
 	dir, name := srcPath.DirAndName()
-	if entry, err := s3a.getEntry(dir, name); err != nil || entry.IsDirectory {
+	entry, err := s3a.getEntry(dir, name)
+	if err != nil || entry.IsDirectory || isDeleteMarker(entry) {
 		s3err.WriteErrorResponse(w, r, s3err.ErrInvalidCopySource)
 		return
 	}
@@ -82,33 +101,41 @@ func (s3a *S3ApiServer) CopyObjectHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	dstUrl := fmt.Sprintf("http://%s%s/%s%s",
-		s3a.option.Filer.ToHttpAddress(), s3a.option.BucketsPath, dstBucket, urlEscapeObject(dstObject))
-	srcUrl := fmt.Sprintf("http://%s%s/%s%s",
-		s3a.option.Filer.ToHttpAddress(), s3a.option.BucketsPath, srcBucket, urlEscapeObject(srcObject))
-
-	_, _, resp, err := util.DownloadFile(srcUrl, s3a.maybeGetFilerJwtAuthorizationToken(false))
-	if err != nil {
-		s3err.WriteErrorResponse(w, r, s3err.ErrInvalidCopySource)
+	// the copy would lose the iv of objects encrypted with customer provided keys
+	if _, found := entry.Extended[s3_constants.SeaweedFSSSECustomerKeyMD5]; found || hasSSECustomerKey(r.Header) {
+		s3err.WriteErrorResponse(w, r, s3err.ErrNotImplemented)
 		return
 	}
-	defer util.CloseResponse(resp)
 
-	tagErr := processMetadata(r.Header, resp.Header, replaceMeta, replaceTagging, s3a.getTags, dir, name)
-	if tagErr != nil {
-		s3err.WriteErrorResponse(w, r, s3err.ErrInvalidCopySource)
+	unlock := s3a.lockObject(dstBucket, dstObject)
+	defer unlock()
+	versionId, err := s3a.prepareNewVersion(dstBucket, dstObject)
+	if err != nil {
+		glog.Errorf("prepare new version of %s%s: %v", dstBucket, dstObject, err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
 		return
 	}
-	glog.V(2).Infof("copy from %s to %s", srcUrl, dstUrl)
-	destination := fmt.Sprintf("%s/%s%s", s3a.option.BucketsPath, dstBucket, dstObject)
-	etag, errCode := s3a.putToFiler(r, dstUrl, resp.Body, destination, dstBucket)
 
+	dstPath := util.FullPath(fmt.Sprintf("%s/%s%s", s3a.option.BucketsPath, dstBucket, dstObject))
+	glog.V(2).Infof("copy from %s to %s", srcPath, dstPath)
+	var etag string
+	var errCode s3err.ErrorCode
+	if entry.IsInRemoteOnly() {
+		etag, errCode = s3a.copyThroughFiler(r, srcBucket, srcObject, dstBucket, dstObject, replaceMeta, replaceTagging, versionId)
+	} else {
+		var dstEntry *filer_pb.Entry
+		if dstEntry, errCode = s3a.copyEntry(r, entry, dstPath, replaceMeta, replaceTagging, versionId); errCode == s3err.ErrNone {
+			etag = filer.ETag(dstEntry)
+		}
+	}
 	if errCode != s3err.ErrNone {
+		s3a.restoreLatestVersion(dstBucket, dstObject)
 		s3err.WriteErrorResponse(w, r, errCode)
 		return
 	}
 
 	setEtag(w, etag)
+	setVersionIdResponseHeader(w, versionId)
 
 	response := CopyObjectResult{
 		ETag:         etag,
@@ -119,6 +146,202 @@ func (s3a *S3ApiServer) CopyObjectHandler(w http.ResponseWriter, r *http.Request
 
 }
 
+// copyEntry creates the destination entry with a copy of each source chunk.
+// The volume servers of the new chunks read the data from the source volume servers,
+// so the data does not go through the gateway.
+// Chunks are not shared between the entries, since the volume servers do not count references to needles.
+// A non empty versionId is set as the version of the destination entry.
+func (s3a *S3ApiServer) copyEntry(r *http.Request, srcEntry *filer_pb.Entry, dstPath util.FullPath, replaceMeta, replaceTagging bool, versionId string) (*filer_pb.Entry, s3err.ErrorCode) {
+
+	extended, err := processMetadataBytes(r.Header, srcEntry.Extended, replaceMeta, replaceTagging)
+	if err != nil {
+		glog.Errorf("CopyObjectHandler ValidateTags error %s: %v", r.URL, err)
+		return nil, s3err.ErrInvalidTag
+	}
+	delete(extended, s3_constants.ExtVersionIdKey)
+	if versionId != "" {
+		extended[s3_constants.ExtVersionIdKey] = []byte(versionId)
+	}
+
+	chunks, err := s3a.copyChunks(srcEntry.GetChunks(), string(dstPath))
+	if err != nil {
+		glog.Errorf("copy chunks to %s: %v", dstPath, err)
+		return nil, s3err.ErrInternalError
+	}
+
+	dir, name := dstPath.DirAndName()
+	dstEntry := &filer_pb.Entry{
+		Name:       name,
+		Attributes: proto.Clone(srcEntry.Attributes).(*filer_pb.FuseAttributes),
+		Chunks:     chunks,
+		Content:    srcEntry.Content,
+		Extended:   extended,
+	}
+	now := time.Now()
+	dstEntry.Attributes.Mtime, dstEntry.Attributes.MtimeNs = now.Unix(), int64(now.Nanosecond())
+	dstEntry.Attributes.Crtime = now.Unix()
+	if contentType := r.Header.Get("Content-Type"); replaceMeta && contentType != "" {
+		dstEntry.Attributes.Mime = contentType
+	}
+
+	if err = s3a.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		return filer_pb.CreateEntry(client, &filer_pb.CreateEntryRequest{
+			Directory: dir,
+			Entry:     dstEntry,
+		})
+	}); err != nil {
+		glog.Errorf("create %s: %v", dstPath, err)
+		return nil, filerErrorToS3Error(err.Error())
+	}
+
+	return dstEntry, s3err.ErrNone
+}
+
+// copyThroughFiler reads the source object through the filer, and writes it as the destination object.
+// It is used for the objects only in the remote storage, which have no chunks to copy.
+func (s3a *S3ApiServer) copyThroughFiler(r *http.Request, srcBucket, srcObject, dstBucket, dstObject string, replaceMeta, replaceTagging bool, versionId string) (etag string, errCode s3err.ErrorCode) {
+	dstUrl := s3a.toFilerUrl(dstBucket, dstObject)
+	srcUrl := s3a.toFilerUrl(srcBucket, srcObject)
+
+	_, _, resp, err := util.DownloadFile(srcUrl, s3a.maybeGetFilerJwtAuthorizationToken(false))
+	if err != nil {
+		return "", s3err.ErrInvalidCopySource
+	}
+	defer util.CloseResponse(resp)
+
+	dir, name := util.FullPath(fmt.Sprintf("%s/%s%s", s3a.option.BucketsPath, srcBucket, srcObject)).DirAndName()
+	if err = processMetadata(r.Header, resp.Header, replaceMeta, replaceTagging, s3a.getTags, dir, name); err != nil {
+		return "", s3err.ErrInvalidCopySource
+	}
+	removeSeaweedSSECustomerHeaders(r.Header)
+	r.Header.Del(s3_constants.ExtVersionIdKey)
+	r.Header.Del(s3_constants.ExtDeleteMarkerKey)
+	if versionId != "" {
+		r.Header.Set(s3_constants.ExtVersionIdKey, versionId)
+	}
+	destination := fmt.Sprintf("%s/%s%s", s3a.option.BucketsPath, dstBucket, dstObject)
+	return s3a.putToFiler(r, dstUrl, resp.Body, destination, dstBucket)
+}
+
+func (s3a *S3ApiServer) copyChunks(chunks []*filer_pb.FileChunk, dstPath string) ([]*filer_pb.FileChunk, error) {
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+
+	lookupFn := filer.LookupFn(s3a)
+	dataChunks, _, err := filer.ResolveChunkManifest(lookupFn, chunks, 0, math.MaxInt64)
+	if err != nil {
+		return nil, fmt.Errorf("resolve chunk manifest: %v", err)
+	}
+
+	// the batches of chunks are copied in parallel, each batch by one volume server
+	copiedChunks := make([]*filer_pb.FileChunk, len(dataChunks))
+	var wg sync.WaitGroup
+	var copyErr error
+	var copyErrLock sync.Mutex
+	executor := util.NewLimitedConcurrentExecutor(copyChunkConcurrency)
+	cipher := false
+	for _, chunk := range dataChunks {
+		cipher = cipher || len(chunk.CipherKey) > 0
+	}
+	for start := 0; start < len(dataChunks); start += copyChunkBatchSize {
+		start, stop := start, min(start+copyChunkBatchSize, len(dataChunks))
+		wg.Add(1)
+		executor.Execute(func() {
+			defer wg.Done()
+			if err := s3a.copyChunkBatch(lookupFn, dataChunks[start:stop], copiedChunks[start:stop], dstPath); err != nil {
+				copyErrLock.Lock()
+				copyErr = err
+				copyErrLock.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	if copyErr != nil {
+		return nil, copyErr
+	}
+
+	// the manifests of large files are saved the same way as the chunks, encrypted if any chunk is
+	manifestedChunks, err := filer.MaybeManifestize(func(reader io.Reader, name string, offset int64, tsNs int64) (*filer_pb.FileChunk, error) {
+		return s3a.uploadChunkData(reader, cipher, dstPath, offset, tsNs)
+	}, copiedChunks)
+	if err != nil {
+		return nil, fmt.Errorf("manifestize: %v", err)
+	}
+	return manifestedChunks, nil
+}
+
+// copyChunkBatch asks a volume server to copy the whole chunks into new needles, and sets the copied chunks.
+// The encrypted chunks are copied as they are stored, and keep their cipher keys.
+func (s3a *S3ApiServer) copyChunkBatch(lookupFn wdclient.LookupFileIdFunctionType, chunks, copiedChunks []*filer_pb.FileChunk, dstPath string) error {
+
+	ranges := make([]*volume_server_pb.CopyNeedleRangesRequest_Range, len(chunks))
+	for i, chunk := range chunks {
+		urlStrings, err := lookupFn(chunk.GetFileIdString())
+		if err != nil {
+			return fmt.Errorf("lookup %s: %v", chunk.GetFileIdString(), err)
+		}
+		ranges[i] = &volume_server_pb.CopyNeedleRangesRequest_Range{
+			SourceUrls:   urlStrings,
+			Size:         int64(chunk.Size),
+			IsCompressed: chunk.IsCompressed,
+			IsFullChunk:  true,
+			Raw:          len(chunk.CipherKey) > 0,
+		}
+	}
+	fileIds, eTags, _, err := s3a.copyNeedleRanges(ranges, dstPath)
+	if err != nil {
+		return fmt.Errorf("copy %s...: %v", chunks[0].GetFileIdString(), err)
+	}
+
+	for i, chunk := range chunks {
+		// the copied data is the same as the source chunk
+		eTag := chunk.ETag
+		if eTag == "" {
+			eTag = eTags[i]
+		}
+		copiedChunks[i] = &filer_pb.FileChunk{
+			FileId:       fileIds[i],
+			Offset:       chunk.Offset,
+			Size:         chunk.Size,
+			ModifiedTsNs: chunk.ModifiedTsNs,
+			ETag:         eTag,
+		}
+		if ranges[i].Raw {
+			copiedChunks[i].CipherKey = chunk.CipherKey
+			copiedChunks[i].IsCompressed = chunk.IsCompressed
+		}
+	}
+	return nil
+}
+
+func (s3a *S3ApiServer) uploadChunkData(reader io.Reader, cipher bool, dstPath string, offset, modifiedTsNs int64) (*filer_pb.FileChunk, error) {
+
+	fileId, uploadResult, err, _ := operation.UploadWithRetry(
+		s3a,
+		&filer_pb.AssignVolumeRequest{
+			Count:      1,
+			DataCenter: s3a.option.DataCenter,
+			Path:       dstPath,
+		},
+		&operation.UploadOption{
+			Cipher: cipher,
+		},
+		func(host, fileId string) string {
+			return fmt.Sprintf("http://%s/%s", host, fileId)
+		},
+		reader,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("upload: %v", err)
+	}
+	if uploadResult.Error != "" {
+		return nil, fmt.Errorf("upload result: %v", uploadResult.Error)
+	}
+
+	return uploadResult.ToPbFileChunk(fileId, offset, modifiedTsNs), nil
+}
+
 func pathToBucketAndObject(path string) (bucket, object string) {
 	path = strings.TrimPrefix(path, "/")
 	parts := strings.SplitN(path, "/", 2)
@@ -169,7 +392,45 @@ func (s3a *S3ApiServer) CopyObjectPartHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	if err = s3a.checkUploadId(dstObject, uploadID); err != nil {
+		s3err.WriteErrorResponse(w, r, s3err.ErrNoSuchUpload)
+		return
+	}
+
 	rangeHeader := r.Header.Get("x-amz-copy-source-range")
+	destination := fmt.Sprintf("%s/%s%s", s3a.option.BucketsPath, dstBucket, dstObject)
+
+	srcPath := util.FullPath(fmt.Sprintf("%s/%s%s", s3a.option.BucketsPath, srcBucket, srcObject))
+	dir, name := srcPath.DirAndName()
+	entry, err := s3a.getEntry(dir, name)
+	if err != nil || entry.IsDirectory || isDeleteMarker(entry) {
+		s3err.WriteErrorResponse(w, r, s3err.ErrInvalidCopySource)
+		return
+	}
+
+	// the objects encrypted with customer provided keys, stored inline, or only in the remote storage are read through the filer
+	if _, found := entry.Extended[s3_constants.SeaweedFSSSECustomerKeyMD5]; !found && !hasSSECustomerKey(r.Header) &&
+		len(entry.Content) == 0 && !entry.IsInRemoteOnly() {
+		start, size, ok := parseCopySourceRange(rangeHeader, int64(filer.FileSize(entry)))
+		if !ok {
+			s3err.WriteErrorResponse(w, r, s3err.ErrInvalidRange)
+			return
+		}
+		etag, copied, errCode := s3a.copyPartChunks(entry, start, size, dstBucket, uploadID, partID, destination)
+		if errCode != s3err.ErrNone {
+			s3err.WriteErrorResponse(w, r, errCode)
+			return
+		}
+		if copied {
+			glog.V(2).Infof("copy chunks of %s to %s part %d", srcPath, destination, partID)
+			setEtag(w, etag)
+			writeSuccessResponseXML(w, r, CopyPartResult{
+				ETag:         etag,
+				LastModified: time.Now().UTC(),
+			})
+			return
+		}
+	}
 
 	dstUrl := s3a.genPartUploadUrl(dstBucket, uploadID, partID)
 	srcUrl := fmt.Sprintf("http://%s%s/%s%s",
@@ -184,7 +445,7 @@ func (s3a *S3ApiServer) CopyObjectPartHandler(w http.ResponseWriter, r *http.Req
 	defer dataReader.Close()
 
 	glog.V(2).Infof("copy from %s to %s", srcUrl, dstUrl)
-	destination := fmt.Sprintf("%s/%s%s", s3a.option.BucketsPath, dstBucket, dstObject)
+	removeSeaweedSSECustomerHeaders(r.Header)
 	etag, errCode := s3a.putToFiler(r, dstUrl, dataReader, destination, dstBucket)
 
 	if errCode != s3err.ErrNone {
@@ -203,6 +464,176 @@ func (s3a *S3ApiServer) CopyObjectPartHandler(w http.ResponseWriter, r *http.Req
 
 }
 
+// copyPartChunks creates the part entry with a copy of the source chunks in the range [start, start+size).
+// The volume server of the new chunks reads the data from the source volume servers,
+// so the data does not go through the gateway.
+// It returns false if the range has holes or encrypted chunks, and the part should be read through the filer instead.
+func (s3a *S3ApiServer) copyPartChunks(srcEntry *filer_pb.Entry, start, size int64, bucket, uploadID string, partID int, dstPath string) (etag string, copied bool, errCode s3err.ErrorCode) {
+
+	lookupFn := filer.LookupFn(s3a)
+	dataChunks, _, err := filer.ResolveChunkManifest(lookupFn, srcEntry.GetChunks(), start, start+size)
+	if err != nil {
+		glog.Errorf("resolve chunk manifest of %s: %v", srcEntry.Name, err)
+		return "", false, s3err.ErrInternalError
+	}
+
+	var views []*filer.ChunkView
+	stop := start
+	for x := filer.ViewFromChunks(lookupFn, dataChunks, start, size).Front(); x != nil; x = x.Next {
+		// the volume servers do not know the cipher keys
+		if x.Value.ViewOffset != stop || len(x.Value.CipherKey) > 0 {
+			return "", false, s3err.ErrNone
+		}
+		views = append(views, x.Value)
+		stop += int64(x.Value.ViewSize)
+	}
+	if len(views) == 0 || stop != start+size {
+		return "", false, s3err.ErrNone
+	}
+
+	ranges := make([]*volume_server_pb.CopyNeedleRangesRequest_Range, len(views))
+	for i, cv := range views {
+		urlStrings, err := lookupFn(cv.FileId)
+		if err != nil {
+			glog.Errorf("lookup %s: %v", cv.FileId, err)
+			return "", false, s3err.ErrInternalError
+		}
+		ranges[i] = &volume_server_pb.CopyNeedleRangesRequest_Range{
+			SourceUrls:   urlStrings,
+			Offset:       cv.OffsetInChunk,
+			Size:         int64(cv.ViewSize),
+			IsCompressed: cv.IsGzipped,
+			IsFullChunk:  cv.IsFullChunk(),
+		}
+	}
+	fileIds, eTags, md5, err := s3a.copyNeedleRanges(ranges, dstPath)
+	if err != nil {
+		glog.Errorf("copy chunks to %s: %v", dstPath, err)
+		return "", false, s3err.ErrInternalError
+	}
+
+	now := time.Now()
+	chunks := make([]*filer_pb.FileChunk, len(views))
+	for i, cv := range views {
+		chunks[i] = &filer_pb.FileChunk{
+			FileId:       fileIds[i],
+			Offset:       cv.ViewOffset - start,
+			Size:         cv.ViewSize,
+			ModifiedTsNs: now.UnixNano(),
+			ETag:         eTags[i],
+		}
+	}
+
+	partEntry := &filer_pb.Entry{
+		Name: fmt.Sprintf("%04d_%s%s", partID, uuid.NewString(), multipartExt),
+		Attributes: &filer_pb.FuseAttributes{
+			Mtime:    now.Unix(),
+			MtimeNs:  int64(now.Nanosecond()),
+			Crtime:   now.Unix(),
+			FileMode: uint32(0660),
+			Uid:      filer_pb.OS_UID,
+			Gid:      filer_pb.OS_GID,
+			Mime:     srcEntry.Attributes.GetMime(),
+			FileSize: uint64(size),
+			Md5:      md5,
+		},
+		Chunks: chunks,
+	}
+
+	uploadDirectory := s3a.genUploadsFolder(bucket) + "/" + uploadID
+	if err = s3a.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		return filer_pb.CreateEntry(client, &filer_pb.CreateEntryRequest{
+			Directory: uploadDirectory,
+			Entry:     partEntry,
+		})
+	}); err != nil {
+		glog.Errorf("create part %s/%s: %v", uploadDirectory, partEntry.Name, err)
+		return "", false, filerErrorToS3Error(err.Error())
+	}
+
+	return fmt.Sprintf("%x", md5), true, s3err.ErrNone
+}
+
+// copyNeedleRanges assigns one file id for each range, and asks the assigned volume server to copy the ranges.
+// It returns the file ids and etags of the new chunks, and the md5 of all the copied data.
+func (s3a *S3ApiServer) copyNeedleRanges(ranges []*volume_server_pb.CopyNeedleRangesRequest_Range, dstPath string) (fileIds, eTags []string, md5 []byte, err error) {
+
+	var assignResult *filer_pb.AssignVolumeResponse
+	if err = s3a.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		resp, assignErr := client.AssignVolume(context.Background(), &filer_pb.AssignVolumeRequest{
+			Count:      int32(len(ranges)),
+			DataCenter: s3a.option.DataCenter,
+			Path:       dstPath,
+		})
+		if assignErr != nil {
+			return assignErr
+		}
+		if resp.Error != "" {
+			return errors.New(resp.Error)
+		}
+		assignResult = resp
+		return nil
+	}); err != nil {
+		return nil, nil, nil, fmt.Errorf("assign: %v", err)
+	}
+	if int(assignResult.Count) < len(ranges) {
+		return nil, nil, nil, fmt.Errorf("assigned %d file ids, expected %d", assignResult.Count, len(ranges))
+	}
+	fid, err := needle.ParseFileIdFromString(assignResult.FileId)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("parse assigned file id %s: %v", assignResult.FileId, err)
+	}
+
+	location := assignResult.GetLocation()
+	volumeServer := pb.NewServerAddressWithGrpcPort(location.GetUrl(), int(location.GetGrpcPort()))
+	err = operation.WithVolumeServerClient(false, volumeServer, s3a.option.GrpcDialOption, func(client volume_server_pb.VolumeServerClient) error {
+		resp, copyErr := client.CopyNeedleRanges(context.Background(), &volume_server_pb.CopyNeedleRangesRequest{
+			VolumeId: uint32(fid.VolumeId),
+			NeedleId: uint64(fid.Key),
+			Cookie:   uint32(fid.Cookie),
+			Ranges:   ranges,
+			Auth:     assignResult.Auth,
+		})
+		if copyErr != nil {
+			return copyErr
+		}
+		eTags, md5 = resp.ETags, resp.Md5
+		return nil
+	})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("volume server %s: %v", volumeServer, err)
+	}
+	if len(eTags) != len(ranges) {
+		return nil, nil, nil, fmt.Errorf("volume server %s copied %d ranges, expected %d", volumeServer, len(eTags), len(ranges))
+	}
+
+	for i := range ranges {
+		fileIds = append(fileIds, needle.NewFileId(fid.VolumeId, uint64(fid.Key)+uint64(i), uint32(fid.Cookie)).String())
+	}
+	return fileIds, eTags, md5, nil
+}
+
+// parseCopySourceRange parses the x-amz-copy-source-range "bytes=first-last" of an object with the size.
+// The whole object is copied without the range.
+func parseCopySourceRange(rangeHeader string, objectSize int64) (start, size int64, ok bool) {
+	if rangeHeader == "" {
+		return 0, objectSize, true
+	}
+	first, last, found := strings.Cut(strings.TrimPrefix(rangeHeader, "bytes="), "-")
+	if !found || !strings.HasPrefix(rangeHeader, "bytes=") {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false
+	}
+	stop, err := strconv.ParseInt(last, 10, 64)
+	if err != nil || stop < start || stop >= objectSize {
+		return 0, 0, false
+	}
+	return start, stop - start + 1, true
+}
+
 func replaceDirective(reqHeader http.Header) (replaceMeta, replaceTagging bool) {
 	return reqHeader.Get(s3_constants.AmzUserMetaDirective) == DirectiveReplace, reqHeader.Get(s3_constants.AmzObjectTaggingDirective) == DirectiveReplace
 }
@@ -395,6 +395,30 @@ func TestProcessMetadataBytes(t *testing.T) {
 	}
 }
 
+func TestParseCopySourceRange(t *testing.T) {
+	tests := []struct {
+		rangeHeader string
+		start, size int64
+		ok          bool
+	}{
+		{"", 0, 100, true},
+		{"bytes=0-99", 0, 100, true},
+		{"bytes=10-19", 10, 10, true},
+		{"bytes=99-99", 99, 1, true},
+		{"bytes=10-100", 0, 0, false},
+		{"bytes=20-10", 0, 0, false},
+		{"bytes=-10", 0, 0, false},
+		{"bytes=10-", 0, 0, false},
+		{"10-20", 0, 0, false},
+	}
+	for _, tt := range tests {
+		start, size, ok := parseCopySourceRange(tt.rangeHeader, 100)
+		if start != tt.start || size != tt.size || ok != tt.ok {
+			t.Errorf("parseCopySourceRange(%q) = %d, %d, %v, want %d, %d, %v", tt.rangeHeader, start, size, ok, tt.start, tt.size, tt.ok)
+		}
+	}
+}
+
 func fmtTagging(maps ...map[string]string) {
 	for _, m := range maps {
 		if tagging := m[s3_constants.AmzObjectTagging]; len(tagging) > 0 {
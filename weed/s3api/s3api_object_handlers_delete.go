@@ -27,6 +27,21 @@ func (s3a *S3ApiServer) DeleteObjectHandler(w http.ResponseWriter, r *http.Reque
 	bucket, object := s3_constants.GetBucketAndObject(r)
 	glog.V(3).Infof("DeleteObjectHandler %s %s", bucket, object)
 
+	if s3a.getBucketVersioning(bucket) != "" {
+		versionId, deleteMarker, err := s3a.deleteVersionedObject(bucket, object, r.URL.Query().Get("versionId"))
+		if err != nil {
+			glog.Errorf("delete %s%s: %v", bucket, object, err)
+			s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+			return
+		}
+		setVersionIdResponseHeader(w, versionId)
+		if deleteMarker {
+			w.Header().Set(s3_constants.AmzDeleteMarker, "true")
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
 	target := util.FullPath(fmt.Sprintf("%s/%s%s", s3a.option.BucketsPath, bucket, object))
 	dir, name := target.DirAndName()
 
@@ -61,7 +76,10 @@ func (s3a *S3ApiServer) DeleteObjectHandler(w http.ResponseWriter, r *http.Reque
 
 // / ObjectIdentifier carries key name for the object to delete.
 type ObjectIdentifier struct {
-	ObjectName string `xml:"Key"`
+	ObjectName            string `xml:"Key"`
+	VersionId             string `xml:"VersionId,omitempty"`
+	DeleteMarker          bool   `xml:"DeleteMarker,omitempty"`
+	DeleteMarkerVersionId string `xml:"DeleteMarkerVersionId,omitempty"`
 }
 
 // DeleteObjectsRequest - xml carrying the object key names which needs to be deleted.
@@ -122,6 +140,8 @@ func (s3a *S3ApiServer) DeleteMultipleObjectsHandler(w http.ResponseWriter, r *h
 	if s3err.Logger != nil {
 		auditLog = s3err.GetAccessLog(r, http.StatusNoContent, s3err.ErrNone)
 	}
+
+	versioning := s3a.getBucketVersioning(bucket)
 	s3a.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
 
 		// delete file entries
@@ -129,6 +149,27 @@ func (s3a *S3ApiServer) DeleteMultipleObjectsHandler(w http.ResponseWriter, r *h
 			if object.ObjectName == "" {
 				continue
 			}
+			if versioning != "" {
+				versionId, deleteMarker, err := s3a.deleteVersionedObject(bucket, "/"+strings.TrimPrefix(object.ObjectName, "/"), object.VersionId)
+				if err == nil {
+					object.DeleteMarker = deleteMarker
+					if deleteMarker {
+						object.DeleteMarkerVersionId = versionId
+					}
+					deletedObjects = append(deletedObjects, object)
+				} else {
+					deleteErrors = append(deleteErrors, DeleteError{
+						Code:    "",
+						Message: err.Error(),
+						Key:     object.ObjectName,
+					})
+				}
+				if auditLog != nil {
+					auditLog.Key = object.ObjectName
+					s3err.PostAccessLog(*auditLog)
+				}
+				continue
+			}
 			lastSeparator := strings.LastIndex(object.ObjectName, "/")
 			parentDirectoryPath, entryName, isDeleteData, isRecursive := "", object.ObjectName, true, false
 			if lastSeparator > 0 && lastSeparator+1 < len(object.ObjectName) {
@@ -146,6 +146,9 @@ func (s3a *S3ApiServer) listFilerEntries(bucket string, originalPrefix string, m
 		maxKeys:               maxKeys,
 		prefixEndsOnDelimiter: strings.HasSuffix(originalPrefix, "/") && len(originalMarker) == 0,
 	}
+	if s3a.getBucketVersioning(bucket) != "" {
+		cursor.versionsFolder = s3a.genVersionsFolder(bucket, "")
+	}
 
 	// check filer
 	err = s3a.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
@@ -153,6 +156,9 @@ func (s3a *S3ApiServer) listFilerEntries(bucket string, originalPrefix string, m
 			empty := true
 			nextMarker, doErr = s3a.doListFilerEntries(client, reqDir, prefix, cursor, marker, delimiter, false, func(dir string, entry *filer_pb.Entry) {
 				empty = false
+				if isDeleteMarker(entry) {
+					return
+				}
 				dirName, entryName, prefixName := entryUrlEncode(dir, entry.Name, encodingTypeUrl)
 				if entry.IsDirectory {
 					if entry.IsDirectoryKeyObject() {
@@ -248,6 +254,8 @@ type ListingCursor struct {
 	maxKeys               uint16
 	isTruncated           bool
 	prefixEndsOnDelimiter bool
+	// the folder of the object versions, skipped in a bucket with versioning
+	versionsFolder string
 }
 
 // the prefix and marker may be in different directories
@@ -340,7 +348,7 @@ func (s3a *S3ApiServer) doListFilerEntries(client filer_pb.SeaweedFilerClient, d
 	request := &filer_pb.ListEntriesRequest{
 		Directory:          dir,
 		Prefix:             prefix,
-		Limit:              uint32(cursor.maxKeys + 2), // bucket root directory needs to skip additional s3_constants.MultipartUploadsFolder folder
+		Limit:              uint32(cursor.maxKeys + 3), // bucket root directory needs to skip additional s3_constants.MultipartUploadsFolder and s3_constants.VersionsFolder folders
 		StartFromFileName:  marker,
 		InclusiveStartFrom: inclusiveStartFrom,
 	}
@@ -386,6 +394,9 @@ func (s3a *S3ApiServer) doListFilerEntries(client filer_pb.SeaweedFilerClient, d
 			if entry.Name == s3_constants.MultipartUploadsFolder { // FIXME no need to apply to all directories. this extra also affects maxKeys
 				continue
 			}
+			if cursor.versionsFolder != "" && dir+"/"+entry.Name == cursor.versionsFolder {
+				continue
+			}
 			if delimiter != "/" || cursor.prefixEndsOnDelimiter {
 				if cursor.prefixEndsOnDelimiter {
 					cursor.prefixEndsOnDelimiter = false
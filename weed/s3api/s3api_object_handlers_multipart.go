@@ -31,6 +31,12 @@ const (
 func (s3a *S3ApiServer) NewMultipartUploadHandler(w http.ResponseWriter, r *http.Request) {
 	bucket, object := s3_constants.GetBucketAndObject(r)
 
+	if hasSSECustomerKey(r.Header) {
+		// multipart uploads are not encrypted with customer provided keys yet
+		s3err.WriteErrorResponse(w, r, s3err.ErrNotImplemented)
+		return
+	}
+
 	createMultipartUploadInput := &s3.CreateMultipartUploadInput{
 		Bucket:   aws.String(bucket),
 		Key:      objectKey(aws.String(object)),
@@ -92,6 +98,7 @@ func (s3a *S3ApiServer) CompleteMultipartUploadHandler(w http.ResponseWriter, r
 		return
 	}
 
+	setVersionIdResponseHeader(w, aws.StringValue(response.VersionId))
 	writeSuccessResponseXML(w, r, response)
 
 }
@@ -209,6 +216,11 @@ func (s3a *S3ApiServer) ListObjectPartsHandler(w http.ResponseWriter, r *http.Re
 func (s3a *S3ApiServer) PutObjectPartHandler(w http.ResponseWriter, r *http.Request) {
 	bucket, object := s3_constants.GetBucketAndObject(r)
 
+	if hasSSECustomerKey(r.Header) {
+		s3err.WriteErrorResponse(w, r, s3err.ErrNotImplemented)
+		return
+	}
+
 	uploadID := r.URL.Query().Get("uploadId")
 	err := s3a.checkUploadId(object, uploadID)
 	if err != nil {
@@ -244,8 +256,20 @@ func (s3a *S3ApiServer) PutObjectPartHandler(w http.ResponseWriter, r *http.Requ
 			return
 		}
 	}
+	if isUnsignedChunkedRequest(r) {
+		dataReader = newUnsignedChunkedReader(r)
+	}
 	defer dataReader.Close()
 
+	checksum, errCode := newChecksumReader(r.Header, dataReader)
+	if errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+	if checksum != nil {
+		dataReader = checksum
+	}
+
 	glog.V(2).Infof("PutObjectPartHandler %s %s %04d", bucket, uploadID, partID)
 
 	uploadUrl := s3a.genPartUploadUrl(bucket, uploadID, partID)
@@ -255,13 +279,15 @@ func (s3a *S3ApiServer) PutObjectPartHandler(w http.ResponseWriter, r *http.Requ
 	}
 	destination := fmt.Sprintf("%s/%s%s", s3a.option.BucketsPath, bucket, object)
 
+	removeSeaweedSSECustomerHeaders(r.Header)
 	etag, errCode := s3a.putToFiler(r, uploadUrl, dataReader, destination, bucket)
 	if errCode != s3err.ErrNone {
-		s3err.WriteErrorResponse(w, r, errCode)
+		s3err.WriteErrorResponse(w, r, checksum.checkUpload(errCode))
 		return
 	}
 
 	setEtag(w, etag)
+	checksum.setResponseHeader(w)
 
 	writeSuccessResponseEmpty(w, r)
 
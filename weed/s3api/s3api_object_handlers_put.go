@@ -2,10 +2,12 @@ package s3api
 
 import (
 	"crypto/md5"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -68,8 +70,20 @@ func (s3a *S3ApiServer) PutObjectHandler(w http.ResponseWriter, r *http.Request)
 			return
 		}
 	}
+	if isUnsignedChunkedRequest(r) {
+		dataReader = newUnsignedChunkedReader(r)
+	}
 	defer dataReader.Close()
 
+	checksum, checksumErrCode := newChecksumReader(r.Header, dataReader)
+	if checksumErrCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, checksumErrCode)
+		return
+	}
+	if checksum != nil {
+		dataReader = checksum
+	}
+
 	objectContentType := r.Header.Get("Content-Type")
 	if strings.HasSuffix(object, "/") && r.ContentLength <= 1024 {
 		if err := s3a.mkdir(
@@ -87,19 +101,62 @@ func (s3a *S3ApiServer) PutObjectHandler(w http.ResponseWriter, r *http.Request)
 			return
 		}
 	} else {
+		sseKey, errCode := parseSSECustomerKey(r.Header)
+		if errCode != s3err.ErrNone {
+			s3err.WriteErrorResponse(w, r, errCode)
+			return
+		}
+
 		uploadUrl := s3a.toFilerUrl(bucket, object)
 		if objectContentType == "" {
 			dataReader = mimeDetect(r, dataReader)
 		}
 
-		etag, errCode := s3a.putToFiler(r, uploadUrl, dataReader, "", bucket)
+		var body io.Reader = dataReader
+		removeSeaweedSSECustomerHeaders(r.Header)
+		if sseKey != nil {
+			var iv []byte
+			if body, iv, err = sseKey.encryptReader(dataReader); err != nil {
+				glog.Errorf("encrypt %s%s: %v", bucket, object, err)
+				s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+				return
+			}
+			removeSSECustomerKeyHeaders(r.Header)
+			// the digest of the plain text is verified while reading, and does not match the stored content
+			r.Header.Del("Content-Md5")
+			r.Header.Set(s3_constants.SeaweedFSSSECustomerAlgorithm, sseCustomerAlgorithmAES256)
+			r.Header.Set(s3_constants.SeaweedFSSSECustomerKeyMD5, sseKey.KeyMD5)
+			r.Header.Set(s3_constants.SeaweedFSSSECustomerIV, base64.StdEncoding.EncodeToString(iv))
+		}
+
+		r.Header.Del(s3_constants.ExtVersionIdKey)
+		r.Header.Del(s3_constants.ExtDeleteMarkerKey)
+		unlock := s3a.lockObject(bucket, object)
+		defer unlock()
+		versionId, err := s3a.prepareNewVersion(bucket, object)
+		if err != nil {
+			glog.Errorf("prepare new version of %s%s: %v", bucket, object, err)
+			s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+			return
+		}
+		if versionId != "" {
+			r.Header.Set(s3_constants.ExtVersionIdKey, versionId)
+		}
+
+		etag, errCode := s3a.putToFiler(r, uploadUrl, body, "", bucket)
 
 		if errCode != s3err.ErrNone {
-			s3err.WriteErrorResponse(w, r, errCode)
+			s3a.restoreLatestVersion(bucket, object)
+			s3err.WriteErrorResponse(w, r, checksum.checkUpload(errCode))
 			return
 		}
 
 		setEtag(w, etag)
+		checksum.setResponseHeader(w)
+		setVersionIdResponseHeader(w, versionId)
+		if sseKey != nil {
+			setSSECustomerResponseHeaders(w, sseKey.KeyMD5)
+		}
 	}
 
 	writeSuccessResponseEmpty(w, r)
@@ -122,11 +179,15 @@ func (s3a *S3ApiServer) putToFiler(r *http.Request, uploadUrl string, dataReader
 		proxyReq.Header.Set(s3_constants.SeaweedStorageDestinationHeader, destination)
 	}
 
+	query := proxyReq.URL.Query()
 	if s3a.option.FilerGroup != "" {
-		query := proxyReq.URL.Query()
 		query.Add("collection", s3a.getCollectionName(bucket))
-		proxyReq.URL.RawQuery = query.Encode()
 	}
+	if s3a.option.ChunkSizeMB > 0 {
+		// the filer uploads each chunk to the volume servers as it is read from the streamed body
+		query.Set("maxMB", strconv.Itoa(s3a.option.ChunkSizeMB))
+	}
+	proxyReq.URL.RawQuery = query.Encode()
 
 	for header, values := range r.Header {
 		for _, value := range values {
@@ -186,8 +247,13 @@ func filerErrorToS3Error(errString string) s3err.ErrorCode {
 	}
 }
 
+// maybeAddFilerJwtAuthorization adds the filer jwt to the proxied request, with the authenticated identity as its subject
 func (s3a *S3ApiServer) maybeAddFilerJwtAuthorization(r *http.Request, isWrite bool) {
-	encodedJwt := s3a.maybeGetFilerJwtAuthorizationToken(isWrite)
+	var user string
+	if s3a.iam != nil && s3a.iam.isEnabled() {
+		user = r.Header.Get(s3_constants.AmzIdentityId)
+	}
+	encodedJwt := s3a.maybeGetFilerUserJwtAuthorizationToken(isWrite, user)
 
 	if encodedJwt == "" {
 		return
@@ -197,11 +263,15 @@ func (s3a *S3ApiServer) maybeAddFilerJwtAuthorization(r *http.Request, isWrite b
 }
 
 func (s3a *S3ApiServer) maybeGetFilerJwtAuthorizationToken(isWrite bool) string {
+	return s3a.maybeGetFilerUserJwtAuthorizationToken(isWrite, "")
+}
+
+func (s3a *S3ApiServer) maybeGetFilerUserJwtAuthorizationToken(isWrite bool, user string) string {
 	var encodedJwt security.EncodedJwt
 	if isWrite {
-		encodedJwt = security.GenJwtForFilerServer(s3a.filerGuard.SigningKey, s3a.filerGuard.ExpiresAfterSec)
+		encodedJwt = security.GenJwtForFilerUser(s3a.filerGuard.SigningKey, s3a.filerGuard.ExpiresAfterSec, user)
 	} else {
-		encodedJwt = security.GenJwtForFilerServer(s3a.filerGuard.ReadSigningKey, s3a.filerGuard.ReadExpiresAfterSec)
+		encodedJwt = security.GenJwtForFilerUser(s3a.filerGuard.ReadSigningKey, s3a.filerGuard.ReadExpiresAfterSec, user)
 	}
 	return string(encodedJwt)
 }
@@ -0,0 +1,90 @@
+package s3api
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3select"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+// maxSelectRequestSize limits the request body, with the SQL expression of up to 256KB
+const maxSelectRequestSize = 512 * 1024
+
+// SelectObjectContentHandler filters the object content with the SQL expression, and streams the results back.
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_SelectObjectContent.html
+func (s3a *S3ApiServer) SelectObjectContentHandler(w http.ResponseWriter, r *http.Request) {
+
+	bucket, object := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("SelectObjectContentHandler %s %s", bucket, object)
+
+	sseKey, errCode := parseSSECustomerKey(r.Header)
+	if errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSelectRequestSize+1))
+	if err != nil {
+		glog.Errorf("SelectObjectContentHandler read %s/%s: %v", bucket, object, err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+	if len(body) > maxSelectRequestSize {
+		s3err.WriteErrorResponse(w, r, s3err.ErrEntityTooLarge)
+		return
+	}
+	selectQuery, err := s3select.Parse(body)
+	if err != nil {
+		glog.V(1).Infof("SelectObjectContentHandler %s/%s: %v", bucket, object, err)
+		s3err.WriteErrorResponse(w, r, s3select.ErrorCode(err))
+		return
+	}
+
+	destUrl := s3a.toFilerUrl(bucket, object)
+	proxyReq, err := http.NewRequest(http.MethodGet, destUrl, nil)
+	if err != nil {
+		glog.Errorf("NewRequest %s: %v", destUrl, err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+	proxyReq.Header.Set("X-Forwarded-For", r.RemoteAddr)
+	s3a.maybeAddFilerJwtAuthorization(proxyReq, false)
+	resp, err := s3a.client.Do(proxyReq)
+	if err != nil {
+		glog.Errorf("get %s from filer: %v", destUrl, err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+	defer util.CloseResponse(resp)
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound,
+		resp.Header.Get(s3_constants.ExtDeleteMarkerKey) == "true",
+		resp.Header.Get(s3_constants.SeaweedFSIsDirectoryKey) == "true":
+		s3err.WriteErrorResponse(w, r, s3err.ErrNoSuchKey)
+		return
+	case resp.StatusCode != http.StatusOK:
+		glog.Errorf("get %s from filer: %s", destUrl, resp.Status)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+	storedKeyMD5, errCode := decryptSSECustomerResponse(r, sseKey, resp)
+	if errCode != s3err.ErrNone {
+		s3err.WriteErrorResponse(w, r, errCode)
+		return
+	}
+	if storedKeyMD5 != "" {
+		setSSECustomerResponseHeaders(w, storedKeyMD5)
+	}
+
+	// the errors after the response header are reported in the event stream
+	w.WriteHeader(http.StatusOK)
+	if err = selectQuery.Run(resp.Body, w); err != nil {
+		glog.V(1).Infof("SelectObjectContentHandler %s/%s: %v", bucket, object, err)
+	}
+	s3err.PostLog(r, http.StatusOK, s3err.ErrNone)
+}
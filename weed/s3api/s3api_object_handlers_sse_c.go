@@ -0,0 +1,205 @@
+package s3api
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+)
+
+const sseCustomerAlgorithmAES256 = "AES256"
+
+// SSECustomerKey is the customer provided key of SSE-C requests.
+// Each object is encrypted with AES-256-CTR, using a data key derived from the customer key and a random IV.
+// Only the IV and the MD5 of the customer key are saved with the object.
+type SSECustomerKey struct {
+	Key    []byte
+	KeyMD5 string
+}
+
+// parseSSECustomerKey returns nil if the request does not use SSE-C.
+func parseSSECustomerKey(header http.Header) (*SSECustomerKey, s3err.ErrorCode) {
+	algorithm := header.Get(s3_constants.AmzServerSideEncryptionCustomerAlgorithm)
+	encodedKey := header.Get(s3_constants.AmzServerSideEncryptionCustomerKey)
+	keyMD5 := header.Get(s3_constants.AmzServerSideEncryptionCustomerKeyMD5)
+	if algorithm == "" && encodedKey == "" && keyMD5 == "" {
+		return nil, s3err.ErrNone
+	}
+	if algorithm != sseCustomerAlgorithmAES256 {
+		return nil, s3err.ErrInvalidEncryptionAlgorithm
+	}
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil || len(key) != 32 {
+		return nil, s3err.ErrInvalidSSECustomerKey
+	}
+	sum := md5.Sum(key)
+	if base64.StdEncoding.EncodeToString(sum[:]) != keyMD5 {
+		return nil, s3err.ErrSSECustomerKeyMD5Mismatch
+	}
+	return &SSECustomerKey{Key: key, KeyMD5: keyMD5}, s3err.ErrNone
+}
+
+func hasSSECustomerKey(header http.Header) bool {
+	return header.Get(s3_constants.AmzServerSideEncryptionCustomerAlgorithm) != "" ||
+		header.Get(s3_constants.AmzServerSideEncryptionCustomerKey) != ""
+}
+
+// removeSSECustomerKeyHeaders keeps the customer key from being forwarded to the filer.
+func removeSSECustomerKeyHeaders(header http.Header) {
+	header.Del(s3_constants.AmzServerSideEncryptionCustomerAlgorithm)
+	header.Del(s3_constants.AmzServerSideEncryptionCustomerKey)
+	header.Del(s3_constants.AmzServerSideEncryptionCustomerKeyMD5)
+}
+
+// removeSeaweedSSECustomerHeaders keeps the clients from storing their own key md5 and iv with the object,
+// since the filer saves the Seaweed- headers in the entry.
+func removeSeaweedSSECustomerHeaders(header http.Header) {
+	header.Del(s3_constants.SeaweedFSSSECustomerAlgorithm)
+	header.Del(s3_constants.SeaweedFSSSECustomerKeyMD5)
+	header.Del(s3_constants.SeaweedFSSSECustomerIV)
+}
+
+func (k *SSECustomerKey) newStream(iv []byte, offset int64) (cipher.Stream, error) {
+	mac := hmac.New(sha256.New, k.Key)
+	mac.Write(iv)
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	// advance the counter to the block containing the offset
+	counter := make([]byte, aes.BlockSize)
+	copy(counter, iv)
+	low := binary.BigEndian.Uint64(counter[8:])
+	high := binary.BigEndian.Uint64(counter[:8])
+	next := low + uint64(offset/aes.BlockSize)
+	if next < low {
+		high++
+	}
+	binary.BigEndian.PutUint64(counter[:8], high)
+	binary.BigEndian.PutUint64(counter[8:], next)
+
+	stream := cipher.NewCTR(block, counter)
+	if skip := offset % aes.BlockSize; skip > 0 {
+		discard := make([]byte, skip)
+		stream.XORKeyStream(discard, discard)
+	}
+	return stream, nil
+}
+
+// encryptReader encrypts the object body, and returns the IV to save with the object.
+func (k *SSECustomerKey) encryptReader(reader io.Reader) (io.Reader, []byte, error) {
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, nil, err
+	}
+	stream, err := k.newStream(iv, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &cipher.StreamReader{S: stream, R: reader}, iv, nil
+}
+
+// decryptReader decrypts the object body starting at the offset of the object.
+func (k *SSECustomerKey) decryptReader(reader io.Reader, iv []byte, offset int64) (io.Reader, error) {
+	stream, err := k.newStream(iv, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &cipher.StreamReader{S: stream, R: reader}, nil
+}
+
+func setSSECustomerResponseHeaders(w http.ResponseWriter, keyMD5 string) {
+	w.Header().Set(s3_constants.AmzServerSideEncryptionCustomerAlgorithm, sseCustomerAlgorithmAES256)
+	w.Header().Set(s3_constants.AmzServerSideEncryptionCustomerKeyMD5, keyMD5)
+}
+
+// passThroughSSECustomerResponse verifies the customer key against the object, and decrypts the object on the fly.
+// Objects stored without SSE-C are passed through as is.
+func passThroughSSECustomerResponse(r *http.Request, sseKey *SSECustomerKey) func(proxyResponse *http.Response, w http.ResponseWriter) (statusCode int) {
+	return func(proxyResponse *http.Response, w http.ResponseWriter) (statusCode int) {
+		storedKeyMD5, errCode := decryptSSECustomerResponse(r, sseKey, proxyResponse)
+		if errCode != s3err.ErrNone {
+			s3err.WriteErrorResponse(w, r, errCode)
+			return s3err.GetAPIError(errCode).HTTPStatusCode
+		}
+		if storedKeyMD5 != "" {
+			setSSECustomerResponseHeaders(w, storedKeyMD5)
+		}
+		return passThroughResponse(proxyResponse, w)
+	}
+}
+
+// decryptSSECustomerResponse verifies the customer key against the object, and replaces the response body with the decrypted content.
+// It returns the key md5 of the object stored with SSE-C, or "" for the objects stored without SSE-C, which are left as is.
+func decryptSSECustomerResponse(r *http.Request, sseKey *SSECustomerKey, proxyResponse *http.Response) (storedKeyMD5 string, errCode s3err.ErrorCode) {
+	storedKeyMD5 = proxyResponse.Header.Get(s3_constants.SeaweedFSSSECustomerKeyMD5)
+	encodedIV := proxyResponse.Header.Get(s3_constants.SeaweedFSSSECustomerIV)
+	proxyResponse.Header.Del(s3_constants.SeaweedFSSSECustomerAlgorithm)
+	proxyResponse.Header.Del(s3_constants.SeaweedFSSSECustomerKeyMD5)
+	proxyResponse.Header.Del(s3_constants.SeaweedFSSSECustomerIV)
+
+	if storedKeyMD5 == "" {
+		if sseKey != nil {
+			return "", s3err.ErrInvalidRequest
+		}
+		return "", s3err.ErrNone
+	}
+	if sseKey == nil {
+		return "", s3err.ErrSSECustomerKeyMissing
+	}
+	if sseKey.KeyMD5 != storedKeyMD5 {
+		return "", s3err.ErrSSECustomerKeyMismatch
+	}
+	iv, err := base64.StdEncoding.DecodeString(encodedIV)
+	if err != nil || len(iv) != aes.BlockSize {
+		glog.Errorf("invalid sse-c iv %q of %s: %v", encodedIV, r.URL.Path, err)
+		return "", s3err.ErrInternalError
+	}
+	offset, err := parseContentRangeStart(proxyResponse.Header.Get("Content-Range"))
+	if err != nil {
+		glog.Errorf("invalid content range of %s: %v", r.URL.Path, err)
+		return "", s3err.ErrInternalError
+	}
+	decrypted, err := sseKey.decryptReader(proxyResponse.Body, iv, offset)
+	if err != nil {
+		glog.Errorf("decrypt %s: %v", r.URL.Path, err)
+		return "", s3err.ErrInternalError
+	}
+	proxyResponse.Body = &decryptedReadCloser{Reader: decrypted, Closer: proxyResponse.Body}
+	return storedKeyMD5, s3err.ErrNone
+}
+
+// parseContentRangeStart returns the start of "bytes start-end/size", or 0 without content range.
+func parseContentRangeStart(contentRange string) (int64, error) {
+	if contentRange == "" {
+		return 0, nil
+	}
+	rangeSpec, found := strings.CutPrefix(contentRange, "bytes ")
+	if !found {
+		return 0, fmt.Errorf("unexpected content range %q", contentRange)
+	}
+	start, _, found := strings.Cut(rangeSpec, "-")
+	if !found {
+		return 0, fmt.Errorf("unexpected content range %q", contentRange)
+	}
+	return strconv.ParseInt(start, 10, 64)
+}
+
+type decryptedReadCloser struct {
+	io.Reader
+	io.Closer
+}
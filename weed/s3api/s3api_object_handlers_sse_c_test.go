@@ -0,0 +1,137 @@
+package s3api
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/security"
+	weed_server "github.com/seaweedfs/seaweedfs/weed/server"
+)
+
+func sseCustomerKeyHeader(key []byte, algorithm string) http.Header {
+	sum := md5.Sum(key)
+	header := http.Header{}
+	header.Set(s3_constants.AmzServerSideEncryptionCustomerAlgorithm, algorithm)
+	header.Set(s3_constants.AmzServerSideEncryptionCustomerKey, base64.StdEncoding.EncodeToString(key))
+	header.Set(s3_constants.AmzServerSideEncryptionCustomerKeyMD5, base64.StdEncoding.EncodeToString(sum[:]))
+	return header
+}
+
+func TestParseSSECustomerKey(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, 32)
+
+	sseKey, errCode := parseSSECustomerKey(http.Header{})
+	assert.Nil(t, sseKey)
+	assert.Equal(t, s3err.ErrNone, errCode)
+
+	sseKey, errCode = parseSSECustomerKey(sseCustomerKeyHeader(key, "AES256"))
+	assert.Equal(t, s3err.ErrNone, errCode)
+	assert.Equal(t, key, sseKey.Key)
+
+	_, errCode = parseSSECustomerKey(sseCustomerKeyHeader(key, "aws:kms"))
+	assert.Equal(t, s3err.ErrInvalidEncryptionAlgorithm, errCode)
+
+	_, errCode = parseSSECustomerKey(sseCustomerKeyHeader(key[:16], "AES256"))
+	assert.Equal(t, s3err.ErrInvalidSSECustomerKey, errCode)
+
+	header := sseCustomerKeyHeader(key, "AES256")
+	header.Set(s3_constants.AmzServerSideEncryptionCustomerKeyMD5, "bad")
+	_, errCode = parseSSECustomerKey(header)
+	assert.Equal(t, s3err.ErrSSECustomerKeyMD5Mismatch, errCode)
+}
+
+func TestSSECustomerKeyDecryptAtOffset(t *testing.T) {
+	sseKey, _ := parseSSECustomerKey(sseCustomerKeyHeader(bytes.Repeat([]byte{3}, 32), "AES256"))
+
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	encryptedReader, iv, err := sseKey.encryptReader(bytes.NewReader(data))
+	assert.Nil(t, err)
+	encrypted, _ := io.ReadAll(encryptedReader)
+	assert.NotEqual(t, data, encrypted)
+
+	for _, offset := range []int64{0, 1, 15, 16, 17, 500, 999} {
+		decryptedReader, err := sseKey.decryptReader(bytes.NewReader(encrypted[offset:]), iv, offset)
+		assert.Nil(t, err)
+		decrypted, _ := io.ReadAll(decryptedReader)
+		assert.Equal(t, data[offset:], decrypted, "offset %d", offset)
+	}
+}
+
+func TestParseContentRangeStart(t *testing.T) {
+	start, err := parseContentRangeStart("")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), start)
+
+	start, err = parseContentRangeStart("bytes 100-199/1000")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(100), start)
+
+	_, err = parseContentRangeStart("items 1-2/3")
+	assert.NotNil(t, err)
+}
+
+func TestPutObjectRemovesClientSeaweedSSECustomerHeaders(t *testing.T) {
+	var filerHeaders http.Header
+	filerServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		filerHeaders = r.Header.Clone()
+		json.NewEncoder(w).Encode(weed_server.FilerPostResult{Name: path.Base(r.URL.Path), Size: int64(len(data))})
+	}))
+	defer filerServer.Close()
+
+	s3a := &S3ApiServer{
+		option: &S3ApiServerOption{
+			Filer:       pb.ServerAddress(strings.TrimPrefix(filerServer.URL, "http://")),
+			BucketsPath: "/buckets",
+		},
+		iam:        &IdentityAccessManagement{},
+		filerGuard: security.NewGuard(nil, "", 0, "", 0),
+		client:     &http.Client{},
+	}
+	s3a.bucketRegistry = &BucketRegistry{
+		metadataCache: map[string]*BucketMetaData{"bucket1": {Name: "bucket1"}},
+		notFound:      make(map[string]struct{}),
+		s3a:           s3a,
+	}
+
+	key := bytes.Repeat([]byte{7}, 32)
+	for _, header := range []http.Header{{}, sseCustomerKeyHeader(key, "AES256")} {
+		r := httptest.NewRequest(http.MethodPut, "/bucket1/obj", strings.NewReader("hello"))
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		r.Header.Set(s3_constants.SeaweedFSSSECustomerAlgorithm, "AES256")
+		r.Header.Set(s3_constants.SeaweedFSSSECustomerKeyMD5, "forged")
+		r.Header.Set(s3_constants.SeaweedFSSSECustomerIV, "forged")
+		r = mux.SetURLVars(r, map[string]string{"bucket": "bucket1", "object": "obj"})
+		w := httptest.NewRecorder()
+		s3a.PutObjectHandler(w, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		for _, h := range []string{s3_constants.SeaweedFSSSECustomerAlgorithm, s3_constants.SeaweedFSSSECustomerKeyMD5, s3_constants.SeaweedFSSSECustomerIV} {
+			if len(header) == 0 {
+				assert.Empty(t, filerHeaders.Values(h), h)
+			} else {
+				assert.Len(t, filerHeaders.Values(h), 1, h)
+				assert.NotEqual(t, "forged", filerHeaders.Get(h), h)
+			}
+		}
+	}
+}
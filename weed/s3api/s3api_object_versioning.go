@@ -0,0 +1,438 @@
+package s3api
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+// In a bucket with versioning, the object path always has the latest version, either the object or a delete marker.
+// The older versions are moved to <bucket>/.versions/<object>/<versionId>, keeping their chunks.
+// Objects written before versioning is enabled, or while it is suspended, have the "null" version id.
+
+const (
+	nullVersionId = "null"
+
+	objectLockDuration      = 5 * time.Second
+	objectLockRenewInterval = 2 * time.Second
+)
+
+// newVersionId returns ids sorting the newer versions first
+func newVersionId() string {
+	return fmt.Sprintf("%016x", math.MaxInt64-time.Now().UnixNano())
+}
+
+func (s3a *S3ApiServer) getBucketVersioning(bucket string) string {
+	metadata, errCode := s3a.bucketRegistry.GetBucketMetadata(bucket)
+	if errCode != s3err.ErrNone {
+		return ""
+	}
+	return metadata.Versioning
+}
+
+func (s3a *S3ApiServer) genVersionsFolder(bucket, object string) string {
+	return fmt.Sprintf("%s/%s/%s%s", s3a.option.BucketsPath, bucket, s3_constants.VersionsFolder, object)
+}
+
+func getVersionId(entry *filer_pb.Entry) string {
+	if versionId, found := entry.Extended[s3_constants.ExtVersionIdKey]; found && len(versionId) > 0 {
+		return string(versionId)
+	}
+	return nullVersionId
+}
+
+// isValidVersionId keeps the version id from escaping the versions folder of the object
+func isValidVersionId(versionId string) bool {
+	return versionId != "" && versionId != "." && versionId != ".." && !strings.Contains(versionId, "/")
+}
+
+func isDeleteMarker(entry *filer_pb.Entry) bool {
+	return string(entry.Extended[s3_constants.ExtDeleteMarkerKey]) == "true"
+}
+
+func setVersionIdResponseHeader(w http.ResponseWriter, versionId string) {
+	if versionId != "" {
+		w.Header().Set(s3_constants.AmzVersionId, versionId)
+	}
+}
+
+// lockObject holds the filer distributed lock of the object in a bucket with versioning, until the returned unlock is called.
+// The lock is held while the latest version is archived and the new version is written,
+// so concurrent writes and deletes of the object do not archive the same latest version.
+func (s3a *S3ApiServer) lockObject(bucket, object string) (unlock func()) {
+	if s3a.lockClient == nil || s3a.getBucketVersioning(bucket) == "" {
+		return func() {}
+	}
+	key := fmt.Sprintf("s3.object:%s/%s%s", s3a.option.BucketsPath, bucket, object)
+
+	// the requests to this gateway wait in turn, instead of polling the filer for the lock
+	localLock := s3a.objectLocks.AcquireLock("versioning", key, util.ExclusiveLock)
+	lock := s3a.lockClient.NewShortLivedLock(key, fmt.Sprintf("s3-%d", s3a.randomClientId))
+
+	// the lock expires after a few seconds, and is renewed while a large object is written
+	done, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(objectLockRenewInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := lock.AttemptToLock(objectLockDuration); err != nil {
+					glog.Warningf("renew lock of %s%s: %v", bucket, object, err)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+		if err := lock.StopShortLivedLock(); err != nil {
+			glog.Warningf("unlock %s%s: %v", bucket, object, err)
+		}
+		s3a.objectLocks.ReleaseLock(key, localLock)
+	}
+}
+
+// prepareNewVersion moves the latest version of the object aside before it is replaced,
+// and returns the version id of the new version, "" for the null version or if the bucket has no versioning.
+func (s3a *S3ApiServer) prepareNewVersion(bucket, object string) (versionId string, err error) {
+	switch s3a.getBucketVersioning(bucket) {
+	case s3_constants.VersioningEnabled:
+		return newVersionId(), s3a.archiveLatestVersion(bucket, object, false)
+	case s3_constants.VersioningSuspended:
+		// the new null version replaces the existing null version
+		if err = s3a.rm(s3a.genVersionsFolder(bucket, object), nullVersionId, true, false); err != nil {
+			return "", err
+		}
+		return "", s3a.archiveLatestVersion(bucket, object, true)
+	}
+	return "", nil
+}
+
+func (s3a *S3ApiServer) archiveLatestVersion(bucket, object string, keepNullVersion bool) error {
+	dir, name := util.FullPath(fmt.Sprintf("%s/%s%s", s3a.option.BucketsPath, bucket, object)).DirAndName()
+	entry, err := s3a.getEntry(dir, name)
+	if err == filer_pb.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("lookup %s/%s: %v", dir, name, err)
+	}
+	if entry.IsDirectory {
+		return nil
+	}
+	versionId := getVersionId(entry)
+	if versionId == nullVersionId && keepNullVersion {
+		return nil
+	}
+	return s3a.renameEntry(dir, name, s3a.genVersionsFolder(bucket, object), versionId)
+}
+
+func (s3a *S3ApiServer) renameEntry(oldDir, oldName, newDir, newName string) error {
+	return s3a.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		_, err := client.AtomicRenameEntry(context.Background(), &filer_pb.AtomicRenameEntryRequest{
+			OldDirectory: oldDir,
+			OldName:      oldName,
+			NewDirectory: newDir,
+			NewName:      newName,
+		})
+		if err != nil {
+			return fmt.Errorf("move %s/%s to %s/%s: %v", oldDir, oldName, newDir, newName, err)
+		}
+		return nil
+	})
+}
+
+// listObjectVersions returns the older versions of the object, the newest first
+func (s3a *S3ApiServer) listObjectVersions(bucket, object string) ([]*filer_pb.Entry, error) {
+	var versions []*filer_pb.Entry
+	err := filer_pb.ReadDirAllEntries(s3a, util.FullPath(s3a.genVersionsFolder(bucket, object)), "", func(entry *filer_pb.Entry, isLast bool) error {
+		if !entry.IsDirectory {
+			versions = append(versions, entry)
+		}
+		return nil
+	})
+	if err != nil && err != filer_pb.ErrNotFound {
+		return nil, err
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		a, b := versions[i].Attributes, versions[j].Attributes
+		if a.Mtime != b.Mtime {
+			return a.Mtime > b.Mtime
+		}
+		return a.MtimeNs > b.MtimeNs
+	})
+	return versions, nil
+}
+
+// resolveObjectVersion returns the object path to read the version from
+func (s3a *S3ApiServer) resolveObjectVersion(bucket, object, versionId string) (string, s3err.ErrorCode) {
+	if !isValidVersionId(versionId) {
+		return "", s3err.ErrNoSuchVersion
+	}
+	dir, name := util.FullPath(fmt.Sprintf("%s/%s%s", s3a.option.BucketsPath, bucket, object)).DirAndName()
+	entry, err := s3a.getEntry(dir, name)
+	if err != nil && err != filer_pb.ErrNotFound {
+		return "", s3err.ErrInternalError
+	}
+	if err == nil && !entry.IsDirectory && getVersionId(entry) == versionId {
+		if isDeleteMarker(entry) {
+			return "", s3err.ErrMethodNotAllowed
+		}
+		return object, s3err.ErrNone
+	}
+	entry, err = s3a.getEntry(s3a.genVersionsFolder(bucket, object), versionId)
+	if err == filer_pb.ErrNotFound {
+		return "", s3err.ErrNoSuchVersion
+	}
+	if err != nil {
+		return "", s3err.ErrInternalError
+	}
+	if isDeleteMarker(entry) {
+		return "", s3err.ErrMethodNotAllowed
+	}
+	return fmt.Sprintf("/%s%s/%s", s3_constants.VersionsFolder, object, versionId), s3err.ErrNone
+}
+
+// deleteVersionedObject puts a delete marker on top of the object, or, with a version id,
+// permanently deletes that version. Deleting the latest version makes the previous version the latest.
+func (s3a *S3ApiServer) deleteVersionedObject(bucket, object, versionId string) (deletedVersionId string, deleteMarker bool, err error) {
+	unlock := s3a.lockObject(bucket, object)
+	defer unlock()
+
+	dir, name := util.FullPath(fmt.Sprintf("%s/%s%s", s3a.option.BucketsPath, bucket, object)).DirAndName()
+
+	if versionId == "" {
+		if versionId, err = s3a.prepareNewVersion(bucket, object); err != nil {
+			return "", false, err
+		}
+		now := time.Now()
+		err = s3a.mkFile(dir, name, nil, func(entry *filer_pb.Entry) {
+			entry.Attributes.Mtime, entry.Attributes.MtimeNs = now.Unix(), int64(now.Nanosecond())
+			entry.Extended = map[string][]byte{
+				s3_constants.ExtDeleteMarkerKey: []byte("true"),
+			}
+			if versionId != "" {
+				entry.Extended[s3_constants.ExtVersionIdKey] = []byte(versionId)
+			}
+		})
+		if versionId == "" {
+			versionId = nullVersionId
+		}
+		return versionId, true, err
+	}
+
+	if !isValidVersionId(versionId) {
+		return versionId, false, nil
+	}
+	latest, err := s3a.getEntry(dir, name)
+	if err != nil && err != filer_pb.ErrNotFound {
+		return "", false, err
+	}
+	if err == nil && !latest.IsDirectory && getVersionId(latest) == versionId {
+		if err = s3a.rm(dir, name, true, false); err != nil {
+			return "", false, err
+		}
+		return versionId, isDeleteMarker(latest), s3a.promotePreviousVersion(bucket, object)
+	}
+
+	versionsFolder := s3a.genVersionsFolder(bucket, object)
+	version, err := s3a.getEntry(versionsFolder, versionId)
+	if err == filer_pb.ErrNotFound {
+		return versionId, false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return versionId, isDeleteMarker(version), s3a.rm(versionsFolder, versionId, true, false)
+}
+
+// restoreLatestVersion moves the latest version back, after failing to write a new version
+func (s3a *S3ApiServer) restoreLatestVersion(bucket, object string) {
+	if s3a.getBucketVersioning(bucket) == "" {
+		return
+	}
+	dir, name := util.FullPath(fmt.Sprintf("%s/%s%s", s3a.option.BucketsPath, bucket, object)).DirAndName()
+	if _, err := s3a.getEntry(dir, name); err != filer_pb.ErrNotFound {
+		return
+	}
+	if err := s3a.promotePreviousVersion(bucket, object); err != nil {
+		glog.Errorf("restore latest version of %s%s: %v", bucket, object, err)
+	}
+}
+
+func (s3a *S3ApiServer) promotePreviousVersion(bucket, object string) error {
+	versions, err := s3a.listObjectVersions(bucket, object)
+	if err != nil || len(versions) == 0 {
+		return err
+	}
+	dir, name := util.FullPath(fmt.Sprintf("%s/%s%s", s3a.option.BucketsPath, bucket, object)).DirAndName()
+	return s3a.renameEntry(s3a.genVersionsFolder(bucket, object), versions[0].Name, dir, name)
+}
+
+type ListObjectVersionsResult struct {
+	XMLName             xml.Name            `xml:"http://s3.amazonaws.com/doc/2006-03-01/ ListVersionsResult"`
+	Name                string              `xml:"Name"`
+	Prefix              string              `xml:"Prefix"`
+	KeyMarker           string              `xml:"KeyMarker"`
+	VersionIdMarker     string              `xml:"VersionIdMarker"`
+	NextKeyMarker       string              `xml:"NextKeyMarker,omitempty"`
+	NextVersionIdMarker string              `xml:"NextVersionIdMarker,omitempty"`
+	MaxKeys             int                 `xml:"MaxKeys"`
+	IsTruncated         bool                `xml:"IsTruncated"`
+	Versions            []VersionEntry      `xml:"Version,omitempty"`
+	DeleteMarkers       []DeleteMarkerEntry `xml:"DeleteMarker,omitempty"`
+}
+
+func (result *ListObjectVersionsResult) addVersion(key string, entry *filer_pb.Entry, isLatest bool) {
+	lastModified := time.Unix(entry.Attributes.Mtime, entry.Attributes.MtimeNs).UTC()
+	owner := CanonicalUser{
+		ID:          fmt.Sprintf("%x", entry.Attributes.Uid),
+		DisplayName: entry.Attributes.UserName,
+	}
+	if isDeleteMarker(entry) {
+		result.DeleteMarkers = append(result.DeleteMarkers, DeleteMarkerEntry{
+			Key:          key,
+			VersionId:    getVersionId(entry),
+			IsLatest:     isLatest,
+			LastModified: lastModified,
+			Owner:        owner,
+		})
+		return
+	}
+	storageClass := "STANDARD"
+	if v, ok := entry.Extended[s3_constants.AmzStorageClass]; ok {
+		storageClass = string(v)
+	}
+	result.Versions = append(result.Versions, VersionEntry{
+		Key:          key,
+		VersionId:    getVersionId(entry),
+		IsLatest:     isLatest,
+		LastModified: lastModified,
+		ETag:         "\"" + filer.ETag(entry) + "\"",
+		Size:         int64(filer.FileSize(entry)),
+		Owner:        owner,
+		StorageClass: StorageClass(storageClass),
+	})
+}
+
+// ListObjectVersionsHandler lists all versions of the objects, including delete markers.
+// The listing pages by keys, all versions of one key are in the same page. Delimiters are not supported.
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_ListObjectVersions.html
+func (s3a *S3ApiServer) ListObjectVersionsHandler(w http.ResponseWriter, r *http.Request) {
+
+	bucket, _ := s3_constants.GetBucketAndObject(r)
+	glog.V(3).Infof("ListObjectVersionsHandler %s", bucket)
+
+	values := r.URL.Query()
+	if values.Get("delimiter") != "" {
+		s3err.WriteErrorResponse(w, r, s3err.ErrNotImplemented)
+		return
+	}
+	originalPrefix := values.Get("prefix")
+	keyMarker := values.Get("key-marker")
+	maxKeys := maxObjectListSizeLimit
+	if values.Get("max-keys") != "" {
+		var err error
+		if maxKeys, err = strconv.Atoi(values.Get("max-keys")); err != nil || maxKeys < 0 {
+			s3err.WriteErrorResponse(w, r, s3err.ErrInvalidMaxKeys)
+			return
+		}
+		if maxKeys > maxObjectListSizeLimit {
+			maxKeys = maxObjectListSizeLimit
+		}
+	}
+
+	result, err := s3a.listVersions(bucket, originalPrefix, keyMarker, maxKeys)
+	if err != nil {
+		glog.Errorf("ListObjectVersionsHandler %s: %v", bucket, err)
+		s3err.WriteErrorResponse(w, r, s3err.ErrInternalError)
+		return
+	}
+	result.VersionIdMarker = values.Get("version-id-marker")
+
+	writeSuccessResponseXML(w, r, result)
+}
+
+func (s3a *S3ApiServer) listVersions(bucket, originalPrefix, keyMarker string, maxKeys int) (result *ListObjectVersionsResult, err error) {
+	result = &ListObjectVersionsResult{
+		Name:      bucket,
+		Prefix:    originalPrefix,
+		KeyMarker: keyMarker,
+		MaxKeys:   maxKeys,
+	}
+
+	requestDir, prefix, marker := normalizePrefixMarker(originalPrefix, keyMarker)
+	bucketPrefix := fmt.Sprintf("%s/%s/", s3a.option.BucketsPath, bucket)
+	reqDir := bucketPrefix[:len(bucketPrefix)-1]
+	if requestDir != "" {
+		reqDir = fmt.Sprintf("%s%s", bucketPrefix, requestDir)
+	}
+	cursor := &ListingCursor{
+		maxKeys:        uint16(maxKeys),
+		versionsFolder: s3a.genVersionsFolder(bucket, ""),
+	}
+
+	var lastKey string
+	err = s3a.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		for {
+			empty := true
+			var eachErr error
+			nextMarker, doErr := s3a.doListFilerEntries(client, reqDir, prefix, cursor, marker, "", false, func(dir string, entry *filer_pb.Entry) {
+				empty = false
+				if entry.IsDirectory || eachErr != nil {
+					return
+				}
+				key := fmt.Sprintf("%s/%s", dir, entry.Name)[len(bucketPrefix):]
+				if !strings.HasPrefix(key, originalPrefix) {
+					return
+				}
+				versions, listErr := s3a.listObjectVersions(bucket, "/"+key)
+				if listErr != nil {
+					eachErr = listErr
+					return
+				}
+				result.addVersion(key, entry, true)
+				for _, version := range versions {
+					result.addVersion(key, version, false)
+				}
+				lastKey = key
+				cursor.maxKeys--
+			})
+			if doErr != nil {
+				return doErr
+			}
+			if eachErr != nil {
+				return eachErr
+			}
+			if cursor.isTruncated || empty {
+				break
+			}
+			marker = nextMarker
+		}
+		return nil
+	})
+
+	result.IsTruncated = cursor.isTruncated
+	if result.IsTruncated {
+		result.NextKeyMarker = lastKey
+	}
+	return
+}
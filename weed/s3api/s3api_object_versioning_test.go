@@ -0,0 +1,125 @@
+package s3api
+
+import (
+	"context"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/seaweedfs/seaweedfs/weed/cluster"
+	"github.com/seaweedfs/seaweedfs/weed/cluster/lock_manager"
+	"github.com/seaweedfs/seaweedfs/weed/pb"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestNewVersionIdSortsNewerFirst(t *testing.T) {
+	var versionIds []string
+	for i := 0; i < 3; i++ {
+		versionIds = append(versionIds, newVersionId())
+		time.Sleep(time.Millisecond)
+	}
+	assert.True(t, sort.SliceIsSorted(versionIds, func(i, j int) bool {
+		return versionIds[i] > versionIds[j]
+	}), "version ids %v", versionIds)
+}
+
+func TestIsValidVersionId(t *testing.T) {
+	assert.True(t, isValidVersionId(nullVersionId))
+	assert.True(t, isValidVersionId(newVersionId()))
+	for _, versionId := range []string{"", ".", "..", "../a", "a/b"} {
+		assert.False(t, isValidVersionId(versionId), versionId)
+	}
+}
+
+func TestCompleteMultipartUploadResultHidesVersionId(t *testing.T) {
+	result := &CompleteMultipartUploadResult{VersionId: aws.String("0123")}
+	result.Key = aws.String("key")
+	encoded := string(s3err.EncodeXMLResponse(result))
+	assert.Contains(t, encoded, "<Key>key</Key>")
+	assert.NotContains(t, encoded, "VersionId")
+}
+
+type lockTestFiler struct {
+	filer_pb.UnimplementedSeaweedFilerServer
+	locks *lock_manager.LockManager
+}
+
+func (f *lockTestFiler) DistributedLock(ctx context.Context, req *filer_pb.LockRequest) (*filer_pb.LockResponse, error) {
+	resp := &filer_pb.LockResponse{}
+	expiredAtNs := time.Now().Add(time.Duration(req.SecondsToLock) * time.Second).UnixNano()
+	var err error
+	if resp.LockOwner, resp.RenewToken, err = f.locks.Lock(req.Name, expiredAtNs, req.RenewToken, req.Owner); err != nil {
+		resp.Error = err.Error()
+	}
+	return resp, nil
+}
+
+func (f *lockTestFiler) DistributedUnlock(ctx context.Context, req *filer_pb.UnlockRequest) (*filer_pb.UnlockResponse, error) {
+	resp := &filer_pb.UnlockResponse{}
+	if _, err := f.locks.Unlock(req.Name, req.RenewToken); err != nil {
+		resp.Error = err.Error()
+	}
+	return resp, nil
+}
+
+func TestLockObject(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	grpcServer := grpc.NewServer()
+	filer_pb.RegisterSeaweedFilerServer(grpcServer, &lockTestFiler{locks: lock_manager.NewLockManager()})
+	go grpcServer.Serve(listener)
+	defer grpcServer.Stop()
+
+	filerAddress := pb.NewServerAddressWithGrpcPort("127.0.0.1:8888", listener.Addr().(*net.TCPAddr).Port)
+	s3a := &S3ApiServer{
+		option:      &S3ApiServerOption{BucketsPath: "/buckets"},
+		lockClient:  cluster.NewLockClient(grpc.WithTransportCredentials(insecure.NewCredentials()), filerAddress),
+		objectLocks: util.NewLockTable[string](),
+	}
+	s3a.bucketRegistry = &BucketRegistry{
+		metadataCache: map[string]*BucketMetaData{
+			"versioned": {Name: "versioned", Versioning: s3_constants.VersioningEnabled},
+			"plain":     {Name: "plain"},
+		},
+		notFound: make(map[string]struct{}),
+		s3a:      s3a,
+	}
+
+	// a concurrent write and delete of the same object take turns
+	var holders atomic.Int32
+	var overlapped atomic.Bool
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := s3a.lockObject("versioned", "/obj")
+			defer unlock()
+			if holders.Add(1) > 1 {
+				overlapped.Store(true)
+			}
+			time.Sleep(100 * time.Millisecond)
+			holders.Add(-1)
+		}()
+	}
+	wg.Wait()
+	assert.False(t, overlapped.Load(), "the object lock should be held by one request at a time")
+
+	// the objects without versioning are not locked
+	unlock := s3a.lockObject("plain", "/obj")
+	unlock2 := s3a.lockObject("plain", "/obj")
+	unlock()
+	unlock2()
+}
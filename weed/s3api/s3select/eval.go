@@ -0,0 +1,669 @@
+package s3select
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+)
+
+// The values are nil for NULL, missing for the absent fields, string, int64, float64, bool,
+// and *jsonObject or []interface{} for the nested json values.
+type missingValue struct{}
+
+var missing = missingValue{}
+
+func isNull(v interface{}) bool {
+	return v == nil || v == missing
+}
+
+type expr interface {
+	eval(r record) (interface{}, error)
+}
+
+type literal struct {
+	value interface{}
+}
+
+func (l *literal) eval(r record) (interface{}, error) {
+	return l.value, nil
+}
+
+type pathElement struct {
+	name   string
+	quoted bool
+	index  int // array index if name is empty
+}
+
+// columnRef is a column of the csv record, or a path in the json record, optionally prefixed by the table alias
+type columnRef struct {
+	path  []pathElement
+	alias string
+}
+
+// name is the last name in the path, or "" for an array element
+func (c *columnRef) name() string {
+	return c.path[len(c.path)-1].name
+}
+
+func (c *columnRef) eval(r record) (interface{}, error) {
+	if r == nil {
+		return missing, nil
+	}
+	path := c.path
+	if len(path) > 1 && c.alias != "" && path[0].name != "" &&
+		(path[0].name == c.alias || !path[0].quoted && strings.EqualFold(path[0].name, c.alias)) {
+		path = path[1:]
+	}
+	value := r.field(path[0].name, path[0].quoted)
+	for _, element := range path[1:] {
+		switch v := value.(type) {
+		case *jsonObject:
+			if element.name == "" {
+				return missing, nil
+			}
+			value = v.field(element.name, element.quoted)
+		case []interface{}:
+			if element.name != "" || element.index >= len(v) {
+				return missing, nil
+			}
+			value = v[element.index]
+		default:
+			return missing, nil
+		}
+	}
+	return value, nil
+}
+
+type notExpr struct {
+	x expr
+}
+
+func (n *notExpr) eval(r record) (interface{}, error) {
+	v, err := n.x.eval(r)
+	if b, ok := v.(bool); ok {
+		return !b, err
+	}
+	return nil, err
+}
+
+type logicalExpr struct {
+	and         bool
+	left, right expr
+}
+
+func (l *logicalExpr) eval(r record) (interface{}, error) {
+	left, err := l.left.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	// the short circuit of false AND x, and true OR x
+	if b, ok := left.(bool); ok && b != l.and {
+		return b, nil
+	}
+	right, err := l.right.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	lb, lok := left.(bool)
+	rb, rok := right.(bool)
+	switch {
+	case rok && rb != l.and:
+		return rb, nil
+	case lok && rok:
+		return lb, nil
+	}
+	return nil, nil
+}
+
+type compareExpr struct {
+	op          string
+	left, right expr
+}
+
+func (c *compareExpr) eval(r record) (interface{}, error) {
+	left, err := c.left.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	right, err := c.right.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	if isNull(left) || isNull(right) {
+		return nil, nil
+	}
+	result, ok := compareValues(left, right)
+	if !ok {
+		return nil, nil
+	}
+	switch c.op {
+	case "=":
+		return result == 0, nil
+	case "!=", "<>":
+		return result != 0, nil
+	case "<":
+		return result < 0, nil
+	case "<=":
+		return result <= 0, nil
+	case ">":
+		return result > 0, nil
+	case ">=":
+		return result >= 0, nil
+	}
+	return nil, nil
+}
+
+// compareValues compares the numbers, the strings, or the booleans.
+// A string is compared with a number as a number, if it is one.
+func compareValues(a, b interface{}) (int, bool) {
+	_, aString := a.(string)
+	_, bString := b.(string)
+	if aString && bString {
+		return strings.Compare(a.(string), b.(string)), true
+	}
+	if an, ok := toNumber(a); ok && (!aString || isNumber(b)) {
+		if bn, ok := toNumber(b); ok && (!bString || isNumber(a)) {
+			return compareNumbers(an, bn), true
+		}
+		return 0, false
+	}
+	if ab, ok := a.(bool); ok {
+		if bb, ok := b.(bool); ok {
+			switch {
+			case ab == bb:
+				return 0, true
+			case bb:
+				return -1, true
+			}
+			return 1, true
+		}
+	}
+	return 0, false
+}
+
+func isNumber(v interface{}) bool {
+	switch v.(type) {
+	case int64, float64:
+		return true
+	}
+	return false
+}
+
+// toNumber returns the int64 or float64 value, parsing the strings.
+func toNumber(v interface{}) (interface{}, bool) {
+	switch n := v.(type) {
+	case int64, float64:
+		return n, true
+	case string:
+		if parsed := parseNumber(strings.TrimSpace(n)); parsed != nil {
+			return parsed, true
+		}
+	}
+	return nil, false
+}
+
+func parseNumber(s string) interface{} {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	return nil
+}
+
+func toFloat(n interface{}) float64 {
+	if i, ok := n.(int64); ok {
+		return float64(i)
+	}
+	return n.(float64)
+}
+
+func compareNumbers(a, b interface{}) int {
+	ai, aInt := a.(int64)
+	bi, bInt := b.(int64)
+	if aInt && bInt {
+		switch {
+		case ai < bi:
+			return -1
+		case ai > bi:
+			return 1
+		}
+		return 0
+	}
+	af, bf := toFloat(a), toFloat(b)
+	switch {
+	case af < bf:
+		return -1
+	case af > bf:
+		return 1
+	}
+	return 0
+}
+
+type arithmeticExpr struct {
+	op          string
+	left, right expr
+}
+
+func (a *arithmeticExpr) eval(r record) (interface{}, error) {
+	left, err := a.left.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	right, err := a.right.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	if isNull(left) || isNull(right) {
+		return nil, nil
+	}
+	if a.op == "||" {
+		return formatValue(left) + formatValue(right), nil
+	}
+	ln, err := mustNumber(left)
+	if err != nil {
+		return nil, err
+	}
+	rn, err := mustNumber(right)
+	if err != nil {
+		return nil, err
+	}
+	li, lInt := ln.(int64)
+	ri, rInt := rn.(int64)
+	if lInt && rInt {
+		switch a.op {
+		case "+":
+			return li + ri, nil
+		case "-":
+			return li - ri, nil
+		case "*":
+			return li * ri, nil
+		}
+		if ri == 0 {
+			return nil, nil
+		}
+		if a.op == "/" {
+			return li / ri, nil
+		}
+		return li % ri, nil
+	}
+	lf, rf := toFloat(ln), toFloat(rn)
+	switch a.op {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	}
+	if rf == 0 {
+		return nil, nil
+	}
+	if a.op == "/" {
+		return lf / rf, nil
+	}
+	return math.Mod(lf, rf), nil
+}
+
+func mustNumber(v interface{}) (interface{}, error) {
+	if n, ok := toNumber(v); ok {
+		return n, nil
+	}
+	return nil, newError(s3err.ErrCastFailed, "%s is not a number", formatValue(v))
+}
+
+type negativeExpr struct {
+	x expr
+}
+
+func (n *negativeExpr) eval(r record) (interface{}, error) {
+	v, err := n.x.eval(r)
+	if err != nil || isNull(v) {
+		return nil, err
+	}
+	number, err := mustNumber(v)
+	if err != nil {
+		return nil, err
+	}
+	if i, ok := number.(int64); ok {
+		return -i, nil
+	}
+	return -number.(float64), nil
+}
+
+type isNullExpr struct {
+	x           expr
+	not         bool
+	missingOnly bool
+}
+
+func (i *isNullExpr) eval(r record) (interface{}, error) {
+	v, err := i.x.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	result := isNull(v)
+	if i.missingOnly {
+		result = v == missing
+	}
+	return result != i.not, nil
+}
+
+type likeExpr struct {
+	x, pattern, escape expr
+	not                bool
+}
+
+func (l *likeExpr) eval(r record) (interface{}, error) {
+	v, err := l.x.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := l.pattern.eval(r)
+	if err != nil {
+		return nil, err
+	}
+	if isNull(v) || isNull(pattern) {
+		return nil, nil
+	}
+	var escape rune
+	if l.escape != nil {
+		e, err := l.escape.eval(r)
+		if err != nil {
+			return nil, err
+		}
+		if s, ok := e.(string); !ok || utf8.RuneCountInString(s) != 1 {
+			return nil, newError(s3err.ErrInvalidRequestParameter, "LIKE escape must be a single character")
+		} else {
+			escape, _ = utf8.DecodeRuneInString(s)
+		}
+	}
+	return matchLike([]rune(formatValue(v)), []rune(formatValue(pattern)), escape) != l.not, nil
+}
+
+// matchLike matches % with any characters, and _ with one character
+func matchLike(s, pattern []rune, escape rune) bool {
+	var si, pi int
+	starPattern, starString := -1, 0
+	for si < len(s) {
+		if pi < len(pattern) {
+			c := pattern[pi]
+			switch {
+			case escape != 0 && c == escape && pi+1 < len(pattern):
+				if pattern[pi+1] == s[si] {
+					si, pi = si+1, pi+2
+					continue
+				}
+			case c == '%':
+				starPattern, starString = pi, si
+				pi++
+				continue
+			case c == '_' || c == s[si]:
+				si, pi = si+1, pi+1
+				continue
+			}
+		}
+		if starPattern < 0 {
+			return false
+		}
+		starString++
+		si, pi = starString, starPattern+1
+	}
+	for pi < len(pattern) && pattern[pi] == '%' {
+		pi++
+	}
+	return pi == len(pattern)
+}
+
+type betweenExpr struct {
+	x, low, high expr
+	not          bool
+}
+
+func (b *betweenExpr) eval(r record) (interface{}, error) {
+	low, err := (&compareExpr{op: ">=", left: b.x, right: b.low}).eval(r)
+	if err != nil {
+		return nil, err
+	}
+	high, err := (&compareExpr{op: "<=", left: b.x, right: b.high}).eval(r)
+	if err != nil {
+		return nil, err
+	}
+	if low == nil || high == nil {
+		return nil, nil
+	}
+	return (low.(bool) && high.(bool)) != b.not, nil
+}
+
+type inExpr struct {
+	x    expr
+	list []expr
+	not  bool
+}
+
+func (i *inExpr) eval(r record) (interface{}, error) {
+	var unknown bool
+	for _, element := range i.list {
+		equal, err := (&compareExpr{op: "=", left: i.x, right: element}).eval(r)
+		if err != nil {
+			return nil, err
+		}
+		if equal == true {
+			return !i.not, nil
+		}
+		unknown = unknown || equal == nil
+	}
+	if unknown {
+		return nil, nil
+	}
+	return i.not, nil
+}
+
+type castExpr struct {
+	x        expr
+	typeName string
+}
+
+func (c *castExpr) eval(r record) (interface{}, error) {
+	v, err := c.x.eval(r)
+	if err != nil || isNull(v) {
+		return v, err
+	}
+	switch c.typeName {
+	case "STRING":
+		return formatValue(v), nil
+	case "INT":
+		switch n := v.(type) {
+		case bool:
+			if n {
+				return int64(1), nil
+			}
+			return int64(0), nil
+		case float64:
+			return int64(n), nil
+		}
+		n, ok := toNumber(v)
+		if !ok {
+			break
+		}
+		if f, isFloat := n.(float64); isFloat {
+			return int64(f), nil
+		}
+		return n, nil
+	case "FLOAT":
+		if n, ok := toNumber(v); ok {
+			return toFloat(n), nil
+		}
+	case "BOOL":
+		switch b := v.(type) {
+		case bool:
+			return b, nil
+		case string:
+			if parsed, err := strconv.ParseBool(strings.TrimSpace(b)); err == nil {
+				return parsed, nil
+			}
+		case int64, float64:
+			return toFloat(b) != 0, nil
+		}
+	}
+	return nil, newError(s3err.ErrCastFailed, "cannot cast %s to %s", formatValue(v), c.typeName)
+}
+
+type functionExpr struct {
+	name string
+	args []expr
+}
+
+func (f *functionExpr) eval(r record) (interface{}, error) {
+	args := make([]interface{}, len(f.args))
+	for i, arg := range f.args {
+		v, err := arg.eval(r)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	switch f.name {
+	case "COALESCE":
+		for _, arg := range args {
+			if !isNull(arg) {
+				return arg, nil
+			}
+		}
+		return nil, nil
+	case "NULLIF":
+		if result, ok := compareValues(args[0], args[1]); ok && result == 0 {
+			return nil, nil
+		}
+		return args[0], nil
+	}
+	if isNull(args[0]) {
+		return nil, nil
+	}
+	s := formatValue(args[0])
+	switch f.name {
+	case "LOWER":
+		return strings.ToLower(s), nil
+	case "UPPER":
+		return strings.ToUpper(s), nil
+	case "TRIM":
+		return strings.TrimSpace(s), nil
+	case "CHAR_LENGTH", "CHARACTER_LENGTH":
+		return int64(utf8.RuneCountInString(s)), nil
+	case "SUBSTRING":
+		return substring(s, args[1:])
+	}
+	return nil, newError(s3err.ErrUnsupportedSqlOperation, "unsupported function %s", f.name)
+}
+
+// substring returns the characters from the 1-based start, with the optional length
+func substring(s string, args []interface{}) (interface{}, error) {
+	runes := []rune(s)
+	var bounds [2]int64
+	for i, arg := range args {
+		if isNull(arg) {
+			return nil, nil
+		}
+		n, err := mustNumber(arg)
+		if err != nil {
+			return nil, err
+		}
+		bounds[i] = int64(toFloat(n))
+	}
+	start, end := bounds[0]-1, int64(len(runes))
+	if len(args) > 1 {
+		end = start + bounds[1]
+	}
+	start = max(0, min(start, int64(len(runes))))
+	end = max(start, min(end, int64(len(runes))))
+	return string(runes[start:end]), nil
+}
+
+// aggregateExpr accumulates the selected records, and evaluates to the result after all the records
+type aggregateExpr struct {
+	name     string
+	arg      expr // nil for COUNT(*)
+	count    int64
+	sumInt   int64
+	sumFloat float64
+	isFloat  bool
+	extreme  interface{}
+}
+
+func (a *aggregateExpr) accumulate(r record) error {
+	if a.arg == nil {
+		a.count++
+		return nil
+	}
+	v, err := a.arg.eval(r)
+	if err != nil || isNull(v) {
+		return err
+	}
+	switch a.name {
+	case "SUM", "AVG":
+		n, err := mustNumber(v)
+		if err != nil {
+			return err
+		}
+		if i, ok := n.(int64); ok && !a.isFloat {
+			a.sumInt += i
+		} else {
+			if !a.isFloat {
+				a.sumFloat, a.isFloat = float64(a.sumInt), true
+			}
+			a.sumFloat += toFloat(n)
+		}
+	case "MIN", "MAX":
+		if a.extreme == nil {
+			a.extreme = v
+		} else if result, ok := compareValues(v, a.extreme); ok && (result < 0) == (a.name == "MIN") && result != 0 {
+			a.extreme = v
+		}
+	}
+	a.count++
+	return nil
+}
+
+func (a *aggregateExpr) eval(r record) (interface{}, error) {
+	switch a.name {
+	case "COUNT":
+		return a.count, nil
+	case "MIN", "MAX":
+		return a.extreme, nil
+	}
+	if a.count == 0 {
+		return nil, nil
+	}
+	sum := interface{}(a.sumInt)
+	if a.isFloat {
+		sum = a.sumFloat
+	}
+	if a.name == "AVG" {
+		return toFloat(sum) / float64(a.count), nil
+	}
+	return sum, nil
+}
+
+// formatValue formats the value as a csv field
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil, missingValue:
+		return ""
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	var buf bytes.Buffer
+	writeJSONValue(&buf, value)
+	return buf.String()
+}
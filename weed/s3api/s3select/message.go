@@ -0,0 +1,104 @@
+package s3select
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"hash/crc32"
+	"io"
+	"net/http"
+)
+
+// the string type of the event stream header values
+const headerValueTypeString = 7
+
+type eventHeader struct {
+	name  string
+	value string
+}
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/RESTSelectObjectAppendix.html
+// A message is the total length, the headers length, the prelude crc, the headers, the payload, and the message crc.
+func encodeMessage(headers []eventHeader, payload []byte) []byte {
+	var encodedHeaders bytes.Buffer
+	for _, header := range headers {
+		encodedHeaders.WriteByte(byte(len(header.name)))
+		encodedHeaders.WriteString(header.name)
+		encodedHeaders.WriteByte(headerValueTypeString)
+		encodedHeaders.Write(binary.BigEndian.AppendUint16(nil, uint16(len(header.value))))
+		encodedHeaders.WriteString(header.value)
+	}
+	totalLength := 12 + encodedHeaders.Len() + len(payload) + 4
+	message := make([]byte, 0, totalLength)
+	message = binary.BigEndian.AppendUint32(message, uint32(totalLength))
+	message = binary.BigEndian.AppendUint32(message, uint32(encodedHeaders.Len()))
+	message = binary.BigEndian.AppendUint32(message, crc32.ChecksumIEEE(message))
+	message = append(message, encodedHeaders.Bytes()...)
+	message = append(message, payload...)
+	return binary.BigEndian.AppendUint32(message, crc32.ChecksumIEEE(message))
+}
+
+type stats struct {
+	XMLName        xml.Name `xml:"Stats"`
+	BytesScanned   int64    `xml:"BytesScanned"`
+	BytesProcessed int64    `xml:"BytesProcessed"`
+	BytesReturned  int64    `xml:"BytesReturned"`
+}
+
+// eventWriter writes the events to the response, and keeps the first write error
+type eventWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *eventWriter) write(headers []eventHeader, payload []byte) error {
+	if e.err != nil {
+		return e.err
+	}
+	if _, e.err = e.w.Write(encodeMessage(headers, payload)); e.err != nil {
+		return e.err
+	}
+	if flusher, ok := e.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
+
+func (e *eventWriter) writeRecords(payload []byte) error {
+	return e.write([]eventHeader{
+		{":event-type", "Records"},
+		{":content-type", "application/octet-stream"},
+		{":message-type", "event"},
+	}, payload)
+}
+
+func (e *eventWriter) writeStats(bytesScanned, bytesProcessed, bytesReturned int64) error {
+	payload, err := xml.Marshal(&stats{
+		BytesScanned:   bytesScanned,
+		BytesProcessed: bytesProcessed,
+		BytesReturned:  bytesReturned,
+	})
+	if err != nil {
+		return err
+	}
+	return e.write([]eventHeader{
+		{":event-type", "Stats"},
+		{":content-type", "text/xml"},
+		{":message-type", "event"},
+	}, append([]byte(xml.Header), payload...))
+}
+
+func (e *eventWriter) writeEnd() error {
+	return e.write([]eventHeader{
+		{":event-type", "End"},
+		{":message-type", "event"},
+	}, nil)
+}
+
+func (e *eventWriter) writeError(code, message string) error {
+	return e.write([]eventHeader{
+		{":error-code", code},
+		{":error-message", message},
+		{":message-type", "error"},
+	}, nil)
+}
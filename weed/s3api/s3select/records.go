@@ -0,0 +1,390 @@
+package s3select
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+)
+
+// record is one row of the object content
+type record interface {
+	// field returns the value by the column name or the json key, or missing.
+	// Unquoted names are case insensitive.
+	field(name string, quoted bool) interface{}
+	// fields returns all the named values, for "SELECT *"
+	fields() []namedValue
+}
+
+type namedValue struct {
+	name  string
+	value interface{}
+}
+
+type recordReader interface {
+	next() (record, error)
+}
+
+func newRecordReader(r io.Reader, input *InputSerialization) recordReader {
+	if input.JSON != nil {
+		decoder := json.NewDecoder(r)
+		decoder.UseNumber()
+		return &jsonRecordReader{decoder: decoder}
+	}
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	if input.CSV.Comments != nil {
+		reader.Comment, _ = utf8.DecodeRuneInString(*input.CSV.Comments)
+		if reader.Comment == utf8.RuneError {
+			reader.Comment = 0
+		}
+	}
+	if input.CSV.FieldDelimiter != "" {
+		reader.Comma, _ = utf8.DecodeRuneInString(input.CSV.FieldDelimiter)
+	}
+	if reader.Comment == reader.Comma {
+		reader.Comment = 0
+	}
+	return &csvRecordReader{
+		reader:         reader,
+		fileHeaderInfo: strings.ToUpper(input.CSV.FileHeaderInfo),
+	}
+}
+
+// readError reports the malformed content with the parsing error code, and the broken compressed content as such
+func readError(err error, parsingErrorCode s3err.ErrorCode) error {
+	var parseError *csv.ParseError
+	var syntaxError *json.SyntaxError
+	switch {
+	case errors.As(err, &parseError), errors.As(err, &syntaxError), errors.Is(err, io.ErrUnexpectedEOF):
+		return newError(parsingErrorCode, "%v", err)
+	case errors.Is(err, gzip.ErrChecksum), errors.Is(err, gzip.ErrHeader), strings.HasPrefix(err.Error(), "bzip2 data invalid"):
+		return newError(s3err.ErrInvalidCompressionFormat, "%v", err)
+	}
+	return err
+}
+
+type csvRecordReader struct {
+	reader         *csv.Reader
+	fileHeaderInfo string
+	header         *csvHeader
+	headerRead     bool
+}
+
+type csvHeader struct {
+	names     []string
+	index     map[string]int
+	lowerCase map[string]int
+}
+
+func (c *csvRecordReader) next() (record, error) {
+	values, err := c.reader.Read()
+	if err == io.EOF {
+		return nil, err
+	}
+	if err != nil {
+		return nil, readError(err, s3err.ErrCSVParsingError)
+	}
+	if !c.headerRead && (c.fileHeaderInfo == "USE" || c.fileHeaderInfo == "IGNORE") {
+		c.headerRead = true
+		if c.fileHeaderInfo == "USE" {
+			c.header = newCSVHeader(values)
+		}
+		return c.next()
+	}
+	return &csvRecord{values: values, header: c.header}, nil
+}
+
+func newCSVHeader(names []string) *csvHeader {
+	header := &csvHeader{
+		names:     names,
+		index:     make(map[string]int),
+		lowerCase: make(map[string]int),
+	}
+	for i := len(names) - 1; i >= 0; i-- {
+		header.index[names[i]] = i
+		header.lowerCase[strings.ToLower(names[i])] = i
+	}
+	return header
+}
+
+type csvRecord struct {
+	values []string
+	header *csvHeader
+}
+
+func (c *csvRecord) field(name string, quoted bool) interface{} {
+	if c.header != nil {
+		i, found := c.header.index[name]
+		if !found && !quoted {
+			i, found = c.header.lowerCase[strings.ToLower(name)]
+		}
+		if found {
+			return c.column(i)
+		}
+	}
+	// the positional columns are named _1, _2, ...
+	if position, found := strings.CutPrefix(name, "_"); found {
+		if i, err := strconv.Atoi(position); err == nil && i > 0 {
+			return c.column(i - 1)
+		}
+	}
+	return missing
+}
+
+func (c *csvRecord) column(i int) interface{} {
+	if i < len(c.values) {
+		return c.values[i]
+	}
+	return missing
+}
+
+func (c *csvRecord) fields() []namedValue {
+	fields := make([]namedValue, len(c.values))
+	for i, value := range c.values {
+		fields[i] = namedValue{name: "_" + strconv.Itoa(i+1), value: value}
+		if c.header != nil && i < len(c.header.names) {
+			fields[i].name = c.header.names[i]
+		}
+	}
+	return fields
+}
+
+type jsonRecordReader struct {
+	decoder *json.Decoder
+}
+
+func (j *jsonRecordReader) next() (record, error) {
+	value, err := decodeJSONValue(j.decoder)
+	if err == io.EOF {
+		return nil, err
+	}
+	if err != nil {
+		return nil, readError(err, s3err.ErrJSONParsingError)
+	}
+	if object, ok := value.(*jsonObject); ok {
+		return object, nil
+	}
+	return &jsonObject{keys: []string{"_1"}, values: map[string]interface{}{"_1": value}}, nil
+}
+
+// jsonObject keeps the keys in the order of the input
+type jsonObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (o *jsonObject) field(name string, quoted bool) interface{} {
+	if value, found := o.values[name]; found {
+		return value
+	}
+	if !quoted {
+		for _, key := range o.keys {
+			if strings.EqualFold(key, name) {
+				return o.values[key]
+			}
+		}
+	}
+	return missing
+}
+
+func (o *jsonObject) fields() []namedValue {
+	fields := make([]namedValue, len(o.keys))
+	for i, key := range o.keys {
+		fields[i] = namedValue{name: key, value: o.values[key]}
+	}
+	return fields
+}
+
+func decodeJSONValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := token.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			object := &jsonObject{values: make(map[string]interface{})}
+			for decoder.More() {
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return nil, unexpectedEOF(err)
+				}
+				key, _ := keyToken.(string)
+				value, err := decodeJSONValue(decoder)
+				if err != nil {
+					return nil, unexpectedEOF(err)
+				}
+				if _, found := object.values[key]; !found {
+					object.keys = append(object.keys, key)
+				}
+				object.values[key] = value
+			}
+			if _, err = decoder.Token(); err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			return object, nil
+		case '[':
+			array := []interface{}{}
+			for decoder.More() {
+				value, err := decodeJSONValue(decoder)
+				if err != nil {
+					return nil, unexpectedEOF(err)
+				}
+				array = append(array, value)
+			}
+			if _, err = decoder.Token(); err != nil {
+				return nil, unexpectedEOF(err)
+			}
+			return array, nil
+		}
+	case json.Number:
+		return parseNumber(string(t)), nil
+	}
+	return token, nil
+}
+
+// unexpectedEOF reports the content ending inside a value as malformed
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+type recordWriter interface {
+	write(buf *bytes.Buffer, values []namedValue)
+}
+
+func newRecordWriter(output *OutputSerialization) (recordWriter, error) {
+	if (output.CSV == nil) == (output.JSON == nil) {
+		return nil, newError(s3err.ErrInvalidRequestParameter, "exactly one of CSV and JSON output is required")
+	}
+	if output.JSON != nil {
+		return &jsonRecordWriter{recordDelimiter: withDefault(output.JSON.RecordDelimiter, "\n")}, nil
+	}
+	writer := &csvRecordWriter{
+		fieldDelimiter:  withDefault(output.CSV.FieldDelimiter, ","),
+		recordDelimiter: withDefault(output.CSV.RecordDelimiter, "\n"),
+		quote:           withDefault(output.CSV.QuoteCharacter, `"`),
+		quoteEscape:     withDefault(output.CSV.QuoteEscapeCharacter, `"`),
+	}
+	switch strings.ToUpper(output.CSV.QuoteFields) {
+	case "", "ASNEEDED":
+	case "ALWAYS":
+		writer.quoteAlways = true
+	default:
+		return nil, newError(s3err.ErrInvalidRequestParameter, "invalid QuoteFields %q", output.CSV.QuoteFields)
+	}
+	return writer, nil
+}
+
+func withDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+type csvRecordWriter struct {
+	fieldDelimiter  string
+	recordDelimiter string
+	quote           string
+	quoteEscape     string
+	quoteAlways     bool
+}
+
+func (c *csvRecordWriter) write(buf *bytes.Buffer, values []namedValue) {
+	for i, v := range values {
+		if i > 0 {
+			buf.WriteString(c.fieldDelimiter)
+		}
+		s := formatValue(v.value)
+		if c.quoteAlways || strings.Contains(s, c.fieldDelimiter) || strings.Contains(s, c.quote) ||
+			strings.Contains(s, c.recordDelimiter) || strings.ContainsAny(s, "\r\n") {
+			buf.WriteString(c.quote)
+			buf.WriteString(strings.ReplaceAll(s, c.quote, c.quoteEscape+c.quote))
+			buf.WriteString(c.quote)
+		} else {
+			buf.WriteString(s)
+		}
+	}
+	buf.WriteString(c.recordDelimiter)
+}
+
+type jsonRecordWriter struct {
+	recordDelimiter string
+}
+
+func (j *jsonRecordWriter) write(buf *bytes.Buffer, values []namedValue) {
+	buf.WriteByte('{')
+	first := true
+	for _, v := range values {
+		if v.value == missing {
+			continue
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		writeJSONString(buf, v.name)
+		buf.WriteByte(':')
+		writeJSONValue(buf, v.value)
+	}
+	buf.WriteByte('}')
+	buf.WriteString(j.recordDelimiter)
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	encoded, _ := json.Marshal(s)
+	buf.Write(encoded)
+}
+
+func writeJSONValue(buf *bytes.Buffer, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		writeJSONString(buf, v)
+	case int64:
+		buf.WriteString(strconv.FormatInt(v, 10))
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			buf.WriteString("null")
+		} else {
+			buf.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+		}
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case *jsonObject:
+		buf.WriteByte('{')
+		for i, key := range v.keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSONString(buf, key)
+			buf.WriteByte(':')
+			writeJSONValue(buf, v.values[key])
+		}
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, element := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeJSONValue(buf, element)
+		}
+		buf.WriteByte(']')
+	default:
+		buf.WriteString("null")
+	}
+}
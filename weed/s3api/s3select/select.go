@@ -0,0 +1,267 @@
+package s3select
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+)
+
+// recordsPayloadSize is the size of the records buffered before sending a Records event
+const recordsPayloadSize = 128 * 1024
+
+// https://docs.aws.amazon.com/AmazonS3/latest/API/API_SelectObjectContent.html
+type SelectObjectContentRequest struct {
+	XMLName             xml.Name            `xml:"SelectObjectContentRequest"`
+	Expression          string              `xml:"Expression"`
+	ExpressionType      string              `xml:"ExpressionType"`
+	InputSerialization  InputSerialization  `xml:"InputSerialization"`
+	OutputSerialization OutputSerialization `xml:"OutputSerialization"`
+	ScanRange           *struct{}           `xml:"ScanRange"`
+}
+
+type InputSerialization struct {
+	CompressionType string     `xml:"CompressionType"`
+	CSV             *CSVInput  `xml:"CSV"`
+	JSON            *JSONInput `xml:"JSON"`
+	Parquet         *struct{}  `xml:"Parquet"`
+}
+
+type CSVInput struct {
+	FileHeaderInfo       string  `xml:"FileHeaderInfo"`
+	Comments             *string `xml:"Comments"`
+	QuoteEscapeCharacter string  `xml:"QuoteEscapeCharacter"`
+	RecordDelimiter      string  `xml:"RecordDelimiter"`
+	FieldDelimiter       string  `xml:"FieldDelimiter"`
+	QuoteCharacter       string  `xml:"QuoteCharacter"`
+}
+
+type JSONInput struct {
+	Type string `xml:"Type"`
+}
+
+type OutputSerialization struct {
+	CSV  *CSVOutput  `xml:"CSV"`
+	JSON *JSONOutput `xml:"JSON"`
+}
+
+type CSVOutput struct {
+	QuoteFields          string `xml:"QuoteFields"`
+	QuoteEscapeCharacter string `xml:"QuoteEscapeCharacter"`
+	RecordDelimiter      string `xml:"RecordDelimiter"`
+	FieldDelimiter       string `xml:"FieldDelimiter"`
+	QuoteCharacter       string `xml:"QuoteCharacter"`
+}
+
+type JSONOutput struct {
+	RecordDelimiter string `xml:"RecordDelimiter"`
+}
+
+// Error is a failed select request, with the S3 error code to report.
+type Error struct {
+	Code    s3err.ErrorCode
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func newError(code s3err.ErrorCode, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// ErrorCode returns the S3 error code of a select error.
+func ErrorCode(err error) s3err.ErrorCode {
+	var selectErr *Error
+	if errors.As(err, &selectErr) {
+		return selectErr.Code
+	}
+	return s3err.ErrInternalError
+}
+
+// Select is a parsed SelectObjectContent request, to run once on the object content.
+type Select struct {
+	request *SelectObjectContentRequest
+	query   *query
+	output  recordWriter
+}
+
+// Parse parses and validates the SelectObjectContent request body.
+func Parse(body []byte) (*Select, error) {
+	request := &SelectObjectContentRequest{}
+	if err := xml.Unmarshal(body, request); err != nil {
+		return nil, newError(s3err.ErrMalformedXML, "parse select request: %v", err)
+	}
+	if request.ExpressionType != "SQL" {
+		return nil, newError(s3err.ErrInvalidExpressionType, "unsupported expression type %q", request.ExpressionType)
+	}
+	if request.ScanRange != nil {
+		return nil, newError(s3err.ErrNotImplemented, "ScanRange is not supported")
+	}
+	if err := validateInput(&request.InputSerialization); err != nil {
+		return nil, err
+	}
+	output, err := newRecordWriter(&request.OutputSerialization)
+	if err != nil {
+		return nil, err
+	}
+	q, err := parseQuery(request.Expression)
+	if err != nil {
+		return nil, err
+	}
+	return &Select{request: request, query: q, output: output}, nil
+}
+
+func validateInput(input *InputSerialization) error {
+	switch strings.ToUpper(input.CompressionType) {
+	case "", "NONE", "GZIP", "BZIP2":
+	default:
+		return newError(s3err.ErrInvalidCompressionFormat, "unsupported compression type %q", input.CompressionType)
+	}
+	if input.Parquet != nil {
+		return newError(s3err.ErrNotImplemented, "Parquet input is not supported")
+	}
+	if (input.CSV == nil) == (input.JSON == nil) {
+		return newError(s3err.ErrInvalidRequestParameter, "exactly one of CSV and JSON input is required")
+	}
+	if input.JSON != nil {
+		switch strings.ToUpper(input.JSON.Type) {
+		case "", "DOCUMENT", "LINES":
+			return nil
+		}
+		return newError(s3err.ErrInvalidRequestParameter, "invalid JSON type %q", input.JSON.Type)
+	}
+	csvInput := input.CSV
+	switch strings.ToUpper(csvInput.FileHeaderInfo) {
+	case "", "NONE", "USE", "IGNORE":
+	default:
+		return newError(s3err.ErrInvalidRequestParameter, "invalid FileHeaderInfo %q", csvInput.FileHeaderInfo)
+	}
+	if csvInput.FieldDelimiter != "" && (utf8.RuneCountInString(csvInput.FieldDelimiter) != 1 || strings.ContainsAny(csvInput.FieldDelimiter, "\"\r\n")) {
+		return newError(s3err.ErrInvalidRequestParameter, "invalid FieldDelimiter %q", csvInput.FieldDelimiter)
+	}
+	if csvInput.Comments != nil && utf8.RuneCountInString(*csvInput.Comments) > 1 {
+		return newError(s3err.ErrInvalidRequestParameter, "invalid Comments %q", *csvInput.Comments)
+	}
+	// the csv reader only supports the standard quoting, and splits the records by "\n" or "\r\n"
+	if csvInput.QuoteCharacter != "" && csvInput.QuoteCharacter != `"` {
+		return newError(s3err.ErrNotImplemented, "unsupported QuoteCharacter %q", csvInput.QuoteCharacter)
+	}
+	if csvInput.QuoteEscapeCharacter != "" && csvInput.QuoteEscapeCharacter != `"` {
+		return newError(s3err.ErrNotImplemented, "unsupported QuoteEscapeCharacter %q", csvInput.QuoteEscapeCharacter)
+	}
+	if csvInput.RecordDelimiter != "" && csvInput.RecordDelimiter != "\n" && csvInput.RecordDelimiter != "\r\n" {
+		return newError(s3err.ErrNotImplemented, "unsupported RecordDelimiter %q", csvInput.RecordDelimiter)
+	}
+	return nil
+}
+
+// Run selects the records from the object content, and writes the results to w as an event stream.
+// Once started, the errors are reported to the client with an error event, and returned for logging.
+func (s *Select) Run(object io.Reader, w io.Writer) error {
+	events := &eventWriter{w: w}
+	err := s.run(object, events)
+	if err != nil && events.err == nil {
+		events.writeError(s3err.GetAPIError(ErrorCode(err)).Code, err.Error())
+	}
+	return err
+}
+
+func (s *Select) run(object io.Reader, events *eventWriter) error {
+	scanned := &countingReader{r: object}
+	decompressed, err := decompress(scanned, s.request.InputSerialization.CompressionType)
+	if err != nil {
+		return err
+	}
+	processed := &countingReader{r: decompressed}
+	records := newRecordReader(processed, &s.request.InputSerialization)
+
+	var buf bytes.Buffer
+	var returned, selected int64
+	q := s.query
+	for q.limit < 0 || len(q.aggregates) > 0 || selected < q.limit {
+		r, err := records.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		matched, err := q.matches(r)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			continue
+		}
+		if len(q.aggregates) > 0 {
+			if err = q.accumulate(r); err != nil {
+				return err
+			}
+			continue
+		}
+		values, err := q.project(r)
+		if err != nil {
+			return err
+		}
+		s.output.write(&buf, values)
+		selected++
+		if buf.Len() >= recordsPayloadSize {
+			returned += int64(buf.Len())
+			if err = events.writeRecords(buf.Bytes()); err != nil {
+				return err
+			}
+			buf.Reset()
+		}
+	}
+	if len(q.aggregates) > 0 && q.limit != 0 {
+		values, err := q.project(nil)
+		if err != nil {
+			return err
+		}
+		s.output.write(&buf, values)
+	}
+	if buf.Len() > 0 {
+		returned += int64(buf.Len())
+		if err = events.writeRecords(buf.Bytes()); err != nil {
+			return err
+		}
+	}
+	if err = events.writeStats(scanned.n, processed.n, returned); err != nil {
+		return err
+	}
+	return events.writeEnd()
+}
+
+func decompress(r io.Reader, compressionType string) (io.Reader, error) {
+	switch strings.ToUpper(compressionType) {
+	case "GZIP":
+		gzipReader, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, newError(s3err.ErrInvalidCompressionFormat, "open gzip: %v", err)
+		}
+		return gzipReader, nil
+	case "BZIP2":
+		return bzip2.NewReader(r), nil
+	}
+	return r, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,228 @@
+package s3select
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"strings"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+)
+
+const testCSV = `name,city,age
+alice,Paris,31
+bob,"New York, NY",45
+# a comment
+carol,London,
+dave,Paris,28
+`
+
+const testJSONLines = `{"name": "alice", "address": {"city": "Paris"}, "tags": ["a", "b"], "age": 31}
+{"name": "bob", "address": {"city": "New York"}, "age": 45.5}
+{"name": "carol", "tags": []}
+`
+
+func selectRequest(expression, input, output string) []byte {
+	return []byte(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<SelectObjectContentRequest xmlns="http://s3.amazonaws.com/doc/2006-03-01/">
+  <Expression>%s</Expression>
+  <ExpressionType>SQL</ExpressionType>
+  <InputSerialization>%s</InputSerialization>
+  <OutputSerialization>%s</OutputSerialization>
+</SelectObjectContentRequest>`, expression, input, output))
+}
+
+type testMessage struct {
+	headers map[string]string
+	payload []byte
+}
+
+func decodeMessages(t *testing.T, data []byte) (messages []testMessage) {
+	for len(data) > 0 {
+		if len(data) < 16 {
+			t.Fatalf("truncated message of %d bytes", len(data))
+		}
+		totalLength := binary.BigEndian.Uint32(data)
+		headersLength := binary.BigEndian.Uint32(data[4:])
+		if crc32.ChecksumIEEE(data[:8]) != binary.BigEndian.Uint32(data[8:]) {
+			t.Fatalf("prelude crc mismatch")
+		}
+		message := data[:totalLength]
+		if crc32.ChecksumIEEE(message[:totalLength-4]) != binary.BigEndian.Uint32(message[totalLength-4:]) {
+			t.Fatalf("message crc mismatch")
+		}
+		m := testMessage{headers: make(map[string]string), payload: message[12+headersLength : totalLength-4]}
+		for h := message[12 : 12+headersLength]; len(h) > 0; {
+			nameLength := int(h[0])
+			name := string(h[1 : 1+nameLength])
+			h = h[1+nameLength:]
+			if h[0] != headerValueTypeString {
+				t.Fatalf("header %s value type %d", name, h[0])
+			}
+			valueLength := int(binary.BigEndian.Uint16(h[1:]))
+			m.headers[name] = string(h[3 : 3+valueLength])
+			h = h[3+valueLength:]
+		}
+		messages = append(messages, m)
+		data = data[totalLength:]
+	}
+	return messages
+}
+
+func runSelect(t *testing.T, request []byte, content []byte) (records string, messages []testMessage, err error) {
+	s, err := Parse(request)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	var out bytes.Buffer
+	err = s.Run(bytes.NewReader(content), &out)
+	messages = decodeMessages(t, out.Bytes())
+	for _, m := range messages {
+		if m.headers[":event-type"] == "Records" {
+			records += string(m.payload)
+		}
+	}
+	return records, messages, err
+}
+
+func TestSelectCSV(t *testing.T) {
+	csvInput := `<CSV><FileHeaderInfo>USE</FileHeaderInfo></CSV>`
+	csvOutput := `<CSV/>`
+	tests := []struct {
+		expression string
+		output     string
+		want       string
+	}{
+		{"SELECT * FROM S3Object", csvOutput, "alice,Paris,31\nbob,\"New York, NY\",45\ncarol,London,\ndave,Paris,28\n"},
+		{"SELECT s.name FROM S3Object s WHERE s.city = 'Paris'", csvOutput, "alice\ndave\n"},
+		{"SELECT name, age FROM S3Object WHERE age &lt;&gt; '' AND CAST(age AS INT) &gt; 30", csvOutput, "alice,31\nbob,45\n"},
+		{"SELECT name FROM S3Object WHERE age &lt; 30 AND age != ''", csvOutput, "dave\n"},
+		{"SELECT name FROM S3Object WHERE age = '' OR city LIKE 'New%'", csvOutput, "bob\ncarol\n"},
+		{"SELECT UPPER(name) AS n, CHAR_LENGTH(city) FROM S3Object LIMIT 2", `<JSON/>`, "{\"n\":\"ALICE\",\"_2\":5}\n{\"n\":\"BOB\",\"_2\":12}\n"},
+		{"SELECT COUNT(*), MAX(CAST(age AS INT)), AVG(CAST(age AS INT)) FROM S3Object WHERE age IS NOT NULL AND age &lt;&gt; ''", csvOutput, "3,45,34.666666666666664\n"},
+		{"SELECT _1 FROM S3Object WHERE _2 IN ('London', 'Berlin')", csvOutput, "carol\n"},
+		{"SELECT \"name\" FROM S3Object WHERE NOT (city BETWEEN 'M' AND 'Z')", csvOutput, "carol\n"},
+		{"SELECT name || '@' || city FROM S3Object WHERE name = 'bob'", `<CSV><QuoteFields>ALWAYS</QuoteFields><FieldDelimiter>;</FieldDelimiter></CSV>`, "\"bob@New York, NY\"\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			records, messages, err := runSelect(t, selectRequest(tt.expression, csvInput, tt.output), []byte(testCSV))
+			if err != nil {
+				t.Fatalf("run: %v", err)
+			}
+			if records != tt.want {
+				t.Errorf("records %q, want %q", records, tt.want)
+			}
+			if last := messages[len(messages)-1]; last.headers[":event-type"] != "End" {
+				t.Errorf("last message %v", last.headers)
+			}
+		})
+	}
+}
+
+func TestSelectJSON(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       string
+	}{
+		{"SELECT * FROM S3Object[*] s WHERE s.age &gt; 40", "{\"name\":\"bob\",\"address\":{\"city\":\"New York\"},\"age\":45.5}\n"},
+		{"SELECT s.name, s.address.city, s.tags[1] FROM S3Object[*] s", "{\"name\":\"alice\",\"city\":\"Paris\",\"_3\":\"b\"}\n{\"name\":\"bob\",\"city\":\"New York\"}\n{\"name\":\"carol\"}\n"},
+		{"SELECT s.name FROM S3Object s WHERE s.address IS MISSING", "{\"name\":\"carol\"}\n"},
+		{"SELECT SUM(s.age), COUNT(s.age) FROM S3Object s", "{\"_1\":76.5,\"_2\":2}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			request := selectRequest(tt.expression, `<JSON><Type>LINES</Type></JSON>`, `<JSON/>`)
+			records, _, err := runSelect(t, request, []byte(testJSONLines))
+			if err != nil {
+				t.Fatalf("run: %v", err)
+			}
+			if records != tt.want {
+				t.Errorf("records %q, want %q", records, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectGzipStats(t *testing.T) {
+	var compressed bytes.Buffer
+	gzipWriter := gzip.NewWriter(&compressed)
+	gzipWriter.Write([]byte(testCSV))
+	gzipWriter.Close()
+
+	request := selectRequest("SELECT _1 FROM S3Object LIMIT 1", `<CompressionType>GZIP</CompressionType><CSV/>`, `<CSV/>`)
+	records, messages, err := runSelect(t, request, compressed.Bytes())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if records != "name\n" {
+		t.Errorf("records %q", records)
+	}
+	var statsPayload string
+	for _, m := range messages {
+		if m.headers[":event-type"] == "Stats" {
+			statsPayload = string(m.payload)
+		}
+	}
+	if !strings.Contains(statsPayload, "<BytesReturned>5</BytesReturned>") {
+		t.Errorf("stats %s", statsPayload)
+	}
+}
+
+func TestSelectParseErrors(t *testing.T) {
+	tests := []struct {
+		request []byte
+		want    s3err.ErrorCode
+	}{
+		{[]byte("<SelectObjectContentRequest>"), s3err.ErrMalformedXML},
+		{selectRequest("SELECT * FROM", `<CSV/>`, `<CSV/>`), s3err.ErrUnsupportedSyntax},
+		{selectRequest("SELECT * FROM S3Object WHERE", `<CSV/>`, `<CSV/>`), s3err.ErrUnsupportedSyntax},
+		{selectRequest("SELECT * FROM S3Object WHERE _1 = 'x", `<CSV/>`, `<CSV/>`), s3err.ErrUnsupportedSyntax},
+		{selectRequest("SELECT _1, COUNT(*) FROM S3Object", `<CSV/>`, `<CSV/>`), s3err.ErrUnsupportedSqlOperation},
+		{selectRequest("SELECT * FROM S3Object WHERE COUNT(*) &gt; 1", `<CSV/>`, `<CSV/>`), s3err.ErrUnsupportedSqlOperation},
+		{selectRequest("SELECT * FROM S3Object", `<CSV/><JSON/>`, `<CSV/>`), s3err.ErrInvalidRequestParameter},
+		{selectRequest("SELECT * FROM S3Object", `<CompressionType>ZIP</CompressionType><CSV/>`, `<CSV/>`), s3err.ErrInvalidCompressionFormat},
+		{selectRequest("SELECT * FROM S3Object", `<Parquet/>`, `<CSV/>`), s3err.ErrNotImplemented},
+	}
+	for _, tt := range tests {
+		if _, err := Parse(tt.request); ErrorCode(err) != tt.want {
+			t.Errorf("parse %s: %v, want %v", tt.request, err, s3err.GetAPIError(tt.want).Code)
+		}
+	}
+}
+
+func TestSelectErrorEvent(t *testing.T) {
+	request := selectRequest("SELECT * FROM S3Object", `<CSV/>`, `<CSV/>`)
+	_, messages, err := runSelect(t, request, []byte("a,b\nc,\"d\n"))
+	if ErrorCode(err) != s3err.ErrCSVParsingError {
+		t.Fatalf("run: %v", err)
+	}
+	last := messages[len(messages)-1]
+	if last.headers[":message-type"] != "error" || last.headers[":error-code"] != "CSVParsingError" {
+		t.Errorf("last message %v", last.headers)
+	}
+}
+
+func TestMatchLike(t *testing.T) {
+	tests := []struct {
+		s, pattern string
+		want       bool
+	}{
+		{"New York", "New%", true},
+		{"New York", "%York", true},
+		{"New York", "N_w%k", true},
+		{"New York", "%x%", false},
+		{"50%", "50!%", true},
+		{"500", "50!%", false},
+		{"", "%", true},
+		{"abc", "a%c%", true},
+	}
+	for _, tt := range tests {
+		if got := matchLike([]rune(tt.s), []rune(tt.pattern), '!'); got != tt.want {
+			t.Errorf("%q LIKE %q = %v, want %v", tt.s, tt.pattern, got, tt.want)
+		}
+	}
+}
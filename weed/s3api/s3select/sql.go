@@ -0,0 +1,638 @@
+package s3select
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3err"
+)
+
+// The supported SQL is
+//
+//	SELECT * | expression [[AS] name], ... FROM S3Object[[*]] [[AS] alias] [WHERE condition] [LIMIT n]
+//
+// with the comparison, LIKE, BETWEEN, IN, IS [NOT] NULL, IS [NOT] MISSING, AND, OR, NOT,
+// the arithmetic and || operators, CAST, the string functions, COALESCE and NULLIF,
+// and the COUNT, SUM, AVG, MIN, MAX aggregates.
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdentifier
+	tokenQuotedIdentifier
+	tokenString
+	tokenNumber
+	tokenSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+var reservedWords = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "LIMIT": true, "AS": true,
+	"AND": true, "OR": true, "NOT": true, "LIKE": true, "ESCAPE": true, "BETWEEN": true, "IN": true,
+	"IS": true, "NULL": true, "MISSING": true, "TRUE": true, "FALSE": true, "CAST": true,
+}
+
+var castTypes = map[string]string{
+	"STRING": "STRING", "VARCHAR": "STRING", "CHAR": "STRING",
+	"INT": "INT", "INTEGER": "INT", "BIGINT": "INT", "SMALLINT": "INT",
+	"FLOAT": "FLOAT", "DOUBLE": "FLOAT", "REAL": "FLOAT", "DECIMAL": "FLOAT", "NUMERIC": "FLOAT",
+	"BOOL": "BOOL", "BOOLEAN": "BOOL",
+}
+
+// the number of arguments of the functions, -1 for any
+var functionArities = map[string][2]int{
+	"LOWER": {1, 1}, "UPPER": {1, 1}, "TRIM": {1, 1}, "CHAR_LENGTH": {1, 1}, "CHARACTER_LENGTH": {1, 1},
+	"SUBSTRING": {2, 3}, "COALESCE": {1, -1}, "NULLIF": {2, 2},
+}
+
+func syntaxError(format string, args ...interface{}) *Error {
+	return newError(s3err.ErrUnsupportedSyntax, format, args...)
+}
+
+func tokenize(sql string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case isIdentifierStart(c):
+			j := i + 1
+			for j < len(sql) && (isIdentifierStart(sql[j]) || isDigit(sql[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenIdentifier, text: sql[i:j], pos: i})
+			i = j
+		case isDigit(c) || c == '.' && i+1 < len(sql) && isDigit(sql[i+1]):
+			j := i
+			for j < len(sql) && (isDigit(sql[j]) || sql[j] == '.') {
+				j++
+			}
+			if j < len(sql) && (sql[j] == 'e' || sql[j] == 'E') {
+				k := j + 1
+				if k < len(sql) && (sql[k] == '+' || sql[k] == '-') {
+					k++
+				}
+				if k < len(sql) && isDigit(sql[k]) {
+					for j = k; j < len(sql) && isDigit(sql[j]); j++ {
+					}
+				}
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: sql[i:j], pos: i})
+			i = j
+		case c == '\'' || c == '"':
+			// the quotes are escaped by doubling them
+			var text strings.Builder
+			j := i + 1
+			for ; ; j++ {
+				if j >= len(sql) {
+					return nil, syntaxError("unterminated %c at %d", c, i)
+				}
+				if sql[j] == c {
+					if j+1 < len(sql) && sql[j+1] == c {
+						j++
+					} else {
+						break
+					}
+				}
+				text.WriteByte(sql[j])
+			}
+			kind := tokenString
+			if c == '"' {
+				kind = tokenQuotedIdentifier
+			}
+			tokens = append(tokens, token{kind: kind, text: text.String(), pos: i})
+			i = j + 1
+		default:
+			if i+1 < len(sql) {
+				switch sql[i : i+2] {
+				case "<=", ">=", "<>", "!=", "||":
+					tokens = append(tokens, token{kind: tokenSymbol, text: sql[i : i+2], pos: i})
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("=<>*,()[].+-/%", rune(c)) {
+				return nil, syntaxError("unexpected character %q at %d", c, i)
+			}
+			tokens = append(tokens, token{kind: tokenSymbol, text: string(c), pos: i})
+			i++
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(sql)}), nil
+}
+
+func isIdentifierStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+type projection struct {
+	expr expr
+	name string
+}
+
+type query struct {
+	projections []projection // nil for SELECT *
+	where       expr
+	limit       int64 // -1 without limit
+	aggregates  []*aggregateExpr
+}
+
+func (q *query) matches(r record) (bool, error) {
+	if q.where == nil {
+		return true, nil
+	}
+	v, err := q.where.eval(r)
+	return v == true, err
+}
+
+func (q *query) accumulate(r record) error {
+	for _, aggregate := range q.aggregates {
+		if err := aggregate.accumulate(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// project evaluates the projections on the record, or on the aggregates with a nil record
+func (q *query) project(r record) ([]namedValue, error) {
+	if q.projections == nil {
+		return r.fields(), nil
+	}
+	values := make([]namedValue, len(q.projections))
+	for i, p := range q.projections {
+		v, err := p.expr.eval(r)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = namedValue{name: p.name, value: v}
+	}
+	return values, nil
+}
+
+type parser struct {
+	tokens      []token
+	pos         int
+	columns     []*columnRef
+	aggregates  []*aggregateExpr
+	inAggregate bool
+	inWhere     bool
+	// the column references outside the aggregates
+	columnCount int
+}
+
+func parseQuery(sql string) (*query, error) {
+	tokens, err := tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	return p.parseSelect()
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// back returns the token, except the end, to be parsed again
+func (p *parser) back(t token) {
+	if t.kind != tokenEOF {
+		p.pos--
+	}
+}
+
+func (p *parser) isKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == tokenIdentifier && strings.EqualFold(t.text, keyword)
+}
+
+func (p *parser) acceptKeyword(keyword string) bool {
+	if p.isKeyword(keyword) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) acceptSymbol(symbol string) bool {
+	if t := p.peek(); t.kind == tokenSymbol && t.text == symbol {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectKeyword(keyword string) error {
+	if !p.acceptKeyword(keyword) {
+		return p.unexpected(keyword)
+	}
+	return nil
+}
+
+func (p *parser) expectSymbol(symbol string) error {
+	if !p.acceptSymbol(symbol) {
+		return p.unexpected(symbol)
+	}
+	return nil
+}
+
+func (p *parser) unexpected(expected string) error {
+	t := p.peek()
+	if t.kind == tokenEOF {
+		return syntaxError("expected %s at the end", expected)
+	}
+	return syntaxError("expected %s at %d, found %q", expected, t.pos, t.text)
+}
+
+// acceptName returns the name after AS, or the unreserved identifier, or ""
+func (p *parser) acceptName() (string, error) {
+	t := p.peek()
+	if p.acceptKeyword("AS") {
+		if t = p.next(); t.kind != tokenIdentifier && t.kind != tokenQuotedIdentifier {
+			p.back(t)
+			return "", p.unexpected("name")
+		}
+		return t.text, nil
+	}
+	if t.kind == tokenQuotedIdentifier || t.kind == tokenIdentifier && !reservedWords[strings.ToUpper(t.text)] {
+		p.pos++
+		return t.text, nil
+	}
+	return "", nil
+}
+
+func (p *parser) parseSelect() (*query, error) {
+	if err := p.expectKeyword("SELECT"); err != nil {
+		return nil, err
+	}
+	q := &query{limit: -1}
+	if !p.acceptSymbol("*") {
+		for {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			proj := projection{expr: e, name: "_" + strconv.Itoa(len(q.projections)+1)}
+			if column, ok := e.(*columnRef); ok && column.name() != "" {
+				proj.name = column.name()
+			}
+			name, err := p.acceptName()
+			if err != nil {
+				return nil, err
+			}
+			if name != "" {
+				proj.name = name
+			}
+			q.projections = append(q.projections, proj)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+	}
+	if len(p.aggregates) > 0 && (q.projections == nil || p.columnCount > 0) {
+		return nil, newError(s3err.ErrUnsupportedSqlOperation, "aggregates can not be mixed with the columns")
+	}
+
+	if err := p.expectKeyword("FROM"); err != nil {
+		return nil, err
+	}
+	if t := p.next(); t.kind != tokenIdentifier || !strings.EqualFold(t.text, "S3Object") {
+		p.back(t)
+		return nil, p.unexpected("S3Object")
+	}
+	if p.acceptSymbol("[") {
+		if err := p.expectSymbol("*"); err != nil {
+			return nil, err
+		}
+		if err := p.expectSymbol("]"); err != nil {
+			return nil, err
+		}
+	}
+	if t := p.peek(); t.kind == tokenSymbol && (t.text == "." || t.text == "[") {
+		return nil, newError(s3err.ErrUnsupportedSqlOperation, "paths in the FROM clause are not supported")
+	}
+	alias, err := p.acceptName()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.acceptKeyword("WHERE") {
+		p.inWhere = true
+		if q.where, err = p.parseExpr(); err != nil {
+			return nil, err
+		}
+		p.inWhere = false
+	}
+	if p.acceptKeyword("LIMIT") {
+		t := p.next()
+		if q.limit, err = strconv.ParseInt(t.text, 10, 64); t.kind != tokenNumber || err != nil || q.limit < 0 {
+			p.back(t)
+			return nil, p.unexpected("the limit")
+		}
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, p.unexpected("the end")
+	}
+	for _, column := range p.columns {
+		column.alias = alias
+	}
+	q.aggregates = p.aggregates
+	return q, nil
+}
+
+func (p *parser) parseExpr() (expr, error) {
+	left, err := p.parseAnd()
+	for err == nil && p.acceptKeyword("OR") {
+		var right expr
+		if right, err = p.parseAnd(); err == nil {
+			left = &logicalExpr{and: false, left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) parseAnd() (expr, error) {
+	left, err := p.parseNot()
+	for err == nil && p.acceptKeyword("AND") {
+		var right expr
+		if right, err = p.parseNot(); err == nil {
+			left = &logicalExpr{and: true, left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) parseNot() (expr, error) {
+	if p.acceptKeyword("NOT") {
+		x, err := p.parseNot()
+		return &notExpr{x: x}, err
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (expr, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind == tokenSymbol {
+		switch t.text {
+		case "=", "!=", "<>", "<", "<=", ">", ">=":
+			p.pos++
+			right, err := p.parseAdditive()
+			return &compareExpr{op: t.text, left: left, right: right}, err
+		}
+	}
+	if p.acceptKeyword("IS") {
+		not := p.acceptKeyword("NOT")
+		switch {
+		case p.acceptKeyword("NULL"):
+			return &isNullExpr{x: left, not: not}, nil
+		case p.acceptKeyword("MISSING"):
+			return &isNullExpr{x: left, not: not, missingOnly: true}, nil
+		}
+		return nil, p.unexpected("NULL or MISSING")
+	}
+	not := p.acceptKeyword("NOT")
+	switch {
+	case p.acceptKeyword("LIKE"):
+		like := &likeExpr{x: left, not: not}
+		if like.pattern, err = p.parseAdditive(); err == nil && p.acceptKeyword("ESCAPE") {
+			like.escape, err = p.parseAdditive()
+		}
+		return like, err
+	case p.acceptKeyword("BETWEEN"):
+		between := &betweenExpr{x: left, not: not}
+		if between.low, err = p.parseAdditive(); err != nil {
+			return nil, err
+		}
+		if err = p.expectKeyword("AND"); err != nil {
+			return nil, err
+		}
+		between.high, err = p.parseAdditive()
+		return between, err
+	case p.acceptKeyword("IN"):
+		in := &inExpr{x: left, not: not}
+		if err = p.expectSymbol("("); err != nil {
+			return nil, err
+		}
+		for {
+			element, err := p.parseAdditive()
+			if err != nil {
+				return nil, err
+			}
+			in.list = append(in.list, element)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+		return in, p.expectSymbol(")")
+	case not:
+		return nil, p.unexpected("LIKE, BETWEEN or IN")
+	}
+	return left, nil
+}
+
+func (p *parser) parseAdditive() (expr, error) {
+	left, err := p.parseMultiplicative()
+	for err == nil {
+		t := p.peek()
+		if t.kind != tokenSymbol || t.text != "+" && t.text != "-" && t.text != "||" {
+			break
+		}
+		p.pos++
+		var right expr
+		if right, err = p.parseMultiplicative(); err == nil {
+			left = &arithmeticExpr{op: t.text, left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) parseMultiplicative() (expr, error) {
+	left, err := p.parseUnary()
+	for err == nil {
+		t := p.peek()
+		if t.kind != tokenSymbol || t.text != "*" && t.text != "/" && t.text != "%" {
+			break
+		}
+		p.pos++
+		var right expr
+		if right, err = p.parseUnary(); err == nil {
+			left = &arithmeticExpr{op: t.text, left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) parseUnary() (expr, error) {
+	if p.acceptSymbol("-") {
+		x, err := p.parseUnary()
+		if l, ok := x.(*literal); ok && isNumber(l.value) {
+			v, _ := (&negativeExpr{x: l}).eval(nil)
+			return &literal{value: v}, err
+		}
+		return &negativeExpr{x: x}, err
+	}
+	p.acceptSymbol("+")
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		if v := parseNumber(t.text); v != nil {
+			return &literal{value: v}, nil
+		}
+		return nil, syntaxError("invalid number %q at %d", t.text, t.pos)
+	case tokenString:
+		return &literal{value: t.text}, nil
+	case tokenQuotedIdentifier:
+		return p.parseColumn(t)
+	case tokenSymbol:
+		if t.text == "(" {
+			e, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			return e, p.expectSymbol(")")
+		}
+	case tokenIdentifier:
+		switch strings.ToUpper(t.text) {
+		case "TRUE":
+			return &literal{value: true}, nil
+		case "FALSE":
+			return &literal{value: false}, nil
+		case "NULL":
+			return &literal{value: nil}, nil
+		case "MISSING":
+			return &literal{value: missing}, nil
+		case "CAST":
+			return p.parseCast()
+		}
+		if p.acceptSymbol("(") {
+			return p.parseFunction(strings.ToUpper(t.text))
+		}
+		if !reservedWords[strings.ToUpper(t.text)] {
+			return p.parseColumn(t)
+		}
+	}
+	p.back(t)
+	return nil, p.unexpected("an expression")
+}
+
+func (p *parser) parseColumn(t token) (expr, error) {
+	column := &columnRef{path: []pathElement{{name: t.text, quoted: t.kind == tokenQuotedIdentifier}}}
+	for {
+		if p.acceptSymbol(".") {
+			t = p.next()
+			if t.kind != tokenIdentifier && t.kind != tokenQuotedIdentifier {
+				p.back(t)
+				return nil, p.unexpected("a name")
+			}
+			column.path = append(column.path, pathElement{name: t.text, quoted: t.kind == tokenQuotedIdentifier})
+		} else if p.acceptSymbol("[") {
+			t = p.next()
+			index, err := strconv.Atoi(t.text)
+			if t.kind != tokenNumber || err != nil || index < 0 {
+				p.back(t)
+				return nil, p.unexpected("an array index")
+			}
+			column.path = append(column.path, pathElement{index: index})
+			if err = p.expectSymbol("]"); err != nil {
+				return nil, err
+			}
+		} else {
+			break
+		}
+	}
+	p.columns = append(p.columns, column)
+	if !p.inAggregate {
+		p.columnCount++
+	}
+	return column, nil
+}
+
+func (p *parser) parseCast() (expr, error) {
+	if err := p.expectSymbol("("); err != nil {
+		return nil, err
+	}
+	x, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if err = p.expectKeyword("AS"); err != nil {
+		return nil, err
+	}
+	t := p.next()
+	typeName, found := castTypes[strings.ToUpper(t.text)]
+	if t.kind != tokenIdentifier || !found {
+		return nil, newError(s3err.ErrUnsupportedSqlOperation, "unsupported cast type %q", t.text)
+	}
+	return &castExpr{x: x, typeName: typeName}, p.expectSymbol(")")
+}
+
+func (p *parser) parseFunction(name string) (expr, error) {
+	switch name {
+	case "COUNT", "SUM", "AVG", "MIN", "MAX":
+		if p.inAggregate || p.inWhere {
+			return nil, newError(s3err.ErrUnsupportedSqlOperation, "%s is not allowed here", name)
+		}
+		aggregate := &aggregateExpr{name: name}
+		if name != "COUNT" || !p.acceptSymbol("*") {
+			p.inAggregate = true
+			arg, err := p.parseExpr()
+			p.inAggregate = false
+			if err != nil {
+				return nil, err
+			}
+			aggregate.arg = arg
+		}
+		p.aggregates = append(p.aggregates, aggregate)
+		return aggregate, p.expectSymbol(")")
+	}
+	arity, found := functionArities[name]
+	if !found {
+		return nil, newError(s3err.ErrUnsupportedSqlOperation, "unsupported function %s", name)
+	}
+	function := &functionExpr{name: name}
+	if !p.acceptSymbol(")") {
+		for {
+			arg, err := p.parseExpr()
+			if err != nil {
+				return nil, err
+			}
+			function.args = append(function.args, arg)
+			if !p.acceptSymbol(",") {
+				break
+			}
+		}
+		if err := p.expectSymbol(")"); err != nil {
+			return nil, err
+		}
+	}
+	if len(function.args) < arity[0] || arity[1] >= 0 && len(function.args) > arity[1] {
+		return nil, syntaxError("wrong number of arguments to %s", name)
+	}
+	return function, nil
+}
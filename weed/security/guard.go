@@ -75,6 +75,11 @@ func (g *Guard) WhiteList(f http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// IsWhiteListed checks whether the request is from a host in the white list, which is false for an empty white list
+func (g *Guard) IsWhiteListed(r *http.Request) bool {
+	return len(g.whiteList) != 0 && g.checkWhiteList(nil, r) == nil
+}
+
 func GetActualRemoteHost(r *http.Request) (host string, err error) {
 	host = r.Header.Get("HTTP_X_FORWARDED_FOR")
 	if host == "" {
@@ -51,12 +51,18 @@ func GenJwtForVolumeServer(signingKey SigningKey, expiresAfterSec int, fileId st
 // GenJwtForFilerServer creates a JSON-web-token for using the authenticated Filer API. Used f.e. inside
 // the S3 API
 func GenJwtForFilerServer(signingKey SigningKey, expiresAfterSec int) EncodedJwt {
+	return GenJwtForFilerUser(signingKey, expiresAfterSec, "")
+}
+
+// GenJwtForFilerUser creates a JSON-web-token for using the authenticated Filer API on behalf of the user,
+// which is the subject of the token
+func GenJwtForFilerUser(signingKey SigningKey, expiresAfterSec int, user string) EncodedJwt {
 	if len(signingKey) == 0 {
 		return ""
 	}
 
 	claims := SeaweedFilerClaims{
-		jwt.RegisteredClaims{},
+		jwt.RegisteredClaims{Subject: user},
 	}
 	if expiresAfterSec > 0 {
 		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Second * time.Duration(expiresAfterSec)))
@@ -37,9 +37,9 @@ func LoadServerTLS(config *util.ViperProxy, component string) (grpc.ServerOption
 		return nil, nil
 	}
 
-	serverIdentityProvider, err := pemfile.NewProvider(serverOptions)
+	serverIdentityProvider, err := loadCertProvider(serverOptions)
 	if err != nil {
-		glog.Warningf("pemfile.NewProvider(%v) %v failed: %v", serverOptions, component, err)
+		glog.Warningf("loadCertProvider(%v) %v failed: %v", serverOptions, component, err)
 		return nil, nil
 	}
 
@@ -47,9 +47,9 @@ func LoadServerTLS(config *util.ViperProxy, component string) (grpc.ServerOption
 		RootFile:        config.GetString("grpc.ca"),
 		RefreshDuration: CredRefreshingInterval,
 	}
-	serverRootProvider, err := pemfile.NewProvider(serverRootOptions)
+	serverRootProvider, err := loadCertProvider(serverRootOptions)
 	if err != nil {
-		glog.Warningf("pemfile.NewProvider(%v) failed: %v", serverRootOptions, err)
+		glog.Warningf("loadCertProvider(%v) failed: %v", serverRootOptions, err)
 		return nil, nil
 	}
 
@@ -104,18 +104,18 @@ func LoadClientTLS(config *util.ViperProxy, component string) grpc.DialOption {
 		KeyFile:         keyFileName,
 		RefreshDuration: CredRefreshingInterval,
 	}
-	clientProvider, err := pemfile.NewProvider(clientOptions)
+	clientProvider, err := loadCertProvider(clientOptions)
 	if err != nil {
-		glog.Warningf("pemfile.NewProvider(%v) failed %v", clientOptions, err)
+		glog.Warningf("loadCertProvider(%v) failed %v", clientOptions, err)
 		return grpc.WithTransportCredentials(insecure.NewCredentials())
 	}
 	clientRootOptions := pemfile.Options{
 		RootFile:        config.GetString("grpc.ca"),
 		RefreshDuration: CredRefreshingInterval,
 	}
-	clientRootProvider, err := pemfile.NewProvider(clientRootOptions)
+	clientRootProvider, err := loadCertProvider(clientRootOptions)
 	if err != nil {
-		glog.Warningf("pemfile.NewProvider(%v) failed: %v", clientRootOptions, err)
+		glog.Warningf("loadCertProvider(%v) failed: %v", clientRootOptions, err)
 		return grpc.WithTransportCredentials(insecure.NewCredentials())
 	}
 	options := &advancedtls.Options{
@@ -0,0 +1,194 @@
+package security
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc/credentials/tls/certprovider"
+	"google.golang.org/grpc/credentials/tls/certprovider/pemfile"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+	"github.com/seaweedfs/seaweedfs/weed/util/grace"
+)
+
+// the grpc components configured by UseTlsFiles
+var tlsComponents = []string{"master", "volume", "filer", "s3", "msg_broker", "client"}
+
+// the certificates set by UseTlsFiles, also used by the http servers and clients
+var (
+	tlsIdentity *fileCertProvider
+	tlsRoots    *fileCertProvider
+)
+
+// UseTlsFiles configures the grpc servers and clients of all components with the same certificates,
+// overriding the ones in security.toml. The servers require client certificates signed by the ca.
+// The master, volume and filer http servers then serve https with the same certificates,
+// and the internal http clients send their requests over https.
+func UseTlsFiles(caFile, certFile, keyFile string) (err error) {
+	if caFile == "" || certFile == "" || keyFile == "" {
+		return fmt.Errorf("the tls ca, cert and key files should be all set")
+	}
+	if tlsIdentity, err = loadCertProvider(pemfile.Options{CertFile: certFile, KeyFile: keyFile, RefreshDuration: CredRefreshingInterval}); err != nil {
+		return fmt.Errorf("load %s and %s: %v", certFile, keyFile, err)
+	}
+	if tlsRoots, err = loadCertProvider(pemfile.Options{RootFile: caFile, RefreshDuration: CredRefreshingInterval}); err != nil {
+		return fmt.Errorf("load %s: %v", caFile, err)
+	}
+	v := util.GetViper()
+	v.Set("grpc.ca", caFile)
+	for _, component := range tlsComponents {
+		v.Set("grpc."+component+".cert", certFile)
+		v.Set("grpc."+component+".key", keyFile)
+	}
+	util.UseHttps(&tls.Config{
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return tlsIdentity.certificate()
+		},
+		// the host name is not checked, as for grpc, so the certificate is verified with the ca below
+		InsecureSkipVerify:    true,
+		VerifyPeerCertificate: tlsRoots.verifyPeerCertificate,
+	})
+	return nil
+}
+
+// LoadServerTLSHTTP returns the https config of the master, volume and filer http servers
+// with the certificates set by UseTlsFiles, or nil if they are not set.
+// The clients should present a certificate signed by the ca.
+func LoadServerTLSHTTP() *tls.Config {
+	if tlsIdentity == nil {
+		return nil
+	}
+	return &tls.Config{
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return tlsIdentity.certificate()
+		},
+		ClientAuth:            tls.RequireAnyClientCert,
+		VerifyPeerCertificate: tlsRoots.verifyPeerCertificate,
+	}
+}
+
+var (
+	certProviders     = make(map[pemfile.Options]*fileCertProvider)
+	certProvidersLock sync.Mutex
+	certReloadOnce    sync.Once
+)
+
+// fileCertProvider provides the certificates in the pem files.
+// The files are loaded again periodically and on SIGHUP, so rotated certificates are used without a restart.
+type fileCertProvider struct {
+	options     pemfile.Options
+	keyMaterial atomic.Pointer[certprovider.KeyMaterial]
+}
+
+// loadCertProvider returns the provider shared by all the servers and clients using the same files.
+func loadCertProvider(options pemfile.Options) (*fileCertProvider, error) {
+	certProvidersLock.Lock()
+	defer certProvidersLock.Unlock()
+
+	if p, found := certProviders[options]; found {
+		return p, nil
+	}
+	p := &fileCertProvider{options: options}
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+	certProviders[options] = p
+	certReloadOnce.Do(func() {
+		grace.OnReload(reloadCertProviders)
+		go func() {
+			for range time.Tick(CredRefreshingInterval) {
+				reloadCertProviders()
+			}
+		}()
+	})
+	return p, nil
+}
+
+func reloadCertProviders() {
+	certProvidersLock.Lock()
+	defer certProvidersLock.Unlock()
+
+	for _, p := range certProviders {
+		// the loaded certificates are kept if the files are not valid
+		if err := p.load(); err != nil {
+			glog.Errorf("reload certificates %s%s: %v", p.options.CertFile, p.options.RootFile, err)
+			continue
+		}
+		glog.V(1).Infof("reloaded certificates %s%s", p.options.CertFile, p.options.RootFile)
+	}
+}
+
+func (p *fileCertProvider) load() error {
+	keyMaterial := &certprovider.KeyMaterial{}
+	if p.options.CertFile != "" || p.options.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(p.options.CertFile, p.options.KeyFile)
+		if err != nil {
+			return err
+		}
+		keyMaterial.Certs = []tls.Certificate{cert}
+	}
+	if p.options.RootFile != "" {
+		data, err := os.ReadFile(p.options.RootFile)
+		if err != nil {
+			return err
+		}
+		keyMaterial.Roots = x509.NewCertPool()
+		if !keyMaterial.Roots.AppendCertsFromPEM(data) {
+			return fmt.Errorf("no certificates in %s", p.options.RootFile)
+		}
+	}
+	if keyMaterial.Certs == nil && keyMaterial.Roots == nil {
+		return fmt.Errorf("no certificate files")
+	}
+	p.keyMaterial.Store(keyMaterial)
+	return nil
+}
+
+func (p *fileCertProvider) KeyMaterial(ctx context.Context) (*certprovider.KeyMaterial, error) {
+	return p.keyMaterial.Load(), nil
+}
+
+// Close keeps the provider, which is shared
+func (p *fileCertProvider) Close() {
+}
+
+func (p *fileCertProvider) certificate() (*tls.Certificate, error) {
+	keyMaterial := p.keyMaterial.Load()
+	if len(keyMaterial.Certs) == 0 {
+		return nil, fmt.Errorf("no certificate in %s", p.options.CertFile)
+	}
+	return &keyMaterial.Certs[0], nil
+}
+
+// verifyPeerCertificate verifies the peer certificate chain with the loaded ca, without checking the host name.
+// The same certificate is used by the servers and the clients, so any key usage is accepted.
+func (p *fileCertProvider) verifyPeerCertificate(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	if len(rawCerts) == 0 {
+		return fmt.Errorf("no peer certificate")
+	}
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for i, rawCert := range rawCerts {
+		cert, err := x509.ParseCertificate(rawCert)
+		if err != nil {
+			return fmt.Errorf("parse peer certificate: %v", err)
+		}
+		certs[i] = cert
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         p.keyMaterial.Load().Roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
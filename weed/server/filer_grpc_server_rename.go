@@ -33,6 +33,10 @@ func (fs *FilerServer) AtomicRenameEntry(ctx context.Context, req *filer_pb.Atom
 		fs.filer.RollbackTransaction(ctx)
 		return nil, fmt.Errorf("%s/%s not found: %v", req.OldDirectory, req.OldName, err)
 	}
+	if err = fs.filer.CheckWormRetentionRecursively(ctx, oldEntry, "rename"); err != nil {
+		fs.filer.RollbackTransaction(ctx)
+		return nil, err
+	}
 
 	moveErr := fs.moveEntry(ctx, nil, oldParent, oldEntry, newParent, req.NewName, req.Signatures)
 	if moveErr != nil {
@@ -71,6 +75,10 @@ func (fs *FilerServer) StreamRenameEntry(req *filer_pb.StreamRenameEntryRequest,
 		fs.filer.RollbackTransaction(ctx)
 		return fmt.Errorf("%s/%s not found: %v", req.OldDirectory, req.OldName, err)
 	}
+	if err = fs.filer.CheckWormRetentionRecursively(ctx, oldEntry, "rename"); err != nil {
+		fs.filer.RollbackTransaction(ctx)
+		return err
+	}
 
 	if oldEntry.IsDirectory() {
 		// follow https://pubs.opengroup.org/onlinepubs/000095399/functions/rename.html
@@ -82,6 +90,7 @@ func (fs *FilerServer) StreamRenameEntry(req *filer_pb.StreamRenameEntryRequest,
 				return fmt.Errorf("%s is not directory", targetDir)
 			}
 			if entries, _, _ := fs.filer.ListDirectoryEntries(context.Background(), targetDir, "", false, 1, "", "", ""); len(entries) > 0 {
+				fs.filer.RollbackTransaction(ctx)
 				return fmt.Errorf("%s is not empty", targetDir)
 			}
 		}
@@ -0,0 +1,23 @@
+package weed_server
+
+import (
+	"context"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func (fs *FilerServer) CreateSnapshot(ctx context.Context, req *filer_pb.CreateSnapshotRequest) (*filer_pb.CreateSnapshotResponse, error) {
+
+	glog.V(4).Infof("CreateSnapshot %v", req)
+
+	snapshotId, err := fs.filer.CreateSnapshot(ctx, util.FullPath(req.Directory))
+	if err != nil {
+		return nil, err
+	}
+
+	return &filer_pb.CreateSnapshotResponse{
+		SnapshotId: snapshotId,
+	}, nil
+}
@@ -0,0 +1,92 @@
+package weed_server
+
+import (
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+// WatchDirectory streams the metadata changes under the path prefix as create, update or delete events.
+// It is a simplified view of SubscribeMetadata, starting from now unless since_ns is set.
+func (fs *FilerServer) WatchDirectory(req *filer_pb.WatchRequest, stream filer_pb.SeaweedFiler_WatchDirectoryServer) error {
+
+	pathPrefix := req.PathPrefix
+	if pathPrefix == "" {
+		pathPrefix = "/"
+	}
+	sinceNs := req.SinceNs
+	if sinceNs == 0 {
+		sinceNs = time.Now().UnixNano()
+	}
+	clientId := util.RandomInt32()
+	for clientId == 0 {
+		clientId = util.RandomInt32()
+	}
+
+	return fs.SubscribeMetadata(&filer_pb.SubscribeMetadataRequest{
+		ClientName: "watch:" + req.ClientName,
+		PathPrefix: pathPrefix,
+		SinceNs:    sinceNs,
+		ClientId:   clientId,
+	}, &watchDirectoryStream{
+		SeaweedFiler_WatchDirectoryServer: stream,
+		eventTypes:                        req.EventTypes,
+	})
+}
+
+// watchDirectoryStream converts the metadata subscription responses to watch events
+type watchDirectoryStream struct {
+	filer_pb.SeaweedFiler_WatchDirectoryServer
+	eventTypes []filer_pb.WatchEvent_EventType
+}
+
+func (s *watchDirectoryStream) Send(resp *filer_pb.SubscribeMetadataResponse) error {
+	event := toWatchEvent(resp)
+	if event == nil || !s.isWatched(event.EventType) {
+		return nil
+	}
+	return s.SeaweedFiler_WatchDirectoryServer.Send(event)
+}
+
+func (s *watchDirectoryStream) isWatched(eventType filer_pb.WatchEvent_EventType) bool {
+	if len(s.eventTypes) == 0 {
+		return true
+	}
+	for _, t := range s.eventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// toWatchEvent returns nil for the empty notifications only carrying the timestamp
+func toWatchEvent(resp *filer_pb.SubscribeMetadataResponse) *filer_pb.WatchEvent {
+	notification := resp.EventNotification
+	if notification == nil {
+		return nil
+	}
+	event := &filer_pb.WatchEvent{
+		Directory: resp.Directory,
+		TsNs:      resp.TsNs,
+	}
+	switch {
+	case notification.OldEntry == nil && notification.NewEntry != nil:
+		event.EventType = filer_pb.WatchEvent_CREATE
+		event.Entry = notification.NewEntry
+	case notification.OldEntry != nil && notification.NewEntry == nil:
+		event.EventType = filer_pb.WatchEvent_DELETE
+		event.Entry = notification.OldEntry
+	case notification.OldEntry != nil && notification.NewEntry != nil:
+		event.EventType = filer_pb.WatchEvent_UPDATE
+		event.Entry = notification.NewEntry
+		event.OldEntry = notification.OldEntry
+		if notification.NewParentPath != resp.Directory {
+			event.NewParentPath = notification.NewParentPath
+		}
+	default:
+		return nil
+	}
+	return event
+}
@@ -0,0 +1,48 @@
+package weed_server
+
+import (
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+)
+
+func TestToWatchEvent(t *testing.T) {
+	a := &filer_pb.Entry{Name: "a"}
+	b := &filer_pb.Entry{Name: "b"}
+
+	tests := []struct {
+		notification  *filer_pb.EventNotification
+		eventType     filer_pb.WatchEvent_EventType
+		entry         *filer_pb.Entry
+		newParentPath string
+	}{
+		{&filer_pb.EventNotification{NewEntry: a, NewParentPath: "/dir"}, filer_pb.WatchEvent_CREATE, a, ""},
+		{&filer_pb.EventNotification{OldEntry: a}, filer_pb.WatchEvent_DELETE, a, ""},
+		{&filer_pb.EventNotification{OldEntry: a, NewEntry: b, NewParentPath: "/dir"}, filer_pb.WatchEvent_UPDATE, b, ""},
+		{&filer_pb.EventNotification{OldEntry: a, NewEntry: b, NewParentPath: "/other"}, filer_pb.WatchEvent_UPDATE, b, "/other"},
+	}
+	for i, tt := range tests {
+		event := toWatchEvent(&filer_pb.SubscribeMetadataResponse{Directory: "/dir", EventNotification: tt.notification, TsNs: 5})
+		if event == nil {
+			t.Fatalf("case %d: no event", i)
+		}
+		if event.EventType != tt.eventType || event.Entry != tt.entry || event.NewParentPath != tt.newParentPath || event.TsNs != 5 || event.Directory != "/dir" {
+			t.Errorf("case %d: unexpected event %+v", i, event)
+		}
+	}
+
+	if event := toWatchEvent(&filer_pb.SubscribeMetadataResponse{EventNotification: &filer_pb.EventNotification{}}); event != nil {
+		t.Errorf("empty notification should be skipped, got %+v", event)
+	}
+}
+
+func TestWatchDirectoryStreamEventTypes(t *testing.T) {
+	all := &watchDirectoryStream{}
+	deletes := &watchDirectoryStream{eventTypes: []filer_pb.WatchEvent_EventType{filer_pb.WatchEvent_DELETE}}
+	if !all.isWatched(filer_pb.WatchEvent_CREATE) || !all.isWatched(filer_pb.WatchEvent_DELETE) {
+		t.Errorf("empty event types should watch all events")
+	}
+	if deletes.isWatched(filer_pb.WatchEvent_CREATE) || !deletes.isWatched(filer_pb.WatchEvent_DELETE) {
+		t.Errorf("only delete events should be watched")
+	}
+}
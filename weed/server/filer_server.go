@@ -14,6 +14,7 @@ import (
 
 	"google.golang.org/grpc"
 
+	"github.com/seaweedfs/seaweedfs/weed/util/chunk_cache"
 	"github.com/seaweedfs/seaweedfs/weed/util/grace"
 
 	"github.com/seaweedfs/seaweedfs/weed/operation"
@@ -71,9 +72,25 @@ type FilerOption struct {
 	ConcurrentUploadLimit int64
 	ShowUIDirectoryDelete bool
 	DownloadMaxBytesPs    int64
+	UploadMaxBytes        int64
 	DiskType              string
 	AllowedOrigins        []string
 	ExposeDirectoryData   bool
+	ReadCacheDir          string
+	ReadCacheSizeMB       int64
+	RateLimitRPM          int
+	RateLimitBurst        int
+	RateLimitWhiteList    []string
+	CasDedupDir           string
+	WormAdminWhiteList    []string
+	RecompressOnRead      bool
+	DetectContentType     bool
+	AuditLog              string
+	AuditLogMaxMB         int
+	GcInterval            time.Duration
+	GcGracePeriod         time.Duration
+	GcRateLimit           int
+	QuotaRecountInterval  time.Duration
 }
 
 type FilerServer struct {
@@ -92,6 +109,7 @@ type FilerServer struct {
 	filer          *filer.Filer
 	filerGuard     *security.Guard
 	volumeGuard    *security.Guard
+	wormAdminGuard *security.Guard
 	grpcDialOption grpc.DialOption
 
 	// metrics read from the master
@@ -101,6 +119,10 @@ type FilerServer struct {
 	// track known metadata listeners
 	knownListenersLock sync.Mutex
 	knownListeners     map[int32]int32
+
+	rateLimiter  *clientRateLimiter
+	recompressor *chunkRecompressor
+	auditLog     *auditLog
 }
 
 func NewFilerServer(defaultMux, readonlyMux *http.ServeMux, option *FilerOption) (fs *FilerServer, err error) {
@@ -152,9 +174,33 @@ func NewFilerServer(defaultMux, readonlyMux *http.ServeMux, option *FilerOption)
 		}
 	})
 	fs.filer.Cipher = option.Cipher
+	if option.ReadCacheDir != "" && option.ReadCacheSizeMB > 0 {
+		if fs.filer.ReadCache, err = chunk_cache.NewFlatFileChunkCache(option.ReadCacheDir, option.ReadCacheSizeMB*1024*1024); err != nil {
+			glog.Fatalf("read cache %s: %v", option.ReadCacheDir, err)
+		}
+	}
+	if option.CasDedupDir != "" {
+		if fs.filer.ChunkDedup, err = filer.NewChunkDedup(option.CasDedupDir); err != nil {
+			glog.Fatalf("chunk dedup %s: %v", option.CasDedupDir, err)
+		}
+	}
+	if option.RateLimitRPM > 0 {
+		if fs.rateLimiter, err = newClientRateLimiter(option.RateLimitRPM, option.RateLimitBurst, option.RateLimitWhiteList); err != nil {
+			glog.Fatalf("filer rate limit: %v", err)
+		}
+	}
+	if option.RecompressOnRead {
+		fs.recompressor = newChunkRecompressor(fs)
+	}
+	if option.AuditLog != "" {
+		if fs.auditLog, err = newAuditLog(option.AuditLog, option.AuditLogMaxMB); err != nil {
+			glog.Fatalf("audit log %s: %v", option.AuditLog, err)
+		}
+	}
 	// we do not support IP whitelist right now
 	fs.filerGuard = security.NewGuard([]string{}, signingKey, expiresAfterSec, readSigningKey, readExpiresAfterSec)
 	fs.volumeGuard = security.NewGuard([]string{}, volumeSigningKey, volumeExpiresAfterSec, volumeReadSigningKey, volumeReadExpiresAfterSec)
+	fs.wormAdminGuard = security.NewGuard(option.WormAdminWhiteList, "", 0, "", 0)
 
 	fs.checkWithMaster()
 
@@ -187,12 +233,12 @@ func NewFilerServer(defaultMux, readonlyMux *http.ServeMux, option *FilerOption)
 	handleStaticResources(defaultMux)
 	if !option.DisableHttp {
 		defaultMux.HandleFunc("/healthz", fs.filerHealthzHandler)
-		defaultMux.HandleFunc("/", fs.filerHandler)
+		defaultMux.HandleFunc("/", fs.rateLimit(fs.filerHandler))
 	}
 	if defaultMux != readonlyMux {
 		handleStaticResources(readonlyMux)
 		readonlyMux.HandleFunc("/healthz", fs.filerHealthzHandler)
-		readonlyMux.HandleFunc("/", fs.readonlyFilerHandler)
+		readonlyMux.HandleFunc("/", fs.rateLimit(fs.readonlyFilerHandler))
 	}
 
 	existingNodes := fs.filer.ListExistingPeerUpdates(context.Background())
@@ -207,8 +253,18 @@ func NewFilerServer(defaultMux, readonlyMux *http.ServeMux, option *FilerOption)
 
 	fs.filer.LoadFilerConf()
 
+	fs.filer.LoadFilerQuotas(option.QuotaRecountInterval)
+
 	fs.filer.LoadRemoteStorageConfAndMapping()
 
+	if option.GcInterval > 0 {
+		go fs.filer.LoopGarbageCollection(filer.GcOption{
+			Interval:    option.GcInterval,
+			GracePeriod: option.GcGracePeriod,
+			RateLimit:   option.GcRateLimit,
+		})
+	}
+
 	grace.OnInterrupt(func() {
 		fs.filer.Shutdown()
 	})
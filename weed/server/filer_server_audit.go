@@ -0,0 +1,243 @@
+package weed_server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/security"
+	"github.com/seaweedfs/seaweedfs/weed/stats"
+)
+
+// the number of rotated audit log files kept, as <path>.1 to <path>.N
+const auditLogBackups = 5
+
+// auditLog writes one json line per filer http request to a local file, rotated by size.
+// The line is written before the response completes, so no request is missed.
+type auditLog struct {
+	sync.Mutex
+	path     string
+	maxBytes int64
+	file     *os.File
+	size     int64
+}
+
+type auditRecord struct {
+	Timestamp        string `json:"timestamp"`
+	ClientIP         string `json:"clientIP"`
+	User             string `json:"user,omitempty"`
+	Method           string `json:"method"`
+	Path             string `json:"path"`
+	From             string `json:"from,omitempty"` // the source of renames
+	StatusCode       int    `json:"statusCode"`
+	BytesTransferred int64  `json:"bytesTransferred"`
+	DurationMs       int64  `json:"durationMs"`
+}
+
+func newAuditLog(path string, maxMB int) (*auditLog, error) {
+	l := &auditLog{
+		path:     path,
+		maxBytes: int64(maxMB) * 1024 * 1024,
+	}
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *auditLog) open() error {
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	l.file, l.size = file, stat.Size()
+	return nil
+}
+
+func (l *auditLog) rotate() error {
+	l.file.Close()
+	for i := auditLogBackups - 1; i > 0; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", l.path, i), fmt.Sprintf("%s.%d", l.path, i+1))
+	}
+	if err := os.Rename(l.path, l.path+".1"); err != nil {
+		glog.Errorf("rotate audit log %s: %v", l.path, err)
+	}
+	return l.open()
+}
+
+func (l *auditLog) write(record *auditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.Lock()
+	defer l.Unlock()
+	if l.maxBytes > 0 && l.size > 0 && l.size+int64(len(line)) > l.maxBytes {
+		if err = l.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := l.file.Write(line)
+	l.size += int64(n)
+	return err
+}
+
+// auditResponseWriter counts the bytes sent and received for the audit log,
+// and writes the audit record before the last byte of the response
+type auditResponseWriter struct {
+	http.ResponseWriter
+	status        int
+	wroteHeader   bool
+	contentLength int64 // -1 if the response has no Content-Length
+	received      *countingReadCloser
+	sent          int64
+	record        func(w *auditResponseWriter) error
+	audited       bool
+	auditErr      error
+}
+
+type countingReadCloser struct {
+	io.ReadCloser
+	count int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (n int, err error) {
+	n, err = c.ReadCloser.Read(p)
+	c.count += int64(n)
+	return
+}
+
+// auditUser is the user authenticated by the filer jwt, set in the request context for the audit record
+type auditUser struct {
+	name string
+}
+
+type auditUserKey struct{}
+
+// setAuditUser sets the user authenticated for the request, if the request is audited
+func setAuditUser(r *http.Request, name string) {
+	if user, ok := r.Context().Value(auditUserKey{}).(*auditUser); ok {
+		user.name = name
+	}
+}
+
+func newAuditResponseWriter(w http.ResponseWriter, r *http.Request) *auditResponseWriter {
+	aw := &auditResponseWriter{ResponseWriter: w, status: http.StatusOK, contentLength: -1}
+	if r.Body != nil {
+		aw.received = &countingReadCloser{ReadCloser: r.Body}
+		r.Body = aw.received
+	}
+	return aw
+}
+
+func (w *auditResponseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = status, true
+		if contentLength, err := strconv.ParseInt(w.Header().Get("Content-Length"), 10, 64); err == nil {
+			w.contentLength = contentLength
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *auditResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.auditErr != nil {
+		return 0, w.auditErr
+	}
+	// the last byte completes the response for the client, so the audit record is written before it
+	if last := w.contentLength - w.sent - 1; !w.audited && last >= 0 && last < int64(len(p)) {
+		n, err := w.write(p[:last])
+		if err != nil {
+			return n, err
+		}
+		if err = w.audit(); err != nil {
+			return n, err
+		}
+		m, err := w.write(p[last:])
+		return n + m, err
+	}
+	return w.write(p)
+}
+
+func (w *auditResponseWriter) write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.sent += int64(n)
+	return n, err
+}
+
+func (w *auditResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// audit writes the audit record once, and counts the failures
+func (w *auditResponseWriter) audit() error {
+	if !w.audited {
+		w.audited = true
+		if err := w.record(w); err != nil {
+			stats.FilerHandlerCounter.WithLabelValues(stats.ErrorAuditLog).Inc()
+			w.auditErr = fmt.Errorf("audit log: %v", err)
+		}
+	}
+	return w.auditErr
+}
+
+// auditRequest wraps the response writer, and returns the function to call when the handler finishes.
+// The audit record is written before the response completes. If it fails, the response is not completed,
+// so the client does not take the request as done.
+func (fs *FilerServer) auditRequest(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, *http.Request, func()) {
+	if fs.auditLog == nil {
+		return w, r, func() {}
+	}
+	start := time.Now()
+	path := r.URL.Path
+	user := &auditUser{}
+	r = r.WithContext(context.WithValue(r.Context(), auditUserKey{}, user))
+	aw := newAuditResponseWriter(w, r)
+	aw.record = func(aw *auditResponseWriter) error {
+		clientIP, _ := security.GetActualRemoteHost(r)
+		record := &auditRecord{
+			Timestamp:        start.UTC().Format(time.RFC3339Nano),
+			ClientIP:         clientIP,
+			User:             user.name,
+			Method:           r.Method,
+			Path:             path,
+			From:             r.URL.Query().Get("mv.from"),
+			StatusCode:       aw.status,
+			BytesTransferred: max(aw.sent, aw.contentLength),
+			DurationMs:       time.Since(start).Milliseconds(),
+		}
+		if aw.received != nil {
+			record.BytesTransferred += aw.received.count
+		}
+		return fs.auditLog.write(record)
+	}
+	return aw, r, func() {
+		if err := aw.audit(); err != nil {
+			glog.Errorf("audit %s %s: %v", r.Method, path, err)
+			if !aw.wroteHeader {
+				writeJsonError(aw.ResponseWriter, r, http.StatusInternalServerError, err)
+				return
+			}
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
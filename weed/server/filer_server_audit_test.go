@@ -0,0 +1,132 @@
+package weed_server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
+	"github.com/seaweedfs/seaweedfs/weed/security"
+)
+
+func TestAuditLogRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := newAuditLog(path, 0)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	l.maxBytes = 400
+	for i := 0; i < 10; i++ {
+		if err = l.write(&auditRecord{Method: "GET", Path: "/dir/file.txt", StatusCode: 200, BytesTransferred: int64(i)}); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	count := 0
+	names, _ := filepath.Glob(path + "*")
+	if len(names) < 3 {
+		t.Errorf("%d audit log files, should be rotated", len(names))
+	}
+	for _, name := range names {
+		file, err := os.Open(name)
+		if err != nil {
+			t.Fatalf("open %s: %v", name, err)
+		}
+		stat, _ := file.Stat()
+		if stat.Size() > l.maxBytes {
+			t.Errorf("%s has %d bytes, over the limit", name, stat.Size())
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var record auditRecord
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil || record.Path != "/dir/file.txt" {
+				t.Errorf("%s line %q: %v", name, scanner.Text(), err)
+			}
+			count++
+		}
+		file.Close()
+	}
+	if count != 10 {
+		t.Errorf("%d records logged, want 10", count)
+	}
+}
+
+// lastByteRecorder copies the audit log when the last byte of the response is written
+type lastByteRecorder struct {
+	*httptest.ResponseRecorder
+	auditLogPath  string
+	contentLength int
+	auditLog      []byte
+}
+
+func (w *lastByteRecorder) Write(p []byte) (int, error) {
+	n, err := w.ResponseRecorder.Write(p)
+	if w.Body.Len() == w.contentLength {
+		w.auditLog, _ = os.ReadFile(w.auditLogPath)
+	}
+	return n, err
+}
+
+func TestAuditRequest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	l, err := newAuditLog(path, 0)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	fs := &FilerServer{auditLog: l, filerGuard: security.NewGuard(nil, "write-key", 0, "read-key", 0)}
+
+	r := httptest.NewRequest(http.MethodGet, "/dir/file.txt", nil)
+	r.Header.Set("Authorization", "BEARER "+string(security.GenJwtForFilerUser(security.SigningKey("read-key"), 10, "alice")))
+	r.Header.Set(s3_constants.AmzIdentityId, "mallory")
+	rec := &lastByteRecorder{ResponseRecorder: httptest.NewRecorder(), auditLogPath: path, contentLength: 11}
+
+	w, r, finish := fs.auditRequest(rec, r)
+	if !fs.maybeCheckJwtAuthorization(r, false) {
+		t.Fatalf("jwt should be accepted")
+	}
+	w.Header().Set("Content-Length", "11")
+	w.Write([]byte("hello "))
+	w.Write([]byte("world"))
+	finish()
+
+	var record auditRecord
+	if err := json.Unmarshal(rec.auditLog, &record); err != nil {
+		t.Fatalf("the audit record should be written before the last byte: %q %v", rec.auditLog, err)
+	}
+	if record.User != "alice" || record.BytesTransferred != 11 || record.StatusCode != http.StatusOK {
+		t.Errorf("audit record %+v, expected user alice from the jwt with 11 bytes", record)
+	}
+	if data, _ := os.ReadFile(path); strings.Count(string(data), "\n") != 1 {
+		t.Errorf("audit log %q should have one record", data)
+	}
+}
+
+func TestAuditRequestFailure(t *testing.T) {
+	l, err := newAuditLog(filepath.Join(t.TempDir(), "audit.log"), 0)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	l.file.Close()
+	fs := &FilerServer{auditLog: l}
+
+	rec := httptest.NewRecorder()
+	w, _, finish := fs.auditRequest(rec, httptest.NewRequest(http.MethodGet, "/dir/file.txt", nil))
+	w.Header().Set("Content-Length", "5")
+	if _, err := w.Write([]byte("hello")); err == nil {
+		t.Errorf("the response should fail when the audit record is not written")
+	}
+	if body := rec.Body.String(); body != "hell" {
+		t.Errorf("the response should not be completed, sent %q", body)
+	}
+	defer func() {
+		if recovered := recover(); recovered != http.ErrAbortHandler {
+			t.Errorf("the handler should be aborted, recovered %v", recovered)
+		}
+	}()
+	finish()
+}
@@ -3,6 +3,7 @@ package weed_server
 import (
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"os"
 	"strconv"
@@ -21,6 +22,8 @@ import (
 
 func (fs *FilerServer) filerHandler(w http.ResponseWriter, r *http.Request) {
 	start := time.Now()
+	w, r, audit := fs.auditRequest(w, r)
+	defer audit()
 	statusRecorder := stats.NewStatusResponseWriter(w)
 	w = statusRecorder
 	origin := r.Header.Get("Origin")
@@ -63,6 +66,10 @@ func (fs *FilerServer) filerHandler(w http.ResponseWriter, r *http.Request) {
 		stats.FilerRequestHistogram.WithLabelValues(stats.ChunkProxy).Observe(time.Since(start).Seconds())
 		return
 	}
+	if r.Method == http.MethodGet && r.URL.Path == filerWatchPath {
+		fs.WatchHandler(w, r)
+		return
+	}
 	requestMethod := r.Method
 	defer func(method *string) {
 		stats.FilerRequestCounter.WithLabelValues(*method, strconv.Itoa(statusRecorder.Status)).Inc()
@@ -87,8 +94,17 @@ func (fs *FilerServer) filerHandler(w http.ResponseWriter, r *http.Request) {
 			fs.DeleteHandler(w, r)
 		}
 	case http.MethodPost, http.MethodPut:
-		// wait until in flight data is less than the limit
 		contentLength := getContentLength(r)
+		if fs.option.UploadMaxBytes > 0 {
+			if contentLength > fs.option.UploadMaxBytes {
+				writeJsonError(w, r, http.StatusRequestEntityTooLarge, fmt.Errorf("upload of %d bytes exceeds the limit of %d bytes", contentLength, fs.option.UploadMaxBytes))
+				return
+			}
+			// the uploads without content length are cut off at the limit
+			r.Body = http.MaxBytesReader(w, r.Body, fs.option.UploadMaxBytes)
+		}
+
+		// wait until in flight data is less than the limit
 		fs.inFlightDataLimitCond.L.Lock()
 		inFlightDataSize := atomic.LoadInt64(&fs.inFlightDataSize)
 		for fs.option.ConcurrentUploadLimit != 0 && inFlightDataSize > fs.option.ConcurrentUploadLimit {
@@ -121,6 +137,8 @@ func (fs *FilerServer) filerHandler(w http.ResponseWriter, r *http.Request) {
 func (fs *FilerServer) readonlyFilerHandler(w http.ResponseWriter, r *http.Request) {
 
 	start := time.Now()
+	w, r, audit := fs.auditRequest(w, r)
+	defer audit()
 	statusRecorder := stats.NewStatusResponseWriter(w)
 	w = statusRecorder
 
@@ -147,6 +165,10 @@ func (fs *FilerServer) readonlyFilerHandler(w http.ResponseWriter, r *http.Reque
 		w.Header().Set("Access-Control-Allow-Headers", "OPTIONS, GET, HEAD")
 		w.Header().Set("Access-Control-Allow-Credentials", "true")
 	}
+	if r.Method == http.MethodGet && r.URL.Path == filerWatchPath {
+		fs.WatchHandler(w, r)
+		return
+	}
 	requestMethod := r.Method
 	defer func(method *string) {
 		stats.FilerRequestCounter.WithLabelValues(*method, strconv.Itoa(statusRecorder.Status)).Inc()
@@ -219,6 +241,9 @@ func (fs *FilerServer) maybeCheckJwtAuthorization(r *http.Request, isWrite bool)
 		glog.V(1).Infof("jwt invalid from %s: %v", r.RemoteAddr, tokenStr)
 		return false
 	} else {
+		if claims, ok := token.Claims.(*security.SeaweedFilerClaims); ok {
+			setAuditUser(r, claims.Subject)
+		}
 		return true
 	}
 }
@@ -232,3 +257,10 @@ func (fs *FilerServer) filerHealthzHandler(w http.ResponseWriter, r *http.Reques
 		w.WriteHeader(http.StatusOK)
 	}
 }
+
+// isUploadTooLarge tells whether the upload is cut off by the -uploadMaxMB limit
+func isUploadTooLarge(err error) bool {
+	var maxBytesError *http.MaxBytesError
+	// the read errors are wrapped as text
+	return errors.As(err, &maxBytesError) || strings.Contains(err.Error(), "http: request body too large")
+}
@@ -15,10 +15,10 @@ var (
 )
 
 func init() {
-	client = &http.Client{Transport: &http.Transport{
+	client = &http.Client{Transport: util.NewInternalTransport(&http.Transport{
 		MaxIdleConns:        1024,
 		MaxIdleConnsPerHost: 1024,
-	}}
+	})}
 }
 
 func (fs *FilerServer) maybeAddVolumeJwtAuthorization(r *http.Request, fileId string, isWrite bool) {
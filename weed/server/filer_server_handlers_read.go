@@ -50,17 +50,19 @@ func checkPreconditions(w http.ResponseWriter, r *http.Request, entry *filer.Ent
 		return false
 	}
 	w.Header().Set("Last-Modified", entry.Attr.Mtime.UTC().Format(http.TimeFormat))
+	// the http dates have second precision, while the mtime keeps nanoseconds
+	mtime := entry.Attr.Mtime.Truncate(time.Second)
 
 	ifMatchETagHeader := r.Header.Get("If-Match")
 	ifUnmodifiedSinceHeader := r.Header.Get("If-Unmodified-Since")
 	if ifMatchETagHeader != "" {
-		if util.CanonicalizeETag(etag) != util.CanonicalizeETag(ifMatchETagHeader) {
+		if !etagMatches(etag, ifMatchETagHeader, false) {
 			w.WriteHeader(http.StatusPreconditionFailed)
 			return true
 		}
 	} else if ifUnmodifiedSinceHeader != "" {
 		if t, parseError := time.Parse(http.TimeFormat, ifUnmodifiedSinceHeader); parseError == nil {
-			if t.Before(entry.Attr.Mtime) {
+			if t.Before(mtime) {
 				w.WriteHeader(http.StatusPreconditionFailed)
 				return true
 			}
@@ -70,14 +72,14 @@ func checkPreconditions(w http.ResponseWriter, r *http.Request, entry *filer.Ent
 	ifNoneMatchETagHeader := r.Header.Get("If-None-Match")
 	ifModifiedSinceHeader := r.Header.Get("If-Modified-Since")
 	if ifNoneMatchETagHeader != "" {
-		if util.CanonicalizeETag(etag) == util.CanonicalizeETag(ifNoneMatchETagHeader) {
+		if etagMatches(etag, ifNoneMatchETagHeader, true) {
 			SetEtag(w, etag)
 			w.WriteHeader(http.StatusNotModified)
 			return true
 		}
 	} else if ifModifiedSinceHeader != "" {
 		if t, parseError := time.Parse(http.TimeFormat, ifModifiedSinceHeader); parseError == nil {
-			if !t.Before(entry.Attr.Mtime) {
+			if !t.Before(mtime) {
 				SetEtag(w, etag)
 				w.WriteHeader(http.StatusNotModified)
 				return true
@@ -88,8 +90,39 @@ func checkPreconditions(w http.ResponseWriter, r *http.Request, entry *filer.Ent
 	return false
 }
 
+// etagMatches tells whether the etag is in the comma separated If-Match or If-None-Match list, or the list is "*".
+// The weak comparison of If-None-Match ignores the "W/" prefix, which never matches with the strong comparison of If-Match.
+func etagMatches(etag, header string, weak bool) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = candidate[2:]
+		}
+		if util.CanonicalizeETag(candidate) == util.CanonicalizeETag(etag) {
+			return true
+		}
+	}
+	return false
+}
+
 func (fs *FilerServer) GetOrHeadHandler(w http.ResponseWriter, r *http.Request) {
 
+	if snapshot := r.URL.Query().Get("snapshot"); snapshot != "" {
+		snapshotId, err := strconv.ParseUint(snapshot, 10, 64)
+		if err != nil {
+			writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("invalid snapshot %s: %v", snapshot, err))
+			return
+		}
+		// read the entry as it was recorded in the snapshot
+		r.URL.Path = string(filer.SnapshotPath(snapshotId, util.FullPath(r.URL.Path)))
+	}
+
 	path := r.URL.Path
 	isForDirectory := strings.HasSuffix(path, "/")
 	if isForDirectory && len(path) > 1 {
@@ -231,6 +264,10 @@ func (fs *FilerServer) GetOrHeadHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if fs.recompressor != nil {
+		fs.recompressor.maybeRecompress(entry)
+	}
+
 	if rangeReq := r.Header.Get("Range"); rangeReq == "" {
 		ext := filepath.Ext(filename)
 		if len(ext) > 0 {
@@ -278,7 +315,7 @@ func (fs *FilerServer) GetOrHeadHandler(w http.ResponseWriter, r *http.Request)
 			}
 		}
 
-		streamFn, err := filer.PrepareStreamContentWithThrottler(fs.filer.MasterClient, fs.maybeGetVolumeReadJwtAuthorizationToken, chunks, offset, size, fs.option.DownloadMaxBytesPs)
+		streamFn, err := filer.PrepareStreamContentWithCache(fs.filer.MasterClient, fs.maybeGetVolumeReadJwtAuthorizationToken, chunks, offset, size, fs.option.DownloadMaxBytesPs, fs.filer.ReadCache)
 		if err != nil {
 			stats.FilerHandlerCounter.WithLabelValues(stats.ErrorReadStream).Inc()
 			glog.Errorf("failed to prepare stream content %s: %v", r.URL, err)
@@ -2,7 +2,9 @@ package weed_server
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -15,6 +17,7 @@ import (
 
 // listDirectoryHandler lists directories and folders under a directory
 // files are sorted by name and paginated via "lastFileName" and "limit".
+// "cursor" is the url safe base64 of "lastFileName", returned as "Cursor" for the next page.
 // sub directories are listed on the first page, when "lastFileName"
 // is empty.
 func (fs *FilerServer) listDirectoryHandler(w http.ResponseWriter, r *http.Request) {
@@ -31,12 +34,21 @@ func (fs *FilerServer) listDirectoryHandler(w http.ResponseWriter, r *http.Reque
 		path = path[:len(path)-1]
 	}
 
+	// the page is kept in memory, so it is limited
 	limit, limitErr := strconv.Atoi(r.FormValue("limit"))
-	if limitErr != nil {
+	if limitErr != nil || limit <= 0 || limit > fs.option.DirListingLimit {
 		limit = fs.option.DirListingLimit
 	}
 
 	lastFileName := r.FormValue("lastFileName")
+	if cursor := r.FormValue("cursor"); cursor != "" {
+		name, err := base64.RawURLEncoding.DecodeString(cursor)
+		if err != nil {
+			writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("invalid cursor %s: %v", cursor, err))
+			return
+		}
+		lastFileName = string(name)
+	}
 	namePattern := r.FormValue("namePattern")
 	namePatternExclude := r.FormValue("namePatternExclude")
 
@@ -57,6 +69,10 @@ func (fs *FilerServer) listDirectoryHandler(w http.ResponseWriter, r *http.Reque
 		lastFileName = entries[len(entries)-1].Name()
 		emptyFolder = false
 	}
+	var cursor string
+	if shouldDisplayLoadMore {
+		cursor = base64.RawURLEncoding.EncodeToString([]byte(lastFileName))
+	}
 
 	glog.V(4).Infof("listDirectory %s, last file %s, limit %d: %d items", path, lastFileName, limit, len(entries))
 
@@ -66,6 +82,7 @@ func (fs *FilerServer) listDirectoryHandler(w http.ResponseWriter, r *http.Reque
 			Entries               interface{}
 			Limit                 int
 			LastFileName          string
+			Cursor                string
 			ShouldDisplayLoadMore bool
 			EmptyFolder           bool
 		}{
@@ -73,6 +90,7 @@ func (fs *FilerServer) listDirectoryHandler(w http.ResponseWriter, r *http.Reque
 			entries,
 			limit,
 			lastFileName,
+			cursor,
 			shouldDisplayLoadMore,
 			emptyFolder,
 		})
@@ -85,6 +103,7 @@ func (fs *FilerServer) listDirectoryHandler(w http.ResponseWriter, r *http.Reque
 		Entries               interface{}
 		Limit                 int
 		LastFileName          string
+		Cursor                string
 		ShouldDisplayLoadMore bool
 		EmptyFolder           bool
 		ShowDirectoryDelete   bool
@@ -94,6 +113,7 @@ func (fs *FilerServer) listDirectoryHandler(w http.ResponseWriter, r *http.Reque
 		entries,
 		limit,
 		lastFileName,
+		cursor,
 		shouldDisplayLoadMore,
 		emptyFolder,
 		fs.option.ShowUIDirectoryDelete,
@@ -0,0 +1,145 @@
+package weed_server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/filer/leveldb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+	"github.com/spf13/viper"
+)
+
+type listDirectoryResult struct {
+	Entries               []*filer.Entry
+	Limit                 int
+	Cursor                string
+	ShouldDisplayLoadMore bool
+}
+
+func newListDirectoryTestServer(t *testing.T, dirListingLimit int, names ...string) *FilerServer {
+	v := viper.New()
+	v.Set("dir", t.TempDir())
+	store := &leveldb.LevelDBStore{}
+	if err := store.Initialize(v, ""); err != nil {
+		t.Fatalf("initialize store: %v", err)
+	}
+	t.Cleanup(store.Shutdown)
+
+	f := &filer.Filer{Store: filer.NewFilerStoreWrapper(store)}
+	for _, name := range names {
+		entry := &filer.Entry{
+			FullPath: util.FullPath("/dir/" + name),
+			Attr:     filer.Attr{Mtime: time.Now(), Crtime: time.Now(), Mode: 0644},
+		}
+		if err := f.Store.InsertEntry(context.Background(), entry); err != nil {
+			t.Fatalf("insert %s: %v", name, err)
+		}
+	}
+
+	return &FilerServer{
+		filer: f,
+		option: &FilerOption{
+			ExposeDirectoryData: true,
+			DirListingLimit:     dirListingLimit,
+		},
+	}
+}
+
+func listDirectory(t *testing.T, fs *FilerServer, query url.Values) (int, listDirectoryResult) {
+	r := httptest.NewRequest(http.MethodGet, "/dir/?"+query.Encode(), nil)
+	r.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	fs.listDirectoryHandler(w, r)
+
+	var result listDirectoryResult
+	if w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+			t.Fatalf("decode %s: %v", w.Body.String(), err)
+		}
+	}
+	return w.Code, result
+}
+
+func TestListDirectoryHandlerPagesByCursor(t *testing.T) {
+	names := []string{"a&b", "c", "d&lastFileName=x", "e", "f=g"}
+	fs := newListDirectoryTestServer(t, 2, names...)
+
+	var listed []string
+	query := url.Values{"limit": {"100"}}
+	for pages := 0; ; pages++ {
+		if pages > len(names) {
+			t.Fatalf("listing does not end, listed %v", listed)
+		}
+		code, result := listDirectory(t, fs, query)
+		if code != http.StatusOK {
+			t.Fatalf("list %v: status %d", query, code)
+		}
+		// the page size is capped by the dir listing limit
+		if result.Limit != 2 || len(result.Entries) > 2 {
+			t.Fatalf("limit %d with %d entries, want at most 2", result.Limit, len(result.Entries))
+		}
+		for _, entry := range result.Entries {
+			listed = append(listed, entry.Name())
+		}
+		if !result.ShouldDisplayLoadMore {
+			if result.Cursor != "" {
+				t.Errorf("last page has cursor %q", result.Cursor)
+			}
+			break
+		}
+		if result.Cursor == "" {
+			t.Fatalf("page %d has more entries but no cursor", pages)
+		}
+		query = url.Values{"limit": {"100"}, "cursor": {result.Cursor}}
+	}
+
+	if len(listed) != len(names) {
+		t.Fatalf("listed %v, want %v", listed, names)
+	}
+	for i := range names {
+		if listed[i] != names[i] {
+			t.Errorf("listed %v, want %v", listed, names)
+			break
+		}
+	}
+}
+
+func TestListDirectoryHandlerBadCursor(t *testing.T) {
+	fs := newListDirectoryTestServer(t, 2, "a")
+
+	if code, _ := listDirectory(t, fs, url.Values{"cursor": {"not base64!"}}); code != http.StatusBadRequest {
+		t.Errorf("bad cursor: status %d, want %d", code, http.StatusBadRequest)
+	}
+}
+
+func TestListDirectoryHandlerLimit(t *testing.T) {
+	fs := newListDirectoryTestServer(t, 3, "a", "b", "c", "d")
+
+	tests := []struct {
+		limit     string
+		wantLimit int
+	}{
+		{"", 3},
+		{"1", 1},
+		{"3", 3},
+		{"4", 3},
+		{"-1", 3},
+		{"abc", 3},
+	}
+	for _, tt := range tests {
+		code, result := listDirectory(t, fs, url.Values{"limit": {tt.limit}})
+		if code != http.StatusOK {
+			t.Errorf("limit %q: status %d", tt.limit, code)
+			continue
+		}
+		if result.Limit != tt.wantLimit || len(result.Entries) != tt.wantLimit {
+			t.Errorf("limit %q: got limit %d with %d entries, want %d", tt.limit, result.Limit, len(result.Entries), tt.wantLimit)
+		}
+	}
+}
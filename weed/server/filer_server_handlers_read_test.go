@@ -0,0 +1,66 @@
+package weed_server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+)
+
+func TestEtagMatches(t *testing.T) {
+	etag := "c228d16bcb82c2021244bb49b9e180b8"
+	tests := []struct {
+		header string
+		weak   bool
+		want   bool
+	}{
+		{`"c228d16bcb82c2021244bb49b9e180b8"`, false, true},
+		{`c228d16bcb82c2021244bb49b9e180b8`, false, true},
+		{`"abc"`, false, false},
+		{`"abc", "c228d16bcb82c2021244bb49b9e180b8"`, false, true},
+		{`*`, false, true},
+		{`W/"c228d16bcb82c2021244bb49b9e180b8"`, false, false},
+		{`W/"c228d16bcb82c2021244bb49b9e180b8"`, true, true},
+		{`"abc",W/"def"`, true, false},
+	}
+	for _, tt := range tests {
+		if got := etagMatches(etag, tt.header, tt.weak); got != tt.want {
+			t.Errorf("etagMatches(%s, weak %v) = %v, want %v", tt.header, tt.weak, got, tt.want)
+		}
+	}
+}
+
+func TestCheckPreconditionsModifiedSince(t *testing.T) {
+	mtime := time.Date(2024, 5, 6, 7, 8, 9, 123456789, time.UTC)
+	entry := &filer.Entry{Attr: filer.Attr{Mtime: mtime}}
+	lastModified := mtime.Format(http.TimeFormat)
+	before := mtime.Add(-time.Second).Format(http.TimeFormat)
+
+	tests := []struct {
+		header     string
+		value      string
+		stop       bool
+		wantStatus int
+	}{
+		{"If-Unmodified-Since", lastModified, false, http.StatusOK},
+		{"If-Unmodified-Since", before, true, http.StatusPreconditionFailed},
+		{"If-Modified-Since", lastModified, true, http.StatusNotModified},
+		{"If-Modified-Since", before, false, http.StatusOK},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/a.txt", nil)
+		r.Header.Set(tt.header, tt.value)
+		w := httptest.NewRecorder()
+		if stop := checkPreconditions(w, r, entry); stop != tt.stop {
+			t.Errorf("%s: %s stopped %v, want %v", tt.header, tt.value, stop, tt.stop)
+		}
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s: %s status %d, want %d", tt.header, tt.value, w.Code, tt.wantStatus)
+		}
+		if w.Header().Get("Last-Modified") != lastModified {
+			t.Errorf("Last-Modified %s, want %s", w.Header().Get("Last-Modified"), lastModified)
+		}
+	}
+}
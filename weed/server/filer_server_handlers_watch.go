@@ -0,0 +1,226 @@
+package weed_server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+const (
+	filerWatchPath      = "/filer/watch"
+	filerWatchKeepAlive = 30 * time.Second
+)
+
+type filerWatchEvent struct {
+	EventType   string `json:"eventType"`
+	Path        string `json:"path"`
+	OldPath     string `json:"oldPath,omitempty"`
+	IsDirectory bool   `json:"isDirectory"`
+	Size        uint64 `json:"size"`
+	ModifiedAt  string `json:"modifiedAt,omitempty"`
+}
+
+// WatchHandler streams the metadata changes under the directories matching the "dir" parameter as server-sent events.
+// The "dir" parameter can have the wildcard characters '*' and '?', which do not match '/'.
+// The browsers can not set the headers for EventSource, so the jwt can also be passed as the "token" parameter.
+// The event id is the change timestamp in nanoseconds, to resume from the Last-Event-ID after reconnecting.
+func (fs *FilerServer) WatchHandler(w http.ResponseWriter, r *http.Request) {
+
+	query := r.URL.Query()
+	if token := query.Get("token"); token != "" && query.Get("jwt") == "" {
+		query.Set("jwt", token)
+		r.URL.RawQuery = query.Encode()
+	}
+	if !fs.maybeCheckJwtAuthorization(r, false) {
+		writeJsonError(w, r, http.StatusUnauthorized, errors.New("wrong jwt"))
+		return
+	}
+	if _, ok := w.(http.Flusher); !ok {
+		writeJsonError(w, r, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+
+	dir := query.Get("dir")
+	if dir == "" {
+		dir = "/"
+	}
+	if !strings.HasPrefix(dir, "/") {
+		writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("dir %s should be an absolute path", dir))
+		return
+	}
+	dir = path.Clean(dir)
+	if _, err := filepath.Match(dir, "/"); err != nil {
+		writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("dir %s: %v", dir, err))
+		return
+	}
+	// the subscription is narrowed down to the directory before the first wildcard
+	pathPrefix := dir
+	if i := strings.IndexAny(dir, "*?["); i >= 0 {
+		pathPrefix = dir[:strings.LastIndex(dir[:i], "/")+1]
+	}
+
+	sinceNs := time.Now().UnixNano()
+	if lastEventId := r.Header.Get("Last-Event-ID"); lastEventId != "" {
+		if tsNs, err := strconv.ParseInt(lastEventId, 10, 64); err == nil {
+			sinceNs = tsNs
+		}
+	}
+	clientId := util.RandomInt32()
+	for clientId == 0 {
+		clientId = util.RandomInt32()
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	ctx := r.Context()
+	if addr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr); err == nil {
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: addr})
+	}
+	stream := &filerWatchStream{ctx: ctx, w: w, dir: dir}
+	if err := stream.write(": watching %s\n\n", dir); err != nil {
+		return
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		keepAlive := time.NewTicker(filerWatchKeepAlive)
+		defer keepAlive.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				// nudge the subscription waiting for new events, until it notices the client is gone
+				fs.deleteClient("", "watch:sse", clientId, 0)
+				for {
+					fs.filer.MetaAggregator.ListenersCond.Broadcast()
+					select {
+					case <-done:
+						return
+					case <-time.After(time.Second):
+					}
+				}
+			case <-keepAlive.C:
+				stream.write(":\n\n")
+			}
+		}
+	}()
+
+	err := fs.SubscribeMetadata(&filer_pb.SubscribeMetadataRequest{
+		ClientName: "watch:sse",
+		PathPrefix: pathPrefix,
+		SinceNs:    sinceNs,
+		ClientId:   clientId,
+	}, stream)
+	if err != nil && ctx.Err() == nil {
+		glog.V(0).Infof("watch %s from %s: %v", dir, r.RemoteAddr, err)
+	}
+}
+
+// filerWatchStream converts the metadata subscription responses to server-sent events.
+// The keep alive comments are written from another goroutine.
+type filerWatchStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	sync.Mutex
+	w   http.ResponseWriter
+	dir string
+}
+
+func (s *filerWatchStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *filerWatchStream) Send(resp *filer_pb.SubscribeMetadataResponse) error {
+	event, found := toFilerWatchEvent(s.dir, toWatchEvent(resp))
+	if !found {
+		return nil
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return s.write("id: %d\ndata: %s\n\n", resp.TsNs, data)
+}
+
+func (s *filerWatchStream) write(format string, args ...interface{}) error {
+	s.Lock()
+	defer s.Unlock()
+	if _, err := fmt.Fprintf(s.w, format, args...); err != nil {
+		return err
+	}
+	s.w.(http.Flusher).Flush()
+	return nil
+}
+
+// toFilerWatchEvent keeps the events with the old or new path under a directory matching the pattern
+func toFilerWatchEvent(pattern string, watchEvent *filer_pb.WatchEvent) (event filerWatchEvent, found bool) {
+	if watchEvent == nil {
+		return
+	}
+	entry := watchEvent.Entry
+	event.Path = string(util.NewFullPath(watchEvent.Directory, entry.Name))
+	switch watchEvent.EventType {
+	case filer_pb.WatchEvent_CREATE:
+		event.EventType = "create"
+	case filer_pb.WatchEvent_DELETE:
+		event.EventType = "delete"
+	default:
+		event.EventType = "update"
+		newParentPath := watchEvent.NewParentPath
+		if newParentPath == "" {
+			newParentPath = watchEvent.Directory
+		}
+		if oldPath := string(util.NewFullPath(watchEvent.Directory, watchEvent.OldEntry.Name)); oldPath != string(util.NewFullPath(newParentPath, entry.Name)) {
+			event.EventType = "rename"
+			event.OldPath, event.Path = oldPath, string(util.NewFullPath(newParentPath, entry.Name))
+		}
+	}
+
+	if strings.HasPrefix(event.Path, filer.SystemLogDir) {
+		return
+	}
+	if !isUnderWatchedDirectory(pattern, event.Path) && (event.OldPath == "" || !isUnderWatchedDirectory(pattern, event.OldPath)) {
+		return
+	}
+
+	event.IsDirectory = entry.IsDirectory
+	event.Size = filer.FileSize(entry)
+	if entry.Attributes != nil && entry.Attributes.Mtime > 0 {
+		event.ModifiedAt = time.Unix(entry.Attributes.Mtime, 0).UTC().Format(time.RFC3339)
+	}
+	return event, true
+}
+
+// isUnderWatchedDirectory checks whether any parent directory of the path matches the pattern
+func isUnderWatchedDirectory(pattern string, fullPath string) bool {
+	for dir, _ := util.FullPath(fullPath).DirAndName(); ; dir, _ = util.FullPath(dir).DirAndName() {
+		if matched, _ := filepath.Match(pattern, dir); matched {
+			return true
+		}
+		if dir == "/" {
+			return false
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package weed_server
+
+import (
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+)
+
+func TestToFilerWatchEvent(t *testing.T) {
+	a := &filer_pb.Entry{Name: "a.jpg", Attributes: &filer_pb.FuseAttributes{FileSize: 3, Mtime: 1700000000}}
+	b := &filer_pb.Entry{Name: "b.jpg", Attributes: &filer_pb.FuseAttributes{FileSize: 5}}
+
+	tests := []struct {
+		pattern   string
+		event     *filer_pb.WatchEvent
+		eventType string
+		path      string
+		oldPath   string
+	}{
+		{"/photos", &filer_pb.WatchEvent{EventType: filer_pb.WatchEvent_CREATE, Directory: "/photos", Entry: a}, "create", "/photos/a.jpg", ""},
+		{"/photos", &filer_pb.WatchEvent{EventType: filer_pb.WatchEvent_DELETE, Directory: "/photos/2024", Entry: a}, "delete", "/photos/2024/a.jpg", ""},
+		{"/photos", &filer_pb.WatchEvent{EventType: filer_pb.WatchEvent_UPDATE, Directory: "/photos", Entry: a, OldEntry: a}, "update", "/photos/a.jpg", ""},
+		{"/photos", &filer_pb.WatchEvent{EventType: filer_pb.WatchEvent_UPDATE, Directory: "/photos", Entry: b, OldEntry: a}, "rename", "/photos/b.jpg", "/photos/a.jpg"},
+		{"/photos", &filer_pb.WatchEvent{EventType: filer_pb.WatchEvent_UPDATE, Directory: "/tmp", Entry: a, OldEntry: a, NewParentPath: "/photos"}, "rename", "/photos/a.jpg", "/tmp/a.jpg"},
+		{"/photos/*/thumbs", &filer_pb.WatchEvent{EventType: filer_pb.WatchEvent_CREATE, Directory: "/photos/2024/thumbs", Entry: a}, "create", "/photos/2024/thumbs/a.jpg", ""},
+		{"/", &filer_pb.WatchEvent{EventType: filer_pb.WatchEvent_CREATE, Directory: "/", Entry: a}, "create", "/a.jpg", ""},
+	}
+	for i, tt := range tests {
+		event, found := toFilerWatchEvent(tt.pattern, tt.event)
+		if !found {
+			t.Fatalf("case %d: event not found", i)
+		}
+		if event.EventType != tt.eventType || event.Path != tt.path || event.OldPath != tt.oldPath {
+			t.Errorf("case %d: unexpected event %+v", i, event)
+		}
+	}
+
+	if event, _ := toFilerWatchEvent("/photos", tests[0].event); event.Size != 3 || event.ModifiedAt != "2023-11-14T22:13:20Z" {
+		t.Errorf("unexpected size or modified time %+v", event)
+	}
+
+	for i, event := range []*filer_pb.WatchEvent{
+		{EventType: filer_pb.WatchEvent_CREATE, Directory: "/photos2", Entry: a},
+		{EventType: filer_pb.WatchEvent_CREATE, Directory: "/", Entry: &filer_pb.Entry{Name: "photos"}},
+		{EventType: filer_pb.WatchEvent_CREATE, Directory: "/photos/2024", Entry: a},
+	} {
+		pattern := "/photos"
+		if i == 2 {
+			pattern = "/photos/*/thumbs"
+		}
+		if _, found := toFilerWatchEvent(pattern, event); found {
+			t.Errorf("case %d: event %+v should be skipped", i, event)
+		}
+	}
+	if _, found := toFilerWatchEvent("/", nil); found {
+		t.Errorf("nil event should be skipped")
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
 
+	"github.com/seaweedfs/seaweedfs/weed/filer"
 	"github.com/seaweedfs/seaweedfs/weed/glog"
 	"github.com/seaweedfs/seaweedfs/weed/operation"
 	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
@@ -70,7 +71,7 @@ func (fs *FilerServer) assignNewFileInfo(so *operation.StorageOption) (fileId, u
 
 func (fs *FilerServer) PostHandler(w http.ResponseWriter, r *http.Request, contentLength int64) {
 
-	ctx := context.Background()
+	ctx := fs.maybeBypassWorm(context.Background(), r)
 
 	destination := r.RequestURI
 	if finalDestination := r.Header.Get(s3_constants.SeaweedStorageDestinationHeader); finalDestination != "" {
@@ -98,6 +99,11 @@ func (fs *FilerServer) PostHandler(w http.ResponseWriter, r *http.Request, conte
 		}
 		return
 	}
+	if err = fs.filer.Quotas.CheckHardLimit(so.Collection); err != nil {
+		glog.V(1).Infoln("post", r.RequestURI, ":", err.Error())
+		writeJsonError(w, r, http.StatusInsufficientStorage, err)
+		return
+	}
 
 	if util.FullPath(r.URL.Path).IsLongerFileName(so.MaxFileNameLength) {
 		glog.V(1).Infoln("post", r.RequestURI, ": ", "entry name too long")
@@ -114,6 +120,16 @@ func (fs *FilerServer) PostHandler(w http.ResponseWriter, r *http.Request, conte
 		so.SaveInside = true
 	}
 
+	if !strings.HasSuffix(r.URL.Path, "/") && !query.Has("bypassWorm") {
+		// reject overwriting a retained file before uploading the content
+		if existing, findErr := fs.filer.FindEntry(ctx, util.FullPath(r.URL.Path)); findErr == nil {
+			if err = fs.filer.CheckWormRetention(ctx, existing, "overwrite"); err != nil {
+				writeJsonError(w, r, http.StatusForbidden, err)
+				return
+			}
+		}
+	}
+
 	if query.Has("mv.from") {
 		fs.move(ctx, w, r, so)
 	} else {
@@ -159,6 +175,10 @@ func (fs *FilerServer) move(ctx context.Context, w http.ResponseWriter, r *http.
 		writeJsonError(w, r, http.StatusBadRequest, err)
 		return
 	}
+	if err = fs.filer.CheckWormRetentionRecursively(ctx, srcEntry, "move"); err != nil {
+		writeJsonError(w, r, http.StatusForbidden, err)
+		return
+	}
 
 	oldDir, oldName := srcPath.DirAndName()
 	newDir, newName := dstPath.DirAndName()
@@ -211,12 +231,17 @@ func (fs *FilerServer) DeleteHandler(w http.ResponseWriter, r *http.Request) {
 		objectPath = objectPath[0 : len(objectPath)-1]
 	}
 
-	err := fs.filer.DeleteEntryMetaAndData(context.Background(), util.FullPath(objectPath), isRecursive, ignoreRecursiveError, !skipChunkDeletion, false, nil)
+	ctx := fs.maybeBypassWorm(context.Background(), r)
+	err := fs.filer.DeleteEntryMetaAndData(ctx, util.FullPath(objectPath), isRecursive, ignoreRecursiveError, !skipChunkDeletion, false, nil)
 	if err != nil {
 		if err == filer_pb.ErrNotFound {
 			writeJsonQuiet(w, r, http.StatusNoContent, nil)
 			return
 		}
+		if errors.Is(err, filer.ErrWormRetained) {
+			writeJsonError(w, r, http.StatusForbidden, err)
+			return
+		}
 		glog.V(1).Infoln("deleting", objectPath, ":", err.Error())
 		writeJsonError(w, r, http.StatusInternalServerError, err)
 		return
@@ -288,3 +313,15 @@ func (fs *FilerServer) detectStorageOption0(requestURI, qCollection, qReplicatio
 
 	return so, err
 }
+
+// maybeBypassWorm lets the request with bypassWorm=true from the WORM admin white list change the retained files
+func (fs *FilerServer) maybeBypassWorm(ctx context.Context, r *http.Request) context.Context {
+	if r.URL.Query().Get("bypassWorm") != "true" {
+		return ctx
+	}
+	if !fs.wormAdminGuard.IsWhiteListed(r) {
+		glog.V(0).Infof("audit: %s is not allowed to bypass WORM for %s %s", r.RemoteAddr, r.Method, r.URL.Path)
+		return ctx
+	}
+	return filer.WithWormBypass(ctx, r.RemoteAddr)
+}
@@ -8,6 +8,7 @@ import (
 	//"github.com/seaweedfs/seaweedfs/weed/s3api"
 	"github.com/seaweedfs/seaweedfs/weed/s3api/s3_constants"
 	"io"
+	"mime"
 	"net/http"
 	"os"
 	"path"
@@ -49,8 +50,12 @@ func (fs *FilerServer) autoChunk(ctx context.Context, w http.ResponseWriter, r *
 		reply, md5bytes, err = fs.doPutAutoChunk(ctx, w, r, chunkSize, contentLength, so)
 	}
 	if err != nil {
-		if strings.HasPrefix(err.Error(), "read input:") || err.Error() == io.ErrUnexpectedEOF.Error() {
+		if isUploadTooLarge(err) {
+			writeJsonError(w, r, http.StatusRequestEntityTooLarge, fmt.Errorf("upload exceeds the limit of %d bytes", fs.option.UploadMaxBytes))
+		} else if strings.HasPrefix(err.Error(), "read input:") || err.Error() == io.ErrUnexpectedEOF.Error() {
 			writeJsonError(w, r, util.HttpStatusCancelled, err)
+		} else if errors.Is(err, filer.ErrWormRetained) {
+			writeJsonError(w, r, http.StatusForbidden, err)
 		} else if strings.HasSuffix(err.Error(), "is a file") || strings.HasSuffix(err.Error(), "already exists") {
 			writeJsonError(w, r, http.StatusConflict, err)
 		} else {
@@ -84,17 +89,25 @@ func (fs *FilerServer) doPostAutoChunk(ctx context.Context, w http.ResponseWrite
 	if contentType == "application/octet-stream" {
 		contentType = ""
 	}
+	var reader io.Reader = part1
+	if contentType == "" && fs.option.DetectContentType {
+		extFileName := fileName
+		if extFileName == "" {
+			extFileName = r.URL.Path
+		}
+		contentType, reader = detectContentType(extFileName, reader)
+	}
 
 	if so.SaveInside {
 		buf := bufPool.Get().(*bytes.Buffer)
 		buf.Reset()
-		buf.ReadFrom(part1)
+		buf.ReadFrom(reader)
 		filerResult, replyerr = fs.saveMetaData(ctx, r, fileName, contentType, so, nil, nil, 0, buf.Bytes())
 		bufPool.Put(buf)
 		return
 	}
 
-	fileChunks, md5Hash, chunkOffset, err, smallContent := fs.uploadReaderToChunks(w, r, part1, chunkSize, fileName, contentType, contentLength, so)
+	fileChunks, md5Hash, chunkOffset, err, smallContent := fs.uploadReaderToChunks(w, r, reader, chunkSize, fileName, contentType, contentLength, so)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -120,8 +133,12 @@ func (fs *FilerServer) doPutAutoChunk(ctx context.Context, w http.ResponseWriter
 	if contentType == "application/octet-stream" {
 		contentType = ""
 	}
+	var reader io.Reader = r.Body
+	if contentType == "" && fs.option.DetectContentType {
+		contentType, reader = detectContentType(fileName, reader)
+	}
 
-	fileChunks, md5Hash, chunkOffset, err, smallContent := fs.uploadReaderToChunks(w, r, r.Body, chunkSize, fileName, contentType, contentLength, so)
+	fileChunks, md5Hash, chunkOffset, err, smallContent := fs.uploadReaderToChunks(w, r, reader, chunkSize, fileName, contentType, contentLength, so)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -181,7 +198,7 @@ func (fs *FilerServer) saveMetaData(ctx context.Context, r *http.Request, fileNa
 		}
 	}
 
-	var entry *filer.Entry
+	var entry, overwrittenEntry *filer.Entry
 	var newChunks []*filer_pb.FileChunk
 	var mergedChunks []*filer_pb.FileChunk
 
@@ -215,6 +232,9 @@ func (fs *FilerServer) saveMetaData(ctx context.Context, r *http.Request, fileNa
 
 	} else {
 		glog.V(4).Infoln("saving", path)
+		if fs.filer.ChunkDedup != nil && len(fileChunks) > 0 {
+			overwrittenEntry, _ = fs.filer.FindEntry(ctx, util.FullPath(path))
+		}
 		newChunks = fileChunks
 		entry = &filer.Entry{
 			FullPath: util.FullPath(path),
@@ -279,6 +299,8 @@ func (fs *FilerServer) saveMetaData(ctx context.Context, r *http.Request, fileNa
 		replyerr = dbErr
 		filerResult.Error = dbErr.Error()
 		glog.V(0).Infof("failing to write %s to filer server : %v", path, dbErr)
+	} else {
+		fs.filer.ReleaseOverwrittenChunks(ctx, overwrittenEntry, fileChunks)
 	}
 	return filerResult, replyerr
 }
@@ -424,3 +446,25 @@ func SaveAmzMetaData(r *http.Request, existing map[string][]byte, isReplace bool
 	return
 
 }
+
+// detectContentType detects the content type from the first 512 bytes of the data.
+// The file extension is used for the generic types, since css, js or json are only detected as text.
+// The returned reader still reads the data from the beginning.
+func detectContentType(fileName string, reader io.Reader) (string, io.Reader) {
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(reader, head)
+	reader = io.MultiReader(bytes.NewReader(head[:n]), reader)
+	contentType := ""
+	if n > 0 {
+		contentType = http.DetectContentType(head[:n])
+	}
+	if contentType == "" || contentType == "application/octet-stream" || strings.HasPrefix(contentType, "text/plain") {
+		if byExtension := mime.TypeByExtension(path.Ext(fileName)); byExtension != "" {
+			return byExtension, reader
+		}
+	}
+	if contentType == "application/octet-stream" {
+		return "", reader
+	}
+	return contentType, reader
+}
@@ -0,0 +1,32 @@
+package weed_server
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestDetectContentType(t *testing.T) {
+	png := []byte("\x89PNG\x0D\x0A\x1A\x0A" + "rest of the image")
+	tests := []struct {
+		fileName string
+		data     []byte
+		want     string
+	}{
+		{"image.bin", png, "image/png"},
+		{"page.html", []byte("<html><body>hello</body></html>"), "text/html; charset=utf-8"},
+		{"style.css", []byte("body { color: red; }"), "text/css; charset=utf-8"},
+		{"notes", []byte("some notes"), "text/plain; charset=utf-8"},
+		{"data", []byte{0x00, 0x01, 0x02}, ""},
+		{"empty", nil, ""},
+	}
+	for _, tt := range tests {
+		contentType, reader := detectContentType(tt.fileName, bytes.NewReader(tt.data))
+		if contentType != tt.want {
+			t.Errorf("detectContentType(%s) = %q, want %q", tt.fileName, contentType, tt.want)
+		}
+		if data, _ := io.ReadAll(reader); !bytes.Equal(data, tt.data) {
+			t.Errorf("detectContentType(%s) reads %q, want %q", tt.fileName, data, tt.data)
+		}
+	}
+}
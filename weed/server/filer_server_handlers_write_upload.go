@@ -13,6 +13,7 @@ import (
 
 	"golang.org/x/exp/slices"
 
+	"github.com/seaweedfs/seaweedfs/weed/filer"
 	"github.com/seaweedfs/seaweedfs/weed/glog"
 	"github.com/seaweedfs/seaweedfs/weed/operation"
 	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
@@ -166,6 +167,19 @@ func (fs *FilerServer) doUpload(urlLocation string, limitedReader io.Reader, fil
 }
 
 func (fs *FilerServer) dataToChunk(fileName, contentType string, data []byte, chunkOffset int64, so *operation.StorageOption) ([]*filer_pb.FileChunk, error) {
+	// the chunks in ttl volumes expire with the volumes, and can not be shared
+	var contentKey []byte
+	if fs.filer.ChunkDedup != nil && so.TtlSeconds == 0 && len(data) > 0 {
+		contentKey = filer.DedupContentKey(data, so.Collection, so.Replication, so.DiskType)
+		if chunk := fs.filer.ChunkDedup.Acquire(contentKey, fs.isChunkVolumeFound); chunk != nil {
+			stats.FilerHandlerCounter.WithLabelValues(stats.ChunkDedup).Inc()
+			chunk.Offset = chunkOffset
+			chunk.ModifiedTsNs = time.Now().UnixNano()
+			chunk.Fid, _ = filer_pb.ToFileIdObject(chunk.FileId)
+			return []*filer_pb.FileChunk{chunk}, nil
+		}
+	}
+
 	dataReader := util.NewBytesReader(data)
 
 	// retry to assign a different file id
@@ -208,5 +222,14 @@ func (fs *FilerServer) dataToChunk(fileName, contentType string, data []byte, ch
 	if uploadResult.Size == 0 {
 		return nil, nil
 	}
-	return []*filer_pb.FileChunk{uploadResult.ToPbFileChunk(fileId, chunkOffset, time.Now().UnixNano())}, nil
+	chunk := uploadResult.ToPbFileChunk(fileId, chunkOffset, time.Now().UnixNano())
+	if contentKey != nil {
+		fs.filer.ChunkDedup.Register(contentKey, chunk)
+	}
+	return []*filer_pb.FileChunk{chunk}, nil
+}
+
+func (fs *FilerServer) isChunkVolumeFound(chunk *filer_pb.FileChunk) bool {
+	_, err := fs.filer.MasterClient.LookupFileId(chunk.GetFileIdString())
+	return err == nil
 }
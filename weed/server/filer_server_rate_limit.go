@@ -0,0 +1,151 @@
+package weed_server
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/seaweedfs/seaweedfs/weed/stats"
+)
+
+const clientRateLimiterIdleTimeout = 10 * time.Minute
+
+type clientLimiter struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// clientRateLimiter keeps one token bucket for each client ip.
+type clientRateLimiter struct {
+	sync.Mutex
+	limit    rate.Limit
+	burst    int
+	limiters map[string]*clientLimiter
+	trusted  []*net.IPNet // the proxies and internal clients, e.g. the s3 gateways and mounts
+}
+
+// newClientRateLimiter limits each client to requestsPerMinute. The whiteList has the ip addresses
+// or CIDR ranges of the trusted clients, which are not limited themselves.
+func newClientRateLimiter(requestsPerMinute, burst int, whiteList []string) (*clientRateLimiter, error) {
+	if burst <= 0 {
+		burst = 1
+	}
+	l := &clientRateLimiter{
+		limit:    rate.Limit(float64(requestsPerMinute) / 60),
+		burst:    burst,
+		limiters: make(map[string]*clientLimiter),
+	}
+	for _, entry := range whiteList {
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("rate limit white list: %v", err)
+		}
+		l.trusted = append(l.trusted, ipNet)
+	}
+	go l.loopRemoveIdleClients()
+	return l, nil
+}
+
+// clientOf returns the client ip to limit. The requests from the trusted clients are limited by the
+// first address in X-Forwarded-For, which the s3 gateway sets to its own client, or not at all without it.
+func (l *clientRateLimiter) clientOf(r *http.Request) (client string, limited bool) {
+	client, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		client = r.RemoteAddr
+	}
+	if !l.isTrusted(client) {
+		return client, true
+	}
+	forwardedFor, _, _ := strings.Cut(r.Header.Get("X-Forwarded-For"), ",")
+	if forwardedFor = strings.TrimSpace(forwardedFor); forwardedFor == "" {
+		return client, false
+	}
+	if host, _, err := net.SplitHostPort(forwardedFor); err == nil {
+		forwardedFor = host
+	}
+	return forwardedFor, true
+}
+
+func (l *clientRateLimiter) isTrusted(client string) bool {
+	ip := net.ParseIP(client)
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range l.trusted {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// allow takes one token from the client bucket, or returns how long to wait for the next token.
+func (l *clientRateLimiter) allow(client string, now time.Time) (bool, time.Duration) {
+	l.Lock()
+	c, found := l.limiters[client]
+	if !found {
+		c = &clientLimiter{limiter: rate.NewLimiter(l.limit, l.burst)}
+		l.limiters[client] = c
+	}
+	c.lastSeen = now
+	l.Unlock()
+
+	reservation := c.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+func (l *clientRateLimiter) loopRemoveIdleClients() {
+	for {
+		time.Sleep(clientRateLimiterIdleTimeout)
+		l.removeIdleClients(time.Now())
+	}
+}
+
+func (l *clientRateLimiter) removeIdleClients(now time.Time) {
+	l.Lock()
+	defer l.Unlock()
+	for client, c := range l.limiters {
+		if now.Sub(c.lastSeen) > clientRateLimiterIdleTimeout {
+			delete(l.limiters, client)
+		}
+	}
+}
+
+// rateLimit rejects the requests over the per client rate with 429 Too Many Requests.
+func (fs *FilerServer) rateLimit(handler http.HandlerFunc) http.HandlerFunc {
+	if fs.rateLimiter == nil {
+		return handler
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		client, limited := fs.rateLimiter.clientOf(r)
+		if !limited {
+			handler(w, r)
+			return
+		}
+		if ok, retryAfter := fs.rateLimiter.allow(client, time.Now()); !ok {
+			stats.FilerHandlerCounter.WithLabelValues(stats.ErrorRateLimited).Inc()
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			writeJsonError(w, r, http.StatusTooManyRequests, fmt.Errorf("too many requests from %s", client))
+			return
+		}
+		handler(w, r)
+	}
+}
@@ -0,0 +1,104 @@
+package weed_server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientRateLimiter(t *testing.T) {
+	l, err := newClientRateLimiter(60, 2, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.allow("10.0.0.1", now); !ok {
+			t.Fatalf("request %d within the burst was limited", i)
+		}
+	}
+	ok, retryAfter := l.allow("10.0.0.1", now)
+	if ok {
+		t.Fatalf("request over the burst was allowed")
+	}
+	if retryAfter <= 0 || retryAfter > time.Second {
+		t.Errorf("retry after %v, expected up to 1s", retryAfter)
+	}
+	if ok, _ = l.allow("10.0.0.2", now); !ok {
+		t.Errorf("another client was limited")
+	}
+	if ok, _ = l.allow("10.0.0.1", now.Add(time.Second)); !ok {
+		t.Errorf("request after the refill was limited")
+	}
+
+	l.removeIdleClients(now.Add(2 * clientRateLimiterIdleTimeout))
+	if len(l.limiters) != 0 {
+		t.Errorf("%d idle clients left", len(l.limiters))
+	}
+}
+
+func TestFilerRateLimitHandler(t *testing.T) {
+	rateLimiter, err := newClientRateLimiter(1, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := &FilerServer{rateLimiter: rateLimiter}
+	handler := fs.rateLimit(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/dir/file", nil)
+	request.RemoteAddr = "10.0.0.1:1234"
+	recorder := httptest.NewRecorder()
+	handler(recorder, request)
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("first request status %d", recorder.Code)
+	}
+
+	request.RemoteAddr = "10.0.0.1:5678"
+	recorder = httptest.NewRecorder()
+	handler(recorder, request)
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("second request status %d, expected %d", recorder.Code, http.StatusTooManyRequests)
+	}
+	if retryAfter := recorder.Header().Get("Retry-After"); retryAfter != "60" {
+		t.Errorf("Retry-After %q, expected 60", retryAfter)
+	}
+}
+
+func TestClientRateLimiterWhiteList(t *testing.T) {
+	if _, err := newClientRateLimiter(1, 1, []string{"10.0.0.0/33"}); err == nil {
+		t.Errorf("invalid CIDR range accepted")
+	}
+	l, err := newClientRateLimiter(1, 1, []string{"10.0.0.9", "192.168.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		remoteAddr   string
+		forwardedFor string
+		client       string
+		limited      bool
+	}{
+		{"10.0.0.1:1234", "", "10.0.0.1", true},
+		// the X-Forwarded-For of the untrusted clients is ignored
+		{"10.0.0.1:1234", "10.0.0.2", "10.0.0.1", true},
+		{"10.0.0.9:1234", "", "10.0.0.9", false},
+		// the s3 gateway forwards the ip and port of its client
+		{"10.0.0.9:1234", "172.16.0.1:5678", "172.16.0.1", true},
+		{"192.168.1.1:1234", "172.16.0.1, 10.0.0.9", "172.16.0.1", true},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/dir/file", nil)
+		r.RemoteAddr = tt.remoteAddr
+		if tt.forwardedFor != "" {
+			r.Header.Set("X-Forwarded-For", tt.forwardedFor)
+		}
+		if client, limited := l.clientOf(r); client != tt.client || limited != tt.limited {
+			t.Errorf("%s forwarding for %q: client %s limited %v, expected %s %v", tt.remoteAddr, tt.forwardedFor, client, limited, tt.client, tt.limited)
+		}
+	}
+}
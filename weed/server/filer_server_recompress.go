@@ -0,0 +1,220 @@
+package weed_server
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/karlseguin/ccache/v2"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/operation"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/stats"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+const (
+	recompressQueueSize   = 1024
+	recompressCheckedSize = 100000
+	// the checked chunks are checked again after a while, in case the migration failed
+	recompressCheckedTtl = 24 * time.Hour
+)
+
+// chunkRecompressor migrates the chunks stored as gzip to zstd in the background, after the files are read.
+// Only the chunks with the gzip Content-Encoding from the volume servers are migrated, so it is idempotent.
+type chunkRecompressor struct {
+	fs      *FilerServer
+	queue   chan util.FullPath
+	checked *ccache.Cache
+}
+
+func newChunkRecompressor(fs *FilerServer) *chunkRecompressor {
+	r := &chunkRecompressor{
+		fs:      fs,
+		queue:   make(chan util.FullPath, recompressQueueSize),
+		checked: ccache.New(ccache.Configure().MaxSize(recompressCheckedSize).ItemsToPrune(1000)),
+	}
+	go r.loop()
+	return r
+}
+
+// maybeRecompress queues the file to migrate in the background, if any of its chunks is not checked yet
+func (r *chunkRecompressor) maybeRecompress(entry *filer.Entry) {
+	if entry.IsDirectory() || entry.IsInRemoteOnly() || len(entry.GetChunks()) == 0 {
+		return
+	}
+	hasUnchecked := false
+	for _, chunk := range entry.GetChunks() {
+		if r.checked.Get(chunk.GetFileIdString()) == nil {
+			hasUnchecked = true
+			break
+		}
+	}
+	if !hasUnchecked {
+		return
+	}
+	select {
+	case r.queue <- entry.FullPath:
+	default:
+		glog.V(1).Infof("recompress queue is full, skip %s", entry.FullPath)
+	}
+}
+
+func (r *chunkRecompressor) loop() {
+	for fullPath := range r.queue {
+		if err := r.recompressEntry(context.Background(), fullPath); err != nil {
+			stats.FilerHandlerCounter.WithLabelValues(stats.ErrorRecompressChunk).Inc()
+			glog.Warningf("recompress %s: %v", fullPath, err)
+		}
+	}
+}
+
+func (r *chunkRecompressor) recompressEntry(ctx context.Context, fullPath util.FullPath) error {
+	f := r.fs.filer
+	entry, err := f.FindEntry(ctx, fullPath)
+	if err != nil {
+		if err == filer_pb.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+	if entry.IsDirectory() || entry.IsInRemoteOnly() || filer.HasChunkManifest(entry.GetChunks()) {
+		return nil
+	}
+	if f.CheckWormRetention(ctx, entry, "recompress") != nil {
+		return nil
+	}
+
+	so, err := r.fs.detectStorageOption(string(fullPath), "", "", entry.Attr.TtlSec, "", "", "", "")
+	if err != nil {
+		return err
+	}
+
+	chunks := entry.GetChunks()
+	newChunks := make([]*filer_pb.FileChunk, len(chunks))
+	var uploadedChunks []*filer_pb.FileChunk
+	for i, chunk := range chunks {
+		newChunks[i] = chunk
+		fileId := chunk.GetFileIdString()
+		if chunk.CipherKey != nil || r.checked.Get(fileId) != nil {
+			continue
+		}
+		newChunk, err := r.recompressChunk(chunk, so)
+		if err != nil {
+			f.DeleteUncommittedChunks(uploadedChunks)
+			return fmt.Errorf("chunk %s: %v", fileId, err)
+		}
+		r.checked.Set(fileId, true, recompressCheckedTtl)
+		if newChunk != nil {
+			r.checked.Set(newChunk.GetFileIdString(), true, recompressCheckedTtl)
+			newChunks[i] = newChunk
+			uploadedChunks = append(uploadedChunks, newChunk)
+		}
+	}
+	if len(uploadedChunks) == 0 {
+		return nil
+	}
+
+	// the file could be changed during the migration, and is only updated if it still has the same chunks.
+	// the old chunks are deleted after the entry is updated
+	updated, err := f.UpdateEntryIf(ctx, fullPath, func(latest *filer.Entry) bool {
+		if !isSameChunkList(latest.GetChunks(), chunks) {
+			return false
+		}
+		latest.Chunks = newChunks
+		return true
+	})
+	if err != nil || !updated {
+		f.DeleteUncommittedChunks(uploadedChunks)
+		return err
+	}
+	stats.FilerHandlerCounter.WithLabelValues(stats.ChunkRecompress).Add(float64(len(uploadedChunks)))
+	glog.V(1).Infof("recompressed %d chunks of %s to zstd", len(uploadedChunks), fullPath)
+	return nil
+}
+
+// recompressChunk uploads the chunk stored as gzip as a new zstd chunk, or returns nil if it is not stored as gzip
+func (r *chunkRecompressor) recompressChunk(chunk *filer_pb.FileChunk, so *operation.StorageOption) (*filer_pb.FileChunk, error) {
+	fileId := chunk.GetFileIdString()
+	urlStrings, err := r.fs.filer.MasterClient.GetLookupFileIdFunction()(fileId)
+	if err != nil {
+		return nil, err
+	}
+	if len(urlStrings) == 0 {
+		return nil, fmt.Errorf("no locations")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, urlStrings[0], nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+	if jwt := r.fs.maybeGetVolumeReadJwtAuthorizationToken(fileId); jwt != "" {
+		req.Header.Set("Authorization", "BEARER "+jwt)
+	}
+	resp, err := util.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer util.CloseResponse(resp)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("read %s: %s", urlStrings[0], resp.Status)
+	}
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		return nil, nil
+	}
+	var gzipped bytes.Buffer
+	if _, err = io.Copy(&gzipped, resp.Body); err != nil {
+		return nil, err
+	}
+	data, err := util.DecompressData(gzipped.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	compressed, err := util.ZstdData(data, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	newFileId, urlLocation, auth, err := r.fs.assignNewFileInfo(so)
+	if err != nil {
+		return nil, err
+	}
+	uploadResult, err, _ := operation.Upload(bytes.NewReader(compressed), &operation.UploadOption{
+		UploadUrl:         urlLocation,
+		IsInputCompressed: true,
+		ContentEncoding:   "zstd",
+		MimeType:          resp.Header.Get("Content-Type"),
+		Jwt:               auth,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if uploadResult.Error != "" {
+		return nil, fmt.Errorf("upload %s: %s", newFileId, uploadResult.Error)
+	}
+
+	newChunk := proto.Clone(chunk).(*filer_pb.FileChunk)
+	newChunk.FileId = newFileId
+	newChunk.Fid = nil
+	newChunk.IsCompressed = true
+	return newChunk, nil
+}
+
+func isSameChunkList(a, b []*filer_pb.FileChunk) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].GetFileIdString() != b[i].GetFileIdString() {
+			return false
+		}
+	}
+	return true
+}
@@ -78,6 +78,7 @@ func (ms *MasterServer) SendHeartbeat(stream master_pb.Seaweed_SendHeartbeatServ
 			}
 			for _, v := range dn.GetVolumes() {
 				message.DeletedVids = append(message.DeletedVids, uint32(v.Id))
+				ms.broadcastVolumeAssignmentEvent(master_pb.VolumeAssignmentEvent_DEAD_VOLUME, uint32(v.Id), v.Collection, dn)
 			}
 			for _, s := range dn.GetEcShards() {
 				message.DeletedVids = append(message.DeletedVids, uint32(s.VolumeId))
@@ -133,7 +134,7 @@ func (ms *MasterServer) SendHeartbeat(stream master_pb.Seaweed_SendHeartbeatServ
 			if heartbeat.Ip == "" {
 				continue
 			} // ToDo must be removed after update major version
-			dcName, rackName := ms.Topo.Configuration.Locate(heartbeat.Ip, heartbeat.DataCenter, heartbeat.Rack)
+			dcName, rackName := ms.Topo.Configuration.Locate(heartbeat.Ip, int(heartbeat.Port), heartbeat.DataCenter, heartbeat.Rack)
 			dc := ms.Topo.GetOrCreateDataCenter(dcName)
 			rack := dc.GetOrCreateRack(rackName)
 			dn = rack.GetOrCreateDataNode(heartbeat.Ip, int(heartbeat.Port), int(heartbeat.GrpcPort), heartbeat.PublicUrl, heartbeat.MaxVolumeCounts)
@@ -160,6 +161,12 @@ func (ms *MasterServer) SendHeartbeat(stream master_pb.Seaweed_SendHeartbeatServ
 		}
 
 		dn.AdjustMaxVolumeCounts(heartbeat.MaxVolumeCounts)
+		if heartbeat.Ip != "" {
+			// only the full heartbeats carry the volume server settings
+			dn.SetMaxVolumeSize(heartbeat.MaxVolumeSize)
+			dn.SetTags(heartbeat.Tags)
+			dn.SetReportedLocation(heartbeat.DataCenter, heartbeat.Rack)
+		}
 
 		glog.V(4).Infof("master received heartbeat %s", heartbeat.String())
 		stats.MasterReceivedHeartbeatCounter.WithLabelValues("total").Inc()
@@ -180,9 +187,11 @@ func (ms *MasterServer) SendHeartbeat(stream master_pb.Seaweed_SendHeartbeatServ
 			// process delta volume ids if exists for fast volume id updates
 			for _, volInfo := range heartbeat.NewVolumes {
 				message.NewVids = append(message.NewVids, volInfo.Id)
+				ms.broadcastVolumeAssignmentEvent(master_pb.VolumeAssignmentEvent_NEW_VOLUME, volInfo.Id, volInfo.Collection, dn)
 			}
 			for _, volInfo := range heartbeat.DeletedVolumes {
 				message.DeletedVids = append(message.DeletedVids, volInfo.Id)
+				ms.broadcastVolumeAssignmentEvent(master_pb.VolumeAssignmentEvent_DEAD_VOLUME, volInfo.Id, volInfo.Collection, dn)
 			}
 			// update master internal volume layouts
 			ms.Topo.IncrementalSyncDataNodeRegistration(heartbeat.NewVolumes, heartbeat.DeletedVolumes, dn)
@@ -190,7 +199,7 @@ func (ms *MasterServer) SendHeartbeat(stream master_pb.Seaweed_SendHeartbeatServ
 
 		if len(heartbeat.Volumes) > 0 || heartbeat.HasNoVolumes {
 			if heartbeat.Ip != "" {
-				dcName, rackName := ms.Topo.Configuration.Locate(heartbeat.Ip, heartbeat.DataCenter, heartbeat.Rack)
+				dcName, rackName := ms.Topo.Configuration.Locate(heartbeat.Ip, int(heartbeat.Port), heartbeat.DataCenter, heartbeat.Rack)
 				ms.Topo.DataNodeRegistration(dcName, rackName, dn)
 			}
 
@@ -201,10 +210,12 @@ func (ms *MasterServer) SendHeartbeat(stream master_pb.Seaweed_SendHeartbeatServ
 			for _, v := range newVolumes {
 				glog.V(0).Infof("master see new volume %d from %s", uint32(v.Id), dn.Url())
 				message.NewVids = append(message.NewVids, uint32(v.Id))
+				ms.broadcastVolumeAssignmentEvent(master_pb.VolumeAssignmentEvent_NEW_VOLUME, uint32(v.Id), v.Collection, dn)
 			}
 			for _, v := range deletedVolumes {
 				glog.V(0).Infof("master see deleted volume %d from %s", uint32(v.Id), dn.Url())
 				message.DeletedVids = append(message.DeletedVids, uint32(v.Id))
+				ms.broadcastVolumeAssignmentEvent(master_pb.VolumeAssignmentEvent_DEAD_VOLUME, uint32(v.Id), v.Collection, dn)
 			}
 		}
 
@@ -421,6 +432,7 @@ func (ms *MasterServer) GetMasterConfiguration(ctx context.Context, req *master_
 		VolumeSizeLimitMB:      uint32(ms.option.VolumeSizeLimitMB),
 		VolumePreallocate:      ms.option.VolumePreallocate,
 		Leader:                 string(leader),
+		VacuumDisabled:         ms.Topo.IsVacuumDisabled(),
 	}
 
 	return resp, nil
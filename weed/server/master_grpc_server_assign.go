@@ -10,6 +10,7 @@ import (
 
 	"github.com/seaweedfs/seaweedfs/weed/pb/master_pb"
 	"github.com/seaweedfs/seaweedfs/weed/security"
+	"github.com/seaweedfs/seaweedfs/weed/stats"
 	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
 	"github.com/seaweedfs/seaweedfs/weed/storage/super_block"
 	"github.com/seaweedfs/seaweedfs/weed/storage/types"
@@ -36,7 +37,13 @@ func (ms *MasterServer) StreamAssign(server master_pb.Seaweed_StreamAssignServer
 }
 func (ms *MasterServer) Assign(ctx context.Context, req *master_pb.AssignRequest) (*master_pb.AssignResponse, error) {
 
+	start := time.Now()
+	defer func() {
+		stats.MasterAssignHistogram.WithLabelValues("grpc").Observe(time.Since(start).Seconds())
+	}()
+
 	if !ms.Topo.IsLeader() {
+		assignRejected("grpc", stats.AssignNotLeader)
 		return nil, raft.NotLeaderError
 	}
 
@@ -49,10 +56,12 @@ func (ms *MasterServer) Assign(ctx context.Context, req *master_pb.AssignRequest
 	}
 	replicaPlacement, err := super_block.NewReplicaPlacementFromString(req.Replication)
 	if err != nil {
+		assignRejected("grpc", stats.AssignInvalidOption)
 		return nil, err
 	}
 	ttl, err := needle.ReadTTL(req.Ttl)
 	if err != nil {
+		assignRejected("grpc", stats.AssignInvalidOption)
 		return nil, err
 	}
 	diskType := types.ToDiskType(req.DiskType)
@@ -67,9 +76,11 @@ func (ms *MasterServer) Assign(ctx context.Context, req *master_pb.AssignRequest
 		Rack:               req.Rack,
 		DataNode:           req.DataNode,
 		MemoryMapMaxSizeMb: req.MemoryMapMaxSizeMb,
+		Tags:               req.Tags,
 	}
 
 	if !ms.Topo.DataCenterExists(option.DataCenter) {
+		assignRejected("grpc", stats.AssignNoDataCenter)
 		return nil, fmt.Errorf("data center %v not found in topology", option.DataCenter)
 	}
 
@@ -86,6 +97,7 @@ func (ms *MasterServer) Assign(ctx context.Context, req *master_pb.AssignRequest
 		if shouldGrow && !vl.HasGrowRequest() {
 			// if picked volume is almost full, trigger a volume-grow request
 			if ms.Topo.AvailableSpaceFor(option) <= 0 {
+				assignRejected("grpc", stats.AssignNoFreeVolumes)
 				return nil, fmt.Errorf("no free volumes left for " + option.String())
 			}
 			vl.AddGrowRequest()
@@ -126,5 +138,14 @@ func (ms *MasterServer) Assign(ctx context.Context, req *master_pb.AssignRequest
 			Replicas: replicas,
 		}, nil
 	}
+	if lastErr != nil {
+		assignRejected("grpc", stats.AssignNoWritableVolumes)
+	} else {
+		assignRejected("grpc", stats.AssignTimeout)
+	}
 	return nil, lastErr
 }
+
+func assignRejected(assignType, reason string) {
+	stats.MasterAssignRejectedCounter.WithLabelValues(assignType, reason).Inc()
+}
@@ -0,0 +1,97 @@
+package weed_server
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/seaweedfs/raft"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/master_pb"
+	"github.com/seaweedfs/seaweedfs/weed/topology"
+)
+
+// WatchVolumeAssignments streams volume placement changes to placement aware writers,
+// so they do not need to poll /dir/assign to notice new, full, or dead volumes.
+func (ms *MasterServer) WatchVolumeAssignments(req *master_pb.WatchVolumeAssignmentsRequest, stream master_pb.Seaweed_WatchVolumeAssignmentsServer) error {
+
+	if !ms.Topo.IsLeader() {
+		return raft.NotLeaderError
+	}
+
+	watcherName, eventChan := ms.addAssignmentWatcher(req.ClientName)
+	defer func() {
+		// consume event chan to avoid deadlock, go routine exit when event chan is closed
+		go func() {
+			for range eventChan {
+				// no op
+			}
+		}()
+		ms.deleteAssignmentWatcher(watcherName)
+	}()
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case event := <-eventChan:
+			if req.Collection != "" && req.Collection != event.Collection {
+				continue
+			}
+			if err := stream.Send(event); err != nil {
+				glog.V(0).Infof("=> watcher %v: %+v", watcherName, event)
+				return err
+			}
+		case <-ticker.C:
+			if !ms.Topo.IsLeader() {
+				return raft.NotLeaderError
+			}
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func (ms *MasterServer) broadcastVolumeAssignmentEvent(eventType master_pb.VolumeAssignmentEvent_EventType, vid uint32, collection string, dn *topology.DataNode) {
+	event := &master_pb.VolumeAssignmentEvent{
+		EventType:  eventType,
+		VolumeId:   vid,
+		Collection: collection,
+		Url:        dn.Url(),
+		PublicUrl:  dn.PublicUrl,
+		GrpcPort:   uint32(dn.GrpcPort),
+		DataCenter: dn.GetDataCenterId(),
+		TsNs:       time.Now().UnixNano(),
+	}
+	if dn.Parent() != nil {
+		event.Rack = string(dn.GetRack().Id())
+	}
+	ms.assignmentWatchersLock.RLock()
+	for _, ch := range ms.assignmentWatchers {
+		ch <- event
+	}
+	ms.assignmentWatchersLock.RUnlock()
+}
+
+func (ms *MasterServer) addAssignmentWatcher(clientName string) (watcherName string, eventChan chan *master_pb.VolumeAssignmentEvent) {
+	watcherName = clientName + "@" + uuid.New().String()
+	glog.V(0).Infof("+ assignment watcher %v", watcherName)
+
+	// buffered for the same reason as the KeepConnected message chan
+	eventChan = make(chan *master_pb.VolumeAssignmentEvent, 10000)
+
+	ms.assignmentWatchersLock.Lock()
+	ms.assignmentWatchers[watcherName] = eventChan
+	ms.assignmentWatchersLock.Unlock()
+	return
+}
+
+func (ms *MasterServer) deleteAssignmentWatcher(watcherName string) {
+	glog.V(0).Infof("- assignment watcher %v", watcherName)
+	ms.assignmentWatchersLock.Lock()
+	if eventChan, ok := ms.assignmentWatchers[watcherName]; ok {
+		close(eventChan)
+		delete(ms.assignmentWatchers, watcherName)
+	}
+	ms.assignmentWatchersLock.Unlock()
+}
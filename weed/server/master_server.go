@@ -27,6 +27,7 @@ import (
 	"github.com/seaweedfs/seaweedfs/weed/security"
 	"github.com/seaweedfs/seaweedfs/weed/sequence"
 	"github.com/seaweedfs/seaweedfs/weed/shell"
+	"github.com/seaweedfs/seaweedfs/weed/storage"
 	"github.com/seaweedfs/seaweedfs/weed/topology"
 	"github.com/seaweedfs/seaweedfs/weed/util"
 	"github.com/seaweedfs/seaweedfs/weed/wdclient"
@@ -37,6 +38,9 @@ const (
 	SequencerSnowflakeId = "master.sequencer.sequencer_snowflake_id"
 )
 
+// proxies the requests to the leader, over https if the masters serve https
+var leaderProxyTransport = util.NewInternalTransport(util.Transport)
+
 type MasterOption struct {
 	Master            pb.ServerAddress
 	MetaFolder        string
@@ -50,6 +54,7 @@ type MasterOption struct {
 	MetricsAddress          string
 	MetricsIntervalSec      int
 	IsFollower              bool
+	TopologyConfig          string
 }
 
 type MasterServer struct {
@@ -69,6 +74,10 @@ type MasterServer struct {
 	clientChansLock sync.RWMutex
 	clientChans     map[string]chan *master_pb.KeepConnectedResponse
 
+	// notifying volume assignment watchers
+	assignmentWatchersLock sync.RWMutex
+	assignmentWatchers     map[string]chan *master_pb.VolumeAssignmentEvent
+
 	grpcDialOption grpc.DialOption
 
 	MasterClient *wdclient.MasterClient
@@ -114,6 +123,7 @@ func NewMasterServer(r *mux.Router, option *MasterOption, peers map[string]pb.Se
 		preallocateSize:         preallocateSize,
 		volumeGrowthRequestChan: make(chan *topology.VolumeGrowRequest, 1<<6),
 		clientChans:             make(map[string]chan *master_pb.KeepConnectedResponse),
+		assignmentWatchers:      make(map[string]chan *master_pb.VolumeAssignmentEvent),
 		grpcDialOption:          grpcDialOption,
 		MasterClient:            wdclient.NewMasterClient(grpcDialOption, "", cluster.MasterType, option.Master, "", "", *pb.NewServiceDiscoveryFromMap(peers)),
 		adminLocks:              NewAdminLocks(),
@@ -128,6 +138,14 @@ func NewMasterServer(r *mux.Router, option *MasterOption, peers map[string]pb.Se
 		glog.Fatalf("create sequencer failed.")
 	}
 	ms.Topo = topology.NewTopology("topo", seq, uint64(ms.option.VolumeSizeLimitMB)*1024*1024, 5, replicationAsMin)
+	ms.Topo.OnVolumeCapacityFull = func(volumeInfo storage.VolumeInfo, dn *topology.DataNode) {
+		ms.broadcastVolumeAssignmentEvent(master_pb.VolumeAssignmentEvent_FULL_VOLUME, uint32(volumeInfo.Id), volumeInfo.Collection, dn)
+	}
+	if ms.option.TopologyConfig != "" {
+		if err := ms.Topo.Configuration.LoadTopologyToml(ms.option.TopologyConfig); err != nil {
+			glog.Fatalf("load topology config: %v", err)
+		}
+	}
 	ms.vg = topology.NewDefaultVolumeGrowth()
 	glog.V(0).Infoln("Volume Size Limit is", ms.option.VolumeSizeLimitMB, "MB")
 
@@ -201,6 +219,9 @@ func (ms *MasterServer) SetRaftServer(raftServer *RaftServer) {
 				}
 			}
 		}()
+	} else if raftServer.etcdElection != nil {
+		ms.Topo.LeaderElection = raftServer.etcdElection
+		raftServerName = fmt.Sprintf("[%s]", raftServer.serverAddr)
 	}
 	ms.Topo.RaftServerAccessLock.Unlock()
 
@@ -215,6 +236,8 @@ func (ms *MasterServer) SetRaftServer(raftServer *RaftServer) {
 			raftServerName = ms.Topo.HashicorpRaft.String()
 			raftServerLeaderAddr, _ := ms.Topo.HashicorpRaft.LeaderWithID()
 			raftServerLeader = string(raftServerLeaderAddr)
+		} else if ms.Topo.LeaderElection != nil {
+			raftServerLeader = string(ms.Topo.LeaderElection.Leader())
 		}
 		ms.Topo.RaftServerAccessLock.RUnlock()
 		glog.V(0).Infof("%s %s - is the leader.", raftServerName, raftServerLeader)
@@ -256,7 +279,7 @@ func (ms *MasterServer) proxyToLeader(f http.HandlerFunc) http.HandlerFunc {
 			}
 			director(req)
 		}
-		proxy.Transport = util.Transport
+		proxy.Transport = leaderProxyTransport
 		proxy.ServeHTTP(w, r)
 	}
 }
@@ -407,6 +430,10 @@ func (ms *MasterServer) OnPeerUpdate(update *master_pb.ClusterNodeUpdate, startF
 }
 
 func (ms *MasterServer) Shutdown() {
+	if ms.Topo != nil && ms.Topo.LeaderElection != nil {
+		ms.Topo.LeaderElection.Close()
+		return
+	}
 	if ms.Topo == nil || ms.Topo.HashicorpRaft == nil {
 		return
 	}
@@ -415,3 +442,16 @@ func (ms *MasterServer) Shutdown() {
 	}
 	ms.Topo.HashicorpRaft.Shutdown()
 }
+
+// ReloadTopologyConfig reloads the topology.toml, and moves the volume servers to their new racks.
+func (ms *MasterServer) ReloadTopologyConfig() {
+	if ms.option.TopologyConfig == "" {
+		return
+	}
+	if err := ms.Topo.Configuration.LoadTopologyToml(ms.option.TopologyConfig); err != nil {
+		glog.Errorf("reload topology config: %v", err)
+		return
+	}
+	glog.V(0).Infof("reloaded topology config %s", ms.option.TopologyConfig)
+	ms.Topo.RelocateDataNodes()
+}
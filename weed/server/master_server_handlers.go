@@ -102,6 +102,10 @@ func (ms *MasterServer) findVolumeLocation(collection, vid string) operation.Loo
 
 func (ms *MasterServer) dirAssignHandler(w http.ResponseWriter, r *http.Request) {
 	stats.AssignRequest()
+	start := time.Now()
+	defer func() {
+		stats.MasterAssignHistogram.WithLabelValues("http").Observe(time.Since(start).Seconds())
+	}()
 	requestedCount, e := strconv.ParseUint(r.FormValue("count"), 10, 64)
 	if e != nil || requestedCount == 0 {
 		requestedCount = 1
@@ -114,6 +118,7 @@ func (ms *MasterServer) dirAssignHandler(w http.ResponseWriter, r *http.Request)
 
 	option, err := ms.getVolumeGrowOption(r)
 	if err != nil {
+		assignRejected("http", stats.AssignInvalidOption)
 		writeJsonQuiet(w, r, http.StatusNotAcceptable, operation.AssignResult{Error: err.Error()})
 		return
 	}
@@ -127,6 +132,7 @@ func (ms *MasterServer) dirAssignHandler(w http.ResponseWriter, r *http.Request)
 	)
 
 	if !ms.Topo.DataCenterExists(option.DataCenter) {
+		assignRejected("http", stats.AssignNoDataCenter)
 		writeJsonQuiet(w, r, http.StatusBadRequest, operation.AssignResult{
 			Error: fmt.Sprintf("data center %v not found in topology", option.DataCenter),
 		})
@@ -139,6 +145,7 @@ func (ms *MasterServer) dirAssignHandler(w http.ResponseWriter, r *http.Request)
 			// if picked volume is almost full, trigger a volume-grow request
 			glog.V(0).Infof("dirAssign volume growth %v from %v", option.String(), r.RemoteAddr)
 			if ms.Topo.AvailableSpaceFor(option) <= 0 {
+				assignRejected("http", stats.AssignNoFreeVolumes)
 				writeJsonQuiet(w, r, http.StatusNotFound, operation.AssignResult{Error: "No free volumes left for " + option.String()})
 				return
 			}
@@ -165,8 +172,10 @@ func (ms *MasterServer) dirAssignHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	if lastErr != nil {
+		assignRejected("http", stats.AssignNoWritableVolumes)
 		writeJsonQuiet(w, r, http.StatusNotAcceptable, operation.AssignResult{Error: lastErr.Error()})
 	} else {
+		assignRejected("http", stats.AssignTimeout)
 		writeJsonQuiet(w, r, http.StatusRequestTimeout, operation.AssignResult{Error: "request timeout"})
 	}
 }
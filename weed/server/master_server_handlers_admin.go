@@ -155,6 +155,10 @@ func (ms *MasterServer) getVolumeGrowOption(r *http.Request) (*topology.VolumeGr
 		return nil, err
 	}
 	diskType := types.ToDiskType(r.FormValue("disk"))
+	tags, err := util.ParseTags(r.FormValue("tags"))
+	if err != nil {
+		return nil, err
+	}
 
 	preallocate := ms.preallocateSize
 	if r.FormValue("preallocate") != "" {
@@ -173,6 +177,7 @@ func (ms *MasterServer) getVolumeGrowOption(r *http.Request) (*topology.VolumeGr
 		Rack:               r.FormValue("rack"),
 		DataNode:           r.FormValue("dataNode"),
 		MemoryMapMaxSizeMb: memoryMapMaxSizeMb,
+		Tags:               tags,
 	}
 	return volumeGrowOption, nil
 }
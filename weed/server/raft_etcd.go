@@ -0,0 +1,283 @@
+package weed_server
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb"
+	"github.com/seaweedfs/seaweedfs/weed/stats"
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
+	"github.com/seaweedfs/seaweedfs/weed/topology"
+)
+
+const (
+	etcdElectionKey    = "/election"
+	etcdMaxVolumeIdKey = "/max_volume_id"
+	etcdRequestTimeout = 5 * time.Second
+)
+
+// etcdElection elects the master leader with the etcd election api,
+// and keeps the max volume id in etcd instead of the raft log.
+type etcdElection struct {
+	client    *clientv3.Client
+	keyPrefix string
+	self      pb.ServerAddress
+	ttl       int
+	topo      *topology.Topology
+
+	mu       sync.RWMutex
+	leader   pb.ServerAddress
+	election *concurrency.Election // only set when self is the leader
+	stopped  bool
+	cancel   context.CancelFunc
+}
+
+var _ topology.LeaderElection = &etcdElection{}
+
+func NewEtcdRaftServer(option *RaftServerOption) (*RaftServer, error) {
+	s := &RaftServer{
+		peers:      option.Peers,
+		serverAddr: option.ServerAddr,
+		dataDir:    option.DataDir,
+		topo:       option.Topo,
+		cluster:    option.Cluster,
+	}
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   option.EtcdEndpoints,
+		DialTimeout: etcdRequestTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("connect to etcd %v: %v", option.EtcdEndpoints, err)
+	}
+
+	ttl := int(option.ElectionTimeout.Seconds())
+	if ttl < 1 {
+		ttl = 1
+	}
+	s.etcdElection = &etcdElection{
+		client:    client,
+		keyPrefix: strings.TrimSuffix(option.EtcdKeyPrefix, "/"),
+		self:      option.ServerAddr,
+		ttl:       ttl,
+		topo:      option.Topo,
+	}
+	glog.V(0).Infof("Starting etcd leader election with %v at %v", option.ServerAddr, option.EtcdEndpoints)
+	go s.etcdElection.loopCampaign()
+
+	return s, nil
+}
+
+func (e *etcdElection) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.election != nil
+}
+
+func (e *etcdElection) Leader() pb.ServerAddress {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader
+}
+
+func (e *etcdElection) SaveMaxVolumeId(vid needle.VolumeId) error {
+	e.mu.RLock()
+	election := e.election
+	e.mu.RUnlock()
+	if election == nil {
+		return fmt.Errorf("%s is not the leader", e.self)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), etcdRequestTimeout)
+	defer cancel()
+	// only write if the election key of this master is still the leader key
+	resp, err := e.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(election.Key()), "=", election.Rev())).
+		Then(clientv3.OpPut(e.keyPrefix+etcdMaxVolumeIdKey, vid.String())).
+		Commit()
+	if err != nil {
+		return fmt.Errorf("save max volume id %d: %v", vid, err)
+	}
+	if !resp.Succeeded {
+		return fmt.Errorf("save max volume id %d: %s lost the leadership", vid, e.self)
+	}
+	return nil
+}
+
+// Close resigns the leadership, so other masters can take over right away.
+func (e *etcdElection) Close() {
+	e.mu.Lock()
+	e.stopped = true
+	election, cancel := e.election, e.cancel
+	e.election = nil
+	e.mu.Unlock()
+
+	if election != nil {
+		ctx, cancelResign := context.WithTimeout(context.Background(), etcdRequestTimeout)
+		if err := election.Resign(ctx); err != nil {
+			glog.Warningf("resign etcd leader election: %v", err)
+		}
+		cancelResign()
+	}
+	if cancel != nil {
+		cancel()
+	}
+	e.client.Close()
+}
+
+// Peers returns all masters taking part in the election.
+func (e *etcdElection) Peers() (members []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), etcdRequestTimeout)
+	defer cancel()
+	resp, err := e.client.Get(ctx, e.keyPrefix+etcdElectionKey+"/", clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByCreateRevision, clientv3.SortAscend))
+	if err != nil {
+		glog.Warningf("list etcd election candidates: %v", err)
+		return
+	}
+	for _, kv := range resp.Kvs {
+		members = append(members, string(kv.Value))
+	}
+	return
+}
+
+func (e *etcdElection) isStopped() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.stopped
+}
+
+func (e *etcdElection) loopCampaign() {
+	for !e.isStopped() {
+		if err := e.campaign(); err != nil {
+			glog.Errorf("etcd leader election: %v", err)
+			time.Sleep(time.Second)
+		}
+	}
+}
+
+// campaign runs one session, and returns when the session is lost.
+func (e *etcdElection) campaign() error {
+	session, err := concurrency.NewSession(e.client, concurrency.WithTTL(e.ttl))
+	if err != nil {
+		return fmt.Errorf("create session: %v", err)
+	}
+	defer session.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	e.mu.Lock()
+	if e.stopped {
+		e.mu.Unlock()
+		return nil
+	}
+	e.cancel = cancel
+	e.mu.Unlock()
+	go cancelWhenDone(ctx, cancel, session.Done())
+
+	election := concurrency.NewElection(session, e.keyPrefix+etcdElectionKey)
+	go e.observe(ctx, election)
+
+	// blocks until elected
+	if err = election.Campaign(ctx, string(e.self)); err != nil {
+		cancelled := ctx.Err() != nil
+		cancel()
+		e.stepDown(election)
+		if cancelled {
+			return nil
+		}
+		return fmt.Errorf("campaign: %v", err)
+	}
+
+	if err = e.loadMaxVolumeId(ctx); err != nil {
+		resignCtx, cancelResign := context.WithTimeout(context.Background(), etcdRequestTimeout)
+		election.Resign(resignCtx)
+		cancelResign()
+		cancel()
+		e.stepDown(election)
+		return err
+	}
+
+	e.lead(ctx, election)
+	return nil
+}
+
+// cancelWhenDone cancels the campaign once the etcd session is done, e.g. its lease expired.
+func cancelWhenDone(ctx context.Context, cancel context.CancelFunc, sessionDone <-chan struct{}) {
+	select {
+	case <-sessionDone:
+		cancel()
+	case <-ctx.Done():
+	}
+}
+
+// lead serves as the leader until the campaign is cancelled.
+func (e *etcdElection) lead(ctx context.Context, election *concurrency.Election) {
+	e.mu.Lock()
+	e.election = election
+	e.leader = e.self
+	e.mu.Unlock()
+	glog.V(0).Infof("[%s] I am the leader!", e.self)
+	stats.MasterLeaderChangeCounter.WithLabelValues(string(e.self)).Inc()
+
+	<-ctx.Done()
+
+	e.stepDown(election)
+	glog.V(0).Infof("[%s] lost the leadership", e.self)
+}
+
+// stepDown forgets the leadership and the leader once the campaign is cancelled,
+// so this master neither serves as the leader nor proxies to itself.
+// The next session observes the new leader again.
+func (e *etcdElection) stepDown(election *concurrency.Election) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.election == election {
+		e.election = nil
+	}
+	e.leader = ""
+}
+
+func (e *etcdElection) observe(ctx context.Context, election *concurrency.Election) {
+	for resp := range election.Observe(ctx) {
+		if len(resp.Kvs) == 0 {
+			continue
+		}
+		leader := pb.ServerAddress(resp.Kvs[0].Value)
+		e.mu.Lock()
+		if ctx.Err() != nil {
+			// the campaign already stepped down
+			e.mu.Unlock()
+			return
+		}
+		prevLeader := e.leader
+		e.leader = leader
+		e.mu.Unlock()
+		if prevLeader != leader {
+			glog.V(0).Infof("leader change event: %+v => %+v", prevLeader, leader)
+		}
+	}
+}
+
+func (e *etcdElection) loadMaxVolumeId(ctx context.Context) error {
+	resp, err := e.client.Get(ctx, e.keyPrefix+etcdMaxVolumeIdKey)
+	if err != nil {
+		return fmt.Errorf("load max volume id: %v", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return nil
+	}
+	vid, err := strconv.ParseUint(string(resp.Kvs[0].Value), 10, 32)
+	if err != nil {
+		return fmt.Errorf("parse max volume id %q: %v", resp.Kvs[0].Value, err)
+	}
+	e.topo.UpAdjustMaxVolumeId(needle.VolumeId(vid))
+	return nil
+}
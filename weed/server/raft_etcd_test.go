@@ -0,0 +1,52 @@
+package weed_server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/client/v3/concurrency"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb"
+)
+
+func TestEtcdElectionLostSession(t *testing.T) {
+	e := &etcdElection{self: pb.ServerAddress("localhost:9333")}
+	election := &concurrency.Election{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sessionDone := make(chan struct{})
+	go cancelWhenDone(ctx, cancel, sessionDone)
+
+	stepped := make(chan struct{})
+	go func() {
+		e.lead(ctx, election)
+		close(stepped)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !e.IsLeader() {
+		if time.Now().After(deadline) {
+			t.Fatalf("not elected")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if leader := e.Leader(); leader != e.self {
+		t.Fatalf("leader %s, expected %s", leader, e.self)
+	}
+
+	// the lease of the session expired
+	close(sessionDone)
+	select {
+	case <-stepped:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("did not step down after the session is lost")
+	}
+	if e.IsLeader() {
+		t.Errorf("still the leader after the session is lost")
+	}
+	if leader := e.Leader(); leader != "" {
+		t.Errorf("leader %s after the session is lost, expected none", leader)
+	}
+}
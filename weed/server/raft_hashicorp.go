@@ -102,6 +102,7 @@ func NewHashicorpRaftServer(option *RaftServerOption) (*RaftServer, error) {
 		serverAddr: option.ServerAddr,
 		dataDir:    option.DataDir,
 		topo:       option.Topo,
+		cluster:    option.Cluster,
 	}
 
 	c := raft.DefaultConfig()
@@ -16,6 +16,7 @@ import (
 	hashicorpRaft "github.com/hashicorp/raft"
 	"github.com/seaweedfs/raft"
 
+	"github.com/seaweedfs/seaweedfs/weed/cluster"
 	"github.com/seaweedfs/seaweedfs/weed/glog"
 	"github.com/seaweedfs/seaweedfs/weed/topology"
 )
@@ -26,10 +27,13 @@ type RaftServerOption struct {
 	ServerAddr        pb.ServerAddress
 	DataDir           string
 	Topo              *topology.Topology
+	Cluster           *cluster.Cluster
 	RaftResumeState   bool
 	HeartbeatInterval time.Duration
 	ElectionTimeout   time.Duration
 	RaftBootstrap     bool
+	EtcdEndpoints     []string
+	EtcdKeyPrefix     string
 }
 
 type RaftServer struct {
@@ -37,9 +41,11 @@ type RaftServer struct {
 	raftServer       raft.Server
 	RaftHashicorp    *hashicorpRaft.Raft
 	TransportManager *transport.Manager
+	etcdElection     *etcdElection
 	dataDir          string
 	serverAddr       pb.ServerAddress
 	topo             *topology.Topology
+	cluster          *cluster.Cluster
 	*raft.GrpcServer
 }
 
@@ -105,6 +111,7 @@ func NewRaftServer(option *RaftServerOption) (*RaftServer, error) {
 		serverAddr: option.ServerAddr,
 		dataDir:    option.DataDir,
 		topo:       option.Topo,
+		cluster:    option.Cluster,
 	}
 
 	if glog.V(4) {
@@ -180,6 +187,8 @@ func (s *RaftServer) Peers() (members []string) {
 		for _, p := range cfg.Configuration().Servers {
 			members = append(members, string(p.ID))
 		}
+	} else if s.etcdElection != nil {
+		members = s.etcdElection.Peers()
 	}
 	return
 }
@@ -1,7 +1,10 @@
 package weed_server
 
 import (
+	"fmt"
+
 	"github.com/cenkalti/backoff/v4"
+	"github.com/seaweedfs/seaweedfs/weed/cluster"
 	"github.com/seaweedfs/seaweedfs/weed/glog"
 	"github.com/seaweedfs/seaweedfs/weed/pb"
 	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
@@ -29,10 +32,33 @@ func (s *RaftServer) StatusHandler(w http.ResponseWriter, r *http.Request) {
 	writeJsonQuiet(w, r, http.StatusOK, ret)
 }
 
+type ClusterHealthResult struct {
+	Status  string `json:"status"`
+	Masters int    `json:"masters"`
+	Volumes int    `json:"volumes"`
+	Filers  int    `json:"filers"`
+}
+
+// HealthzHandler responds 200 if there is a leader, 503 without a leader, and 423 if the leader is locked by weed shell.
+// Volume servers and filers only report to the leader, so they are counted on the leader.
 func (s *RaftServer) HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	ret := ClusterHealthResult{
+		Status:  "ok",
+		Masters: s.countMasters(),
+	}
+	if s.cluster != nil {
+		ret.Filers = s.cluster.CountClusterNodes(cluster.FilerType)
+	}
+	for _, dc := range s.topo.Children() {
+		for _, rack := range dc.Children() {
+			ret.Volumes += len(rack.Children())
+		}
+	}
+
 	leader, err := s.topo.Leader()
 	if err != nil {
-		w.WriteHeader(http.StatusServiceUnavailable)
+		ret.Status = fmt.Sprintf("no leader: %v", err)
+		writeJsonQuiet(w, r, http.StatusServiceUnavailable, ret)
 		return
 	}
 	if s.serverAddr == leader {
@@ -45,11 +71,23 @@ func (s *RaftServer) HealthzHandler(w http.ResponseWriter, r *http.Request) {
 			glog.Errorf("HealthzHandler: %+v", err)
 		}
 		if isLocked {
-			w.WriteHeader(http.StatusLocked)
+			ret.Status = "locked"
+			writeJsonQuiet(w, r, http.StatusLocked, ret)
 			return
 		}
 	}
-	w.WriteHeader(http.StatusOK)
+	writeJsonQuiet(w, r, http.StatusOK, ret)
+}
+
+// countMasters counts the masters in the cluster, including this one, which is not one of the goraft peers
+func (s *RaftServer) countMasters() int {
+	if s.raftServer != nil {
+		return len(s.raftServer.Peers()) + 1
+	}
+	if count := len(s.Peers()); count > 0 {
+		return count
+	}
+	return 1
 }
 
 func (s *RaftServer) StatsRaftHandler(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,49 @@
+package weed_server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb"
+	"github.com/seaweedfs/seaweedfs/weed/sequence"
+	"github.com/seaweedfs/seaweedfs/weed/topology"
+)
+
+func TestHealthzSingleMaster(t *testing.T) {
+	self := pb.ServerAddress("127.0.0.1:9333")
+	topo := topology.NewTopology("weedfs", sequence.NewMemorySequencer(), 32*1024*1024, 5, false)
+	s, err := NewRaftServer(&RaftServerOption{
+		GrpcDialOption:    grpc.WithTransportCredentials(insecure.NewCredentials()),
+		Peers:             map[string]pb.ServerAddress{string(self): self},
+		ServerAddr:        self,
+		DataDir:           t.TempDir(),
+		Topo:              topo,
+		HeartbeatInterval: 100 * time.Millisecond,
+		ElectionTimeout:   time.Second,
+	})
+	if err != nil {
+		t.Fatalf("start raft server: %v", err)
+	}
+	defer s.raftServer.Stop()
+	topo.RaftServer = s.raftServer
+	s.DoJoinCommand()
+
+	rec := httptest.NewRecorder()
+	s.HealthzHandler(rec, httptest.NewRequest(http.MethodGet, "/cluster/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+	}
+	var ret ClusterHealthResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &ret); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body.String(), err)
+	}
+	if ret.Status != "ok" || ret.Masters != 1 {
+		t.Errorf("health %+v, expected ok with 1 master", ret)
+	}
+}
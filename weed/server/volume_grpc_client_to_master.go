@@ -52,6 +52,7 @@ func (vs *VolumeServer) heartbeat() {
 	glog.V(0).Infof("Volume server start with seed master nodes: %v", vs.SeedMasterNodes)
 	vs.store.SetDataCenter(vs.dataCenter)
 	vs.store.SetRack(vs.rack)
+	vs.store.SetTags(vs.tags)
 
 	grpcDialOption := security.LoadClientTLS(util.GetViper(), "grpc.volume")
 
@@ -0,0 +1,96 @@
+package weed_server
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/volume_server_pb"
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
+	"github.com/seaweedfs/seaweedfs/weed/storage/types"
+	"github.com/seaweedfs/seaweedfs/weed/topology"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+// CopyNeedleRanges reads each range from the source volume servers, and writes it as a new needle with its replicas.
+// The ranges are copied in order, so that the md5 covers all the copied data.
+func (vs *VolumeServer) CopyNeedleRanges(ctx context.Context, req *volume_server_pb.CopyNeedleRangesRequest) (*volume_server_pb.CopyNeedleRangesResponse, error) {
+	v := vs.store.GetVolume(needle.VolumeId(req.VolumeId))
+	if v == nil {
+		return nil, fmt.Errorf("not found volume id %d", req.VolumeId)
+	}
+
+	resp := &volume_server_pb.CopyNeedleRangesResponse{}
+	fileId := needle.NewFileId(v.Id, req.NeedleId, req.Cookie).String()
+	hash := md5.New()
+	for i, r := range req.Ranges {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		var data []byte
+		if r.Raw {
+			var err error
+			if data, err = fetchNeedle(r.SourceUrls); err != nil {
+				return nil, fmt.Errorf("read %v: %v", r.SourceUrls, err)
+			}
+		} else {
+			data = make([]byte, r.Size)
+			n, err := util.RetriedFetchChunkData(data, r.SourceUrls, nil, r.IsCompressed, r.IsFullChunk, r.Offset)
+			if err != nil {
+				return nil, fmt.Errorf("read %v: %v", r.SourceUrls, err)
+			}
+			if n != len(data) {
+				return nil, fmt.Errorf("read %d bytes from %v, expected %d", n, r.SourceUrls, len(data))
+			}
+		}
+		hash.Write(data)
+
+		// the replicas check the jwt of the assigned file id, with the delta of the next needle ids
+		path := "/" + fileId
+		if i > 0 {
+			path += "_" + strconv.Itoa(i)
+		}
+		replicateRequest, err := http.NewRequest(http.MethodPut, path, nil)
+		if err != nil {
+			return nil, err
+		}
+		if req.Auth != "" {
+			replicateRequest.Header.Set("Authorization", "BEARER "+req.Auth)
+		}
+
+		nd := new(needle.Needle)
+		nd.Id = types.NeedleId(req.NeedleId + uint64(i))
+		nd.Cookie = types.Cookie(req.Cookie)
+		nd.Data = data
+		nd.LastModified = uint64(time.Now().Unix())
+		nd.SetHasLastModifiedDate()
+		nd.Checksum = needle.NewCRC(nd.Data)
+		contentMd5 := md5.Sum(data)
+		if _, err = topology.ReplicatedWrite(vs.GetMaster, vs.grpcDialOption, vs.store, v.Id, nd, replicateRequest, base64.StdEncoding.EncodeToString(contentMd5[:])); err != nil {
+			return nil, fmt.Errorf("write %s: %v", path, err)
+		}
+		resp.ETags = append(resp.ETags, nd.Etag())
+	}
+	resp.Md5 = hash.Sum(nil)
+
+	return resp, nil
+}
+
+// fetchNeedle reads the stored bytes of the whole needle from the first source volume server that has it.
+func fetchNeedle(urlStrings []string) (data []byte, err error) {
+	for _, urlString := range urlStrings {
+		if data, _, err = util.Get(urlString + "?readDeleted=true"); err == nil {
+			return data, nil
+		}
+		glog.V(0).Infof("read %s failed, err: %v", urlString, err)
+	}
+	if err == nil {
+		err = fmt.Errorf("no source")
+	}
+	return nil, err
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/seaweedfs/seaweedfs/weed/pb/volume_server_pb"
+	"github.com/seaweedfs/seaweedfs/weed/storage"
 	"github.com/seaweedfs/seaweedfs/weed/storage/backend"
 	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
 )
@@ -23,16 +24,45 @@ func (vs *VolumeServer) VolumeTierMoveDatFromRemote(req *volume_server_pb.Volume
 		return fmt.Errorf("existing collection:%v unexpected input: %v", v.Collection, req.Collection)
 	}
 
+	startTime := time.Now()
+	fn := func(progressed int64, percentage float32) error {
+		now := time.Now()
+		if now.Sub(startTime) < time.Second {
+			return nil
+		}
+		startTime = now
+		return stream.Send(&volume_server_pb.VolumeTierMoveDatFromRemoteResponse{
+			Processed:           progressed,
+			ProcessedPercentage: percentage,
+		})
+	}
+
+	if err := moveDatFromRemote(v, req.KeepRemoteDatFile, fn); err != nil {
+		return err
+	}
+	if req.KeepRemoteDatFile {
+		return nil
+	}
+
+	v.DataBackend.Close()
+	v.DataBackend = nil
+
+	return nil
+}
+
+// moveDatFromRemote copies the .dat file of the volume from the backend storage to the local disk
+func moveDatFromRemote(v *storage.Volume, keepRemoteDatFile bool, fn func(progressed int64, percentage float32) error) error {
+
 	// locate the disk file
 	storageName, storageKey := v.RemoteStorageNameKey()
 	if storageName == "" || storageKey == "" {
-		return fmt.Errorf("volume %d is already on local disk", req.VolumeId)
+		return fmt.Errorf("volume %d is already on local disk", v.Id)
 	}
 
 	// check whether the local .dat already exists
 	_, ok := v.DataBackend.(*backend.DiskFile)
 	if ok {
-		return fmt.Errorf("volume %d is already on local disk", req.VolumeId)
+		return fmt.Errorf("volume %d is already on local disk", v.Id)
 	}
 
 	// check valid storage backend type
@@ -45,25 +75,13 @@ func (vs *VolumeServer) VolumeTierMoveDatFromRemote(req *volume_server_pb.Volume
 		return fmt.Errorf("remote storage %s not found from supported: %v", storageName, keys)
 	}
 
-	startTime := time.Now()
-	fn := func(progressed int64, percentage float32) error {
-		now := time.Now()
-		if now.Sub(startTime) < time.Second {
-			return nil
-		}
-		startTime = now
-		return stream.Send(&volume_server_pb.VolumeTierMoveDatFromRemoteResponse{
-			Processed:           progressed,
-			ProcessedPercentage: percentage,
-		})
-	}
 	// copy the data file
 	_, err := backendStorage.DownloadFile(v.FileName(".dat"), storageKey, fn)
 	if err != nil {
 		return fmt.Errorf("backend %s copy file %s: %v", storageName, v.FileName(".dat"), err)
 	}
 
-	if req.KeepRemoteDatFile {
+	if keepRemoteDatFile {
 		return nil
 	}
 
@@ -78,8 +96,5 @@ func (vs *VolumeServer) VolumeTierMoveDatFromRemote(req *volume_server_pb.Volume
 		return fmt.Errorf("volume %d failed to save remote file info: %v", v.Id, err)
 	}
 
-	v.DataBackend.Close()
-	v.DataBackend = nil
-
 	return nil
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/seaweedfs/seaweedfs/weed/pb/volume_server_pb"
+	"github.com/seaweedfs/seaweedfs/weed/storage"
 	"github.com/seaweedfs/seaweedfs/weed/storage/backend"
 	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
 )
@@ -24,48 +25,54 @@ func (vs *VolumeServer) VolumeTierMoveDatToRemote(req *volume_server_pb.VolumeTi
 		return fmt.Errorf("existing collection:%v unexpected input: %v", v.Collection, req.Collection)
 	}
 
+	startTime := time.Now()
+	fn := func(progressed int64, percentage float32) error {
+		now := time.Now()
+		if now.Sub(startTime) < time.Second {
+			return nil
+		}
+		startTime = now
+		return stream.Send(&volume_server_pb.VolumeTierMoveDatToRemoteResponse{
+			Processed:           progressed,
+			ProcessedPercentage: percentage,
+		})
+	}
+
+	return moveDatToRemote(v, req.DestinationBackendName, req.KeepLocalDatFile, fn)
+}
+
+// moveDatToRemote copies the .dat file of the volume to the backend storage, and reads the volume from there
+func moveDatToRemote(v *storage.Volume, destinationBackendName string, keepLocalDatFile bool, fn func(progressed int64, percentage float32) error) error {
+
 	// locate the disk file
 	diskFile, ok := v.DataBackend.(*backend.DiskFile)
 	if !ok {
-		return nil // already copied to remove. fmt.Errorf("volume %d is not on local disk", req.VolumeId)
+		return nil // already copied to remove. fmt.Errorf("volume %d is not on local disk", v.Id)
 	}
 
 	// check valid storage backend type
-	backendStorage, found := backend.BackendStorages[req.DestinationBackendName]
+	backendStorage, found := backend.BackendStorages[destinationBackendName]
 	if !found {
 		var keys []string
 		for key := range backend.BackendStorages {
 			keys = append(keys, key)
 		}
-		return fmt.Errorf("destination %s not found, supported: %v", req.DestinationBackendName, keys)
+		return fmt.Errorf("destination %s not found, supported: %v", destinationBackendName, keys)
 	}
 
 	// check whether the existing backend storage is the same as requested
 	// if same, skip
-	backendType, backendId := backend.BackendNameToTypeId(req.DestinationBackendName)
+	backendType, backendId := backend.BackendNameToTypeId(destinationBackendName)
 	for _, remoteFile := range v.GetVolumeInfo().GetFiles() {
 		if remoteFile.BackendType == backendType && remoteFile.BackendId == backendId {
-			return fmt.Errorf("destination %s already exists", req.DestinationBackendName)
-		}
-	}
-
-	startTime := time.Now()
-	fn := func(progressed int64, percentage float32) error {
-		now := time.Now()
-		if now.Sub(startTime) < time.Second {
-			return nil
+			return fmt.Errorf("destination %s already exists", destinationBackendName)
 		}
-		startTime = now
-		return stream.Send(&volume_server_pb.VolumeTierMoveDatToRemoteResponse{
-			Processed:           progressed,
-			ProcessedPercentage: percentage,
-		})
 	}
 
 	// copy the data file
 	key, size, err := backendStorage.CopyFile(diskFile.File, fn)
 	if err != nil {
-		return fmt.Errorf("backend %s copy file %s: %v", req.DestinationBackendName, diskFile.Name(), err)
+		return fmt.Errorf("backend %s copy file %s: %v", destinationBackendName, diskFile.Name(), err)
 	}
 
 	// save the remote file to volume tier info
@@ -87,7 +94,7 @@ func (vs *VolumeServer) VolumeTierMoveDatToRemote(req *volume_server_pb.VolumeTi
 		return fmt.Errorf("volume %d failed to load remote file: %v", v.Id, err)
 	}
 
-	if !req.KeepLocalDatFile {
+	if !keepLocalDatFile {
 		os.Remove(v.FileName(".dat"))
 	}
 
@@ -7,8 +7,10 @@ import (
 
 	"github.com/seaweedfs/seaweedfs/weed/pb"
 	"github.com/seaweedfs/seaweedfs/weed/pb/volume_server_pb"
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
 	"github.com/seaweedfs/seaweedfs/weed/storage/types"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
 
 	"github.com/seaweedfs/seaweedfs/weed/stats"
@@ -36,6 +38,7 @@ type VolumeServer struct {
 	pulseSeconds    int
 	dataCenter      string
 	rack            string
+	tags            map[string]string
 	store           *storage.Store
 	guard           *security.Guard
 	grpcDialOption  grpc.DialOption
@@ -50,6 +53,9 @@ type VolumeServer struct {
 	fileSizeLimitBytes      int64
 	isHeartbeating          bool
 	stopChan                chan bool
+	autoCompaction          autoCompaction
+	autoTiering             autoTiering
+	compression             needle.Compression
 }
 
 func NewVolumeServer(adminMux, publicMux *http.ServeMux, ip string,
@@ -58,7 +64,7 @@ func NewVolumeServer(adminMux, publicMux *http.ServeMux, ip string,
 	idxFolder string,
 	needleMapKind storage.NeedleMapKind,
 	masterNodes []pb.ServerAddress, pulseSeconds int,
-	dataCenter string, rack string,
+	dataCenter string, rack string, tags map[string]string,
 	whiteList []string,
 	fixJpgOrientation bool,
 	readMode string,
@@ -70,6 +76,12 @@ func NewVolumeServer(adminMux, publicMux *http.ServeMux, ip string,
 	hasSlowRead bool,
 	readBufferSizeMB int,
 	ldbTimeout int64,
+	autoCompactionOption AutoCompactionOption,
+	readCacheSizeMB int,
+	readCacheMaxFileSizeKB int,
+	maxVolumeSize uint64,
+	compression needle.Compression,
+	autoTieringOption AutoTieringOption,
 ) *VolumeServer {
 
 	v := util.GetViper()
@@ -86,6 +98,7 @@ func NewVolumeServer(adminMux, publicMux *http.ServeMux, ip string,
 		pulseSeconds:                  pulseSeconds,
 		dataCenter:                    dataCenter,
 		rack:                          rack,
+		tags:                          tags,
 		needleMapKind:                 needleMapKind,
 		FixJpgOrientation:             fixJpgOrientation,
 		ReadMode:                      readMode,
@@ -102,13 +115,23 @@ func NewVolumeServer(adminMux, publicMux *http.ServeMux, ip string,
 		hasSlowRead:                   hasSlowRead,
 		readBufferSizeMB:              readBufferSizeMB,
 		ldbTimout:                     ldbTimeout,
+		compression:                   compression,
 	}
 	vs.SeedMasterNodes = masterNodes
+	vs.autoCompaction.set(autoCompactionOption)
+	vs.autoTiering.option = autoTieringOption
 
 	vs.checkWithMaster()
 
 	vs.store = storage.NewStore(vs.grpcDialOption, ip, port, grpcPort, publicUrl, folders, maxCounts, minFreeSpaces, idxFolder, vs.needleMapKind, diskTypes, ldbTimeout)
+	if readCacheSizeMB > 0 {
+		vs.store.SetNeedleCache(storage.NewNeedleCache(int64(readCacheSizeMB)*1024*1024, int64(readCacheMaxFileSizeKB)*1024))
+	}
+	vs.store.SetMaxVolumeSize(maxVolumeSize)
 	vs.guard = security.NewGuard(whiteList, signingKey, expiresAfterSec, readSigningKey, readExpiresAfterSec)
+	if err := stats.Gather.Register(storage.NewVolumeMetricsCollector(vs.store)); err != nil {
+		glog.Warningf("register volume metrics: %v", err)
+	}
 
 	handleStaticResources(adminMux)
 	adminMux.HandleFunc("/status", vs.statusHandler)
@@ -122,6 +145,9 @@ func NewVolumeServer(adminMux, publicMux *http.ServeMux, ip string,
 			adminMux.HandleFunc("/stats/disk", vs.guard.WhiteList(vs.statsDiskHandler))
 		*/
 	}
+	adminMux.HandleFunc("/vol/writechunked", vs.guard.WhiteList(vs.WriteChunkedHandler))
+	adminMux.HandleFunc("/admin/compaction", vs.guard.WhiteList(vs.compactionHandler))
+	adminMux.HandleFunc("/metrics", vs.guard.WhiteList(promhttp.HandlerFor(stats.Gather, promhttp.HandlerOpts{}).ServeHTTP))
 	adminMux.HandleFunc("/", vs.privateStoreHandler)
 	if publicMux != adminMux {
 		// separated admin and public port
@@ -130,6 +156,8 @@ func NewVolumeServer(adminMux, publicMux *http.ServeMux, ip string,
 	}
 
 	go vs.heartbeat()
+	go vs.loopAutoCompaction()
+	go vs.loopAutoTiering()
 	go stats.LoopPushingMetric("volumeServer", util.JoinHostPort(ip, port), vs.metricsAddress, vs.metricsIntervalSec)
 
 	return vs
@@ -0,0 +1,151 @@
+package weed_server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/operation"
+	"github.com/seaweedfs/seaweedfs/weed/pb/master_pb"
+	"github.com/seaweedfs/seaweedfs/weed/stats"
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+const autoCompactionCheckInterval = time.Minute
+
+// AutoCompactionOption controls compacting volumes on the volume server itself.
+// The volumes are only compacted while the vacuum of the master is disabled, so that both do not compact the same volumes.
+type AutoCompactionOption struct {
+	Enabled          bool
+	GarbageThreshold float64
+	Idle             time.Duration
+}
+
+type autoCompaction struct {
+	sync.RWMutex
+	option AutoCompactionOption
+}
+
+func (ac *autoCompaction) get() AutoCompactionOption {
+	ac.RLock()
+	defer ac.RUnlock()
+	return ac.option
+}
+
+func (ac *autoCompaction) set(option AutoCompactionOption) {
+	ac.Lock()
+	defer ac.Unlock()
+	ac.option = option
+}
+
+func (vs *VolumeServer) loopAutoCompaction() {
+	ticker := time.NewTicker(autoCompactionCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-vs.stopChan:
+			return
+		case <-ticker.C:
+		}
+		option := vs.autoCompaction.get()
+		if !option.Enabled {
+			continue
+		}
+		if disabled, err := vs.isMasterVacuumDisabled(); err != nil {
+			glog.V(0).Infof("auto compaction: %v", err)
+			continue
+		} else if !disabled {
+			glog.V(1).Infof("skip auto compaction while the master vacuum is enabled")
+			continue
+		}
+		for _, vid := range vs.store.FindIdleVolumesToCompact(option.GarbageThreshold, option.Idle) {
+			if err := vs.autoCompactVolume(vid); err != nil {
+				glog.Errorf("auto compact volume %d: %v", vid, err)
+			}
+		}
+	}
+}
+
+func (vs *VolumeServer) isMasterVacuumDisabled() (disabled bool, err error) {
+	master := vs.GetMaster(context.Background())
+	err = operation.WithMasterServerClient(false, master, vs.grpcDialOption, func(masterClient master_pb.SeaweedClient) error {
+		resp, err := masterClient.GetMasterConfiguration(context.Background(), &master_pb.GetMasterConfigurationRequest{})
+		if err != nil {
+			return fmt.Errorf("get master %s configuration: %v", master, err)
+		}
+		disabled = resp.VacuumDisabled
+		return nil
+	})
+	return
+}
+
+func (vs *VolumeServer) autoCompactVolume(vid needle.VolumeId) error {
+	start := time.Now()
+	err := vs.store.CompactVolume(vid, 0, vs.compactionBytePerSecond, nil)
+	stats.VolumeServerVacuumingCompactCounter.WithLabelValues(strconv.FormatBool(err == nil)).Inc()
+	stats.VolumeServerVacuumingHistogram.WithLabelValues("compact").Observe(time.Since(start).Seconds())
+	if err != nil {
+		if cleanupErr := vs.store.CommitCleanupVolume(vid); cleanupErr != nil {
+			glog.Errorf("cleanup volume %d: %v", vid, cleanupErr)
+		}
+		return fmt.Errorf("compact: %v", err)
+	}
+
+	commitStart := time.Now()
+	_, _, err = vs.store.CommitCompactVolume(vid)
+	stats.VolumeServerVacuumingCommitCounter.WithLabelValues(strconv.FormatBool(err == nil)).Inc()
+	stats.VolumeServerVacuumingHistogram.WithLabelValues("commit").Observe(time.Since(commitStart).Seconds())
+	if err != nil {
+		if cleanupErr := vs.store.CommitCleanupVolume(vid); cleanupErr != nil {
+			glog.Errorf("cleanup volume %d: %v", vid, cleanupErr)
+		}
+		return fmt.Errorf("commit: %v", err)
+	}
+
+	glog.V(0).Infof("auto compacted volume %d in %v", vid, time.Since(start))
+	return nil
+}
+
+// compactionHandler shows the auto compaction settings, and changes them with
+// the "enabled", "garbageThreshold" and "idle" form values.
+func (vs *VolumeServer) compactionHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Server", "SeaweedFS Volume "+util.VERSION)
+	option := vs.autoCompaction.get()
+	if r.Method == http.MethodPost || r.Method == http.MethodPut {
+		var err error
+		if value := r.FormValue("enabled"); value != "" {
+			if option.Enabled, err = strconv.ParseBool(value); err != nil {
+				writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("parse enabled %s: %v", value, err))
+				return
+			}
+		}
+		if value := r.FormValue("garbageThreshold"); value != "" {
+			if option.GarbageThreshold, err = strconv.ParseFloat(value, 64); err != nil || option.GarbageThreshold <= 0 || option.GarbageThreshold > 1 {
+				writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("garbageThreshold %s should be in (0, 1]", value))
+				return
+			}
+		}
+		if value := r.FormValue("idle"); value != "" {
+			if option.Idle, err = time.ParseDuration(value); err != nil {
+				writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("parse idle %s: %v", value, err))
+				return
+			}
+		}
+		vs.autoCompaction.set(option)
+		glog.V(0).Infof("auto compaction enabled:%v garbageThreshold:%v idle:%v", option.Enabled, option.GarbageThreshold, option.Idle)
+	}
+	writeJsonQuiet(w, r, http.StatusOK, compactionStatus(option))
+}
+
+func compactionStatus(option AutoCompactionOption) map[string]interface{} {
+	return map[string]interface{}{
+		"Enabled":          option.Enabled,
+		"GarbageThreshold": option.GarbageThreshold,
+		"Idle":             option.Idle.String(),
+	}
+}
@@ -42,6 +42,7 @@ func (vs *VolumeServer) statusHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	m["DiskStatuses"] = ds
 	m["Volumes"] = vs.store.VolumeInfos()
+	m["AutoCompaction"] = compactionStatus(vs.autoCompaction.get())
 	writeJsonQuiet(w, r, http.StatusOK, m)
 }
 
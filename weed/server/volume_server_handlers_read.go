@@ -224,10 +224,11 @@ func (vs *VolumeServer) GetOrHeadHandler(w http.ResponseWriter, r *http.Request)
 			if n.Data, err = util.DecompressData(n.Data); err != nil {
 				glog.V(0).Infoln("ungzip error:", err, r.URL.Path)
 			}
-			// } else if strings.Contains(r.Header.Get("Accept-Encoding"), "zstd") && util.IsZstdContent(n.Data) {
-			//	w.Header().Set("Content-Encoding", "zstd")
-		} else if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") && util.IsGzippedContent(n.Data) {
+		} else if strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") && util.IsGzippedContent(n.Data) && r.Header.Get("Range") == "" {
+			// byte ranges are served from the uncompressed data, so that clients can seek by the original offsets
 			w.Header().Set("Content-Encoding", "gzip")
+		} else if strings.Contains(r.Header.Get("Accept-Encoding"), "zstd") && util.IsZstdContent(n.Data) && r.Header.Get("Range") == "" {
+			w.Header().Set("Content-Encoding", "zstd")
 		} else {
 			if n.Data, err = util.DecompressData(n.Data); err != nil {
 				glog.V(0).Infoln("uncompress error:", err, r.URL.Path)
@@ -35,10 +35,18 @@ func (vs *VolumeServer) PostHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if vs.maybeRehydrateVolume(w, r, volumeId) {
+		return
+	}
+
 	bytesBuffer := buffer_pool.SyncPoolGetBuffer()
 	defer buffer_pool.SyncPoolPutBuffer(bytesBuffer)
 
-	reqNeedle, originalSize, contentMd5, ne := needle.CreateNeedleFromRequest(r, vs.FixJpgOrientation, vs.fileSizeLimitBytes, bytesBuffer)
+	compression := vs.compression
+	if v := vs.store.GetVolume(volumeId); v != nil {
+		compression = compression.ForCollection(v.Collection)
+	}
+	reqNeedle, originalSize, contentMd5, ne := needle.CreateNeedleFromRequest(r, vs.FixJpgOrientation, vs.fileSizeLimitBytes, bytesBuffer, compression)
 	if ne != nil {
 		writeJsonError(w, r, http.StatusBadRequest, ne)
 		return
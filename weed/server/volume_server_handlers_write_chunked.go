@@ -0,0 +1,274 @@
+package weed_server
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/operation"
+	"github.com/seaweedfs/seaweedfs/weed/security"
+	"github.com/seaweedfs/seaweedfs/weed/stats"
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
+	"github.com/seaweedfs/seaweedfs/weed/topology"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+const ChunkOffsetHeader = "Seaweed-Chunk-Offset"
+
+/*
+WriteChunkedHandler accepts a multipart stream of data pieces and stores them as one needle.
+Each part carries its offset in the file via the "Seaweed-Chunk-Offset" part header,
+and the part body is the data at that offset. Gaps between parts are zero filled.
+The parts are stitched in a temporary file, and streamed into the needle and to the replicas.
+
+	curl -F 'a=@part0;headers="Seaweed-Chunk-Offset: 0"' \
+	     -F 'b=@part1;headers="Seaweed-Chunk-Offset: 4194304"' \
+	     "http://localhost:8080/vol/writechunked?fid=3,01637037d6&name=big.log"
+*/
+func (vs *VolumeServer) WriteChunkedHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		writeJsonError(w, r, http.StatusMethodNotAllowed, fmt.Errorf("unsupported method %s", r.Method))
+		return
+	}
+	if e := r.ParseForm(); e != nil {
+		glog.V(0).Infoln("form parse error:", e)
+		writeJsonError(w, r, http.StatusBadRequest, e)
+		return
+	}
+
+	vid, fid, found := strings.Cut(r.FormValue("fid"), ",")
+	if !found {
+		writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("invalid fid %q", r.FormValue("fid")))
+		return
+	}
+	volumeId, ve := needle.NewVolumeId(vid)
+	if ve != nil {
+		glog.V(0).Infoln("NewVolumeId error:", ve)
+		writeJsonError(w, r, http.StatusBadRequest, ve)
+		return
+	}
+
+	if !vs.maybeCheckJwtAuthorization(r, vid, fid, true) {
+		writeJsonError(w, r, http.StatusUnauthorized, errors.New("wrong jwt"))
+		return
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		writeJsonError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	// the parts are stitched in a temporary file next to the volume, instead of in memory
+	var tempDir string
+	if v := vs.store.GetVolume(volumeId); v != nil {
+		tempDir = filepath.Dir(v.FileName(".dat"))
+	}
+	dataFile, err := os.CreateTemp(tempDir, "writechunked-*")
+	if err != nil {
+		writeJsonError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	defer func() {
+		dataFile.Close()
+		os.Remove(dataFile.Name())
+	}()
+
+	var mimeType string
+	var dataSize int64
+	for {
+		part, partErr := reader.NextPart()
+		if partErr == io.EOF {
+			break
+		}
+		if partErr != nil {
+			writeJsonError(w, r, http.StatusBadRequest, partErr)
+			return
+		}
+		offset, parseErr := strconv.ParseInt(part.Header.Get(ChunkOffsetHeader), 10, 64)
+		if parseErr != nil || offset < 0 {
+			part.Close()
+			writeJsonError(w, r, http.StatusBadRequest, fmt.Errorf("invalid %s header %q", ChunkOffsetHeader, part.Header.Get(ChunkOffsetHeader)))
+			return
+		}
+		if mimeType == "" {
+			mimeType = part.Header.Get("Content-Type")
+		}
+		stop, stitchErr := stitchChunkedPart(dataFile, offset, part, vs.fileSizeLimitBytes)
+		part.Close()
+		if stitchErr != nil {
+			writeJsonError(w, r, http.StatusBadRequest, stitchErr)
+			return
+		}
+		dataSize = max(dataSize, stop)
+	}
+
+	n := new(needle.Needle)
+	if ne := n.ParsePath(fid); ne != nil {
+		writeJsonError(w, r, http.StatusBadRequest, ne)
+		return
+	}
+	if name := r.FormValue("name"); name != "" && len(name) < 256 {
+		n.Name = []byte(name)
+		n.SetHasName()
+	}
+	if mime := r.FormValue("mime"); mime != "" {
+		mimeType = mime
+	}
+	if mimeType != "" && mimeType != "application/octet-stream" && len(mimeType) < 256 {
+		n.Mime = []byte(mimeType)
+		n.SetHasMime()
+	}
+	n.LastModified, _ = strconv.ParseUint(r.FormValue("ts"), 10, 64)
+	if n.LastModified == 0 {
+		n.LastModified = uint64(time.Now().Unix())
+	}
+	n.SetHasLastModifiedDate()
+
+	crc, h := needle.NewCRCwriter(io.Discard), md5.New()
+	if _, err = io.Copy(io.MultiWriter(crc, h), io.NewSectionReader(dataFile, 0, dataSize)); err != nil {
+		writeJsonError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	n.Checksum = needle.CRC(crc.Sum())
+	contentMd5 := base64.StdEncoding.EncodeToString(h.Sum(nil))
+
+	if writeError := vs.replicatedWriteFrom(volumeId, n, dataFile, dataSize, r); writeError != nil {
+		writeJsonError(w, r, http.StatusInternalServerError, writeError)
+		return
+	}
+
+	ret := operation.UploadResult{
+		Size: uint32(dataSize),
+		ETag: n.Etag(),
+		Mime: string(n.Mime),
+	}
+	if n.HasName() {
+		ret.Name = string(n.Name)
+	}
+	SetEtag(w, ret.ETag)
+	w.Header().Set("Content-MD5", contentMd5)
+	writeJsonQuiet(w, r, http.StatusCreated, ret)
+}
+
+// replicatedWriteFrom writes the needle with its data read from dataFile to the local volume,
+// and streams the data to the other replicas as a chunked upload of one part.
+func (vs *VolumeServer) replicatedWriteFrom(volumeId needle.VolumeId, n *needle.Needle, dataFile *os.File, dataSize int64, r *http.Request) (err error) {
+	var remoteLocations []operation.Location
+	if r.FormValue("type") != "replicate" {
+		if remoteLocations, err = topology.GetWritableRemoteReplications(vs.store, vs.grpcDialOption, volumeId, vs.GetMaster); err != nil {
+			glog.V(0).Infoln(err)
+			return err
+		}
+	}
+
+	if vs.store.GetVolume(volumeId) != nil {
+		start := time.Now()
+		err = vs.store.WriteVolumeNeedleFrom(volumeId, n, io.NewSectionReader(dataFile, 0, dataSize), dataSize, r.FormValue("fsync") == "true")
+		stats.VolumeServerRequestHistogram.WithLabelValues(stats.WriteToLocalDisk).Observe(time.Since(start).Seconds())
+		if err != nil {
+			stats.VolumeServerHandlerCounter.WithLabelValues(stats.ErrorWriteToLocalDisk).Inc()
+			err = fmt.Errorf("failed to write to local disk: %v", err)
+			glog.V(0).Infoln(err)
+			return err
+		}
+	}
+
+	if len(remoteLocations) > 0 {
+		start := time.Now()
+		err = topology.DistributedOperation(remoteLocations, func(location operation.Location) error {
+			return replicateChunkedUpload(location.Url, n, io.NewSectionReader(dataFile, 0, dataSize), r)
+		})
+		stats.VolumeServerRequestHistogram.WithLabelValues(stats.WriteToReplicas).Observe(time.Since(start).Seconds())
+		if err != nil {
+			stats.VolumeServerHandlerCounter.WithLabelValues(stats.ErrorWriteToReplicas).Inc()
+			err = fmt.Errorf("failed to write to replicas for volume %d: %v", volumeId, err)
+			glog.V(0).Infoln(err)
+			return err
+		}
+	}
+	return nil
+}
+
+// replicateChunkedUpload streams the data to the /vol/writechunked of the replica
+func replicateChunkedUpload(replicaUrl string, n *needle.Needle, data io.Reader, r *http.Request) error {
+	q := url.Values{
+		"fid":  {r.FormValue("fid")},
+		"type": {"replicate"},
+		"ts":   {strconv.FormatUint(n.LastModified, 10)},
+	}
+	if n.HasName() {
+		q.Set("name", string(n.Name))
+	}
+	if n.HasMime() {
+		q.Set("mime", string(n.Mime))
+	}
+	if r.FormValue("fsync") == "true" {
+		q.Set("fsync", "true")
+	}
+
+	body, bodyWriter := io.Pipe()
+	multipartWriter := multipart.NewWriter(bodyWriter)
+	go func() {
+		partHeader := make(textproto.MIMEHeader)
+		partHeader.Set("Content-Disposition", `form-data; name="file"`)
+		partHeader.Set(ChunkOffsetHeader, "0")
+		part, err := multipartWriter.CreatePart(partHeader)
+		if err == nil {
+			_, err = io.Copy(part, data)
+		}
+		if err == nil {
+			err = multipartWriter.Close()
+		}
+		bodyWriter.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequest(http.MethodPost, "http://"+replicaUrl+"/vol/writechunked?"+q.Encode(), body)
+	if err != nil {
+		body.CloseWithError(err)
+		return err
+	}
+	req.Header.Set("Content-Type", multipartWriter.FormDataContentType())
+	if jwt := security.GetJwt(r); jwt != "" {
+		req.Header.Set("Authorization", "BEARER "+string(jwt))
+	}
+	resp, err := util.Do(req)
+	if err != nil {
+		body.CloseWithError(err)
+		return err
+	}
+	defer util.CloseResponse(resp)
+	if resp.StatusCode != http.StatusCreated {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("replicate to %s: %s %s", replicaUrl, resp.Status, respBody)
+	}
+	return nil
+}
+
+// stitchChunkedPart writes the data read from src at the offset in dst, and returns where the data stops.
+// The gaps between the parts are left as holes in the file, which read as zeros.
+func stitchChunkedPart(dst io.WriterAt, offset int64, src io.Reader, sizeLimit int64) (stop int64, err error) {
+	if offset > sizeLimit {
+		return 0, fmt.Errorf("chunk offset %d exceeds file size limit %d", offset, sizeLimit)
+	}
+	written, err := io.Copy(io.NewOffsetWriter(dst, offset), io.LimitReader(src, sizeLimit-offset+1))
+	if err != nil {
+		return 0, err
+	}
+	if stop = offset + written; stop > sizeLimit {
+		return 0, fmt.Errorf("chunked upload exceeds file size limit %d", sizeLimit)
+	}
+	return stop, nil
+}
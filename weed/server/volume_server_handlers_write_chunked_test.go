@@ -0,0 +1,34 @@
+package weed_server
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStitchChunkedPart(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "stitched"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if stop, err := stitchChunkedPart(f, 0, strings.NewReader("abc"), 100); err != nil || stop != 3 {
+		t.Fatalf("stitch: %d %v", stop, err)
+	}
+	if stop, err := stitchChunkedPart(f, 5, strings.NewReader("fg"), 100); err != nil || stop != 7 {
+		t.Fatalf("stitch with gap: %d %v", stop, err)
+	}
+	if stop, err := stitchChunkedPart(f, 2, strings.NewReader("CD"), 100); err != nil || stop != 4 {
+		t.Fatalf("stitch overwrite: %d %v", stop, err)
+	}
+	data, _ := os.ReadFile(f.Name())
+	if got, want := string(data), "abCD\x00fg"; got != want {
+		t.Errorf("stitched %q, want %q", got, want)
+	}
+
+	if _, err := stitchChunkedPart(f, 6, strings.NewReader("xyz"), 8); err == nil {
+		t.Errorf("expected size limit error")
+	}
+}
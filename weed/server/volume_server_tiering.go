@@ -0,0 +1,125 @@
+package weed_server
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/storage/backend"
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
+)
+
+const autoTieringCheckInterval = time.Minute
+
+// AutoTieringOption controls moving the cold volumes on the volume server to a remote storage backend,
+// like s3.default configured on the master, and moving them back to the local disk when written to.
+type AutoTieringOption struct {
+	Backend  string
+	QuietFor time.Duration
+}
+
+type autoTiering struct {
+	option AutoTieringOption
+	// the volumes being moved back to the local disk
+	rehydrating sync.Map
+	// when the volumes are moved back to the local disk, so they are not moved to remote again right away
+	rehydratedAt sync.Map
+}
+
+func (vs *VolumeServer) loopAutoTiering() {
+	if vs.autoTiering.option.Backend == "" {
+		return
+	}
+	ticker := time.NewTicker(autoTieringCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-vs.stopChan:
+			return
+		case <-ticker.C:
+		}
+		if _, found := backend.BackendStorages[vs.autoTiering.option.Backend]; !found {
+			glog.V(1).Infof("auto tiering backend %s is not configured yet", vs.autoTiering.option.Backend)
+			continue
+		}
+		for _, vid := range vs.store.FindColdVolumesToTier(vs.autoTiering.option.QuietFor) {
+			if t, found := vs.autoTiering.rehydratedAt.Load(vid); found {
+				if time.Since(t.(time.Time)) < vs.autoTiering.option.QuietFor {
+					continue
+				}
+				vs.autoTiering.rehydratedAt.Delete(vid)
+			}
+			if err := vs.autoTierVolume(vid); err != nil {
+				glog.Errorf("auto tier volume %d to %s: %v", vid, vs.autoTiering.option.Backend, err)
+			}
+		}
+	}
+}
+
+func (vs *VolumeServer) autoTierVolume(vid needle.VolumeId) error {
+	v := vs.store.GetVolume(vid)
+	if v == nil {
+		return fmt.Errorf("volume not found")
+	}
+	start := time.Now()
+	if err := vs.store.MarkVolumeReadonly(vid); err != nil {
+		return err
+	}
+	if err := moveDatToRemote(v, vs.autoTiering.option.Backend, false, func(progressed int64, percentage float32) error {
+		return nil
+	}); err != nil {
+		vs.store.MarkVolumeWritable(vid)
+		return err
+	}
+	glog.V(0).Infof("auto tiered volume %d to %s in %v", vid, vs.autoTiering.option.Backend, time.Since(start))
+	return nil
+}
+
+// maybeRehydrateVolume starts moving the volume back to the local disk, if the volume is tiered by
+// the auto tiering and is written to. The write is rejected until the volume is writable again.
+func (vs *VolumeServer) maybeRehydrateVolume(w http.ResponseWriter, r *http.Request, vid needle.VolumeId) bool {
+	if vs.autoTiering.option.Backend == "" {
+		return false
+	}
+	v := vs.store.GetVolume(vid)
+	if v == nil || !v.HasRemoteFile() {
+		return false
+	}
+	if _, loaded := vs.autoTiering.rehydrating.LoadOrStore(vid, true); !loaded {
+		go func() {
+			defer vs.autoTiering.rehydrating.Delete(vid)
+			if err := vs.rehydrateVolume(vid); err != nil {
+				glog.Errorf("move volume %d back from remote: %v", vid, err)
+				return
+			}
+			vs.autoTiering.rehydratedAt.Store(vid, time.Now())
+		}()
+	}
+	w.Header().Set("Retry-After", "60")
+	writeJsonError(w, r, http.StatusServiceUnavailable, fmt.Errorf("volume %d is being moved back from the remote tier", vid))
+	return true
+}
+
+func (vs *VolumeServer) rehydrateVolume(vid needle.VolumeId) error {
+	v := vs.store.GetVolume(vid)
+	if v == nil {
+		return fmt.Errorf("volume not found")
+	}
+	start := time.Now()
+	if err := moveDatFromRemote(v, false, func(progressed int64, percentage float32) error {
+		return nil
+	}); err != nil {
+		return err
+	}
+	// reload the volume from the local .dat file as a writable volume
+	if err := vs.store.UnmountVolume(vid); err != nil {
+		return fmt.Errorf("unmount: %v", err)
+	}
+	if err := vs.store.MountVolume(vid); err != nil {
+		return fmt.Errorf("mount: %v", err)
+	}
+	glog.V(0).Infof("moved volume %d back from remote in %v", vid, time.Since(start))
+	return nil
+}
@@ -0,0 +1,312 @@
+package weed_server
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/net/webdav"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+const (
+	webDavLockDirectory   = filer.DirectoryEtcSeaweedFS + "/webdav/locks"
+	webDavLockTokenPrefix = "opaquelocktoken:"
+	// used when WebDavOption.LockTimeout is not set
+	defaultWebDavLockTimeout = time.Hour
+	// how long PROPFIND can show locks that were changed by another webdav server
+	webDavLockCacheDuration = time.Second
+)
+
+// webDavLockSystem is an advisory webdav.LockSystem, keeping each lock as an entry in the filer,
+// so the locks survive restarts and are shared by all webdav servers of the filer.
+// The lock roots are kept as full filer paths.
+//
+// For each write request without an If header, the webdav handler takes a temporary lock with
+// infinite timeout and no owner, and unlocks it when the request is done. These only live in memory.
+type webDavLockSystem struct {
+	mu           sync.Mutex
+	filerClient  filer_pb.FilerClient
+	rootPath     string
+	timeout      time.Duration
+	requestLocks map[string]*webDavLock
+
+	cachedLocks     []*webDavLock
+	cachedLocksTime time.Time
+}
+
+type webDavLock struct {
+	Token     string
+	Root      string
+	OwnerXML  string
+	ZeroDepth bool
+	Duration  time.Duration
+	Expires   time.Time
+}
+
+func newWebDavLockSystem(filerClient filer_pb.FilerClient, rootPath string, timeout time.Duration) *webDavLockSystem {
+	if timeout <= 0 {
+		timeout = defaultWebDavLockTimeout
+	}
+	return &webDavLockSystem{
+		filerClient:  filerClient,
+		rootPath:     strings.TrimSuffix(path.Clean("/"+rootPath), "/"),
+		timeout:      timeout,
+		requestLocks: make(map[string]*webDavLock),
+	}
+}
+
+var _ = webdav.LockSystem(&webDavLockSystem{})
+
+// covers tells whether the lock applies to the full path
+func (l *webDavLock) covers(fullPath string) bool {
+	if fullPath == l.Root {
+		return true
+	}
+	return !l.ZeroDepth && (l.Root == "/" || strings.HasPrefix(fullPath, l.Root+"/"))
+}
+
+func (l *webDavLock) conflicts(other *webDavLock) bool {
+	return l.covers(other.Root) || other.covers(l.Root)
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func (ls *webDavLockSystem) fullPath(name string) string {
+	return ls.rootPath + path.Clean("/"+name)
+}
+
+func (ls *webDavLockSystem) details(lock *webDavLock) webdav.LockDetails {
+	root := strings.TrimPrefix(lock.Root, ls.rootPath)
+	if root == "" {
+		root = "/"
+	}
+	return webdav.LockDetails{
+		Root:      root,
+		Duration:  lock.Duration,
+		OwnerXML:  lock.OwnerXML,
+		ZeroDepth: lock.ZeroDepth,
+	}
+}
+
+// duration limits the lock timeout requested by the client
+func (ls *webDavLockSystem) duration(duration time.Duration) time.Duration {
+	if duration < 0 || duration > ls.timeout {
+		return ls.timeout
+	}
+	return duration
+}
+
+// listLocks reads the locks from the filer, and removes the expired ones
+func (ls *webDavLockSystem) listLocks(now time.Time) (locks []*webDavLock, err error) {
+	var expiredTokens []string
+	err = filer_pb.ReadDirAllEntries(ls.filerClient, util.FullPath(webDavLockDirectory), "", func(entry *filer_pb.Entry, isLast bool) error {
+		lock := &webDavLock{}
+		if err := json.Unmarshal(entry.Content, lock); err != nil {
+			glog.Warningf("skip webdav lock %s/%s: %v", webDavLockDirectory, entry.Name, err)
+			return nil
+		}
+		if !lock.Expires.After(now) {
+			expiredTokens = append(expiredTokens, entry.Name)
+			return nil
+		}
+		locks = append(locks, lock)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list webdav locks: %v", err)
+	}
+	for _, token := range expiredTokens {
+		if err := ls.removeLock(token); err != nil {
+			glog.Warningf("remove expired webdav lock %s: %v", token, err)
+		}
+	}
+	ls.cachedLocks, ls.cachedLocksTime = locks, now
+	return locks, nil
+}
+
+func (ls *webDavLockSystem) saveLock(lock *webDavLock) error {
+	data, err := json.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	return ls.filerClient.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		return filer.SaveInsideFiler(client, webDavLockDirectory, strings.TrimPrefix(lock.Token, webDavLockTokenPrefix), data)
+	})
+}
+
+func (ls *webDavLockSystem) removeLock(token string) error {
+	return filer_pb.Remove(ls.filerClient, webDavLockDirectory, strings.TrimPrefix(token, webDavLockTokenPrefix), false, false, false, false, nil)
+}
+
+func (ls *webDavLockSystem) findLock(now time.Time, token string) (*webDavLock, error) {
+	locks, err := ls.listLocks(now)
+	if err != nil {
+		return nil, err
+	}
+	for _, lock := range locks {
+		if lock.Token == token {
+			return lock, nil
+		}
+	}
+	return nil, nil
+}
+
+func (ls *webDavLockSystem) Confirm(now time.Time, name0, name1 string, conditions ...webdav.Condition) (func(), error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	locks, err := ls.listLocks(now)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range []string{name0, name1} {
+		if name == "" {
+			continue
+		}
+		fullPath, confirmed := ls.fullPath(name), false
+		for _, c := range conditions {
+			for _, lock := range locks {
+				if lock.Token == c.Token && lock.covers(fullPath) {
+					confirmed = true
+				}
+			}
+		}
+		if !confirmed {
+			return nil, webdav.ErrConfirmationFailed
+		}
+	}
+	// the locks are advisory, they are not held while serving the request
+	return func() {}, nil
+}
+
+func (ls *webDavLockSystem) Create(now time.Time, details webdav.LockDetails) (string, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	locks, err := ls.listLocks(now)
+	if err != nil {
+		return "", err
+	}
+	lock := &webDavLock{
+		Token:     webDavLockTokenPrefix + uuid.New().String(),
+		Root:      ls.fullPath(details.Root),
+		OwnerXML:  details.OwnerXML,
+		ZeroDepth: details.ZeroDepth,
+		Duration:  ls.duration(details.Duration),
+	}
+	lock.Expires = now.Add(lock.Duration)
+	for _, existing := range locks {
+		if existing.conflicts(lock) {
+			return "", webdav.ErrLocked
+		}
+	}
+	for _, existing := range ls.requestLocks {
+		if existing.conflicts(lock) {
+			return "", webdav.ErrLocked
+		}
+	}
+
+	if details.Duration < 0 && details.ZeroDepth && details.OwnerXML == "" {
+		ls.requestLocks[lock.Token] = lock
+		return lock.Token, nil
+	}
+	if err = ls.saveLock(lock); err != nil {
+		return "", fmt.Errorf("save webdav lock %s: %v", lock.Root, err)
+	}
+	ls.cachedLocks = append(ls.cachedLocks, lock)
+	return lock.Token, nil
+}
+
+func (ls *webDavLockSystem) Refresh(now time.Time, token string, duration time.Duration) (webdav.LockDetails, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	lock, err := ls.findLock(now, token)
+	if err != nil {
+		return webdav.LockDetails{}, err
+	}
+	if lock == nil {
+		return webdav.LockDetails{}, webdav.ErrNoSuchLock
+	}
+	lock.Duration = ls.duration(duration)
+	lock.Expires = now.Add(lock.Duration)
+	if err = ls.saveLock(lock); err != nil {
+		return webdav.LockDetails{}, fmt.Errorf("save webdav lock %s: %v", lock.Root, err)
+	}
+	return ls.details(lock), nil
+}
+
+func (ls *webDavLockSystem) Unlock(now time.Time, token string) error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if _, found := ls.requestLocks[token]; found {
+		delete(ls.requestLocks, token)
+		return nil
+	}
+	lock, err := ls.findLock(now, token)
+	if err != nil {
+		return err
+	}
+	if lock == nil {
+		return webdav.ErrNoSuchLock
+	}
+	if err = ls.removeLock(token); err != nil {
+		return fmt.Errorf("remove webdav lock %s: %v", lock.Root, err)
+	}
+	ls.cachedLocksTime = time.Time{}
+	return nil
+}
+
+// lockDiscovery returns the DAV:lockdiscovery property of the full filer path.
+// It reads the locks from the filer at most once per webDavLockCacheDuration,
+// since PROPFIND asks for the properties of every file in a directory.
+func (ls *webDavLockSystem) lockDiscovery(now time.Time, fullPath string) (string, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	locks := ls.cachedLocks
+	if now.Sub(ls.cachedLocksTime) > webDavLockCacheDuration {
+		var err error
+		if locks, err = ls.listLocks(now); err != nil {
+			return "", err
+		}
+	}
+
+	var b strings.Builder
+	for _, lock := range locks {
+		if !lock.covers(fullPath) || !lock.Expires.After(now) {
+			continue
+		}
+		depth := "infinity"
+		if lock.ZeroDepth {
+			depth = "0"
+		}
+		details := ls.details(lock)
+		fmt.Fprintf(&b, `<D:activelock xmlns:D="DAV:">`+
+			`<D:locktype><D:write/></D:locktype>`+
+			`<D:lockscope><D:exclusive/></D:lockscope>`+
+			`<D:depth>%s</D:depth>`+
+			`<D:owner>%s</D:owner>`+
+			`<D:timeout>Second-%d</D:timeout>`+
+			`<D:locktoken><D:href>%s</D:href></D:locktoken>`+
+			`<D:lockroot><D:href>%s</D:href></D:lockroot>`+
+			`</D:activelock>`,
+			depth, lock.OwnerXML, int64(lock.Expires.Sub(now)/time.Second), xmlEscape(lock.Token), xmlEscape(details.Root))
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,44 @@
+package weed_server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWebDavLockConflicts(t *testing.T) {
+	dir := &webDavLock{Root: "/a"}
+	file := &webDavLock{Root: "/a/b", ZeroDepth: true}
+	other := &webDavLock{Root: "/ab", ZeroDepth: true}
+
+	if !dir.covers("/a/b/c") || !file.covers("/a/b") || file.covers("/a/b/c") || dir.covers("/ab") {
+		t.Errorf("unexpected lock coverage")
+	}
+	if !dir.conflicts(file) || !file.conflicts(dir) {
+		t.Errorf("locks on /a and /a/b should conflict")
+	}
+	if dir.conflicts(other) || file.conflicts(other) {
+		t.Errorf("lock on /ab should not conflict with /a or /a/b")
+	}
+	if !(&webDavLock{Root: "/"}).covers("/x") {
+		t.Errorf("lock on / should cover everything")
+	}
+}
+
+func TestWebDavLockPaths(t *testing.T) {
+	ls := newWebDavLockSystem(nil, "/buckets/", 0)
+	if ls.timeout != defaultWebDavLockTimeout {
+		t.Errorf("timeout %v, expected %v", ls.timeout, defaultWebDavLockTimeout)
+	}
+	if fullPath := ls.fullPath("/docs/a.docx"); fullPath != "/buckets/docs/a.docx" {
+		t.Errorf("full path %s", fullPath)
+	}
+	if root := ls.details(&webDavLock{Root: "/buckets"}).Root; root != "/" {
+		t.Errorf("root %s, expected /", root)
+	}
+	if d := ls.duration(-1); d != time.Hour {
+		t.Errorf("infinite timeout should be limited, got %v", d)
+	}
+	if d := ls.duration(time.Minute); d != time.Minute {
+		t.Errorf("duration %v, expected 1m", d)
+	}
+}
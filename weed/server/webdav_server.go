@@ -2,8 +2,10 @@ package weed_server
 
 import (
 	"context"
+	"encoding/xml"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path"
 	"strings"
@@ -39,6 +41,7 @@ type WebDavOption struct {
 	CacheDir       string
 	CacheSizeMB    int64
 	MaxMB          int
+	LockTimeout    time.Duration // the longest lock timeout, also for locks asking for an infinite timeout
 }
 
 type WebDavServer struct {
@@ -59,6 +62,7 @@ func max(x, y int64) int64 {
 func NewWebDavServer(option *WebDavOption) (ws *WebDavServer, err error) {
 
 	fs, _ := NewWebDavFileSystem(option)
+	locks := fs.(*WebDavFileSystem).locks
 
 	// Fix no set filer.path , accessing "/" returns "//"
 	if option.FilerRootPath == "/" {
@@ -74,7 +78,7 @@ func NewWebDavServer(option *WebDavOption) (ws *WebDavServer, err error) {
 		grpcDialOption: security.LoadClientTLS(util.GetViper(), "grpc.filer"),
 		Handler: &webdav.Handler{
 			FileSystem: fs,
-			LockSystem: webdav.NewMemLS(),
+			LockSystem: locks,
 		},
 	}
 
@@ -90,6 +94,7 @@ type WebDavFileSystem struct {
 	chunkCache     *chunk_cache.TieredChunkCache
 	readerCache    *filer.ReaderCache
 	signature      int32
+	locks          *webDavLockSystem
 }
 
 type FileInfo struct {
@@ -136,6 +141,7 @@ func NewWebDavFileSystem(option *WebDavOption) (webdav.FileSystem, error) {
 		signature:  util.RandomInt32(),
 	}
 	t.readerCache = filer.NewReaderCache(32, chunkCache, filer.LookupFn(t))
+	t.locks = newWebDavLockSystem(t, option.FilerRootPath, option.LockTimeout)
 	return t, nil
 }
 
@@ -634,3 +640,27 @@ func (f *WebDavFile) Stat() (os.FileInfo, error) {
 
 	return f.fs.stat(ctx, f.name)
 }
+
+var lockDiscoveryName = xml.Name{Space: "DAV:", Local: "lockdiscovery"}
+
+// DeadProps only has the DAV:lockdiscovery property, listing the active locks on the file.
+func (f *WebDavFile) DeadProps() (map[xml.Name]webdav.Property, error) {
+	activeLocks, err := f.fs.locks.lockDiscovery(time.Now(), path.Clean(f.name))
+	if err != nil {
+		return nil, err
+	}
+	return map[xml.Name]webdav.Property{
+		lockDiscoveryName: {XMLName: lockDiscoveryName, InnerXML: []byte(activeLocks)},
+	}, nil
+}
+
+// Patch forbids changing any property, the same as when DeadProps is not implemented.
+func (f *WebDavFile) Patch(patches []webdav.Proppatch) ([]webdav.Propstat, error) {
+	pstat := webdav.Propstat{Status: http.StatusForbidden}
+	for _, patch := range patches {
+		for _, p := range patch.Props {
+			pstat.Props = append(pstat.Props, webdav.Property{XMLName: p.XMLName})
+		}
+	}
+	return []webdav.Propstat{pstat}, nil
+}
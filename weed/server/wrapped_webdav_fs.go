@@ -2,6 +2,7 @@ package weed_server
 
 import (
 	"context"
+	"encoding/xml"
 	"golang.org/x/net/webdav"
 	"io/fs"
 	"os"
@@ -86,6 +87,20 @@ func (w wrappedFile) Stat() (fs.FileInfo, error) {
 	return info, err
 }
 
+func (w wrappedFile) DeadProps() (map[xml.Name]webdav.Property, error) {
+	if dph, ok := w.File.(webdav.DeadPropsHolder); ok {
+		return dph.DeadProps()
+	}
+	return nil, nil
+}
+
+func (w wrappedFile) Patch(patches []webdav.Proppatch) ([]webdav.Propstat, error) {
+	if dph, ok := w.File.(webdav.DeadPropsHolder); ok {
+		return dph.Patch(patches)
+	}
+	return nil, webdav.ErrNotImplemented
+}
+
 type wrappedFileInfo struct {
 	subFolder *string
 	fs.FileInfo
@@ -0,0 +1,159 @@
+package shell
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/pb/master_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func init() {
+	Commands = append(Commands, &commandFilerDirQuotaReport{})
+}
+
+type commandFilerDirQuotaReport struct {
+}
+
+type dirUsage struct {
+	path      string
+	byteCount uint64
+	fileCount uint64
+}
+
+func (c *commandFilerDirQuotaReport) Name() string {
+	return "filer.dir.quota.report"
+}
+
+func (c *commandFilerDirQuotaReport) Help() string {
+	return `show the directories using the most space
+
+	filer.dir.quota.report [-depth=3] [-top=20] [-collection=tenant1] /buckets
+
+	The directory tree is walked using the filer metadata. The size of a directory
+	is the sum of the file sizes of all files under it, at any depth. The directories
+	down to -depth levels below the input directory are reported, largest first.
+
+	With -collection, only the files stored in the volumes of that collection are counted.
+	The volumes of the collection are looked up from the master.
+`
+}
+
+func (c *commandFilerDirQuotaReport) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+
+	reportCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	depth := reportCommand.Int("depth", 3, "report the directories down to this depth below the input directory")
+	top := reportCommand.Int("top", 20, "number of directories to report")
+	collection := reportCommand.String("collection", "", "only count the chunks in this collection")
+	if err = reportCommand.Parse(args); err != nil {
+		return nil
+	}
+
+	path, err := commandEnv.parseUrl(findInputDirectory(reportCommand.Args()))
+	if err != nil {
+		return err
+	}
+	if err = commandEnv.checkDirectory(path); err != nil {
+		return err
+	}
+
+	var volumeIds map[string]bool
+	if *collection != "" {
+		topologyInfo, _, err := collectTopologyInfo(commandEnv, 0)
+		if err != nil {
+			return err
+		}
+		volumeIds = collectionVolumeIds(topologyInfo, *collection)
+	}
+
+	var usages []dirUsage
+	if _, err = dirQuotaTraverse(commandEnv, util.FullPath(path), 0, *depth, volumeIds, &usages); err != nil {
+		return err
+	}
+
+	slices.SortFunc(usages, func(a, b dirUsage) int {
+		if a.byteCount != b.byteCount {
+			if a.byteCount > b.byteCount {
+				return -1
+			}
+			return 1
+		}
+		if a.path < b.path {
+			return -1
+		}
+		return 1
+	})
+	if *top > 0 && len(usages) > *top {
+		usages = usages[:*top]
+	}
+	for _, usage := range usages {
+		fmt.Fprintf(writer, "size:%14d %10s\tfiles:%10d\t%s\n", usage.byteCount, util.BytesToHumanReadable(usage.byteCount), usage.fileCount, usage.path)
+	}
+	return nil
+}
+
+func dirQuotaTraverse(filerClient filer_pb.FilerClient, dir util.FullPath, depth, maxDepth int, volumeIds map[string]bool, usages *[]dirUsage) (usage dirUsage, err error) {
+	usage.path = string(dir)
+	err = filer_pb.ReadDirAllEntries(filerClient, dir, "", func(entry *filer_pb.Entry, isLast bool) error {
+		if entry.IsDirectory {
+			subUsage, err := dirQuotaTraverse(filerClient, dir.Child(entry.Name), depth+1, maxDepth, volumeIds, usages)
+			if err != nil {
+				return err
+			}
+			usage.byteCount += subUsage.byteCount
+			usage.fileCount += subUsage.fileCount
+			return nil
+		}
+		usage.byteCount += entryChunkSize(entry, volumeIds)
+		usage.fileCount++
+		return nil
+	})
+	if err != nil {
+		return usage, fmt.Errorf("list %s: %v", dir, err)
+	}
+	if depth <= maxDepth {
+		*usages = append(*usages, usage)
+	}
+	return usage, nil
+}
+
+// entryChunkSize returns the file size of the entry, or if volumeIds is not nil,
+// the file size of the entry only if its chunks are stored in volumeIds.
+// The chunk manifests are not resolved, since they are in the same collection as their data chunks.
+func entryChunkSize(entry *filer_pb.Entry, volumeIds map[string]bool) uint64 {
+	size := max(filer.FileSize(entry), uint64(len(entry.Content)))
+	if volumeIds == nil {
+		return size
+	}
+	for _, chunk := range entry.GetChunks() {
+		if volumeIds[filer.VolumeId(chunk.GetFileIdString())] {
+			return size
+		}
+	}
+	return 0
+}
+
+func collectionVolumeIds(topologyInfo *master_pb.TopologyInfo, collection string) map[string]bool {
+	volumeIds := make(map[string]bool)
+	eachDataNode(topologyInfo, func(dc string, rack RackId, nodeInfo *master_pb.DataNodeInfo) {
+		for _, diskInfo := range nodeInfo.DiskInfos {
+			for _, vi := range diskInfo.VolumeInfos {
+				if vi.Collection == collection {
+					volumeIds[strconv.FormatUint(uint64(vi.Id), 10)] = true
+				}
+			}
+			for _, ecShardInfo := range diskInfo.EcShardInfos {
+				if ecShardInfo.Collection == collection {
+					volumeIds[strconv.FormatUint(uint64(ecShardInfo.Id), 10)] = true
+				}
+			}
+		}
+	})
+	return volumeIds
+}
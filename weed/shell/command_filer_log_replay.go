@@ -0,0 +1,248 @@
+package shell
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func init() {
+	Commands = append(Commands, &commandFilerLogReplay{})
+}
+
+type commandFilerLogReplay struct {
+}
+
+func (c *commandFilerLogReplay) Name() string {
+	return "filer.log.replay"
+}
+
+func (c *commandFilerLogReplay) Help() string {
+	return `print the meta data changes persisted in the filer logs
+
+	filer.log.replay [-since 24h] [-until 2024-01-02T15:04:05Z] [-pathPrefix /buckets/] [-v]
+
+	The log files are read from ` + filer.SystemLogDir + `, without subscribing to a running filer.
+	-since and -until accept either a duration before now, or a time in RFC3339 format.
+	Each change is printed as one line with its time, the operation and the path.
+	With -v, the full event is printed as well.
+`
+}
+
+func (c *commandFilerLogReplay) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+	logReplayCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	sinceStr := logReplayCommand.String("since", "", "only print changes after this time, e.g. 2h or 2024-01-02T15:04:05Z")
+	untilStr := logReplayCommand.String("until", "", "only print changes before this time, e.g. 1h or 2024-01-02T16:04:05Z")
+	pathPrefix := logReplayCommand.String("pathPrefix", "/", "only print changes under this path")
+	verbose := logReplayCommand.Bool("v", false, "print the full event")
+	if err = logReplayCommand.Parse(args); err != nil {
+		return err
+	}
+
+	var since, until time.Time
+	if since, err = parseLogReplayTime(*sinceStr); err != nil {
+		return fmt.Errorf("parse -since: %v", err)
+	}
+	if until, err = parseLogReplayTime(*untilStr); err != nil {
+		return fmt.Errorf("parse -until: %v", err)
+	}
+
+	var dayDirs []string
+	if err = filer_pb.ReadDirAllEntries(commandEnv, filer.SystemLogDir, "", func(entry *filer_pb.Entry, isLast bool) error {
+		if !entry.IsDirectory {
+			return nil
+		}
+		dayTime, parseErr := time.Parse("2006-01-02", entry.Name)
+		if parseErr != nil {
+			return nil
+		}
+		// a log file starting before midnight may contain changes of the next day
+		if !since.IsZero() && dayTime.Add(25*time.Hour).Before(since) {
+			return nil
+		}
+		if !until.IsZero() && dayTime.After(until) {
+			return nil
+		}
+		dayDirs = append(dayDirs, entry.Name)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("list %s: %v", filer.SystemLogDir, err)
+	}
+
+	replay := &logReplay{
+		commandEnv: commandEnv,
+		writer:     writer,
+		since:      since,
+		until:      until,
+		pathPrefix: *pathPrefix,
+		verbose:    *verbose,
+	}
+	for _, dayDir := range dayDirs {
+		if err = replay.replayDay(dayDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseLogReplayTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+type logReplay struct {
+	commandEnv *CommandEnv
+	writer     io.Writer
+	since      time.Time
+	until      time.Time
+	pathPrefix string
+	verbose    bool
+}
+
+// replayDay prints the changes of one day, merging the log files written by different filers in the same minute.
+func (r *logReplay) replayDay(dayDir string) error {
+	dir := util.FullPath(filer.SystemLogDir).Child(dayDir)
+
+	var minute string
+	var minuteFiles []*filer_pb.Entry
+	err := filer_pb.ReadDirAllEntries(r.commandEnv, dir, "", func(entry *filer_pb.Entry, isLast bool) error {
+		if entry.IsDirectory {
+			return nil
+		}
+		// log file names are hh-mm.<filer id>
+		fileMinute, _, _ := strings.Cut(entry.Name, ".")
+		if !r.until.IsZero() {
+			if minuteTime, parseErr := time.Parse("2006-01-02 15-04", dayDir+" "+fileMinute); parseErr == nil && minuteTime.After(r.until) {
+				return nil
+			}
+		}
+		if fileMinute != minute {
+			if err := r.replayFiles(dir, minuteFiles); err != nil {
+				return err
+			}
+			minute, minuteFiles = fileMinute, nil
+		}
+		minuteFiles = append(minuteFiles, entry)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("replay %s: %v", dir, err)
+	}
+	return r.replayFiles(dir, minuteFiles)
+}
+
+func (r *logReplay) replayFiles(dir util.FullPath, logFiles []*filer_pb.Entry) error {
+	var events []*filer_pb.SubscribeMetadataResponse
+	for _, logFile := range logFiles {
+		fileEvents, err := r.readLogFile(logFile)
+		if err != nil {
+			return fmt.Errorf("read %s: %v", dir.Child(logFile.Name), err)
+		}
+		events = append(events, fileEvents...)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].TsNs < events[j].TsNs
+	})
+	for _, event := range events {
+		r.printEvent(event)
+	}
+	return nil
+}
+
+// readLogFile decodes the log entries, each one a 4 bytes size followed by a filer_pb.LogEntry.
+func (r *logReplay) readLogFile(logFile *filer_pb.Entry) (events []*filer_pb.SubscribeMetadataResponse, err error) {
+	var reader io.Reader
+	if len(logFile.Content) > 0 {
+		reader = strings.NewReader(string(logFile.Content))
+	} else {
+		reader = filer.NewChunkStreamReader(r.commandEnv, logFile.GetChunks())
+	}
+
+	sizeBuf := make([]byte, 4)
+	for {
+		if _, err = io.ReadFull(reader, sizeBuf); err != nil {
+			if err == io.EOF {
+				return events, nil
+			}
+			return nil, err
+		}
+		data := make([]byte, util.BytesToUint32(sizeBuf))
+		if _, err = io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		logEntry := &filer_pb.LogEntry{}
+		if err = proto.Unmarshal(data, logEntry); err != nil {
+			return nil, fmt.Errorf("unmarshal log entry: %v", err)
+		}
+		event := &filer_pb.SubscribeMetadataResponse{}
+		if err = proto.Unmarshal(logEntry.Data, event); err != nil {
+			return nil, fmt.Errorf("unmarshal event: %v", err)
+		}
+		if r.isSelected(event) {
+			events = append(events, event)
+		}
+	}
+}
+
+func (r *logReplay) isSelected(event *filer_pb.SubscribeMetadataResponse) bool {
+	if filer_pb.IsEmpty(event) {
+		return false
+	}
+	if !r.since.IsZero() && event.TsNs < r.since.UnixNano() {
+		return false
+	}
+	if !r.until.IsZero() && event.TsNs > r.until.UnixNano() {
+		return false
+	}
+	oldPath, newPath := eventPaths(event)
+	return oldPath != "" && strings.HasPrefix(oldPath, r.pathPrefix) ||
+		newPath != "" && strings.HasPrefix(newPath, r.pathPrefix)
+}
+
+func (r *logReplay) printEvent(event *filer_pb.SubscribeMetadataResponse) {
+	ts := time.Unix(0, event.TsNs).UTC().Format(time.RFC3339Nano)
+	oldPath, newPath := eventPaths(event)
+	switch {
+	case oldPath == "":
+		fmt.Fprintf(r.writer, "%s create %s\n", ts, newPath)
+	case newPath == "":
+		fmt.Fprintf(r.writer, "%s delete %s\n", ts, oldPath)
+	case oldPath != newPath:
+		fmt.Fprintf(r.writer, "%s rename %s => %s\n", ts, oldPath, newPath)
+	default:
+		fmt.Fprintf(r.writer, "%s update %s\n", ts, newPath)
+	}
+	if r.verbose {
+		filer.ProtoToText(r.writer, event)
+		fmt.Fprintln(r.writer)
+	}
+}
+
+func eventPaths(event *filer_pb.SubscribeMetadataResponse) (oldPath, newPath string) {
+	notification := event.EventNotification
+	if notification.OldEntry != nil {
+		oldPath = string(util.NewFullPath(event.Directory, notification.OldEntry.Name))
+	}
+	if notification.NewEntry != nil {
+		newParentPath := notification.NewParentPath
+		if newParentPath == "" {
+			newParentPath = event.Directory
+		}
+		newPath = string(util.NewFullPath(newParentPath, notification.NewEntry.Name))
+	}
+	return
+}
@@ -0,0 +1,65 @@
+package shell
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func appendLogEntry(t *testing.T, buf *bytes.Buffer, event *filer_pb.SubscribeMetadataResponse) {
+	data, err := proto.Marshal(event)
+	assert.Nil(t, err)
+	logEntryData, err := proto.Marshal(&filer_pb.LogEntry{TsNs: event.TsNs, Data: data})
+	assert.Nil(t, err)
+	sizeBuf := make([]byte, 4)
+	util.Uint32toBytes(sizeBuf, uint32(len(logEntryData)))
+	buf.Write(sizeBuf)
+	buf.Write(logEntryData)
+}
+
+func TestFilerLogReplayReadLogFile(t *testing.T) {
+	now := time.Now()
+
+	var buf bytes.Buffer
+	appendLogEntry(t, &buf, &filer_pb.SubscribeMetadataResponse{
+		Directory: "/buckets/b1",
+		TsNs:      now.Add(-2 * time.Hour).UnixNano(),
+		EventNotification: &filer_pb.EventNotification{
+			NewEntry: &filer_pb.Entry{Name: "old.txt"},
+		},
+	})
+	appendLogEntry(t, &buf, &filer_pb.SubscribeMetadataResponse{
+		Directory: "/buckets/b1",
+		TsNs:      now.UnixNano(),
+		EventNotification: &filer_pb.EventNotification{
+			OldEntry:      &filer_pb.Entry{Name: "a.txt"},
+			NewEntry:      &filer_pb.Entry{Name: "b.txt"},
+			NewParentPath: "/buckets/b2",
+		},
+	})
+	appendLogEntry(t, &buf, &filer_pb.SubscribeMetadataResponse{
+		Directory: "/tmp",
+		TsNs:      now.UnixNano(),
+		EventNotification: &filer_pb.EventNotification{
+			OldEntry: &filer_pb.Entry{Name: "c.txt"},
+		},
+	})
+
+	replay := &logReplay{
+		since:      now.Add(-time.Hour),
+		pathPrefix: "/buckets/",
+	}
+	events, err := replay.readLogFile(&filer_pb.Entry{Content: buf.Bytes()})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(events))
+
+	oldPath, newPath := eventPaths(events[0])
+	assert.Equal(t, "/buckets/b1/a.txt", oldPath)
+	assert.Equal(t, "/buckets/b2/b.txt", newPath)
+}
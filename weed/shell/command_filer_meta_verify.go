@@ -0,0 +1,262 @@
+package shell
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/pb"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/security"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func init() {
+	Commands = append(Commands, &commandFilerMetaVerify{})
+}
+
+type commandFilerMetaVerify struct {
+}
+
+func (c *commandFilerMetaVerify) Name() string {
+	return "filer.meta.verify"
+}
+
+func (c *commandFilerMetaVerify) Help() string {
+	return `find the filer entries with chunks that can not be reached on the volume servers
+
+	filer.meta.verify [-filer=localhost:8888] [-dir=/] [-output=broken.txt] [-concurrency=16]
+
+	How it works:
+
+	walk the directory tree on the filer, by default the filer of this shell
+	resolve the chunk manifests of each file
+	send a HEAD request for each chunk to the volume servers, <concurrency> requests at a time
+	  a chunk is reachable if any of its volume locations answers
+	print the files with unreachable chunks, and also write them to the output file
+`
+}
+
+func (c *commandFilerMetaVerify) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+
+	verifyCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	filerAddress := verifyCommand.String("filer", "", "the filer http address, default to the filer of this shell")
+	dir := verifyCommand.String("dir", "/", "the directory to verify")
+	outputFile := verifyCommand.String("output", "", "also write the files with unreachable chunks to this file")
+	concurrency := verifyCommand.Int("concurrency", 16, "number of chunks to check in parallel")
+	if err = verifyCommand.Parse(args); err != nil {
+		return nil
+	}
+	if *concurrency <= 0 {
+		return fmt.Errorf("concurrency should be positive")
+	}
+
+	var filerClient filer_pb.FilerClient = commandEnv
+	if *filerAddress != "" {
+		filerClient = &metaVerifyFilerClient{
+			address:        pb.ServerAddress(*filerAddress),
+			grpcDialOption: commandEnv.option.GrpcDialOption,
+			dataCenter:     commandEnv.MasterClient.DataCenter,
+		}
+	}
+
+	output := writer
+	if *outputFile != "" {
+		f, err := os.OpenFile(*outputFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return fmt.Errorf("open %s: %v", *outputFile, err)
+		}
+		defer f.Close()
+		output = io.MultiWriter(writer, f)
+	}
+
+	v := util.GetViper()
+	verifier := &metaVerifier{
+		filerClient:      filerClient,
+		locations:        make(map[string][]string),
+		readSigningKey:   security.SigningKey(v.GetString("jwt.signing.read.key")),
+		readExpiresAfter: v.GetInt("jwt.signing.read.expires_after_seconds"),
+	}
+
+	type chunkCheck struct {
+		result *metaVerifyResult
+		fileId string
+	}
+	checks := make(chan chunkCheck, 1024)
+	var workerWg sync.WaitGroup
+	for i := 0; i < *concurrency; i++ {
+		workerWg.Add(1)
+		go func() {
+			defer workerWg.Done()
+			for check := range checks {
+				if err := verifier.checkChunk(check.fileId); err != nil {
+					check.result.addError(err)
+				}
+				check.result.wg.Done()
+			}
+		}()
+	}
+
+	// results are printed in the order the files are found, once all their chunks are checked
+	results := make(chan *metaVerifyResult, 1024)
+	var printWg sync.WaitGroup
+	var fileCount, chunkCount, brokenCount int
+	printWg.Add(1)
+	go func() {
+		defer printWg.Done()
+		for result := range results {
+			result.wg.Wait()
+			fileCount++
+			chunkCount += result.chunkCount
+			if len(result.errors) > 0 {
+				brokenCount++
+				fmt.Fprintf(output, "%s\t%s\n", result.path, strings.Join(result.errors, "; "))
+			}
+		}
+	}()
+
+	walkErr := filer_pb.TraverseBfs(filerClient, util.FullPath(*dir), func(parentPath util.FullPath, entry *filer_pb.Entry) {
+		if strings.HasPrefix(string(parentPath), filer.SystemLogDir) || entry.IsDirectory {
+			return
+		}
+		result := &metaVerifyResult{path: parentPath.Child(entry.Name)}
+		dataChunks, manifestChunks, resolveErr := filer.ResolveChunkManifest(verifier.lookupFileId, entry.GetChunks(), 0, math.MaxInt64)
+		if resolveErr != nil {
+			result.addError(fmt.Errorf("resolve chunk manifest: %v", resolveErr))
+		}
+		chunks := append(dataChunks, manifestChunks...)
+		result.chunkCount = len(chunks)
+		result.wg.Add(len(chunks))
+		results <- result
+		for _, chunk := range chunks {
+			checks <- chunkCheck{result: result, fileId: chunk.GetFileIdString()}
+		}
+	})
+	close(checks)
+	close(results)
+	workerWg.Wait()
+	printWg.Wait()
+
+	if walkErr != nil {
+		return fmt.Errorf("traverse %s: %v", *dir, walkErr)
+	}
+	fmt.Fprintf(writer, "total %d files, %d chunks, %d files with unreachable chunks\n", fileCount, chunkCount, brokenCount)
+	return nil
+}
+
+// metaVerifyResult collects the unreachable chunks of one file.
+type metaVerifyResult struct {
+	path       util.FullPath
+	chunkCount int
+	wg         sync.WaitGroup
+	mu         sync.Mutex
+	errors     []string
+}
+
+func (r *metaVerifyResult) addError(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors = append(r.errors, err.Error())
+}
+
+type metaVerifier struct {
+	filerClient      filer_pb.FilerClient
+	locationsLock    sync.Mutex
+	locations        map[string][]string // volume id to the volume server urls, empty if the volume is not found
+	readSigningKey   security.SigningKey
+	readExpiresAfter int
+}
+
+// checkChunk returns nil if any volume server holding the chunk answers a HEAD request for it.
+func (v *metaVerifier) checkChunk(fileId string) error {
+	urls, err := v.lookupVolume(filer.VolumeId(fileId))
+	if err != nil {
+		return fmt.Errorf("%s: %v", fileId, err)
+	}
+	if len(urls) == 0 {
+		return fmt.Errorf("%s: volume not found", fileId)
+	}
+	var jwt security.EncodedJwt
+	if len(v.readSigningKey) > 0 {
+		jwt = security.GenJwtForVolumeServer(v.readSigningKey, v.readExpiresAfter, fileId)
+	}
+	var lastErr error
+	for _, url := range urls {
+		if _, lastErr = util.HeadAuthenticated(fmt.Sprintf("http://%s/%s", url, fileId), string(jwt)); lastErr == nil {
+			return nil
+		}
+	}
+	return lastErr
+}
+
+// lookupFileId resolves the chunk manifests with the same volume locations as the chunk checks.
+func (v *metaVerifier) lookupFileId(fileId string) (fullUrls []string, err error) {
+	urls, err := v.lookupVolume(filer.VolumeId(fileId))
+	if err != nil {
+		return nil, err
+	}
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("volume of %s not found", fileId)
+	}
+	for _, url := range urls {
+		fullUrls = append(fullUrls, fmt.Sprintf("http://%s/%s", url, fileId))
+	}
+	return fullUrls, nil
+}
+
+// lookupVolume asks the filer once for each volume, and remembers the missing volumes too,
+// so that the chunks of a lost volume do not retry the lookup.
+func (v *metaVerifier) lookupVolume(vid string) ([]string, error) {
+	v.locationsLock.Lock()
+	defer v.locationsLock.Unlock()
+	if urls, found := v.locations[vid]; found {
+		return urls, nil
+	}
+	var urls []string
+	err := v.filerClient.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		resp, err := client.LookupVolume(context.Background(), &filer_pb.LookupVolumeRequest{
+			VolumeIds: []string{vid},
+		})
+		if err != nil {
+			return err
+		}
+		if locations, found := resp.LocationsMap[vid]; found {
+			for _, loc := range locations.Locations {
+				urls = append(urls, v.filerClient.AdjustedUrl(loc))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("lookup volume %s: %v", vid, err)
+	}
+	v.locations[vid] = urls
+	return urls, nil
+}
+
+// metaVerifyFilerClient connects to a filer other than the one of the shell.
+type metaVerifyFilerClient struct {
+	address        pb.ServerAddress
+	grpcDialOption grpc.DialOption
+	dataCenter     string
+}
+
+func (fc *metaVerifyFilerClient) WithFilerClient(streamingMode bool, fn func(filer_pb.SeaweedFilerClient) error) error {
+	return pb.WithGrpcFilerClient(streamingMode, 0, fc.address, fc.grpcDialOption, fn)
+}
+
+func (fc *metaVerifyFilerClient) AdjustedUrl(location *filer_pb.Location) string {
+	return location.Url
+}
+
+func (fc *metaVerifyFilerClient) GetDataCenter() string {
+	return fc.dataCenter
+}
@@ -26,7 +26,7 @@ var (
 )
 
 func init() {
-	client = &http.Client{}
+	client = &http.Client{Transport: util.NewInternalTransport(&http.Transport{})}
 	Commands = append(Commands, &commandFsMergeVolumes{})
 }
 
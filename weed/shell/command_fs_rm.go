@@ -2,10 +2,16 @@ package shell
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/seaweedfs/seaweedfs/weed/filer"
 	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
 	"github.com/seaweedfs/seaweedfs/weed/util"
 )
@@ -24,25 +30,43 @@ func (c *commandFsRm) Name() string {
 func (c *commandFsRm) Help() string {
 	return `remove file and directory entries
 
-	fs.rm [-rf] <entry1> <entry2> ...
+	fs.rm [-rf] [-concurrency=N] [-progressInterval=10s] <entry1> <entry2> ...
 
 	fs.rm /dir/file_name1 dir/file_name2
 	fs.rm /dir
+	fs.rm -rf -concurrency=32 /large_dir
 
 	The option "-r" can be recursive.
 	The option "-f" can be ignored by recursive error.
+	The option "-concurrency" deletes the files of a recursive removal with N parallel requests,
+	and prints the deleted, errors and bytesReclaimed statistics every "-progressInterval".
+	The file chunks are deleted asynchronously by the filer.
 `
 }
 
 func (c *commandFsRm) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
 	isRecursive := false
 	ignoreRecursiveError := false
+	concurrency := 1
+	progressInterval := 10 * time.Second
 	var entries []string
 	for _, arg := range args {
 		if !strings.HasPrefix(arg, "-") {
 			entries = append(entries, arg)
 			continue
 		}
+		if value, found := strings.CutPrefix(arg, "-concurrency="); found {
+			if concurrency, err = strconv.Atoi(value); err != nil || concurrency <= 0 {
+				return fmt.Errorf("invalid concurrency %s", value)
+			}
+			continue
+		}
+		if value, found := strings.CutPrefix(arg, "-progressInterval="); found {
+			if progressInterval, err = time.ParseDuration(value); err != nil || progressInterval <= 0 {
+				return fmt.Errorf("invalid progressInterval %s", value)
+			}
+			continue
+		}
 		for _, t := range arg {
 			switch t {
 			case 'r':
@@ -70,12 +94,17 @@ func (c *commandFsRm) Do(args []string, commandEnv *CommandEnv, writer io.Writer
 				Directory: targetDir,
 				Name:      targetName,
 			}
-			_, err = filer_pb.LookupEntry(client, lookupRequest)
+			resp, err := filer_pb.LookupEntry(client, lookupRequest)
 			if err != nil {
 				fmt.Fprintf(writer, "rm: %s: %v\n", targetPath, err)
 				continue
 			}
 
+			if isRecursive && concurrency > 1 && resp.Entry.IsDirectory {
+				// remove the files in parallel, then the left over directories below
+				removeFilesInParallel(commandEnv, client, util.FullPath(targetPath), concurrency, progressInterval, writer)
+			}
+
 			request := &filer_pb.DeleteEntryRequest{
 				Directory:            targetDir,
 				Name:                 targetName,
@@ -98,3 +127,85 @@ func (c *commandFsRm) Do(args []string, commandEnv *CommandEnv, writer io.Writer
 
 	return
 }
+
+type rmStats struct {
+	deleted        atomic.Int64
+	errors         atomic.Int64
+	bytesReclaimed atomic.Int64
+
+	writerLock sync.Mutex // the workers and the progress ticker share the writer
+	writer     io.Writer
+}
+
+func (s *rmStats) printf(format string, a ...interface{}) {
+	s.writerLock.Lock()
+	defer s.writerLock.Unlock()
+	fmt.Fprintf(s.writer, format, a...)
+}
+
+func (s *rmStats) print() {
+	s.printf("deleted:%d errors:%d bytesReclaimed:%d\n", s.deleted.Load(), s.errors.Load(), s.bytesReclaimed.Load())
+}
+
+func removeFilesInParallel(commandEnv *CommandEnv, client filer_pb.SeaweedFilerClient, dir util.FullPath, concurrency int, progressInterval time.Duration, writer io.Writer) {
+
+	stats := rmStats{writer: writer}
+	type fileToDelete struct {
+		parentPath util.FullPath
+		entry      *filer_pb.Entry
+	}
+	files := make(chan fileToDelete, concurrency*4)
+
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range files {
+				resp, err := client.DeleteEntry(context.Background(), &filer_pb.DeleteEntryRequest{
+					Directory:    string(f.parentPath),
+					Name:         f.entry.Name,
+					IsDeleteData: true,
+				})
+				if err == nil && resp.Error != "" {
+					err = errors.New(resp.Error)
+				}
+				if err != nil {
+					stats.printf("rm: %s: %v\n", f.parentPath.Child(f.entry.Name), err)
+					stats.errors.Add(1)
+					continue
+				}
+				stats.deleted.Add(1)
+				stats.bytesReclaimed.Add(int64(filer.FileSize(f.entry)))
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				stats.print()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := filer_pb.TraverseBfs(commandEnv, dir, func(parentPath util.FullPath, entry *filer_pb.Entry) {
+		if !entry.IsDirectory {
+			files <- fileToDelete{parentPath, entry}
+		}
+	})
+	close(files)
+	wg.Wait()
+	close(done)
+
+	if err != nil {
+		stats.printf("rm: %s: %v\n", dir, err)
+	}
+	stats.print()
+}
@@ -0,0 +1,152 @@
+package shell
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"github.com/seaweedfs/seaweedfs/weed/operation"
+	"github.com/seaweedfs/seaweedfs/weed/pb"
+	"github.com/seaweedfs/seaweedfs/weed/pb/volume_server_pb"
+	"github.com/seaweedfs/seaweedfs/weed/storage/idx"
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
+	"github.com/seaweedfs/seaweedfs/weed/storage/types"
+)
+
+func init() {
+	Commands = append(Commands, &commandNeedleSearch{})
+}
+
+type commandNeedleSearch struct {
+}
+
+func (c *commandNeedleSearch) Name() string {
+	return "needle.search"
+}
+
+func (c *commandNeedleSearch) Help() string {
+	return `locate a file id on the volume servers
+
+	needle.search <fileId>
+
+	e.g.:
+	needle.search 3,01637037d6
+
+	This command looks up the volume locations from the master, then reads the index file
+	of the volume on each volume server, and prints where the needle is stored:
+
+	{volumeServer: 127.0.0.1:8080, volumeId: 3, offset: 8, size: 1024, isDeleted: false}
+
+	The offset is the byte offset in the .dat file. For deleted needles, the offset and size
+	of the last written version are printed. Erasure coded volumes are not supported.
+
+`
+}
+
+func (c *commandNeedleSearch) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+
+	searchCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	if err = searchCommand.Parse(args); err != nil {
+		return nil
+	}
+	if searchCommand.NArg() != 1 {
+		return fmt.Errorf("usage: needle.search <fileId>")
+	}
+
+	fileId, err := needle.ParseFileIdFromString(searchCommand.Arg(0))
+	if err != nil {
+		return fmt.Errorf("parse file id %s: %v", searchCommand.Arg(0), err)
+	}
+
+	volumeIdLocations, err := lookupVolumeIds(commandEnv, []string{fileId.VolumeId.String()})
+	if err != nil {
+		return fmt.Errorf("lookup volume %d: %v", fileId.VolumeId, err)
+	}
+	if len(volumeIdLocations) == 0 {
+		return fmt.Errorf("volume %d not found", fileId.VolumeId)
+	}
+	if volumeIdLocations[0].Error != "" {
+		return fmt.Errorf("lookup volume %d: %s", fileId.VolumeId, volumeIdLocations[0].Error)
+	}
+
+	found := false
+	for _, location := range volumeIdLocations[0].Locations {
+		volumeServer := pb.NewServerAddressFromLocation(location)
+		offset, size, isDeleted, hasNeedle, searchErr := searchNeedleInVolumeIndex(commandEnv, volumeServer, fileId)
+		if searchErr != nil {
+			fmt.Fprintf(writer, "{volumeServer: %s, volumeId: %d, error: %v}\n", volumeServer, fileId.VolumeId, searchErr)
+			continue
+		}
+		if !hasNeedle {
+			continue
+		}
+		found = true
+		fmt.Fprintf(writer, "{volumeServer: %s, volumeId: %d, offset: %d, size: %d, isDeleted: %v}\n",
+			volumeServer, fileId.VolumeId, offset, size, isDeleted)
+	}
+	if !found {
+		return fmt.Errorf("needle %s not found in volume %d", fileId.Key, fileId.VolumeId)
+	}
+
+	return nil
+}
+
+// searchNeedleInVolumeIndex replays the index file of the volume, so the deletion keeps the last written offset and size.
+// The index file is streamed to a temporary file, instead of being held in memory.
+func searchNeedleInVolumeIndex(commandEnv *CommandEnv, volumeServer pb.ServerAddress, fileId *needle.FileId) (actualOffset int64, size types.Size, isDeleted bool, found bool, err error) {
+
+	indexFile, err := os.CreateTemp("", fmt.Sprintf("needle_search_%d_*.idx", fileId.VolumeId))
+	if err != nil {
+		return
+	}
+	defer func() {
+		indexFile.Close()
+		os.Remove(indexFile.Name())
+	}()
+
+	err = operation.WithVolumeServerClient(true, volumeServer, commandEnv.option.GrpcDialOption, func(volumeServerClient volume_server_pb.VolumeServerClient) error {
+		copyFileClient, copyErr := volumeServerClient.CopyFile(context.Background(), &volume_server_pb.CopyFileRequest{
+			VolumeId:           uint32(fileId.VolumeId),
+			Ext:                ".idx",
+			CompactionRevision: math.MaxUint32,
+			StopOffset:         math.MaxInt64,
+		})
+		if copyErr != nil {
+			return fmt.Errorf("start copying volume %d.idx: %v", fileId.VolumeId, copyErr)
+		}
+		for {
+			resp, receiveErr := copyFileClient.Recv()
+			if receiveErr == io.EOF {
+				return nil
+			}
+			if receiveErr != nil {
+				return fmt.Errorf("receiving %d.idx: %v", fileId.VolumeId, receiveErr)
+			}
+			if _, writeErr := indexFile.Write(resp.FileContent); writeErr != nil {
+				return fmt.Errorf("write %s: %v", indexFile.Name(), writeErr)
+			}
+		}
+	})
+	if err != nil {
+		return
+	}
+
+	err = idx.WalkIndexFile(indexFile, 0, func(key types.NeedleId, offset types.Offset, entrySize types.Size) error {
+		if key != fileId.Key {
+			return nil
+		}
+		if entrySize.IsDeleted() {
+			isDeleted = found
+			return nil
+		}
+		if offset.IsZero() {
+			return nil
+		}
+		actualOffset, size, isDeleted, found = offset.ToActualOffset(), entrySize, false, true
+		return nil
+	})
+	return
+}
@@ -0,0 +1,118 @@
+package shell
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/seaweedfs/seaweedfs/weed/filer"
+	"github.com/seaweedfs/seaweedfs/weed/pb/filer_pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func init() {
+	Commands = append(Commands, &commandQuotaStatus{})
+}
+
+type commandQuotaStatus struct {
+}
+
+func (c *commandQuotaStatus) Name() string {
+	return "quota.status"
+}
+
+func (c *commandQuotaStatus) Help() string {
+	return `show the usage of the collections with quotas
+
+	The quotas are read from the filer entry /etc/seaweedfs/_quotas, a json file of the limits in bytes:
+
+	{
+	  "tenant1": {"softLimitBytes": 900000000000, "hardLimitBytes": 1000000000000}
+	}
+
+	The usage of a collection is the sum of the sizes of its files,
+	summed up again by walking the directories of the collection.
+
+	The filer logs a warning when a collection is over its soft limit,
+	and rejects new writes with ENOSPC when it is over its hard limit.
+
+	Example:
+		quota.status
+		quota.status -collection=tenant1
+`
+}
+
+func (c *commandQuotaStatus) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+
+	quotaCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	collection := quotaCommand.String("collection", "", "only show this collection")
+	if err = quotaCommand.Parse(args); err != nil {
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if err = commandEnv.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		return filer.ReadEntry(commandEnv.MasterClient, client, filer.DirectoryEtcSeaweedFS, filer.FilerQuotasName, &buf)
+	}); err != nil {
+		if err == filer_pb.ErrNotFound {
+			fmt.Fprintf(writer, "no quotas in %s/%s\n", filer.DirectoryEtcSeaweedFS, filer.FilerQuotasName)
+			return nil
+		}
+		return fmt.Errorf("read %s/%s: %v", filer.DirectoryEtcSeaweedFS, filer.FilerQuotasName, err)
+	}
+	quotas := filer.NewFilerQuotas()
+	if err = quotas.LoadFromBytes(buf.Bytes()); err != nil {
+		return fmt.Errorf("parse %s/%s: %v", filer.DirectoryEtcSeaweedFS, filer.FilerQuotasName, err)
+	}
+
+	detector := &filer.CollectionDetector{}
+	if detector.FilerConf, err = filer.ReadFilerConf(commandEnv.option.FilerAddress, commandEnv.option.GrpcDialOption, commandEnv.MasterClient); err != nil {
+		return fmt.Errorf("read filer conf: %v", err)
+	}
+	if err = commandEnv.WithFilerClient(false, func(client filer_pb.SeaweedFilerClient) error {
+		resp, err := client.GetFilerConfiguration(context.Background(), &filer_pb.GetFilerConfigurationRequest{})
+		if err != nil {
+			return err
+		}
+		detector.DirBucketsPath, detector.DefaultCollection = resp.DirBuckets, resp.Collection
+		return nil
+	}); err != nil {
+		return fmt.Errorf("get filer configuration: %v", err)
+	}
+
+	var collections []string
+	for _, name := range quotas.Collections() {
+		if *collection == "" || name == *collection {
+			collections = append(collections, name)
+		}
+	}
+	slices.Sort(collections)
+
+	for _, name := range collections {
+		var size int64
+		for _, root := range detector.UsageRoots(name) {
+			if err = filer_pb.TraverseBfs(commandEnv, root, func(parentPath util.FullPath, entry *filer_pb.Entry) {
+				if !entry.IsDirectory && detector.Detect(parentPath.Child(entry.Name)) == name {
+					atomic.AddInt64(&size, int64(filer.FromPbEntry(string(parentPath), entry).Size()))
+				}
+			}); err != nil {
+				return fmt.Errorf("traverse %s: %v", root, err)
+			}
+		}
+		quotas.SetUsage(name, size)
+		usedBytes, limit, _ := quotas.Usage(name)
+		status := "ok"
+		if quotas.CheckHardLimit(name) != nil {
+			status = "over hard limit"
+		} else if limit.SoftLimitBytes > 0 && usedBytes > limit.SoftLimitBytes {
+			status = "over soft limit"
+		}
+		fmt.Fprintf(writer, "collection:\"%s\"\tused:%d\tsoftLimit:%d\thardLimit:%d\t%s\n", name, usedBytes, limit.SoftLimitBytes, limit.HardLimitBytes, status)
+	}
+	return nil
+}
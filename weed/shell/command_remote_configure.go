@@ -36,6 +36,8 @@ func (c *commandRemoteConfigure) Help() string {
 	remote.configure -name=cloud1 -type=s3 -s3.access_key=xxx -s3.secret_key=yyy -s3.region=us-east-2
 	remote.configure -name=cloud2 -type=gcs -gcs.appCredentialsFile=~/service-account-file.json -gcs.projectId=yyy
 	remote.configure -name=cloud3 -type=azure -azure.account_name=xxx -azure.account_key=yyy
+	remote.configure -name=cloud3 -type=azure -azure.account_name=xxx -azure.sas_token="sv=...&sig=..."
+	remote.configure -name=cloud3 -type=azure -azure.account_name=xxx -azure.use_msi
 	remote.configure -name=cloud4 -type=aliyun -aliyun.access_key=xxx -aliyun.secret_key=yyy -aliyun.endpoint=oss-cn-shenzhen.aliyuncs.com -aliyun.region=cn-sehnzhen
 	remote.configure -name=cloud5 -type=tencent -tencent.secret_id=xxx -tencent.secret_key=yyy -tencent.endpoint=cos.ap-guangzhou.myqcloud.com
 	remote.configure -name=cloud6 -type=wasabi -wasabi.access_key=xxx -wasabi.secret_key=yyy -wasabi.endpoint=s3.us-west-1.wasabisys.com -wasabi.region=us-west-1
@@ -76,6 +78,9 @@ func (c *commandRemoteConfigure) Do(args []string, commandEnv *CommandEnv, write
 
 	remoteConfigureCommand.StringVar(&conf.AzureAccountName, "azure.account_name", "", "azure account name, default to use env AZURE_STORAGE_ACCOUNT")
 	remoteConfigureCommand.StringVar(&conf.AzureAccountKey, "azure.account_key", "", "azure account name, default to use env AZURE_STORAGE_ACCESS_KEY")
+	remoteConfigureCommand.StringVar(&conf.AzureSasToken, "azure.sas_token", "", "azure shared access signature token, instead of the account key")
+	remoteConfigureCommand.BoolVar(&conf.AzureUseMsi, "azure.use_msi", false, "use the azure managed service identity, instead of the account key")
+	remoteConfigureCommand.StringVar(&conf.AzureMsiClientId, "azure.msi_client_id", "", "client id of the user assigned managed identity, empty for the system assigned one")
 
 	remoteConfigureCommand.StringVar(&conf.BackblazeKeyId, "b2.key_id", "", "backblaze keyID")
 	remoteConfigureCommand.StringVar(&conf.BackblazeApplicationKey, "b2.application_key", "", "backblaze applicationKey. Note that your Master Application Key will not work with the S3 Compatible API. You must create a new key that is eligible for use. For more information: https://help.backblaze.com/hc/en-us/articles/360047425453")
@@ -153,6 +158,8 @@ func (c *commandRemoteConfigure) listExistingRemoteStorages(commandEnv *CommandE
 
 		// change secret key to stars
 		conf.S3SecretKey = strings.Repeat("*", len(conf.S3SecretKey))
+		conf.AzureAccountKey = strings.Repeat("*", len(conf.AzureAccountKey))
+		conf.AzureSasToken = strings.Repeat("*", len(conf.AzureSasToken))
 		conf.AliyunSecretKey = strings.Repeat("*", len(conf.AliyunSecretKey))
 		conf.BaiduAccessKey = strings.Repeat("*", len(conf.BaiduAccessKey))
 		conf.FilebaseSecretKey = strings.Repeat("*", len(conf.FilebaseSecretKey))
@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"math"
 	"os"
 	"time"
 
@@ -34,6 +35,9 @@ func (c *commandVolumeBalance) Help() string {
 
 	volume.balance [-collection ALL_COLLECTIONS|EACH_COLLECTION|<collection_name>] [-force] [-dataCenter=<data_center_name>]
 
+	Without -force, only the plan is printed: each volume move with its size,
+	and the standard deviation of the used volume slot ratio among the servers before and after the moves.
+
 	Algorithm:
 
 	For each type of volume server (different max volume count limit){
@@ -261,6 +265,15 @@ func balanceSelectedVolume(commandEnv *CommandEnv, diskType types.DiskType, volu
 	idealVolumeRatio := float64(selectedVolumeCount) / volumeMaxCount
 
 	hasMoved := true
+	var movedCount int
+	var movedBytes uint64
+	usageStdDevBefore := volumeUsageStdDev(nodesWithCapacity, capacityFunc)
+	defer func() {
+		if err == nil && movedCount > 0 {
+			fmt.Fprintf(os.Stdout, "%s: %d volumes, %d bytes, volume slot usage standard deviation %.4f => %.4f\n",
+				diskType.ReadableString(), movedCount, movedBytes, usageStdDevBefore, volumeUsageStdDev(nodesWithCapacity, capacityFunc))
+		}
+	}()
 
 	// fmt.Fprintf(os.Stdout, " total %d volumes, max %d volumes, idealVolumeRatio %f\n", selectedVolumeCount, volumeMaxCount, idealVolumeRatio)
 
@@ -293,12 +306,15 @@ func balanceSelectedVolume(commandEnv *CommandEnv, diskType types.DiskType, volu
 				break
 			}
 			fmt.Fprintf(os.Stdout, "%s %.2f %.2f:%.2f\t", diskType.ReadableString(), idealVolumeRatio, fullNode.localVolumeRatio(capacityFunc), emptyNode.localVolumeNextRatio(capacityFunc))
-			hasMoved, err = attemptToMoveOneVolume(commandEnv, volumeReplicas, fullNode, candidateVolumes, emptyNode, applyBalancing)
+			var movedVolume *master_pb.VolumeInformationMessage
+			movedVolume, err = attemptToMoveOneVolume(commandEnv, volumeReplicas, fullNode, candidateVolumes, emptyNode, applyBalancing)
 			if err != nil {
 				return
 			}
-			if hasMoved {
+			if hasMoved = movedVolume != nil; hasMoved {
 				// moved one volume
+				movedCount++
+				movedBytes += movedVolume.Size
 				break
 			}
 		}
@@ -306,18 +322,33 @@ func balanceSelectedVolume(commandEnv *CommandEnv, diskType types.DiskType, volu
 	return nil
 }
 
-func attemptToMoveOneVolume(commandEnv *CommandEnv, volumeReplicas map[uint32][]*VolumeReplica, fullNode *Node, candidateVolumes []*master_pb.VolumeInformationMessage, emptyNode *Node, applyBalancing bool) (hasMoved bool, err error) {
+// volumeUsageStdDev is the standard deviation of the ratio of used volume slots among the volume servers
+func volumeUsageStdDev(nodes []*Node, capacityFunc CapacityFunc) float64 {
+	if len(nodes) == 0 {
+		return 0
+	}
+	var sum, squareSum float64
+	for _, n := range nodes {
+		ratio := n.localVolumeRatio(capacityFunc)
+		sum += ratio
+		squareSum += ratio * ratio
+	}
+	mean := sum / float64(len(nodes))
+	return math.Sqrt(math.Max(squareSum/float64(len(nodes))-mean*mean, 0))
+}
+
+func attemptToMoveOneVolume(commandEnv *CommandEnv, volumeReplicas map[uint32][]*VolumeReplica, fullNode *Node, candidateVolumes []*master_pb.VolumeInformationMessage, emptyNode *Node, applyBalancing bool) (movedVolume *master_pb.VolumeInformationMessage, err error) {
 
 	for _, v := range candidateVolumes {
-		hasMoved, err = maybeMoveOneVolume(commandEnv, volumeReplicas, fullNode, v, emptyNode, applyBalancing)
+		hasMoved, err := maybeMoveOneVolume(commandEnv, volumeReplicas, fullNode, v, emptyNode, applyBalancing)
 		if err != nil {
-			return
+			return nil, err
 		}
 		if hasMoved {
-			break
+			return v, nil
 		}
 	}
-	return
+	return nil, nil
 }
 
 func maybeMoveOneVolume(commandEnv *CommandEnv, volumeReplicas map[uint32][]*VolumeReplica, fullNode *Node, candidateVolume *master_pb.VolumeInformationMessage, emptyNode *Node, applyChange bool) (hasMoved bool, err error) {
@@ -352,7 +383,7 @@ func moveVolume(commandEnv *CommandEnv, v *master_pb.VolumeInformationMessage, f
 	if v.Collection == "" {
 		collectionPrefix = ""
 	}
-	fmt.Fprintf(os.Stdout, "  moving %s volume %s%d %s => %s\n", v.DiskType, collectionPrefix, v.Id, fullNode.info.Id, emptyNode.info.Id)
+	fmt.Fprintf(os.Stdout, "  moving %s volume %s%d %s => %s, %d bytes\n", v.DiskType, collectionPrefix, v.Id, fullNode.info.Id, emptyNode.info.Id, v.Size)
 	if applyChange {
 		return LiveMoveVolume(commandEnv.option.GrpcDialOption, os.Stderr, needle.VolumeId(v.Id), pb.NewServerAddressFromDataNode(fullNode.info), pb.NewServerAddressFromDataNode(emptyNode.info), 5*time.Second, v.DiskType, 0, false)
 	}
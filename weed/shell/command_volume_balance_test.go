@@ -286,3 +286,22 @@ func TestDeleteEmptySelection(t *testing.T) {
 	})
 
 }
+
+func TestVolumeUsageStdDev(t *testing.T) {
+	newNode := func(volumeCount int) *Node {
+		n := &Node{
+			info: &master_pb.DataNodeInfo{
+				DiskInfos: map[string]*master_pb.DiskInfo{"": {MaxVolumeCount: 10}},
+			},
+			selectedVolumes: make(map[uint32]*master_pb.VolumeInformationMessage),
+		}
+		for i := 0; i < volumeCount; i++ {
+			n.selectedVolumes[uint32(i)] = &master_pb.VolumeInformationMessage{Id: uint32(i)}
+		}
+		return n
+	}
+	capacityFunc := capacityByMaxVolumeCount(types.HardDriveType)
+
+	assert.InDelta(t, 0, volumeUsageStdDev([]*Node{newNode(5), newNode(5)}, capacityFunc), 1e-9)
+	assert.InDelta(t, 0.3, volumeUsageStdDev([]*Node{newNode(2), newNode(8)}, capacityFunc), 1e-9)
+}
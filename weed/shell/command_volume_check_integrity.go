@@ -0,0 +1,144 @@
+package shell
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb"
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle_map"
+)
+
+func init() {
+	Commands = append(Commands, &commandVolumeCheckIntegrity{})
+}
+
+type commandVolumeCheckIntegrity struct {
+}
+
+func (c *commandVolumeCheckIntegrity) Name() string {
+	return "volume.check.integrity"
+}
+
+func (c *commandVolumeCheckIntegrity) Help() string {
+	return `verify the needle checksums of a live volume
+
+	volume.check.integrity -volumeId=<volume id> [-iops=100] [-o=result.txt]
+
+	How it works:
+
+	for each replica of the volume
+	  read the index file from the volume server
+	  read each live needle back from the volume server, at most <iops> needles per second
+	  recompute the crc32 of the needle data and compare it with the stored checksum
+	  print the needles with mismatched checksums or unreadable data
+`
+}
+
+func (c *commandVolumeCheckIntegrity) Do(args []string, commandEnv *CommandEnv, writer io.Writer) (err error) {
+
+	checkCommand := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+	volumeId := checkCommand.Uint("volumeId", 0, "the volume id")
+	iops := checkCommand.Int("iops", 100, "max number of needles to read per second on each volume server, 0 for no limit")
+	outputFile := checkCommand.String("o", "", "also write the results to this file")
+	verbose := checkCommand.Bool("v", false, "verbose mode")
+	if err = checkCommand.Parse(args); err != nil {
+		return nil
+	}
+	if *volumeId == 0 {
+		return fmt.Errorf("need to specify the volume id with -volumeId")
+	}
+
+	if *outputFile != "" {
+		f, err := os.OpenFile(*outputFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return fmt.Errorf("open %s: %v", *outputFile, err)
+		}
+		defer f.Close()
+		writer = io.MultiWriter(writer, f)
+	}
+
+	topologyInfo, _, err := collectTopologyInfo(commandEnv, 0)
+	if err != nil {
+		return err
+	}
+	volumeReplicas, _ := collectVolumeReplicaLocations(topologyInfo)
+	replicas, found := volumeReplicas[uint32(*volumeId)]
+	if !found {
+		return fmt.Errorf("volume %d not found", *volumeId)
+	}
+
+	for _, replica := range replicas {
+		if err = c.checkReplica(commandEnv, replica, *iops, *verbose, writer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *commandVolumeCheckIntegrity) checkReplica(commandEnv *CommandEnv, replica *VolumeReplica, iops int, verbose bool, writer io.Writer) error {
+
+	volumeServer := pb.NewServerAddressFromDataNode(replica.location.dataNode)
+	version := needle.Version(replica.info.Version)
+	if version == 0 {
+		version = needle.CurrentVersion
+	}
+
+	db := needle_map.NewMemDb()
+	defer db.Close()
+	if err := readIndexDatabase(db, replica.info.Collection, replica.info.Id, volumeServer, verbose, writer, commandEnv.option.GrpcDialOption); err != nil {
+		return fmt.Errorf("read volume %d index from %s: %v", replica.info.Id, volumeServer, err)
+	}
+
+	var throttle <-chan time.Time
+	if iops > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(iops))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	var checked, corrupted int
+	err := db.AscendingVisit(func(needleValue needle_map.NeedleValue) error {
+		if !needleValue.Size.IsValid() {
+			return nil
+		}
+		if throttle != nil {
+			<-throttle
+		}
+		checked++
+		needleBlob, err := readSourceNeedleBlob(commandEnv.option.GrpcDialOption, volumeServer, replica.info.Id, needleValue)
+		if err == nil {
+			err = verifyNeedleBlob(needleBlob, needleValue, version)
+		}
+		if err != nil {
+			corrupted++
+			fmt.Fprintf(writer, "volume %d on %s needle %s offset %d size %d: %v\n",
+				replica.info.Id, volumeServer, needleValue.Key, needleValue.Offset.ToActualOffset(), needleValue.Size, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(writer, "volume %d on %s: checked %d needles, %d corrupted\n", replica.info.Id, volumeServer, checked, corrupted)
+	return nil
+}
+
+// verifyNeedleBlob parses the needle blob, which fails if the stored crc32 does not match the needle data.
+func verifyNeedleBlob(needleBlob []byte, needleValue needle_map.NeedleValue, version needle.Version) error {
+	if int64(len(needleBlob)) < needle.GetActualSize(needleValue.Size, version) {
+		return fmt.Errorf("needle blob size %d, expected %d", len(needleBlob), needle.GetActualSize(needleValue.Size, version))
+	}
+	n := new(needle.Needle)
+	if err := n.ReadBytes(needleBlob, needleValue.Offset.ToActualOffset(), needleValue.Size, version); err != nil {
+		return err
+	}
+	if n.Id != needleValue.Key {
+		return fmt.Errorf("found needle id %s, expected %s", n.Id, needleValue.Key)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/storage/backend"
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle_map"
+	"github.com/seaweedfs/seaweedfs/weed/storage/types"
+)
+
+func TestVerifyNeedleBlob(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "1.dat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	datFile := backend.NewDiskFile(f)
+	defer datFile.Close()
+
+	n := &needle.Needle{Id: 3, Cookie: 0x12345678, Data: []byte("needle data")}
+	n.Checksum = needle.NewCRC(n.Data)
+	offset, _, _, err := n.Append(datFile, needle.CurrentVersion)
+	if err != nil {
+		t.Fatalf("append needle: %v", err)
+	}
+	needleValue := needle_map.NeedleValue{Key: n.Id, Offset: types.ToOffset(int64(offset)), Size: n.Size}
+	blob, err := needle.ReadNeedleBlob(datFile, int64(offset), n.Size, needle.CurrentVersion)
+	if err != nil {
+		t.Fatalf("read needle blob: %v", err)
+	}
+
+	if err = verifyNeedleBlob(blob, needleValue, needle.CurrentVersion); err != nil {
+		t.Errorf("verify needle: %v", err)
+	}
+	blob[types.NeedleHeaderSize+4+2] ^= 0xff
+	if err = verifyNeedleBlob(blob, needleValue, needle.CurrentVersion); err == nil {
+		t.Errorf("expected checksum mismatch on corrupted data")
+	}
+	if err = verifyNeedleBlob(blob[:len(blob)-8], needleValue, needle.CurrentVersion); err == nil {
+		t.Errorf("expected error on truncated needle blob")
+	}
+}
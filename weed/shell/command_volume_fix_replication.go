@@ -51,6 +51,8 @@ func (c *commandVolumeFixReplication) Help() string {
 		  If there are multiple replicas are missing, e.g. replica count is > 2, you may need to run this multiple times.
 		* do not run this too quickly within seconds, since the new volume replica may take a few seconds 
 		  to register itself to the master.
+		* a failed copy is retried on other volume servers, up to -retry times. The volumes still failing
+		  are skipped in the later cycles, and reported at the end.
 
 `
 }
@@ -62,7 +64,7 @@ func (c *commandVolumeFixReplication) Do(args []string, commandEnv *CommandEnv,
 	skipChange := volFixReplicationCommand.Bool("n", false, "skip the changes")
 	doDelete := volFixReplicationCommand.Bool("doDelete", true, "Also delete over-replicated volumes besides fixing under-replication")
 	doCheck := volFixReplicationCommand.Bool("doCheck", true, "Also check synchronization before deleting")
-	retryCount := volFixReplicationCommand.Int("retry", 5, "how many times to retry")
+	retryCount := volFixReplicationCommand.Int("retry", 5, "how many times to retry a failed copy, or to wait for the new replicas in the topology")
 	volumesPerStep := volFixReplicationCommand.Int("volumesPerStep", 0, "how many volumes to fix in one cycle")
 
 	if err = volFixReplicationCommand.Parse(args); err != nil {
@@ -75,6 +77,9 @@ func (c *commandVolumeFixReplication) Do(args []string, commandEnv *CommandEnv,
 
 	takeAction := !*skipChange
 
+	// the volumes failed to fix after all retries
+	failedVolumeIds := make(map[uint32]bool)
+
 	underReplicatedVolumeIdsCount := 1
 	for underReplicatedVolumeIdsCount > 0 {
 		fixedVolumeReplicas := map[string]int{}
@@ -100,7 +105,9 @@ func (c *commandVolumeFixReplication) Do(args []string, commandEnv *CommandEnv,
 			replicaPlacement, _ := super_block.NewReplicaPlacementFromByte(byte(replica.info.ReplicaPlacement))
 			switch {
 			case replicaPlacement.GetCopyCount() > len(replicas):
-				underReplicatedVolumeIds = append(underReplicatedVolumeIds, vid)
+				if !failedVolumeIds[vid] {
+					underReplicatedVolumeIds = append(underReplicatedVolumeIds, vid)
+				}
 			case isMisplaced(replicas, replicaPlacement):
 				misplacedVolumeIds = append(misplacedVolumeIds, vid)
 				fmt.Fprintf(writer, "volume %d replication %s is not well placed %s\n", replica.info.Id, replicaPlacement, replica.location.dataNode.Id)
@@ -129,7 +136,7 @@ func (c *commandVolumeFixReplication) Do(args []string, commandEnv *CommandEnv,
 		underReplicatedVolumeIdsCount = len(underReplicatedVolumeIds)
 		if underReplicatedVolumeIdsCount > 0 {
 			// find the most under populated data nodes
-			fixedVolumeReplicas, err = c.fixUnderReplicatedVolumes(commandEnv, writer, takeAction, underReplicatedVolumeIds, volumeReplicas, allLocations, *retryCount, *volumesPerStep)
+			fixedVolumeReplicas, err = c.fixUnderReplicatedVolumes(commandEnv, writer, takeAction, underReplicatedVolumeIds, volumeReplicas, allLocations, *retryCount, *volumesPerStep, failedVolumeIds)
 			if err != nil {
 				return err
 			}
@@ -169,6 +176,15 @@ func (c *commandVolumeFixReplication) Do(args []string, commandEnv *CommandEnv,
 			}
 		}
 	}
+
+	if len(failedVolumeIds) > 0 {
+		var volumeIds []uint32
+		for vid := range failedVolumeIds {
+			volumeIds = append(volumeIds, vid)
+		}
+		slices.Sort(volumeIds)
+		return fmt.Errorf("failed to fix under replicated volumes %v after %d retries", volumeIds, *retryCount)
+	}
 	return nil
 }
 
@@ -268,27 +284,32 @@ func (c *commandVolumeFixReplication) deleteOneVolume(commandEnv *CommandEnv, wr
 	return nil
 }
 
-func (c *commandVolumeFixReplication) fixUnderReplicatedVolumes(commandEnv *CommandEnv, writer io.Writer, takeAction bool, underReplicatedVolumeIds []uint32, volumeReplicas map[uint32][]*VolumeReplica, allLocations []location, retryCount int, volumesPerStep int) (fixedVolumes map[string]int, err error) {
+func (c *commandVolumeFixReplication) fixUnderReplicatedVolumes(commandEnv *CommandEnv, writer io.Writer, takeAction bool, underReplicatedVolumeIds []uint32, volumeReplicas map[uint32][]*VolumeReplica, allLocations []location, retryCount int, volumesPerStep int, failedVolumeIds map[uint32]bool) (fixedVolumes map[string]int, err error) {
 	fixedVolumes = map[string]int{}
 	if len(underReplicatedVolumeIds) > volumesPerStep && volumesPerStep > 0 {
 		underReplicatedVolumeIds = underReplicatedVolumeIds[0:volumesPerStep]
 	}
 	for _, vid := range underReplicatedVolumeIds {
+		// the retries skip the data nodes failed to copy to
+		failedLocations := make(map[string]bool)
 		for i := 0; i < retryCount+1; i++ {
-			if err = c.fixOneUnderReplicatedVolume(commandEnv, writer, takeAction, volumeReplicas, vid, allLocations); err == nil {
+			if err = c.fixOneUnderReplicatedVolume(commandEnv, writer, takeAction, volumeReplicas, vid, allLocations, failedLocations); err == nil {
 				if takeAction {
 					fixedVolumes[strconv.FormatUint(uint64(vid), 10)] = len(volumeReplicas[vid])
 				}
 				break
 			} else {
-				fmt.Fprintf(writer, "fixing under replicated volume %d: %v\n", vid, err)
+				fmt.Fprintf(writer, "fixing under replicated volume %d (attempt %d/%d): %v\n", vid, i+1, retryCount+1, err)
 			}
 		}
+		if err != nil {
+			failedVolumeIds[vid] = true
+		}
 	}
 	return fixedVolumes, nil
 }
 
-func (c *commandVolumeFixReplication) fixOneUnderReplicatedVolume(commandEnv *CommandEnv, writer io.Writer, takeAction bool, volumeReplicas map[uint32][]*VolumeReplica, vid uint32, allLocations []location) error {
+func (c *commandVolumeFixReplication) fixOneUnderReplicatedVolume(commandEnv *CommandEnv, writer io.Writer, takeAction bool, volumeReplicas map[uint32][]*VolumeReplica, vid uint32, allLocations []location, failedLocations map[string]bool) error {
 	replicas := volumeReplicas[vid]
 	replica := pickOneReplicaToCopyFrom(replicas)
 	replicaPlacement, _ := super_block.NewReplicaPlacementFromByte(byte(replica.info.ReplicaPlacement))
@@ -297,6 +318,9 @@ func (c *commandVolumeFixReplication) fixOneUnderReplicatedVolume(commandEnv *Co
 	keepDataNodesSorted(allLocations, types.ToDiskType(replica.info.DiskType))
 	fn := capacityByFreeVolumeCount(types.ToDiskType(replica.info.DiskType))
 	for _, dst := range allLocations {
+		if failedLocations[dst.String()] {
+			continue
+		}
 		// check whether data nodes satisfy the constraints
 		if fn(dst.dataNode) > 0 && satisfyReplicaPlacement(replicaPlacement, replicas, dst) {
 			// check collection name pattern
@@ -347,6 +371,7 @@ func (c *commandVolumeFixReplication) fixOneUnderReplicatedVolume(commandEnv *Co
 			})
 
 			if err != nil {
+				failedLocations[dst.String()] = true
 				return err
 			}
 
@@ -356,6 +381,9 @@ func (c *commandVolumeFixReplication) fixOneUnderReplicatedVolume(commandEnv *Co
 		}
 	}
 
+	if !foundNewLocation && !hasSkippedCollection && len(failedLocations) > 0 {
+		return fmt.Errorf("no other data nodes to place volume %d replica as %s", replica.info.Id, replicaPlacement)
+	}
 	if !foundNewLocation && !hasSkippedCollection {
 		fmt.Fprintf(writer, "failed to place volume %d replica as %s, existing:%+v\n", replica.info.Id, replicaPlacement, len(replicas))
 	}
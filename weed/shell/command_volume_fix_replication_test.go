@@ -1,6 +1,8 @@
 package shell
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/seaweedfs/seaweedfs/weed/pb/master_pb"
@@ -438,3 +440,33 @@ func TestPickingMisplacedVolumeToDelete(t *testing.T) {
 	}
 
 }
+
+func TestFixOneUnderReplicatedVolumeSkipsFailedLocations(t *testing.T) {
+	newDataNode := func(id string, maxVolumeCount int64) *master_pb.DataNodeInfo {
+		return &master_pb.DataNodeInfo{Id: id, DiskInfos: map[string]*master_pb.DiskInfo{"": {MaxVolumeCount: maxVolumeCount}}}
+	}
+	allLocations := []location{
+		{"dc1", "r1", newDataNode("dn1", 10)},
+		{"dc1", "r1", newDataNode("dn2", 10)},
+		{"dc1", "r1", newDataNode("dn3", 5)},
+	}
+	volumeReplicas := map[uint32][]*VolumeReplica{
+		1: {{location: &allLocations[0], info: &master_pb.VolumeInformationMessage{Id: 1, ReplicaPlacement: 1}}},
+	}
+	collectionPattern := ""
+	c := &commandVolumeFixReplication{collectionPattern: &collectionPattern}
+
+	var output bytes.Buffer
+	failedLocations := map[string]bool{"dc1 r1 dn2": true}
+	if err := c.fixOneUnderReplicatedVolume(nil, &output, false, volumeReplicas, 1, allLocations, failedLocations); err != nil {
+		t.Fatalf("fix: %v", err)
+	}
+	if !strings.Contains(output.String(), "to dataNode dn3") {
+		t.Errorf("should replicate to dn3 instead of the failed dn2: %s", output.String())
+	}
+
+	failedLocations["dc1 r1 dn3"] = true
+	if err := c.fixOneUnderReplicatedVolume(nil, &output, false, volumeReplicas, 1, allLocations, failedLocations); err == nil {
+		t.Errorf("should fail without other data nodes")
+	}
+}
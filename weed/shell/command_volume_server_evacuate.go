@@ -8,9 +8,11 @@ import (
 	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
 	"github.com/seaweedfs/seaweedfs/weed/storage/super_block"
 	"github.com/seaweedfs/seaweedfs/weed/storage/types"
+	"github.com/seaweedfs/seaweedfs/weed/util"
 	"golang.org/x/exp/slices"
 	"io"
 	"os"
+	"time"
 )
 
 func init() {
@@ -36,6 +38,9 @@ func (c *commandVolumeServerEvacuate) Help() string {
 	The volumes on the volume servers will be redistributed.
 
 	Usually this is used to prepare to shutdown or upgrade the volume server.
+	The volumes are moved one by one, each to the volume server with the most free slots
+	meeting the replication requirement. The progress is printed after each volume,
+	with the remaining time estimated from the size moved so far.
 
 	Sometimes a volume can not be moved because there are no
 	good destination to meet the replication requirement. 
@@ -111,6 +116,8 @@ func (c *commandVolumeServerEvacuate) evacuateNormalVolumes(commandEnv *CommandE
 		return fmt.Errorf("%s is not found in this cluster", volumeServer)
 	}
 
+	progress := newEvacuateProgress(thisNodes)
+
 	// move away normal volumes
 	for _, thisNode := range thisNodes {
 		for _, diskInfo := range thisNode.info.DiskInfos {
@@ -133,6 +140,8 @@ func (c *commandVolumeServerEvacuate) evacuateNormalVolumes(commandEnv *CommandE
 				if err != nil {
 					fmt.Fprintf(writer, "move away volume %d from %s: %v\n", vol.Id, volumeServer, err)
 				}
+				progress.done(vol)
+				progress.print(writer, applyChange)
 				if !hasMoved {
 					if skipNonMoveable {
 						replicaPlacement, _ := super_block.NewReplicaPlacementFromByte(byte(vol.ReplicaPlacement))
@@ -147,6 +156,44 @@ func (c *commandVolumeServerEvacuate) evacuateNormalVolumes(commandEnv *CommandE
 	return nil
 }
 
+// evacuateProgress counts the processed volumes, to estimate the remaining time by the processed size
+type evacuateProgress struct {
+	startTime  time.Time
+	totalCount int
+	totalSize  uint64
+	doneCount  int
+	doneSize   uint64
+}
+
+func newEvacuateProgress(nodes []*Node) *evacuateProgress {
+	progress := &evacuateProgress{startTime: time.Now()}
+	for _, node := range nodes {
+		for _, diskInfo := range node.info.DiskInfos {
+			for _, vol := range diskInfo.VolumeInfos {
+				progress.totalCount++
+				progress.totalSize += vol.Size
+			}
+		}
+	}
+	return progress
+}
+
+func (p *evacuateProgress) done(vol *master_pb.VolumeInformationMessage) {
+	p.doneCount++
+	p.doneSize += vol.Size
+}
+
+func (p *evacuateProgress) print(writer io.Writer, applyChange bool) {
+	fmt.Fprintf(writer, "processed %d/%d volumes, %s/%s", p.doneCount, p.totalCount,
+		util.BytesToHumanReadable(p.doneSize), util.BytesToHumanReadable(p.totalSize))
+	if applyChange && p.doneSize > 0 && p.doneSize < p.totalSize {
+		elapsed := time.Since(p.startTime)
+		eta := time.Duration(float64(elapsed) * float64(p.totalSize-p.doneSize) / float64(p.doneSize))
+		fmt.Fprintf(writer, ", ETA %v", eta.Round(time.Second))
+	}
+	fmt.Fprintln(writer)
+}
+
 func (c *commandVolumeServerEvacuate) evacuateEcVolumes(commandEnv *CommandEnv, volumeServer string, skipNonMoveable, applyChange bool, writer io.Writer) error {
 	// find this ec volume server
 	ecNodes, _ := collectEcVolumeServersByDc(c.topologyInfo, "")
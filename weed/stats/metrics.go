@@ -86,6 +86,23 @@ var (
 			Help:      "Counter of master leader changes.",
 		}, []string{"type"})
 
+	MasterAssignHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "master",
+			Name:      "assign_duration_seconds",
+			Help:      "Bucketed histogram of master file id assignment time.",
+			Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 24),
+		}, []string{"type"})
+
+	MasterAssignRejectedCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "master",
+			Name:      "assign_rejected_total",
+			Help:      "Counter of rejected master file id assignments.",
+		}, []string{"type", "reason"})
+
 	FilerRequestCounter = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Namespace: Namespace,
@@ -274,6 +291,8 @@ func init() {
 	Gather.MustRegister(MasterLeaderChangeCounter)
 	Gather.MustRegister(MasterReplicaPlacementMismatch)
 	Gather.MustRegister(MasterVolumeLayout)
+	Gather.MustRegister(MasterAssignHistogram)
+	Gather.MustRegister(MasterAssignRejectedCounter)
 
 	Gather.MustRegister(FilerRequestCounter)
 	Gather.MustRegister(FilerHandlerCounter)
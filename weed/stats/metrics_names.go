@@ -18,6 +18,14 @@ const (
 	ErrorUnmarshalPairs   = "errorUnmarshalPairs"
 	ErrorWriteToReplicas  = "errorWriteToReplicas"
 
+	// master assign rejections
+	AssignNotLeader         = "notLeader"
+	AssignInvalidOption     = "invalidOption"
+	AssignNoDataCenter      = "noDataCenterMatch"
+	AssignNoFreeVolumes     = "noFreeVolumes"
+	AssignNoWritableVolumes = "noWritableVolumes"
+	AssignTimeout           = "timeout"
+
 	// master client
 	FailedToKeepConnected = "failedToKeepConnected"
 	FailedToSend          = "failedToSend"
@@ -33,9 +41,11 @@ const (
 	ChunkProxy               = "chunkProxy"
 	ChunkAssign              = "chunkAssign"
 	ChunkUpload              = "chunkUpload"
+	ChunkDedup               = "chunkDedup"
 	ChunkDoUploadRetry       = "chunkDoUploadRetry"
 	ChunkUploadRetry         = "chunkUploadRetry"
 	ChunkAssignRetry         = "chunkAssignRetry"
+	ChunkRecompress          = "chunkRecompress"
 	ErrorReadNotFound        = "read.notfound"
 	ErrorReadInternal        = "read.internal.error"
 	ErrorWriteEntry          = "write.entry.failed"
@@ -44,6 +54,9 @@ const (
 	ErrorReadChunk           = "read.chunk.failed"
 	ErrorReadCache           = "read.cache.failed"
 	ErrorReadStream          = "read.stream.failed"
+	ErrorRateLimited         = "rate.limited"
+	ErrorRecompressChunk     = "recompress.chunk.failed"
+	ErrorAuditLog            = "audit.log.failed"
 
 	// s3 handler
 	ErrorCompletedNoSuchUpload      = "errorCompletedNoSuchUpload"
@@ -36,6 +36,8 @@ type DiskLocation struct {
 
 	isDiskSpaceLow bool
 	closeCh        chan struct{}
+
+	needleCache *NeedleCache
 }
 
 func GenerateDirUuid(dir string) (dirUuidString string, err error) {
@@ -357,6 +359,10 @@ func (l *DiskLocation) SetVolume(vid needle.VolumeId, volume *Volume) {
 
 	l.volumes[vid] = volume
 	volume.location = l
+	if l.needleCache != nil {
+		// the volume may have been replaced with a copy
+		l.needleCache.deleteVolume(vid)
+	}
 }
 
 func (l *DiskLocation) FindVolume(vid needle.VolumeId) (*Volume, bool) {
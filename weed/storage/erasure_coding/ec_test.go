@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/klauspost/reedsolomon"
@@ -208,3 +209,44 @@ func (this Interval) sameAs(that Interval) bool {
 		this.BlockIndex == that.BlockIndex &&
 		this.Size == that.Size
 }
+
+func TestEncodingDecodingSmallerThanOneStripe(t *testing.T) {
+	dir := t.TempDir()
+	baseFileName := filepath.Join(dir, "7")
+
+	datSize := int64(1234)
+	data := make([]byte, datSize)
+	rand.Read(data)
+	if err := os.WriteFile(baseFileName+".dat", data, 0644); err != nil {
+		t.Fatalf("write dat file: %v", err)
+	}
+
+	// the last stripe is padded with zeros on encoding
+	if err := WriteEcFiles(baseFileName); err != nil {
+		t.Fatalf("WriteEcFiles: %v", err)
+	}
+	var shardFileNames []string
+	for i := 0; i < TotalShardsCount; i++ {
+		fi, err := os.Stat(baseFileName + ToExt(i))
+		if err != nil {
+			t.Fatalf("stat shard %d: %v", i, err)
+		}
+		if fi.Size() != ErasureCodingSmallBlockSize {
+			t.Errorf("shard %d size %d, expected %d", i, fi.Size(), ErasureCodingSmallBlockSize)
+		}
+		shardFileNames = append(shardFileNames, baseFileName+ToExt(i))
+	}
+
+	// and the padding is dropped on decoding
+	decodedBaseFileName := filepath.Join(dir, "decoded")
+	if err := WriteDatFile(decodedBaseFileName, datSize, shardFileNames); err != nil {
+		t.Fatalf("WriteDatFile: %v", err)
+	}
+	decoded, err := os.ReadFile(decodedBaseFileName + ".dat")
+	if err != nil {
+		t.Fatalf("read decoded dat file: %v", err)
+	}
+	if !bytes.Equal(data, decoded) {
+		t.Errorf("decoded %d bytes differ from the original %d bytes", len(decoded), len(data))
+	}
+}
@@ -0,0 +1,107 @@
+package needle
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+const (
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// Compression is how the volume server compresses the compressible uploads, which are not compressed by the clients.
+// The zero value compresses with gzip.
+type Compression struct {
+	Method string
+	// Level is the zstd level, 0 for the zstd default
+	Level int
+	// CollectionLevels overrides the zstd level for the collections
+	CollectionLevels map[string]int
+}
+
+// ParseCompression parses the compression method, the zstd level, and the per collection levels like "realtime:1,archive:19"
+func ParseCompression(method string, level int, collectionLevels string) (c Compression, err error) {
+	c.Method = strings.ToLower(method)
+	if c.Method == "" {
+		c.Method = CompressionGzip
+	}
+	if c.Method != CompressionGzip && c.Method != CompressionZstd {
+		return c, fmt.Errorf("unknown compression %q, should be %s or %s", method, CompressionGzip, CompressionZstd)
+	}
+	if c.Level, err = parseZstdLevel(level); err != nil {
+		return c, err
+	}
+	for _, pair := range strings.Split(collectionLevels, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		collection, levelString, found := strings.Cut(pair, ":")
+		if !found {
+			return c, fmt.Errorf("collection level %q should be <collection>:<level>", pair)
+		}
+		collectionLevel, parseErr := strconv.Atoi(levelString)
+		if parseErr != nil {
+			return c, fmt.Errorf("collection level %q: %v", pair, parseErr)
+		}
+		if collectionLevel, err = parseZstdLevel(collectionLevel); err != nil {
+			return c, err
+		}
+		if c.CollectionLevels == nil {
+			c.CollectionLevels = make(map[string]int)
+		}
+		c.CollectionLevels[collection] = collectionLevel
+	}
+	if (level != 0 || len(c.CollectionLevels) > 0) && c.Method != CompressionZstd {
+		return c, fmt.Errorf("compression levels are only supported by %s", CompressionZstd)
+	}
+	return c, nil
+}
+
+func parseZstdLevel(level int) (int, error) {
+	if level < 0 || level > 22 {
+		return 0, fmt.Errorf("zstd level %d should be between 1 and 22, or 0 for the default", level)
+	}
+	return level, nil
+}
+
+// ForCollection resolves the zstd level hint of the collection
+func (c Compression) ForCollection(collection string) Compression {
+	if level, found := c.CollectionLevels[collection]; found {
+		return Compression{Method: c.Method, Level: level}
+	}
+	return Compression{Method: c.Method, Level: c.Level}
+}
+
+// ForUpload applies the compression hints of an upload, the "compression" method and the zstd "compressionLevel",
+// which override the compression of the volume server and the collection
+func (c Compression) ForUpload(r *http.Request) (Compression, error) {
+	method, levelString := r.FormValue("compression"), r.FormValue("compressionLevel")
+	if method == "" && levelString == "" {
+		return c, nil
+	}
+	if method == "" {
+		method = c.Method
+	}
+	level := 0
+	if levelString != "" {
+		var err error
+		if level, err = strconv.Atoi(levelString); err != nil {
+			return c, fmt.Errorf("compressionLevel %q: %v", levelString, err)
+		}
+	} else if strings.EqualFold(method, c.Method) {
+		level = c.Level
+	}
+	return ParseCompression(method, level, "")
+}
+
+func (c Compression) compress(data []byte) ([]byte, error) {
+	if c.Method == CompressionZstd {
+		return util.ZstdData(data, c.Level)
+	}
+	return util.GzipData(data)
+}
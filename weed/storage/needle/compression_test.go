@@ -0,0 +1,133 @@
+package needle
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+func TestParseCompression(t *testing.T) {
+	c, err := ParseCompression("zstd", 3, "realtime:1, archive:19")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if got := c.ForCollection("archive").Level; got != 19 {
+		t.Errorf("archive level %d", got)
+	}
+	if got := c.ForCollection("realtime").Level; got != 1 {
+		t.Errorf("realtime level %d", got)
+	}
+	if got := c.ForCollection("").Level; got != 3 {
+		t.Errorf("default level %d", got)
+	}
+
+	for _, bad := range [][]interface{}{
+		{"lz4", 0, ""},
+		{"zstd", 23, ""},
+		{"zstd", 0, "archive"},
+		{"zstd", 0, "archive:x"},
+		{"gzip", 0, "archive:19"},
+		{"gzip", 5, ""},
+	} {
+		if _, err := ParseCompression(bad[0].(string), bad[1].(int), bad[2].(string)); err == nil {
+			t.Errorf("parse %v should fail", bad)
+		}
+	}
+}
+
+func TestCreateNeedleWithZstd(t *testing.T) {
+	content := strings.Repeat("compressible text content\n", 100)
+	for _, level := range []int{0, 1, 19} {
+		r := httptest.NewRequest(http.MethodPut, "/3,01637037d6", strings.NewReader(content))
+		r.Header.Set("Content-Type", "text/plain")
+		n, originalSize, _, err := CreateNeedleFromRequest(r, false, 1024*1024, &bytes.Buffer{}, Compression{Method: CompressionZstd, Level: level})
+		if err != nil {
+			t.Fatalf("level %d: %v", level, err)
+		}
+		if !n.IsCompressed() || !util.IsZstdContent(n.Data) {
+			t.Fatalf("level %d: needle data should be zstd compressed", level)
+		}
+		if originalSize != len(content) {
+			t.Errorf("level %d: original size %d", level, originalSize)
+		}
+		data, err := util.DecompressData(n.Data)
+		if err != nil || string(data) != content {
+			t.Errorf("level %d: decompressed %d bytes: %v", level, len(data), err)
+		}
+	}
+}
+
+func TestCreateNeedleFromZstdUpload(t *testing.T) {
+	content := strings.Repeat("compressible text content\n", 100)
+	compressed, _ := util.ZstdData([]byte(content), 0)
+	r := httptest.NewRequest(http.MethodPut, "/3,01637037d6", bytes.NewReader(compressed))
+	r.Header.Set("Content-Type", "text/plain")
+	r.Header.Set("Content-Encoding", "zstd")
+	n, originalSize, _, err := CreateNeedleFromRequest(r, false, 1024*1024, &bytes.Buffer{}, Compression{})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if !n.IsCompressed() || !bytes.Equal(n.Data, compressed) {
+		t.Fatalf("zstd upload should be stored as is")
+	}
+	if originalSize != len(content) {
+		t.Errorf("original size %d", originalSize)
+	}
+}
+
+func TestCreateNeedleWithCompressionHints(t *testing.T) {
+	content := strings.Repeat("compressible text content\n", 100)
+	archive, err := ParseCompression("zstd", 3, "archive:19")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	for _, tc := range []struct {
+		name        string
+		query       string
+		compression Compression
+		zstd        bool
+	}{
+		{"no hint", "", Compression{}, false},
+		{"zstd hint", "?compression=zstd", Compression{}, true},
+		{"zstd level hint", "?compression=zstd&compressionLevel=1", Compression{}, true},
+		{"level hint", "?compressionLevel=1", archive.ForCollection("archive"), true},
+		{"gzip hint", "?compression=gzip", archive.ForCollection("archive"), false},
+	} {
+		r := httptest.NewRequest(http.MethodPut, "/3,01637037d6"+tc.query, strings.NewReader(content))
+		r.Header.Set("Content-Type", "text/plain")
+		n, _, _, err := CreateNeedleFromRequest(r, false, 1024*1024, &bytes.Buffer{}, tc.compression)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if !n.IsCompressed() || util.IsZstdContent(n.Data) != tc.zstd || util.IsGzippedContent(n.Data) == tc.zstd {
+			t.Errorf("%s: needle data should be compressed with zstd %v", tc.name, tc.zstd)
+		}
+		data, err := util.DecompressData(n.Data)
+		if err != nil || string(data) != content {
+			t.Errorf("%s: decompressed %d bytes: %v", tc.name, len(data), err)
+		}
+	}
+
+	for _, query := range []string{"?compression=lz4", "?compressionLevel=x", "?compression=zstd&compressionLevel=23", "?compression=gzip&compressionLevel=5"} {
+		r := httptest.NewRequest(http.MethodPut, "/3,01637037d6"+query, strings.NewReader(content))
+		if _, _, _, err := CreateNeedleFromRequest(r, false, 1024*1024, &bytes.Buffer{}, Compression{}); err == nil {
+			t.Errorf("upload with %s should fail", query)
+		}
+	}
+}
+
+func TestCompressionLevelHint(t *testing.T) {
+	c, _ := ParseCompression("zstd", 3, "archive:19")
+	r := httptest.NewRequest(http.MethodPut, "/3,01637037d6?compression=zstd", nil)
+	if hint, err := c.ForCollection("archive").ForUpload(r); err != nil || hint.Level != 19 {
+		t.Errorf("the collection level should be kept: %+v %v", hint, err)
+	}
+	r = httptest.NewRequest(http.MethodPut, "/3,01637037d6?compressionLevel=1", nil)
+	if hint, err := c.ForCollection("archive").ForUpload(r); err != nil || hint.Method != CompressionZstd || hint.Level != 1 {
+		t.Errorf("the level hint should override the collection level: %+v %v", hint, err)
+	}
+}
@@ -34,6 +34,10 @@ type ParsedUpload struct {
 }
 
 func ParseUpload(r *http.Request, sizeLimit int64, bytesBuffer *bytes.Buffer) (pu *ParsedUpload, e error) {
+	return parseUpload(r, sizeLimit, bytesBuffer, Compression{})
+}
+
+func parseUpload(r *http.Request, sizeLimit int64, bytesBuffer *bytes.Buffer, compression Compression) (pu *ParsedUpload, e error) {
 	bytesBuffer.Reset()
 	pu = &ParsedUpload{bytesBuffer: bytesBuffer}
 	pu.PairMap = make(map[string]string)
@@ -62,6 +66,9 @@ func ParseUpload(r *http.Request, sizeLimit int64, bytesBuffer *bytes.Buffer) (p
 
 	pu.ModifiedTime, _ = strconv.ParseUint(r.FormValue("ts"), 10, 64)
 	pu.Ttl, _ = ReadTTL(r.FormValue("ttl"))
+	if compression, e = compression.ForUpload(r); e != nil {
+		return
+	}
 
 	pu.OriginalDataSize = len(pu.Data)
 	pu.UncompressedData = pu.Data
@@ -84,12 +91,12 @@ func ParseUpload(r *http.Request, sizeLimit int64, bytesBuffer *bytes.Buffer) (p
 		}
 		if shouldBeCompressed, iAmSure := util.IsCompressableFileType(ext, mimeType); shouldBeCompressed && iAmSure {
 			// println("ext", ext, "iAmSure", iAmSure, "shouldBeCompressed", shouldBeCompressed, "mimeType", pu.MimeType)
-			if compressedData, err := util.GzipData(pu.Data); err == nil {
+			if compressedData, err := compression.compress(pu.Data); err == nil {
 				if len(compressedData)*10 < len(pu.Data)*9 {
 					pu.Data = compressedData
 					pu.IsGzipped = true
 				}
-				// println("gzipped data size", len(compressedData))
+				// println("compressed data size", len(compressedData))
 			}
 		}
 	}
@@ -108,9 +115,13 @@ func ParseUpload(r *http.Request, sizeLimit int64, bytesBuffer *bytes.Buffer) (p
 	return
 }
 
+// isCompressedEncoding tells whether the data is uploaded already compressed by gzip or zstd, and stored as is
+func isCompressedEncoding(contentEncoding string) bool {
+	return contentEncoding == "gzip" || contentEncoding == "zstd"
+}
+
 func parsePut(r *http.Request, sizeLimit int64, pu *ParsedUpload) error {
-	pu.IsGzipped = r.Header.Get("Content-Encoding") == "gzip"
-	// pu.IsZstd = r.Header.Get("Content-Encoding") == "zstd"
+	pu.IsGzipped = isCompressedEncoding(r.Header.Get("Content-Encoding"))
 	pu.MimeType = r.Header.Get("Content-Type")
 	pu.FileName = ""
 	dataSize, err := pu.bytesBuffer.ReadFrom(io.LimitReader(r.Body, sizeLimit+1))
@@ -208,8 +219,7 @@ func parseMultipart(r *http.Request, sizeLimit int64, pu *ParsedUpload) (e error
 		}
 
 	}
-	pu.IsGzipped = part.Header.Get("Content-Encoding") == "gzip"
-	// pu.IsZstd = part.Header.Get("Content-Encoding") == "zstd"
+	pu.IsGzipped = isCompressedEncoding(part.Header.Get("Content-Encoding"))
 
 	return
 }
@@ -270,8 +280,7 @@ func parseRawPost(r *http.Request, sizeLimit int64, pu *ParsedUpload) (e error)
 		}
 
 	}
-	pu.IsGzipped = r.Header.Get("Content-Encoding") == "gzip"
-	// pu.IsZstd = r.Header.Get("Content-Encoding") == "zstd"
+	pu.IsGzipped = isCompressedEncoding(r.Header.Get("Content-Encoding"))
 
 	return
 }
@@ -8,22 +8,30 @@ import (
 	. "github.com/seaweedfs/seaweedfs/weed/storage/types"
 	"github.com/seaweedfs/seaweedfs/weed/util"
 	"github.com/seaweedfs/seaweedfs/weed/util/buffer_pool"
+	"io"
 	"math"
 )
 
 func (n *Needle) prepareWriteBuffer(version Version, writeBytes *bytes.Buffer) (Size, int64, error) {
+	return n.prepareWriteBufferAround(version, writeBytes, uint32(len(n.Data)), func() {
+		writeBytes.Write(n.Data)
+	})
+}
+
+// prepareWriteBufferAround writes the needle to writeBytes, with the data of dataSize bytes written by writeData
+func (n *Needle) prepareWriteBufferAround(version Version, writeBytes *bytes.Buffer, dataSize uint32, writeData func()) (Size, int64, error) {
 	writeBytes.Reset()
 	switch version {
 	case Version1:
 		header := make([]byte, NeedleHeaderSize)
 		CookieToBytes(header[0:CookieSize], n.Cookie)
 		NeedleIdToBytes(header[CookieSize:CookieSize+NeedleIdSize], n.Id)
-		n.Size = Size(len(n.Data))
+		n.Size = Size(dataSize)
 		SizeToBytes(header[CookieSize+NeedleIdSize:CookieSize+NeedleIdSize+SizeSize], n.Size)
 		size := n.Size
 		actualSize := NeedleHeaderSize + int64(n.Size)
 		writeBytes.Write(header)
-		writeBytes.Write(n.Data)
+		writeData()
 		padding := PaddingLength(n.Size, version)
 		util.Uint32toBytes(header[0:NeedleChecksumSize], uint32(n.Checksum))
 		writeBytes.Write(header[0 : NeedleChecksumSize+padding])
@@ -37,7 +45,7 @@ func (n *Needle) prepareWriteBuffer(version Version, writeBytes *bytes.Buffer) (
 		} else {
 			n.NameSize = uint8(len(n.Name))
 		}
-		n.DataSize, n.MimeSize = uint32(len(n.Data)), uint8(len(n.Mime))
+		n.DataSize, n.MimeSize = dataSize, uint8(len(n.Mime))
 		if n.DataSize > 0 {
 			n.Size = 4 + Size(n.DataSize) + 1
 			if n.HasName() {
@@ -63,7 +71,7 @@ func (n *Needle) prepareWriteBuffer(version Version, writeBytes *bytes.Buffer) (
 		if n.DataSize > 0 {
 			util.Uint32toBytes(header[0:4], n.DataSize)
 			writeBytes.Write(header[0:4])
-			writeBytes.Write(n.Data)
+			writeData()
 			util.Uint8toBytes(header[0:1], n.Flags)
 			writeBytes.Write(header[0:1])
 			if n.HasName() {
@@ -138,6 +146,58 @@ func (n *Needle) Append(w backend.BackendStorageFile, version Version) (offset u
 	return offset, size, actualSize, err
 }
 
+// AppendFrom appends the needle with its dataSize bytes of data copied from data instead of n.Data,
+// so that large needles are not kept in memory. The checksum of the needle should be set to the crc of the data.
+func (n *Needle) AppendFrom(w backend.BackendStorageFile, version Version, data io.Reader, dataSize int64) (offset uint64, size Size, actualSize int64, err error) {
+
+	if end, _, e := w.GetStat(); e == nil {
+		defer func(w backend.BackendStorageFile, off int64) {
+			if err != nil {
+				if te := w.Truncate(end); te != nil {
+					glog.V(0).Infof("Failed to truncate %s back to %d with error: %v", w.Name(), end, te)
+				}
+			}
+		}(w, end)
+		offset = uint64(end)
+	} else {
+		err = fmt.Errorf("Cannot Read Current Volume Position: %v", e)
+		return
+	}
+	if offset >= MaxPossibleVolumeSize && dataSize != 0 {
+		err = fmt.Errorf("Volume Size %d Exceeded %d", offset, MaxPossibleVolumeSize)
+		return
+	}
+	if dataSize > math.MaxUint32 {
+		err = fmt.Errorf("needle data size %d exceeds %d", dataSize, uint32(math.MaxUint32))
+		return
+	}
+
+	bytesBuffer := buffer_pool.SyncPoolGetBuffer()
+	defer buffer_pool.SyncPoolPutBuffer(bytesBuffer)
+
+	// the needle is written as the bytes before the data, the data, and the bytes after the data
+	var dataStart int
+	if size, actualSize, err = n.prepareWriteBufferAround(version, bytesBuffer, uint32(dataSize), func() {
+		dataStart = bytesBuffer.Len()
+	}); err != nil {
+		return
+	}
+	if _, err = w.WriteAt(bytesBuffer.Bytes()[:dataStart], int64(offset)); err != nil {
+		return
+	}
+	copied, err := io.Copy(io.NewOffsetWriter(w, int64(offset)+int64(dataStart)), io.LimitReader(data, dataSize))
+	if err != nil {
+		return
+	}
+	if copied != dataSize {
+		err = fmt.Errorf("needle data has %d bytes, expected %d", copied, dataSize)
+		return
+	}
+	_, err = w.WriteAt(bytesBuffer.Bytes()[dataStart:], int64(offset)+int64(dataStart)+dataSize)
+
+	return offset, size, actualSize, err
+}
+
 func WriteNeedleBlob(w backend.BackendStorageFile, dataSlice []byte, size Size, appendAtNs uint64, version Version) (offset uint64, err error) {
 
 	if end, _, e := w.GetStat(); e == nil {
@@ -1,7 +1,9 @@
 package needle
 
 import (
+	"bytes"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/seaweedfs/seaweedfs/weed/storage/backend"
@@ -62,3 +64,54 @@ func TestAppend(t *testing.T) {
 		t.Errorf("Fail to Append Needle.")
 	}
 }
+
+func TestAppendFrom(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 1000)
+	newNeedle := func() *Needle {
+		n := &Needle{Cookie: 123, Id: 456, Name: []byte("big.log"), Mime: []byte("text/plain"), LastModified: 123, AppendAtNs: 123, Checksum: NewCRC(data)}
+		n.SetHasName()
+		n.SetHasMime()
+		n.SetHasLastModifiedDate()
+		return n
+	}
+
+	for _, version := range []Version{Version1, Version2, Version3} {
+		appended, streamed := filepath.Join(t.TempDir(), "appended.dat"), filepath.Join(t.TempDir(), "streamed.dat")
+		for _, name := range []string{appended, streamed} {
+			f, err := os.Create(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			datBackend := backend.NewDiskFile(f)
+			n := newNeedle()
+			if name == appended {
+				n.Data = data
+				_, _, _, err = n.Append(datBackend, version)
+			} else {
+				_, _, _, err = n.AppendFrom(datBackend, version, bytes.NewReader(data), int64(len(data)))
+			}
+			datBackend.Close()
+			if err != nil {
+				t.Fatalf("version %d: write %s: %v", version, name, err)
+			}
+		}
+		expected, _ := os.ReadFile(appended)
+		actual, _ := os.ReadFile(streamed)
+		if !bytes.Equal(expected, actual) {
+			t.Errorf("version %d: the streamed needle differs from the appended needle", version)
+		}
+	}
+
+	f, err := os.Create(filepath.Join(t.TempDir(), "short.dat"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	datBackend := backend.NewDiskFile(f)
+	defer datBackend.Close()
+	if _, _, _, err = newNeedle().AppendFrom(datBackend, CurrentVersion, bytes.NewReader(data[:10]), int64(len(data))); err == nil {
+		t.Errorf("appending from short data should fail")
+	}
+	if size, _, _ := datBackend.GetStat(); size != 0 {
+		t.Errorf("the failed append should be truncated, size %d", size)
+	}
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/karlseguin/ccache/v2"
+
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
+	. "github.com/seaweedfs/seaweedfs/weed/storage/types"
+)
+
+// NeedleCache keeps the blobs of recently read small needles in memory, evicting the least recently used ones.
+type NeedleCache struct {
+	cache         *ccache.Cache
+	maxNeedleSize int64
+}
+
+type cachedNeedleBlob struct {
+	offset int64
+	blob   []byte
+}
+
+func (b *cachedNeedleBlob) Size() int64 {
+	return int64(len(b.blob))
+}
+
+// NewNeedleCache creates a cache of maxBytes, only for needles up to maxNeedleSize bytes on disk.
+func NewNeedleCache(maxBytes int64, maxNeedleSize int64) *NeedleCache {
+	return &NeedleCache{
+		cache:         ccache.New(ccache.Configure().MaxSize(maxBytes).ItemsToPrune(100)),
+		maxNeedleSize: maxNeedleSize,
+	}
+}
+
+func needleCacheKey(vid needle.VolumeId, id NeedleId) string {
+	return fmt.Sprintf("%d,%x", vid, uint64(id))
+}
+
+func (c *NeedleCache) accepts(size Size, version needle.Version) bool {
+	return needle.GetActualSize(size, version) <= c.maxNeedleSize
+}
+
+// get returns the cached blob, only if it was read from the same offset, i.e. the needle was not updated or compacted since
+func (c *NeedleCache) get(vid needle.VolumeId, id NeedleId, offset int64) []byte {
+	item := c.cache.Get(needleCacheKey(vid, id))
+	if item == nil {
+		return nil
+	}
+	cached := item.Value().(*cachedNeedleBlob)
+	if cached.offset != offset {
+		return nil
+	}
+	item.Extend(time.Hour)
+	return cached.blob
+}
+
+func (c *NeedleCache) set(vid needle.VolumeId, id NeedleId, offset int64, blob []byte) {
+	c.cache.Set(needleCacheKey(vid, id), &cachedNeedleBlob{offset: offset, blob: blob}, time.Hour)
+}
+
+func (c *NeedleCache) deleteVolume(vid needle.VolumeId) {
+	c.cache.DeletePrefix(fmt.Sprintf("%d,", vid))
+}
+
+// readData is needle.ReadData, but reading the needle blob from the cache if possible
+func (c *NeedleCache) readData(v *Volume, n *needle.Needle, offset int64, size Size) (err error) {
+	if blob := c.get(v.Id, n.Id, offset); blob != nil {
+		// the needle keeps slices of the blob, so give it a copy
+		return n.ReadBytes(append([]byte(nil), blob...), offset, size, v.Version())
+	}
+	blob, err := needle.ReadNeedleBlob(v.DataBackend, offset, size, v.Version())
+	if err != nil {
+		return err
+	}
+	if err = n.ReadBytes(append([]byte(nil), blob...), offset, size, v.Version()); err != nil {
+		if err == needle.ErrorSizeMismatch && OffsetSize == 4 {
+			// the needle is beyond the 32GB offset limit, not worth caching
+			return n.ReadData(v.DataBackend, offset, size, v.Version())
+		}
+		return err
+	}
+	c.set(v.Id, n.Id, offset, blob)
+	return nil
+}
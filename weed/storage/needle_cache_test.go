@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
+	"github.com/seaweedfs/seaweedfs/weed/storage/super_block"
+)
+
+func TestNeedleCache(t *testing.T) {
+	dir := t.TempDir()
+	v, err := NewVolume(dir, dir, "", 1, NeedleMapInMemory, &super_block.ReplicaPlacement{}, &needle.TTL{}, 0, 0, 0)
+	if err != nil {
+		t.Fatalf("volume creation: %v", err)
+	}
+	defer v.Close()
+
+	cache := NewNeedleCache(1024*1024, 4096)
+	location := &DiskLocation{volumes: make(map[needle.VolumeId]*Volume), needleCache: cache}
+	location.SetVolume(v.Id, v)
+
+	readData := func(id uint64) []byte {
+		n := newEmptyNeedle(id)
+		if _, err := v.readNeedle(n, nil, nil); err != nil {
+			t.Fatalf("read file %d: %v", id, err)
+		}
+		return n.Data
+	}
+
+	for _, data := range [][]byte{[]byte("first version"), []byte("second version")} {
+		n := newEmptyNeedle(1)
+		n.Data = data
+		n.Checksum = needle.NewCRC(n.Data)
+		if _, _, _, err = v.writeNeedle2(n, true, false); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+		for i := 0; i < 2; i++ {
+			if got := readData(1); !bytes.Equal(got, data) {
+				t.Errorf("read %q, expected %q", got, data)
+			}
+		}
+	}
+
+	nv, _ := v.nm.Get(1)
+	if cache.get(v.Id, 1, nv.Offset.ToActualOffset()) == nil {
+		t.Errorf("file 1 is not cached")
+	}
+	location.SetVolume(v.Id, v)
+	if cache.get(v.Id, 1, nv.Offset.ToActualOffset()) != nil {
+		t.Errorf("file 1 is still cached after reloading the volume")
+	}
+}
@@ -58,6 +58,7 @@ type Store struct {
 	MasterAddress       pb.ServerAddress
 	grpcDialOption      grpc.DialOption
 	volumeSizeLimit     uint64 // read from the master
+	maxVolumeSize       uint64 // optional, set on the volume server
 	Ip                  string
 	Port                int
 	GrpcPort            int
@@ -65,6 +66,7 @@ type Store struct {
 	Locations           []*DiskLocation
 	dataCenter          string // optional information, overwriting master setting if exists
 	rack                string // optional information, overwriting master setting if exists
+	tags                map[string]string
 	connected           bool
 	NeedleMapKind       NeedleMapKind
 	NewVolumesChan      chan master_pb.VolumeShortInformationMessage
@@ -106,6 +108,14 @@ func NewStore(grpcDialOption grpc.DialOption, ip string, port int, grpcPort int,
 
 	return
 }
+
+// SetNeedleCache caches the needles read from all disk locations in memory
+func (s *Store) SetNeedleCache(cache *NeedleCache) {
+	for _, location := range s.Locations {
+		location.needleCache = cache
+	}
+}
+
 func (s *Store) AddVolume(volumeId needle.VolumeId, collection string, needleMapKind NeedleMapKind, replicaPlacement string, ttlString string, preallocate int64, MemoryMapMaxSizeMb uint32, diskType DiskType, ldbTimeout int64) error {
 	rt, e := super_block.NewReplicaPlacementFromString(replicaPlacement)
 	if e != nil {
@@ -239,6 +249,9 @@ func (s *Store) SetDataCenter(dataCenter string) {
 func (s *Store) SetRack(rack string) {
 	s.rack = rack
 }
+func (s *Store) SetTags(tags map[string]string) {
+	s.tags = tags
+}
 func (s *Store) GetDataCenter() string {
 	return s.dataCenter
 }
@@ -367,6 +380,8 @@ func (s *Store) CollectHeartbeat() *master_pb.Heartbeat {
 		Volumes:         volumeMessages,
 		HasNoVolumes:    len(volumeMessages) == 0,
 		LocationUuids:   uuidList,
+		Tags:            s.tags,
+		MaxVolumeSize:   s.GetMaxVolumeSize(),
 	}
 
 }
@@ -396,7 +411,15 @@ func (s *Store) WriteVolumeNeedle(i needle.VolumeId, n *needle.Needle, checkCook
 			err = fmt.Errorf("volume %d is read only", i)
 			return
 		}
-		_, _, isUnchanged, err = v.writeNeedle2(n, checkCookie, fsync && s.isStopping)
+		if datSize, _, _ := v.FileStat(); s.isOverMaxVolumeSize(datSize) {
+			err = fmt.Errorf("volume %d size %d reached the max volume size %d", i, datSize, s.GetMaxVolumeSize())
+			return
+		}
+		var size Size
+		_, size, isUnchanged, err = v.writeNeedle2(n, checkCookie, fsync && s.isStopping)
+		if err == nil && !isUnchanged {
+			atomic.AddUint64(&v.writeBytes, uint64(size))
+		}
 		return
 	}
 	glog.V(0).Infoln("volume", i, "not found!")
@@ -404,6 +427,25 @@ func (s *Store) WriteVolumeNeedle(i needle.VolumeId, n *needle.Needle, checkCook
 	return
 }
 
+// WriteVolumeNeedleFrom writes the needle with its dataSize bytes of data copied from data, instead of n.Data
+func (s *Store) WriteVolumeNeedleFrom(i needle.VolumeId, n *needle.Needle, data io.Reader, dataSize int64, fsync bool) (err error) {
+	if v := s.findVolume(i); v != nil {
+		if v.IsReadOnly() {
+			return fmt.Errorf("volume %d is read only", i)
+		}
+		if datSize, _, _ := v.FileStat(); s.isOverMaxVolumeSize(datSize) {
+			return fmt.Errorf("volume %d size %d reached the max volume size %d", i, datSize, s.GetMaxVolumeSize())
+		}
+		_, size, err := v.writeNeedleFrom(n, data, dataSize, fsync)
+		if err == nil {
+			atomic.AddUint64(&v.writeBytes, uint64(size))
+		}
+		return err
+	}
+	glog.V(0).Infoln("volume", i, "not found!")
+	return fmt.Errorf("volume %d not found on %s:%d", i, s.Ip, s.Port)
+}
+
 func (s *Store) DeleteVolumeNeedle(i needle.VolumeId, n *needle.Needle) (Size, error) {
 	if v := s.findVolume(i); v != nil {
 		if v.noWriteOrDelete {
@@ -416,7 +458,11 @@ func (s *Store) DeleteVolumeNeedle(i needle.VolumeId, n *needle.Needle) (Size, e
 
 func (s *Store) ReadVolumeNeedle(i needle.VolumeId, n *needle.Needle, readOption *ReadOption, onReadSizeFn func(size Size)) (int, error) {
 	if v := s.findVolume(i); v != nil {
-		return v.readNeedle(n, readOption, onReadSizeFn)
+		count, err := v.readNeedle(n, readOption, onReadSizeFn)
+		if count > 0 {
+			atomic.AddUint64(&v.readBytes, uint64(count))
+		}
+		return count, err
 	}
 	return 0, fmt.Errorf("volume %d not found", i)
 }
@@ -430,7 +476,11 @@ func (s *Store) ReadVolumeNeedleMetaAt(i needle.VolumeId, n *needle.Needle, offs
 
 func (s *Store) ReadVolumeNeedleDataInto(i needle.VolumeId, n *needle.Needle, readOption *ReadOption, writer io.Writer, offset int64, size int64) error {
 	if v := s.findVolume(i); v != nil {
-		return v.readNeedleDataInto(n, readOption, writer, offset, size)
+		err := v.readNeedleDataInto(n, readOption, writer, offset, size)
+		if err == nil {
+			atomic.AddUint64(&v.readBytes, uint64(size))
+		}
+		return err
 	}
 	return fmt.Errorf("volume %d not found", i)
 }
@@ -578,11 +628,28 @@ func (s *Store) GetVolumeSizeLimit() uint64 {
 	return atomic.LoadUint64(&s.volumeSizeLimit)
 }
 
+// SetMaxVolumeSize caps the size of each volume on this server, below the volume size limit of the master.
+func (s *Store) SetMaxVolumeSize(x uint64) {
+	atomic.StoreUint64(&s.maxVolumeSize, x)
+}
+
+func (s *Store) GetMaxVolumeSize() uint64 {
+	return atomic.LoadUint64(&s.maxVolumeSize)
+}
+
+func (s *Store) isOverMaxVolumeSize(volumeSize uint64) bool {
+	maxVolumeSize := s.GetMaxVolumeSize()
+	return maxVolumeSize > 0 && volumeSize >= maxVolumeSize
+}
+
 func (s *Store) MaybeAdjustVolumeMax() (hasChanges bool) {
 	volumeSizeLimit := s.GetVolumeSizeLimit()
 	if volumeSizeLimit == 0 {
 		return
 	}
+	if maxVolumeSize := s.GetMaxVolumeSize(); maxVolumeSize > 0 && maxVolumeSize < volumeSizeLimit {
+		volumeSizeLimit = maxVolumeSize
+	}
 	var newMaxVolumeCount int32
 	for _, diskLocation := range s.Locations {
 		if diskLocation.OriginalMaxVolumeCount == 0 {
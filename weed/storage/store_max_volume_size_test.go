@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
+	"github.com/seaweedfs/seaweedfs/weed/storage/super_block"
+)
+
+func TestStoreMaxVolumeSize(t *testing.T) {
+	dir := t.TempDir()
+	v, err := NewVolume(dir, dir, "", 1, NeedleMapInMemory, &super_block.ReplicaPlacement{}, &needle.TTL{}, 0, 0, 0)
+	if err != nil {
+		t.Fatalf("volume creation: %v", err)
+	}
+	defer v.Close()
+	location := &DiskLocation{volumes: make(map[needle.VolumeId]*Volume)}
+	location.SetVolume(v.Id, v)
+	store := &Store{Locations: []*DiskLocation{location}}
+	store.SetMaxVolumeSize(1024)
+
+	write := func(id uint64) error {
+		n := newEmptyNeedle(id)
+		n.Data = make([]byte, 600)
+		n.Checksum = needle.NewCRC(n.Data)
+		_, err := store.WriteVolumeNeedle(v.Id, n, true, false)
+		return err
+	}
+	isReadOnly := func() bool {
+		heartbeat := store.CollectHeartbeat()
+		if heartbeat.MaxVolumeSize != 1024 {
+			t.Errorf("heartbeat max volume size %d, want 1024", heartbeat.MaxVolumeSize)
+		}
+		for _, volumeMessage := range heartbeat.Volumes {
+			if volumeMessage.Id == uint32(v.Id) {
+				return volumeMessage.ReadOnly
+			}
+		}
+		t.Fatalf("volume %d not in heartbeat", v.Id)
+		return false
+	}
+
+	if err = write(1); err != nil {
+		t.Fatalf("write under the max volume size: %v", err)
+	}
+	if isReadOnly() {
+		t.Errorf("volume under the max volume size reported as read only")
+	}
+	if err = write(2); err != nil {
+		t.Fatalf("write crossing the max volume size: %v", err)
+	}
+	if err = write(3); err == nil {
+		t.Errorf("write over the max volume size should fail")
+	}
+	// the master stops assigning to the volume by the max volume size in the heartbeat,
+	// while the volume is still vacuumed as a writable volume
+	if isReadOnly() {
+		t.Errorf("volume over the max volume size reported as read only")
+	}
+	if _, err = store.DeleteVolumeNeedle(v.Id, newEmptyNeedle(1)); err != nil {
+		t.Errorf("delete from a full volume: %v", err)
+	}
+}
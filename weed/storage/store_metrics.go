@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"strconv"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/seaweedfs/seaweedfs/weed/stats"
+)
+
+var (
+	volumeMetricLabels = []string{"volume_id", "collection"}
+
+	volumeTotalSizeDesc = prometheus.NewDesc(prometheus.BuildFQName(stats.Namespace, "volume", "total_size_bytes"),
+		"Size of the volume data file.", volumeMetricLabels, nil)
+	volumeUsedDesc = prometheus.NewDesc(prometheus.BuildFQName(stats.Namespace, "volume", "used_bytes"),
+		"Size of the needles not deleted.", volumeMetricLabels, nil)
+	volumeNeedleCountDesc = prometheus.NewDesc(prometheus.BuildFQName(stats.Namespace, "volume", "needle_count"),
+		"Number of needles, including the deleted ones.", volumeMetricLabels, nil)
+	volumeDeleteCountDesc = prometheus.NewDesc(prometheus.BuildFQName(stats.Namespace, "volume", "delete_count"),
+		"Number of deleted needles.", volumeMetricLabels, nil)
+	volumeReadBytesDesc = prometheus.NewDesc(prometheus.BuildFQName(stats.Namespace, "volume", "read_bytes_total"),
+		"Bytes of needles read since the volume is loaded.", volumeMetricLabels, nil)
+	volumeWriteBytesDesc = prometheus.NewDesc(prometheus.BuildFQName(stats.Namespace, "volume", "write_bytes_total"),
+		"Bytes of needles written since the volume is loaded.", volumeMetricLabels, nil)
+)
+
+// volumeMetricsCollector reports the metrics of each volume when scraped,
+// so the metrics of deleted or unmounted volumes go away with them.
+type volumeMetricsCollector struct {
+	store *Store
+}
+
+// NewVolumeMetricsCollector exports the size, needle counts and io of each volume of the store.
+func NewVolumeMetricsCollector(store *Store) prometheus.Collector {
+	return &volumeMetricsCollector{store: store}
+}
+
+func (c *volumeMetricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- volumeTotalSizeDesc
+	ch <- volumeUsedDesc
+	ch <- volumeNeedleCountDesc
+	ch <- volumeDeleteCountDesc
+	ch <- volumeReadBytesDesc
+	ch <- volumeWriteBytesDesc
+}
+
+func (c *volumeMetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, location := range c.store.Locations {
+		location.volumesLock.RLock()
+		for vid, v := range location.volumes {
+			labels := []string{strconv.FormatUint(uint64(vid), 10), v.Collection}
+			datSize, _, _ := v.FileStat()
+			var used uint64
+			if contentSize, deletedSize := v.ContentSize(), v.DeletedSize(); contentSize > deletedSize {
+				used = contentSize - deletedSize
+			}
+			ch <- prometheus.MustNewConstMetric(volumeTotalSizeDesc, prometheus.GaugeValue, float64(datSize), labels...)
+			ch <- prometheus.MustNewConstMetric(volumeUsedDesc, prometheus.GaugeValue, float64(used), labels...)
+			ch <- prometheus.MustNewConstMetric(volumeNeedleCountDesc, prometheus.GaugeValue, float64(v.FileCount()), labels...)
+			ch <- prometheus.MustNewConstMetric(volumeDeleteCountDesc, prometheus.GaugeValue, float64(v.DeletedCount()), labels...)
+			ch <- prometheus.MustNewConstMetric(volumeReadBytesDesc, prometheus.CounterValue, float64(atomic.LoadUint64(&v.readBytes)), labels...)
+			ch <- prometheus.MustNewConstMetric(volumeWriteBytesDesc, prometheus.CounterValue, float64(atomic.LoadUint64(&v.writeBytes)), labels...)
+		}
+		location.volumesLock.RUnlock()
+	}
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
+	"github.com/seaweedfs/seaweedfs/weed/storage/super_block"
+)
+
+func TestVolumeMetricsCollector(t *testing.T) {
+	dir := t.TempDir()
+	v, err := NewVolume(dir, dir, "pictures", 7, NeedleMapInMemory, &super_block.ReplicaPlacement{}, &needle.TTL{}, 0, 0, 0)
+	if err != nil {
+		t.Fatalf("volume creation: %v", err)
+	}
+	defer v.Close()
+	location := &DiskLocation{volumes: make(map[needle.VolumeId]*Volume)}
+	location.SetVolume(v.Id, v)
+	store := &Store{Locations: []*DiskLocation{location}}
+
+	for id := uint64(1); id <= 2; id++ {
+		n := newEmptyNeedle(id)
+		n.Data = []byte("some data")
+		n.Checksum = needle.NewCRC(n.Data)
+		if _, err = store.WriteVolumeNeedle(v.Id, n, true, false); err != nil {
+			t.Fatalf("write file %d: %v", id, err)
+		}
+	}
+	if _, err = store.ReadVolumeNeedle(v.Id, newEmptyNeedle(1), nil, nil); err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+	if _, err = store.DeleteVolumeNeedle(v.Id, newEmptyNeedle(2)); err != nil {
+		t.Fatalf("delete file: %v", err)
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(NewVolumeMetricsCollector(store))
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	values := make(map[string]float64)
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			labels := make(map[string]string)
+			for _, label := range m.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["volume_id"] != "7" || labels["collection"] != "pictures" {
+				t.Errorf("%s labels %v", family.GetName(), labels)
+			}
+			values[family.GetName()] = m.GetGauge().GetValue() + m.GetCounter().GetValue()
+		}
+	}
+
+	if values["SeaweedFS_volume_needle_count"] != 2 || values["SeaweedFS_volume_delete_count"] != 1 {
+		t.Errorf("needle count %v, delete count %v", values["SeaweedFS_volume_needle_count"], values["SeaweedFS_volume_delete_count"])
+	}
+	if values["SeaweedFS_volume_write_bytes_total"] == 0 || values["SeaweedFS_volume_read_bytes_total"] == 0 {
+		t.Errorf("write bytes %v, read bytes %v", values["SeaweedFS_volume_write_bytes_total"], values["SeaweedFS_volume_read_bytes_total"])
+	}
+	if used, total := values["SeaweedFS_volume_used_bytes"], values["SeaweedFS_volume_total_size_bytes"]; used == 0 || total <= used {
+		t.Errorf("used %v, total %v", used, total)
+	}
+}
@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
+)
+
+// FindColdVolumesToTier returns the local volumes without replication, which have files
+// but have not been written to or deleted from during the quiet duration.
+// The replicated volumes are skipped, since each replica would upload its own copy.
+func (s *Store) FindColdVolumesToTier(quietFor time.Duration) (vids []needle.VolumeId) {
+	quietSinceNs := uint64(time.Now().Add(-quietFor).UnixNano())
+	for _, location := range s.Locations {
+		location.volumesLock.RLock()
+		for vid, v := range location.volumes {
+			isCompacting, lastAppendAtNs := v.compactionState()
+			if isCompacting || v.HasRemoteFile() {
+				continue
+			}
+			if v.ReplicaPlacement.GetCopyCount() > 1 || lastAppendAtNs == 0 || lastAppendAtNs > quietSinceNs {
+				continue
+			}
+			if v.FileCount() > 0 {
+				vids = append(vids, vid)
+			}
+		}
+		location.volumesLock.RUnlock()
+	}
+	return
+}
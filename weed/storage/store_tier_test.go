@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb/volume_server_pb"
+	"github.com/seaweedfs/seaweedfs/weed/storage/backend"
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
+	"github.com/seaweedfs/seaweedfs/weed/storage/super_block"
+)
+
+// dirBackendStorage keeps the tiered files in a local directory, opened read only
+type dirBackendStorage struct {
+	dir string
+}
+
+func (s *dirBackendStorage) ToProperties() map[string]string {
+	return map[string]string{"dir": s.dir}
+}
+
+func (s *dirBackendStorage) NewStorageFile(key string, tierInfo *volume_server_pb.VolumeInfo) backend.BackendStorageFile {
+	f, _ := os.Open(filepath.Join(s.dir, key))
+	return backend.NewDiskFile(f)
+}
+
+func (s *dirBackendStorage) CopyFile(f *os.File, fn func(progressed int64, percentage float32) error) (key string, size int64, err error) {
+	key = filepath.Base(f.Name())
+	dst, err := os.Create(filepath.Join(s.dir, key))
+	if err != nil {
+		return "", 0, err
+	}
+	defer dst.Close()
+	size, err = io.Copy(dst, io.NewSectionReader(f, 0, 1<<40))
+	return
+}
+
+func (s *dirBackendStorage) DownloadFile(fileName string, key string, fn func(progressed int64, percentage float32) error) (size int64, err error) {
+	return 0, nil
+}
+
+func (s *dirBackendStorage) DeleteFile(key string) (err error) {
+	return os.Remove(filepath.Join(s.dir, key))
+}
+
+func TestFindColdVolumesToTier(t *testing.T) {
+	dir := t.TempDir()
+	v, err := NewVolume(dir, dir, "", 1, NeedleMapInMemory, &super_block.ReplicaPlacement{}, &needle.TTL{}, 0, 0, 0)
+	if err != nil {
+		t.Fatalf("volume creation: %v", err)
+	}
+	defer v.Close()
+	replicated, _ := super_block.NewReplicaPlacementFromString("001")
+	rv, err := NewVolume(dir, dir, "", 2, NeedleMapInMemory, replicated, &needle.TTL{}, 0, 0, 0)
+	if err != nil {
+		t.Fatalf("volume creation: %v", err)
+	}
+	defer rv.Close()
+	empty, err := NewVolume(dir, dir, "", 3, NeedleMapInMemory, &super_block.ReplicaPlacement{}, &needle.TTL{}, 0, 0, 0)
+	if err != nil {
+		t.Fatalf("volume creation: %v", err)
+	}
+	defer empty.Close()
+	for i := 1; i <= 3; i++ {
+		if _, _, _, err = v.writeNeedle2(newRandomNeedle(uint64(i)), true, false); err != nil {
+			t.Fatalf("write file %d: %v", i, err)
+		}
+		if _, _, _, err = rv.writeNeedle2(newRandomNeedle(uint64(i)), true, false); err != nil {
+			t.Fatalf("write file %d: %v", i, err)
+		}
+	}
+
+	location := &DiskLocation{volumes: map[needle.VolumeId]*Volume{v.Id: v, rv.Id: rv, empty.Id: empty}}
+	s := &Store{Locations: []*DiskLocation{location}}
+
+	if vids := s.FindColdVolumesToTier(time.Minute); len(vids) != 0 {
+		t.Errorf("recently written volumes %v should not be tiered", vids)
+	}
+	if vids := s.FindColdVolumesToTier(0); len(vids) != 1 || vids[0] != v.Id {
+		t.Fatalf("only the unreplicated volume with files should be tiered, got %v", vids)
+	}
+
+	// move the volume to the backend, like the volume server does
+	remote := &dirBackendStorage{dir: t.TempDir()}
+	backend.BackendStorages["dir.test"] = remote
+	defer delete(backend.BackendStorages, "dir.test")
+	key, size, err := remote.CopyFile(v.DataBackend.(*backend.DiskFile).File, nil)
+	if err != nil {
+		t.Fatalf("copy: %v", err)
+	}
+	v.GetVolumeInfo().Files = append(v.GetVolumeInfo().GetFiles(), &volume_server_pb.RemoteFile{
+		BackendType: "dir",
+		BackendId:   "test",
+		Key:         key,
+		FileSize:    uint64(size),
+		Extension:   ".dat",
+	})
+	if err = v.LoadRemoteFile(); err != nil {
+		t.Fatalf("load remote file: %v", err)
+	}
+
+	if !v.HasRemoteFile() || !v.IsReadOnly() {
+		t.Errorf("tiered volume should be read only")
+	}
+	if vids := s.FindColdVolumesToTier(0); len(vids) != 0 {
+		t.Errorf("tiered volume %v should not be tiered again", vids)
+	}
+	n := newEmptyNeedle(1)
+	if _, err = v.readNeedle(n, nil, nil); err != nil {
+		t.Errorf("read tiered needle: %v", err)
+	}
+	// the backend file is read only, so the delete only updates the index
+	if _, err = v.deleteNeedle2(newEmptyNeedle(2)); err != nil {
+		t.Errorf("delete tiered needle: %v", err)
+	}
+	if v.DeletedCount() != 1 {
+		t.Errorf("deleted count %d after delete", v.DeletedCount())
+	}
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/seaweedfs/seaweedfs/weed/stats"
 
@@ -47,3 +48,23 @@ func (s *Store) CommitCleanupVolume(vid needle.VolumeId) error {
 	}
 	return fmt.Errorf("volume id %d is not found during cleaning up", vid)
 }
+
+// FindIdleVolumesToCompact returns the local volumes with at least garbageThreshold garbage,
+// which have not been written to or deleted from during the idle duration.
+func (s *Store) FindIdleVolumesToCompact(garbageThreshold float64, idle time.Duration) (vids []needle.VolumeId) {
+	idleSinceNs := uint64(time.Now().Add(-idle).UnixNano())
+	for _, location := range s.Locations {
+		location.volumesLock.RLock()
+		for vid, v := range location.volumes {
+			isCompacting, lastAppendAtNs := v.compactionState()
+			if isCompacting || v.HasRemoteFile() || lastAppendAtNs > idleSinceNs {
+				continue
+			}
+			if v.garbageLevel() >= garbageThreshold {
+				vids = append(vids, vid)
+			}
+		}
+		location.volumesLock.RUnlock()
+	}
+	return
+}
@@ -52,6 +52,9 @@ type Volume struct {
 	location   *DiskLocation
 
 	lastIoError error
+
+	readBytes  uint64 // updated atomically, for the metrics
+	writeBytes uint64 // updated atomically, for the metrics
 }
 
 func NewVolume(dirname string, dirIdx string, collection string, id needle.VolumeId, needleMapKind NeedleMapKind, replicaPlacement *super_block.ReplicaPlacement, ttl *needle.TTL, preallocate int64, memoryMapMaxSizeMb uint32, ldbTimeout int64) (v *Volume, e error) {
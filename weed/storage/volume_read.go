@@ -54,7 +54,11 @@ func (v *Volume) readNeedle(n *needle.Needle, readOption *ReadOption, onReadSize
 		}
 	}
 	if readOption == nil || !readOption.IsMetaOnly {
-		err = n.ReadData(v.DataBackend, nv.Offset.ToActualOffset(), readSize, v.Version())
+		if cache := v.needleCache(); cache != nil && !nv.Size.IsDeleted() && cache.accepts(readSize, v.Version()) {
+			err = cache.readData(v, n, nv.Offset.ToActualOffset(), readSize)
+		} else {
+			err = n.ReadData(v.DataBackend, nv.Offset.ToActualOffset(), readSize, v.Version())
+		}
 		v.checkReadWriteError(err)
 		if err != nil {
 			return 0, err
@@ -77,6 +81,13 @@ func (v *Volume) readNeedle(n *needle.Needle, readOption *ReadOption, onReadSize
 	return -1, ErrorNotFound
 }
 
+func (v *Volume) needleCache() *NeedleCache {
+	if v.location == nil {
+		return nil
+	}
+	return v.location.needleCache
+}
+
 // read needle at a specific offset
 func (v *Volume) readNeedleMetaAt(n *needle.Needle, offset int64, size int32) (err error) {
 	v.dataFileAccessLock.RLock()
@@ -66,6 +66,10 @@ func (v *Volume) LoadRemoteFile() error {
 	}
 
 	v.DataBackend = backendStorage.NewStorageFile(tierFile.Key, v.volumeInfo)
+	// a tiered volume is read only, and deletes only update the index
+	v.hasRemoteFile = true
+	v.noWriteCanDelete = true
+	v.noWriteOrDelete = false
 	return nil
 }
 
@@ -35,6 +35,21 @@ func (v *Volume) garbageLevel() float64 {
 	return float64(deletedSize) / float64(fileSize)
 }
 
+// setCompacting updates isCompacting under the data file lock, which is not held during the compaction itself.
+func (v *Volume) setCompacting(compacting bool) {
+	v.dataFileAccessLock.Lock()
+	defer v.dataFileAccessLock.Unlock()
+	v.isCompacting = compacting
+}
+
+// compactionState reads whether the volume is being compacted or committed,
+// and the time of the last write or delete, under the data file lock.
+func (v *Volume) compactionState() (isCompacting bool, lastAppendAtNs uint64) {
+	v.dataFileAccessLock.RLock()
+	defer v.dataFileAccessLock.RUnlock()
+	return v.isCompacting || v.isCommitCompacting, v.lastAppendAtNs
+}
+
 // compact a volume based on deletions in .dat files
 func (v *Volume) Compact(preallocate int64, compactionBytePerSecond int64) error {
 
@@ -46,10 +61,8 @@ func (v *Volume) Compact(preallocate int64, compactionBytePerSecond int64) error
 	//v.accessLock.Lock()
 	//defer v.accessLock.Unlock()
 	//glog.V(3).Infof("Got Compaction lock...")
-	v.isCompacting = true
-	defer func() {
-		v.isCompacting = false
-	}()
+	v.setCompacting(true)
+	defer v.setCompacting(false)
 
 	v.lastCompactIndexOffset = v.IndexFileSize()
 	v.lastCompactRevision = v.SuperBlock.CompactionRevision
@@ -71,10 +84,8 @@ func (v *Volume) Compact2(preallocate int64, compactionBytePerSecond int64, prog
 	}
 	glog.V(3).Infof("Compact2 volume %d ...", v.Id)
 
-	v.isCompacting = true
-	defer func() {
-		v.isCompacting = false
-	}()
+	v.setCompacting(true)
+	defer v.setCompacting(false)
 
 	v.lastCompactIndexOffset = v.IndexFileSize()
 	v.lastCompactRevision = v.SuperBlock.CompactionRevision
@@ -105,15 +116,19 @@ func (v *Volume) CommitCompact() error {
 	}
 	glog.V(0).Infof("Committing volume %d vacuuming...", v.Id)
 
+	v.dataFileAccessLock.Lock()
+	defer v.dataFileAccessLock.Unlock()
+
 	v.isCommitCompacting = true
 	defer func() {
 		v.isCommitCompacting = false
 	}()
 
-	v.dataFileAccessLock.Lock()
-	defer v.dataFileAccessLock.Unlock()
-
 	glog.V(3).Infof("Got volume %d committing lock...", v.Id)
+	if cache := v.needleCache(); cache != nil {
+		// needles move to new offsets
+		cache.deleteVolume(v.Id)
+	}
 	if v.nm != nil {
 		v.nm.Close()
 		v.nm = nil
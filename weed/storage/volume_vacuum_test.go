@@ -188,3 +188,35 @@ func newEmptyNeedle(id uint64) *needle.Needle {
 	n.Id = types.Uint64ToNeedleId(id)
 	return n
 }
+
+func TestFindIdleVolumesToCompact(t *testing.T) {
+	dir := t.TempDir()
+	v, err := NewVolume(dir, dir, "", 1, NeedleMapInMemory, &super_block.ReplicaPlacement{}, &needle.TTL{}, 0, 0, 0)
+	if err != nil {
+		t.Fatalf("volume creation: %v", err)
+	}
+	defer v.Close()
+	for i := 1; i <= 10; i++ {
+		if _, _, _, err = v.writeNeedle2(newRandomNeedle(uint64(i)), true, false); err != nil {
+			t.Fatalf("write file %d: %v", i, err)
+		}
+	}
+	for i := 1; i <= 5; i++ {
+		if _, err = v.deleteNeedle2(newEmptyNeedle(uint64(i))); err != nil {
+			t.Fatalf("delete file %d: %v", i, err)
+		}
+	}
+
+	location := &DiskLocation{volumes: map[needle.VolumeId]*Volume{v.Id: v}}
+	s := &Store{Locations: []*DiskLocation{location}}
+
+	if vids := s.FindIdleVolumesToCompact(0.01, time.Minute); len(vids) != 0 {
+		t.Errorf("recently modified volumes %v should not be compacted", vids)
+	}
+	if vids := s.FindIdleVolumesToCompact(0.01, 0); len(vids) != 1 {
+		t.Errorf("idle volume with garbage %f should be compacted, got %v", v.garbageLevel(), vids)
+	}
+	if vids := s.FindIdleVolumesToCompact(0.99, 0); len(vids) != 0 {
+		t.Errorf("volume with garbage %f should not be compacted, got %v", v.garbageLevel(), vids)
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"github.com/seaweedfs/seaweedfs/weed/glog"
 	"github.com/seaweedfs/seaweedfs/weed/storage/backend"
 	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle_map"
 	. "github.com/seaweedfs/seaweedfs/weed/storage/types"
+	"io"
 	"os"
 )
 
@@ -143,7 +145,26 @@ func (v *Volume) doWriteRequest(n *needle.Needle, checkCookie bool) (offset uint
 	}
 
 	// check whether existing needle cookie matches
-	nv, ok := v.nm.Get(n.Id)
+	nv, ok, err := v.checkExistingCookie(n, checkCookie)
+	if err != nil {
+		return
+	}
+
+	// append to dat file
+	n.UpdateAppendAtNs(v.lastAppendAtNs)
+	offset, size, _, err = n.Append(v.DataBackend, v.Version())
+	v.checkReadWriteError(err)
+	if err != nil {
+		return
+	}
+	v.lastAppendAtNs = n.AppendAtNs
+
+	v.indexAppendedNeedle(n, offset, nv, ok)
+	return
+}
+
+func (v *Volume) checkExistingCookie(n *needle.Needle, checkCookie bool) (nv *needle_map.NeedleValue, ok bool, err error) {
+	nv, ok = v.nm.Get(n.Id)
 	if ok {
 		existingNeedle, _, _, existingNeedleReadErr := needle.ReadNeedleHeader(v.DataBackend, v.Version(), nv.Offset.ToActualOffset())
 		if existingNeedleReadErr != nil {
@@ -162,25 +183,50 @@ func (v *Volume) doWriteRequest(n *needle.Needle, checkCookie bool) (offset uint
 			return
 		}
 	}
+	return
+}
 
-	// append to dat file
-	n.UpdateAppendAtNs(v.lastAppendAtNs)
-	offset, size, _, err = n.Append(v.DataBackend, v.Version())
-	v.checkReadWriteError(err)
-	if err != nil {
-		return
-	}
-	v.lastAppendAtNs = n.AppendAtNs
-
-	// add to needle map
+// indexAppendedNeedle adds the needle appended at the offset to the needle map, unless nv is a later needle with the same id
+func (v *Volume) indexAppendedNeedle(n *needle.Needle, offset uint64, nv *needle_map.NeedleValue, ok bool) {
 	if !ok || uint64(nv.Offset.ToActualOffset()) < offset {
-		if err = v.nm.Put(n.Id, ToOffset(int64(offset)), n.Size); err != nil {
+		if err := v.nm.Put(n.Id, ToOffset(int64(offset)), n.Size); err != nil {
 			glog.V(4).Infof("failed to save in needle map %d: %v", n.Id, err)
 		}
 	}
 	if v.lastModifiedTsSeconds < n.LastModified {
 		v.lastModifiedTsSeconds = n.LastModified
 	}
+}
+
+// writeNeedleFrom writes the needle with its dataSize bytes of data copied from data, instead of n.Data
+func (v *Volume) writeNeedleFrom(n *needle.Needle, data io.Reader, dataSize int64, fsync bool) (offset uint64, size Size, err error) {
+	if n.Ttl == needle.EMPTY_TTL && v.Ttl != needle.EMPTY_TTL {
+		n.SetHasTtl()
+		n.Ttl = v.Ttl
+	}
+
+	v.dataFileAccessLock.Lock()
+	defer v.dataFileAccessLock.Unlock()
+
+	nv, ok, err := v.checkExistingCookie(n, true)
+	if err != nil {
+		return
+	}
+
+	n.UpdateAppendAtNs(v.lastAppendAtNs)
+	offset, size, _, err = n.AppendFrom(v.DataBackend, v.Version(), data, dataSize)
+	v.checkReadWriteError(err)
+	if err != nil {
+		return
+	}
+	if fsync {
+		if err = v.DataBackend.Sync(); err != nil {
+			return
+		}
+	}
+	v.lastAppendAtNs = n.AppendAtNs
+
+	v.indexAppendedNeedle(n, offset, nv, ok)
 	return
 }
 
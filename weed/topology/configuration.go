@@ -2,6 +2,12 @@ package topology
 
 import (
 	"encoding/xml"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/spf13/viper"
 )
 
 type loc struct {
@@ -22,6 +28,73 @@ type topology struct {
 type Configuration struct {
 	XMLName xml.Name `xml:"Configuration"`
 	Topo    topology `xml:"Topology"`
+
+	// volume server host or host:port => location, loaded from topology.toml
+	serverLocations     map[string]loc
+	serverLocationsLock sync.RWMutex
+}
+
+type tomlRack struct {
+	Name    string   `mapstructure:"name"`
+	Servers []string `mapstructure:"servers"`
+}
+type tomlDataCenter struct {
+	Name  string     `mapstructure:"name"`
+	Racks []tomlRack `mapstructure:"rack"`
+}
+
+// LoadTopologyToml reads the data center and rack of each volume server, e.g.
+//
+//	[[data_center]]
+//	name = "dc1"
+//	  [[data_center.rack]]
+//	  name = "rack1"
+//	  servers = ["10.0.0.1", "volume-a.example.com:8080"]
+//
+// The locations replace the ones loaded before, and take precedence over the locations reported by the volume servers.
+func (c *Configuration) LoadTopologyToml(fileName string) error {
+	v := viper.New()
+	v.SetConfigFile(fileName)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("read %s: %v", fileName, err)
+	}
+	var dataCenters []tomlDataCenter
+	if err := v.UnmarshalKey("data_center", &dataCenters); err != nil {
+		return fmt.Errorf("parse %s: %v", fileName, err)
+	}
+
+	serverLocations := make(map[string]loc)
+	for _, dc := range dataCenters {
+		if dc.Name == "" {
+			return fmt.Errorf("data center without name in %s", fileName)
+		}
+		for _, r := range dc.Racks {
+			if r.Name == "" {
+				return fmt.Errorf("rack without name in data center %s", dc.Name)
+			}
+			for _, server := range r.Servers {
+				if existing, found := serverLocations[server]; found {
+					return fmt.Errorf("%s is in both %s/%s and %s/%s", server, existing.dcName, existing.rackName, dc.Name, r.Name)
+				}
+				serverLocations[server] = loc{dcName: dc.Name, rackName: r.Name}
+			}
+		}
+	}
+
+	c.serverLocationsLock.Lock()
+	c.serverLocations = serverLocations
+	c.serverLocationsLock.Unlock()
+	return nil
+}
+
+func (c *Configuration) lookupServer(ip string, port int) (l loc, found bool) {
+	c.serverLocationsLock.RLock()
+	defer c.serverLocationsLock.RUnlock()
+	if l, found = c.serverLocations[net.JoinHostPort(ip, strconv.Itoa(port))]; found {
+		return
+	}
+	l, found = c.serverLocations[ip]
+	return
 }
 
 func (c *Configuration) String() string {
@@ -31,7 +104,11 @@ func (c *Configuration) String() string {
 	return ""
 }
 
-func (c *Configuration) Locate(ip string, dcName string, rackName string) (dc string, rack string) {
+func (c *Configuration) Locate(ip string, port int, dcName string, rackName string) (dc string, rack string) {
+	if l, found := c.lookupServer(ip, port); found {
+		return l.dcName, l.rackName
+	}
+
 	if dcName == "" {
 		dcName = "DefaultDataCenter"
 	}
@@ -0,0 +1,85 @@
+package topology
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/sequence"
+)
+
+const testTopologyToml = `
+[[data_center]]
+name = "DC1"
+  [[data_center.rack]]
+  name = "RackA"
+  servers = ["10.0.0.1", "10.0.0.2:8081"]
+[[data_center]]
+name = "DC2"
+  [[data_center.rack]]
+  name = "RackB"
+  servers = ["10.0.0.3"]
+`
+
+func loadTestTopologyToml(t *testing.T, c *Configuration) {
+	fileName := filepath.Join(t.TempDir(), "topology.toml")
+	if err := os.WriteFile(fileName, []byte(testTopologyToml), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.LoadTopologyToml(fileName); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigurationLocate(t *testing.T) {
+	c := &Configuration{}
+	loadTestTopologyToml(t, c)
+
+	tests := []struct {
+		ip, reportedDc, reportedRack string
+		port                         int
+		dc, rack                     string
+	}{
+		{"10.0.0.1", "reported", "reported", 8080, "DC1", "RackA"},
+		{"10.0.0.2", "", "", 8081, "DC1", "RackA"},
+		{"10.0.0.2", "", "", 8080, "DefaultDataCenter", "DefaultRack"},
+		{"10.0.0.9", "dc9", "rack9", 8080, "dc9", "rack9"},
+	}
+	for _, tt := range tests {
+		dc, rack := c.Locate(tt.ip, tt.port, tt.reportedDc, tt.reportedRack)
+		if dc != tt.dc || rack != tt.rack {
+			t.Errorf("locate %s:%d: got %s/%s, expected %s/%s", tt.ip, tt.port, dc, rack, tt.dc, tt.rack)
+		}
+	}
+}
+
+func TestRelocateDataNodes(t *testing.T) {
+	topo := NewTopology("weedfs", sequence.NewMemorySequencer(), 32*1024, 5, false)
+	rack := topo.GetOrCreateDataCenter("dc1").GetOrCreateRack("rack1")
+	dn := rack.GetOrCreateDataNode("10.0.0.3", 8080, 0, "10.0.0.3", map[string]uint32{"": 10})
+	rack.GetOrCreateDataNode("10.0.0.4", 8080, 0, "10.0.0.4", map[string]uint32{"": 10})
+
+	loadTestTopologyToml(t, topo.Configuration)
+	topo.RelocateDataNodes()
+
+	if dn.GetDataCenterId() != "DC2" || dn.GetRack().Id() != "RackB" {
+		t.Errorf("volume server at %s/%s, expected DC2/RackB", dn.GetDataCenterId(), dn.GetRack().Id())
+	}
+	assert(t, "nodes left in rack1", len(rack.Children()), 1)
+
+	// removed from the topology.toml, back to the reported rack
+	dn.SetReportedLocation("dc1", "rack1")
+	fileName := filepath.Join(t.TempDir(), "topology.toml")
+	if err := os.WriteFile(fileName, []byte("data_center = []\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := topo.Configuration.LoadTopologyToml(fileName); err != nil {
+		t.Fatal(err)
+	}
+	topo.RelocateDataNodes()
+
+	if dn.GetDataCenterId() != "dc1" || dn.GetRack() != rack {
+		t.Errorf("volume server at %s/%s, expected dc1/rack1", dn.GetDataCenterId(), dn.GetRack().Id())
+	}
+	assert(t, "nodes left in rack1", len(rack.Children()), 2)
+}
@@ -21,6 +21,9 @@ type DataNode struct {
 	LastSeen      int64 // unix time in seconds
 	Counter       int   // in race condition, the previous dataNode was not dead
 	IsTerminating bool
+	tags          atomic.Pointer[map[string]string] // from the heartbeat
+	reported      atomic.Pointer[loc]               // the data center and rack from the heartbeat
+	maxVolumeSize uint64                            // optional cap of each volume on this server, from the heartbeat
 }
 
 func NewDataNode(id string) *DataNode {
@@ -219,6 +222,57 @@ func (dn *DataNode) MatchLocation(ip string, port int) bool {
 	return dn.Ip == ip && dn.Port == port
 }
 
+func (dn *DataNode) SetMaxVolumeSize(maxVolumeSize uint64) {
+	atomic.StoreUint64(&dn.maxVolumeSize, maxVolumeSize)
+}
+
+// volumeSizeLimit returns the cap of the volume server if it is below the size limit of the master.
+func (dn *DataNode) volumeSizeLimit(masterVolumeSizeLimit uint64) uint64 {
+	if maxVolumeSize := atomic.LoadUint64(&dn.maxVolumeSize); maxVolumeSize > 0 && maxVolumeSize < masterVolumeSizeLimit {
+		return maxVolumeSize
+	}
+	return masterVolumeSizeLimit
+}
+
+func (dn *DataNode) SetTags(tags map[string]string) {
+	dn.tags.Store(&tags)
+}
+
+func (dn *DataNode) GetTags() map[string]string {
+	if tags := dn.tags.Load(); tags != nil {
+		return *tags
+	}
+	return nil
+}
+
+// SetReportedLocation keeps the data center and rack reported by the volume server,
+// to move it back there when it is removed from the topology.toml.
+func (dn *DataNode) SetReportedLocation(dcName, rackName string) {
+	dn.reported.Store(&loc{dcName: dcName, rackName: rackName})
+}
+
+func (dn *DataNode) GetReportedLocation() (dcName, rackName string, found bool) {
+	if l := dn.reported.Load(); l != nil {
+		return l.dcName, l.rackName, true
+	}
+	return "", "", false
+}
+
+// MatchTags checks whether the data node has all the tags.
+// The tagged data nodes are kept for the tagged writes, and only match when tags are requested.
+func (dn *DataNode) MatchTags(tags map[string]string) bool {
+	dnTags := dn.GetTags()
+	if len(tags) == 0 {
+		return len(dnTags) == 0
+	}
+	for key, value := range tags {
+		if v, found := dnTags[key]; !found || v != value {
+			return false
+		}
+	}
+	return true
+}
+
 func (dn *DataNode) Url() string {
 	return util.JoinHostPort(dn.Ip, dn.Port)
 }
@@ -228,17 +282,19 @@ func (dn *DataNode) ServerAddress() pb.ServerAddress {
 }
 
 type DataNodeInfo struct {
-	Url       string `json:"Url"`
-	PublicUrl string `json:"PublicUrl"`
-	Volumes   int64  `json:"Volumes"`
-	EcShards  int64  `json:"EcShards"`
-	Max       int64  `json:"Max"`
-	VolumeIds string `json:"VolumeIds"`
+	Url       string            `json:"Url"`
+	PublicUrl string            `json:"PublicUrl"`
+	Volumes   int64             `json:"Volumes"`
+	EcShards  int64             `json:"EcShards"`
+	Max       int64             `json:"Max"`
+	VolumeIds string            `json:"VolumeIds"`
+	Tags      map[string]string `json:"Tags,omitempty"`
 }
 
 func (dn *DataNode) ToInfo() (info DataNodeInfo) {
 	info.Url = dn.Url()
 	info.PublicUrl = dn.PublicUrl
+	info.Tags = dn.GetTags()
 
 	// aggregated volume info
 	var volumeCount, ecShardCount, maxVolumeCount int64
@@ -0,0 +1,17 @@
+package topology
+
+import (
+	"github.com/seaweedfs/seaweedfs/weed/pb"
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
+)
+
+// LeaderElection elects the master leader with an external service instead of raft,
+// which also keeps the max volume id.
+type LeaderElection interface {
+	IsLeader() bool
+	// Leader returns empty if no leader is elected yet
+	Leader() pb.ServerAddress
+	// SaveMaxVolumeId fails if the current master is not the leader
+	SaveMaxVolumeId(vid needle.VolumeId) error
+	Close()
+}
@@ -0,0 +1,52 @@
+package topology
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb"
+	"github.com/seaweedfs/seaweedfs/weed/sequence"
+	"github.com/seaweedfs/seaweedfs/weed/storage/needle"
+)
+
+type fakeLeaderElection struct {
+	leader      pb.ServerAddress
+	isLeader    bool
+	maxVolumeId needle.VolumeId
+}
+
+func (e *fakeLeaderElection) IsLeader() bool           { return e.isLeader }
+func (e *fakeLeaderElection) Leader() pb.ServerAddress { return e.leader }
+func (e *fakeLeaderElection) Close()                   {}
+func (e *fakeLeaderElection) SaveMaxVolumeId(vid needle.VolumeId) error {
+	if !e.isLeader {
+		return fmt.Errorf("not the leader")
+	}
+	e.maxVolumeId = vid
+	return nil
+}
+
+func TestLeaderElection(t *testing.T) {
+	topo := NewTopology("weedfs", sequence.NewMemorySequencer(), 32*1024, 5, false)
+	election := &fakeLeaderElection{leader: "master1:9333"}
+	topo.LeaderElection = election
+
+	if topo.IsLeader() {
+		t.Errorf("should not be the leader")
+	}
+	if leader, err := topo.MaybeLeader(); err != nil || leader != "master1:9333" {
+		t.Errorf("leader %s: %v", leader, err)
+	}
+	if _, err := topo.NextVolumeId(); err == nil {
+		t.Errorf("followers should not assign volume ids")
+	}
+
+	election.isLeader = true
+	vid, err := topo.NextVolumeId()
+	if err != nil {
+		t.Fatalf("next volume id: %v", err)
+	}
+	if vid != 1 || election.maxVolumeId != 1 || topo.GetMaxVolumeId() != 1 {
+		t.Errorf("volume id %d, saved %d, max %d", vid, election.maxVolumeId, topo.GetMaxVolumeId())
+	}
+}
@@ -67,7 +67,7 @@ func (n *NodeImpl) PickNodesByWeight(numberOfNodes int, option *VolumeGrowOption
 	candidatesWeights := make([]int64, 0, len(n.children))
 	//pick nodes which has enough free volumes as candidates, and use free volumes number as node weight.
 	for _, node := range n.children {
-		if node.AvailableSpaceFor(option) <= 0 {
+		if node.AvailableSpaceFor(option) <= 0 || !option.matchTags(node) {
 			continue
 		}
 		totalWeights += node.AvailableSpaceFor(option)
@@ -200,7 +200,7 @@ func (n *NodeImpl) ReserveOneVolume(r int64, option *VolumeGrowOption) (assigned
 			if node.IsDataNode() && node.AvailableSpaceFor(option) > 0 {
 				// fmt.Println("vid =", vid, " assigned to node =", node, ", freeSpace =", node.FreeSpace())
 				dn := node.(*DataNode)
-				if dn.IsTerminating {
+				if dn.IsTerminating || !option.matchTags(dn) {
 					continue
 				}
 				return dn, nil
@@ -267,12 +267,13 @@ func (n *NodeImpl) CollectDeadNodeAndFullVolumes(freshThreshHold int64, volumeSi
 	if n.IsRack() {
 		for _, c := range n.Children() {
 			dn := c.(*DataNode) //can not cast n to DataNode
+			dnVolumeSizeLimit := dn.volumeSizeLimit(volumeSizeLimit)
 			for _, v := range dn.GetVolumes() {
 				topo := n.GetTopology()
 				diskType := types.ToDiskType(v.DiskType)
 				vl := topo.GetVolumeLayout(v.Collection, v.ReplicaPlacement, v.Ttl, diskType)
 
-				if v.Size >= volumeSizeLimit {
+				if v.Size >= dnVolumeSizeLimit {
 					vl.accessLock.RLock()
 					vacuumTime, ok := vl.vacuumedVolumes[v.Id]
 					vl.accessLock.RUnlock()
@@ -284,7 +285,7 @@ func (n *NodeImpl) CollectDeadNodeAndFullVolumes(freshThreshHold int64, volumeSi
 						//fmt.Println("volume",v.Id,"size",v.Size,">",volumeSizeLimit)
 						topo.chanFullVolumes <- v
 					}
-				} else if float64(v.Size) > float64(volumeSizeLimit)*growThreshold {
+				} else if float64(v.Size) > float64(dnVolumeSizeLimit)*growThreshold {
 					topo.chanCrowdedVolumes <- v
 				}
 				copyCount := v.ReplicaPlacement.GetCopyCount()
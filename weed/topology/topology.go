@@ -45,11 +45,15 @@ type Topology struct {
 	chanFullVolumes    chan storage.VolumeInfo
 	chanCrowdedVolumes chan storage.VolumeInfo
 
+	// OnVolumeCapacityFull is notified for each location of a volume that just became full
+	OnVolumeCapacityFull func(volumeInfo storage.VolumeInfo, dn *DataNode)
+
 	Configuration *Configuration
 
 	RaftServer           raft.Server
 	RaftServerAccessLock sync.RWMutex
 	HashicorpRaft        *hashicorpRaft.Raft
+	LeaderElection       LeaderElection
 	UuidAccessLock       sync.RWMutex
 	UuidMap              map[string][]string
 }
@@ -116,6 +120,8 @@ func (t *Topology) IsLeader() bool {
 		if t.HashicorpRaft.State() == hashicorpRaft.Leader {
 			return true
 		}
+	} else if t.LeaderElection != nil {
+		return t.LeaderElection.IsLeader()
 	}
 	return false
 }
@@ -148,6 +154,8 @@ func (t *Topology) MaybeLeader() (l pb.ServerAddress, err error) {
 		l = pb.ServerAddress(t.RaftServer.Leader())
 	} else if t.HashicorpRaft != nil {
 		l = pb.ServerAddress(t.HashicorpRaft.Leader())
+	} else if t.LeaderElection != nil {
+		l = t.LeaderElection.Leader()
 	} else {
 		err = errors.New("Raft Server not ready yet!")
 	}
@@ -198,6 +206,11 @@ func (t *Topology) NextVolumeId() (needle.VolumeId, error) {
 		if future := t.HashicorpRaft.Apply(b, time.Second); future.Error() != nil {
 			return 0, future.Error()
 		}
+	} else if t.LeaderElection != nil {
+		if err := t.LeaderElection.SaveMaxVolumeId(next); err != nil {
+			return 0, err
+		}
+		t.UpAdjustMaxVolumeId(next)
 	}
 	return next, nil
 }
@@ -304,6 +317,10 @@ func (t *Topology) GetDataCenter(dcName string) (dc *DataCenter) {
 func (t *Topology) GetOrCreateDataCenter(dcName string) *DataCenter {
 	t.Lock()
 	defer t.Unlock()
+	return t.doGetOrCreateDataCenter(dcName)
+}
+
+func (t *Topology) doGetOrCreateDataCenter(dcName string) *DataCenter {
 	for _, c := range t.children {
 		dc := c.(*DataCenter)
 		if string(dc.Id()) == dcName {
@@ -373,16 +390,60 @@ func (t *Topology) IncrementalSyncDataNodeRegistration(newVolumes, deletedVolume
 }
 
 func (t *Topology) DataNodeRegistration(dcName, rackName string, dn *DataNode) {
+	// the topology lock keeps the data nodes from being linked while they are relocated
+	t.Lock()
+	defer t.Unlock()
 	if dn.Parent() != nil {
 		return
 	}
 	// registration to topo
-	dc := t.GetOrCreateDataCenter(dcName)
+	dc := t.doGetOrCreateDataCenter(dcName)
 	rack := dc.GetOrCreateRack(rackName)
 	rack.LinkChildNode(dn)
 	glog.Infof("[%s] reLink To topo  ", dn.Id())
 }
 
+// RelocateDataNodes moves the data nodes whose configured data center or rack has changed.
+// The data nodes removed from the configuration move back to the data center and rack they reported.
+func (t *Topology) RelocateDataNodes() {
+	t.Lock()
+	defer t.Unlock()
+
+	type placement struct {
+		dn   *DataNode
+		dc   string
+		rack *Rack
+	}
+	var placements []placement
+	for _, c := range t.children {
+		dc := c.(*DataCenter)
+		for _, r := range dc.Children() {
+			rack := r.(*Rack)
+			for _, n := range rack.Children() {
+				placements = append(placements, placement{dn: n.(*DataNode), dc: string(dc.Id()), rack: rack})
+			}
+		}
+	}
+
+	for _, p := range placements {
+		reportedDc, reportedRack, found := p.dn.GetReportedLocation()
+		if !found {
+			reportedDc, reportedRack = p.dc, string(p.rack.Id())
+		}
+		dcName, rackName := t.Configuration.Locate(p.dn.Ip, p.dn.Port, reportedDc, reportedRack)
+		if dcName == p.dc && rackName == string(p.rack.Id()) {
+			continue
+		}
+		if p.dn.Parent() == nil {
+			// unregistered since
+			continue
+		}
+		p.rack.UnlinkChildNode(p.dn.Id())
+		t.doGetOrCreateDataCenter(dcName).GetOrCreateRack(rackName).LinkChildNode(p.dn)
+		glog.V(0).Infof("moved volume server %s from %s/%s to %s/%s", p.dn.Id(), p.dc, p.rack.Id(), dcName, rackName)
+	}
+}
+
 func (t *Topology) DisableVacuum() {
 	glog.V(0).Infof("DisableVacuum")
 	t.isDisableVacuum = true
@@ -392,3 +453,7 @@ func (t *Topology) EnableVacuum() {
 	glog.V(0).Infof("EnableVacuum")
 	t.isDisableVacuum = false
 }
+
+func (t *Topology) IsVacuumDisabled() bool {
+	return t.isDisableVacuum
+}
@@ -71,6 +71,9 @@ func (t *Topology) SetVolumeCapacityFull(volumeInfo storage.VolumeInfo) bool {
 			disk.UpAdjustDiskUsageDelta(deltaDiskUsages)
 
 		}
+		if t.OnVolumeCapacityFull != nil {
+			t.OnVolumeCapacityFull(volumeInfo, dn)
+		}
 	}
 	return true
 }
@@ -208,3 +208,51 @@ func TestAddRemoveVolume(t *testing.T) {
 	}
 
 }
+
+func TestVolumeOverMaxVolumeSizeOfDataNode(t *testing.T) {
+
+	topo := NewTopology("weedfs", sequence.NewMemorySequencer(), 32*1024, 5, false)
+
+	dc := topo.GetOrCreateDataCenter("dc1")
+	rack := dc.GetOrCreateRack("rack1")
+	maxVolumeCounts := make(map[string]uint32)
+	maxVolumeCounts[""] = 25
+	dn := rack.GetOrCreateDataNode("127.0.0.1", 34534, 0, "127.0.0.1", maxVolumeCounts)
+	dn.SetMaxVolumeSize(1024)
+
+	sync := func(size uint64) {
+		topo.SyncDataNodeRegistration([]*master_pb.VolumeInformationMessage{{
+			Id:      1,
+			Size:    size,
+			Version: uint32(needle.CurrentVersion),
+		}}, dn)
+	}
+	collectFullVolumes := func() {
+		done := make(chan struct{})
+		go func() {
+			rack.CollectDeadNodeAndFullVolumes(0, topo.volumeSizeLimit, 0.9)
+			close(done)
+		}()
+		for {
+			select {
+			case v := <-topo.chanFullVolumes:
+				topo.SetVolumeCapacityFull(v)
+			case <-topo.chanCrowdedVolumes:
+			case <-done:
+				return
+			}
+		}
+	}
+	vl := topo.GetVolumeLayout("", &super_block.ReplicaPlacement{}, needle.EMPTY_TTL, types.HardDriveType)
+
+	sync(1000)
+	collectFullVolumes()
+	assert(t, "writable under the max volume size", len(vl.writables), 1)
+
+	sync(1024)
+	collectFullVolumes()
+	assert(t, "writable over the max volume size", len(vl.writables), 0)
+	if vl.readonlyVolumes.IsTrue(1) {
+		t.Errorf("volume over the max volume size should not be read only, so that it is still vacuumed")
+	}
+}
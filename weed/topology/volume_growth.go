@@ -58,6 +58,7 @@ type VolumeGrowOption struct {
 	Rack               string                        `json:"rack,omitempty"`
 	DataNode           string                        `json:"dataNode,omitempty"`
 	MemoryMapMaxSizeMb uint32                        `json:"memoryMapMaxSizeMb,omitempty"`
+	Tags               map[string]string             `json:"tags,omitempty"`
 }
 
 type VolumeGrowth struct {
@@ -69,6 +70,14 @@ func (o *VolumeGrowOption) String() string {
 	return string(blob)
 }
 
+// matchTags checks the tags of data nodes, other nodes always match
+func (o *VolumeGrowOption) matchTags(node Node) bool {
+	if !node.IsDataNode() {
+		return true
+	}
+	return node.(*DataNode).MatchTags(o.Tags)
+}
+
 func NewDefaultVolumeGrowth() *VolumeGrowth {
 	return &VolumeGrowth{}
 }
@@ -159,7 +168,7 @@ func (vg *VolumeGrowth) findEmptySlotsForOneVolume(topo *Topology, option *Volum
 		for _, rack := range node.Children() {
 			possibleDataNodesCount := 0
 			for _, n := range rack.Children() {
-				if n.AvailableSpaceFor(option) >= 1 {
+				if n.AvailableSpaceFor(option) >= 1 && option.matchTags(n) {
 					possibleDataNodesCount++
 				}
 			}
@@ -190,7 +199,7 @@ func (vg *VolumeGrowth) findEmptySlotsForOneVolume(topo *Topology, option *Volum
 		}
 		possibleDataNodesCount := 0
 		for _, n := range node.Children() {
-			if n.AvailableSpaceFor(option) >= 1 {
+			if n.AvailableSpaceFor(option) >= 1 && option.matchTags(n) {
 				possibleDataNodesCount++
 			}
 		}
@@ -98,6 +98,13 @@ func setup(topologyLayout string) *Topology {
 				if ip, ok := serverMap["ip"]; ok {
 					server.Ip = ip.(string)
 				}
+				if tags, ok := serverMap["tags"]; ok {
+					serverTags := make(map[string]string)
+					for k, v := range tags.(map[string]interface{}) {
+						serverTags[k] = v.(string)
+					}
+					server.SetTags(serverTags)
+				}
 				dcRack.LinkChildNode(server)
 				for _, v := range serverMap["volumes"].([]interface{}) {
 					m := v.(map[string]interface{})
@@ -457,3 +464,107 @@ func TestPickForWrite(t *testing.T) {
 		}
 	}
 }
+
+var topologyLayout5 = `
+{
+  "dc1":{
+    "rack1":{
+      "server111":{
+        "ip": "127.0.0.1",
+        "volumes":[
+          {"id":1, "size":12312, "collection":"test", "replication":"001"},
+          {"id":2, "size":12312, "collection":"test", "replication":"001"}
+        ],
+        "tags":{"tier":"hot"},
+        "limit":10
+      },
+      "server112":{
+        "ip": "127.0.0.2",
+        "volumes":[
+          {"id":1, "size":12312, "collection":"test", "replication":"001"},
+          {"id":3, "size":12312, "collection":"test", "replication":"001"}
+        ],
+        "tags":{"tier":"hot"},
+        "limit":10
+      },
+      "server113":{
+        "ip": "127.0.0.3",
+        "volumes":[
+          {"id":2, "size":12312, "collection":"test", "replication":"001"},
+          {"id":3, "size":12312, "collection":"test", "replication":"001"}
+        ],
+        "limit":10
+      }
+    }
+  }
+}
+`
+
+func TestPickForWriteWithTags(t *testing.T) {
+	topo := setup(topologyLayout5)
+	rp, _ := super_block.NewReplicaPlacementFromString("001")
+	vl := topo.GetVolumeLayout("test", rp, needle.EMPTY_TTL, types.HardDriveType)
+	option := &VolumeGrowOption{
+		Collection:       "test",
+		ReplicaPlacement: rp,
+		Tags:             map[string]string{"tier": "hot"},
+	}
+	for i := 0; i < 10; i++ {
+		fileId, _, _, _, err := topo.PickForWrite(1, option, vl)
+		if err != nil {
+			t.Fatalf("pick for write: %v", err)
+		}
+		if fid, _ := needle.ParseFileIdFromString(fileId); fid.VolumeId != 1 {
+			t.Fatalf("picked %s, only volume 1 has all replicas tagged", fileId)
+		}
+	}
+	if _, active, _ := vl.GetActiveVolumeCount(option); active != 2 {
+		t.Errorf("active tagged volume replicas %d, expected 2", active)
+	}
+
+	option.Tags = map[string]string{"tier": "cold"}
+	if _, _, _, shouldGrow, err := topo.PickForWrite(1, option, vl); err == nil || !shouldGrow {
+		t.Errorf("no volume is tagged cold, should grow")
+	}
+
+	vg := NewDefaultVolumeGrowth()
+	option.Tags = map[string]string{"tier": "hot"}
+	for i := 0; i < 10; i++ {
+		servers, err := vg.findEmptySlotsForOneVolume(topo, option)
+		if err != nil {
+			t.Fatalf("finding empty slots: %v", err)
+		}
+		for _, server := range servers {
+			if !server.MatchTags(option.Tags) {
+				t.Fatalf("assigned to untagged node %s", server.Id())
+			}
+		}
+	}
+
+	// untagged writes do not go to the tagged data nodes
+	option.Tags = nil
+	if _, _, _, shouldGrow, err := topo.PickForWrite(1, option, vl); err == nil || !shouldGrow {
+		t.Errorf("every volume has a tagged replica, should grow")
+	}
+	if _, active, _ := vl.GetActiveVolumeCount(option); active != 0 {
+		t.Errorf("active untagged volumes %d, expected 0", active)
+	}
+	if servers, err := vg.findEmptySlotsForOneVolume(topo, option); err == nil {
+		t.Errorf("only one untagged node, but assigned to %v", servers)
+	}
+
+	// the tags are updated by the heartbeats
+	topo.GetOrCreateDataCenter("dc1").GetOrCreateRack("rack1").GetOrCreateDataNode("127.0.0.2", 0, 0, "", nil).SetTags(nil)
+	for i := 0; i < 10; i++ {
+		fileId, _, _, _, err := topo.PickForWrite(1, option, vl)
+		if err != nil {
+			t.Fatalf("pick for write: %v", err)
+		}
+		if fid, _ := needle.ParseFileIdFromString(fileId); fid.VolumeId != 3 {
+			t.Fatalf("picked %s, only volume 3 has no tagged replica", fileId)
+		}
+	}
+	if _, active, _ := vl.GetActiveVolumeCount(option); active != 1 {
+		t.Errorf("active untagged volumes %d, expected 1", active)
+	}
+}
@@ -179,7 +179,7 @@ func (vl *VolumeLayout) RegisterVolume(v *storage.VolumeInfo, dn *DataNode) {
 }
 
 func (vl *VolumeLayout) rememberOversizedVolume(v *storage.VolumeInfo, dn *DataNode) {
-	if vl.isOversized(v) {
+	if vl.isOversized(v) || uint64(v.Size) >= dn.volumeSizeLimit(vl.volumeSizeLimit) {
 		vl.oversizedVolumes.Add(v.Id, dn)
 	} else {
 		vl.oversizedVolumes.Remove(v.Id, dn)
@@ -296,10 +296,14 @@ func (vl *VolumeLayout) PickForWrite(count uint64, option *VolumeGrowOption) (vi
 		shouldGrow = true
 		return 0, 0, nil, shouldGrow, errors.New("No more writable volumes!")
 	}
-	if option.DataCenter == "" && option.Rack == "" && option.DataNode == "" {
+	if option.DataCenter == "" && option.Rack == "" && option.DataNode == "" && len(option.Tags) == 0 {
 		vid := vl.writables[rand.Intn(lenWriters)]
 		locationList = vl.vid2location[vid]
-		if locationList != nil && locationList.Length() > 0 {
+		if locationList == nil || locationList.Length() == 0 {
+			return 0, 0, nil, shouldGrow, errors.New("Strangely vid " + vid.String() + " is on no machine!")
+		}
+		// a volume on tagged data nodes is skipped, and an untagged one is picked below
+		if locationList.MatchTags(nil) {
 			// check whether picked file is close to full
 			dn := locationList.Head()
 			info, _ := dn.GetVolumesById(vid)
@@ -308,7 +312,6 @@ func (vl *VolumeLayout) PickForWrite(count uint64, option *VolumeGrowOption) (vi
 			}
 			return vid, count, locationList.Copy(), shouldGrow, nil
 		}
-		return 0, 0, nil, shouldGrow, errors.New("Strangely vid " + vid.String() + " is on no machine!")
 	}
 
 	// clone vl.writables
@@ -321,6 +324,10 @@ func (vl *VolumeLayout) PickForWrite(count uint64, option *VolumeGrowOption) (vi
 
 	for _, writableVolumeId := range writables {
 		volumeLocationList := vl.vid2location[writableVolumeId]
+		// tagged writes only go to volumes with all replicas tagged, and untagged writes to untagged ones
+		if !volumeLocationList.MatchTags(option.Tags) {
+			continue
+		}
 		for _, dn := range volumeLocationList.list {
 			if option.DataCenter != "" && dn.GetDataCenter().Id() != NodeId(option.DataCenter) {
 				continue
@@ -341,7 +348,7 @@ func (vl *VolumeLayout) PickForWrite(count uint64, option *VolumeGrowOption) (vi
 			return
 		}
 	}
-	return vid, count, locationList, true, fmt.Errorf("No writable volumes in DataCenter:%v Rack:%v DataNode:%v", option.DataCenter, option.Rack, option.DataNode)
+	return vid, count, locationList, true, fmt.Errorf("No writable volumes in DataCenter:%v Rack:%v DataNode:%v Tags:%v", option.DataCenter, option.Rack, option.DataNode, option.Tags)
 }
 
 func (vl *VolumeLayout) HasGrowRequest() bool {
@@ -366,24 +373,38 @@ func (vl *VolumeLayout) ShouldGrowVolumes(option *VolumeGrowOption) bool {
 func (vl *VolumeLayout) GetActiveVolumeCount(option *VolumeGrowOption) (total, active, crowded int) {
 	vl.accessLock.RLock()
 	defer vl.accessLock.RUnlock()
-	if option.DataCenter == "" {
-		return len(vl.writables), len(vl.writables), len(vl.crowded)
+	if option.DataCenter == "" && len(option.Tags) == 0 {
+		// the volumes on tagged data nodes are not active for untagged writes
+		for _, v := range vl.writables {
+			if locationList := vl.vid2location[v]; locationList == nil || !locationList.MatchTags(nil) {
+				continue
+			}
+			active++
+			if _, found := vl.crowded[v]; found {
+				crowded++
+			}
+		}
+		return len(vl.writables), active, crowded
 	}
 	total = len(vl.writables)
 	for _, v := range vl.writables {
+		if !vl.vid2location[v].MatchTags(option.Tags) {
+			continue
+		}
 		for _, dn := range vl.vid2location[v].list {
-			if dn.GetDataCenter().Id() == NodeId(option.DataCenter) {
-				if option.Rack != "" && dn.GetRack().Id() != NodeId(option.Rack) {
-					continue
-				}
-				if option.DataNode != "" && dn.Id() != NodeId(option.DataNode) {
-					continue
-				}
-				active++
-				info, _ := dn.GetVolumesById(v)
-				if float64(info.Size) > float64(vl.volumeSizeLimit)*VolumeGrowStrategy.Threshold {
-					crowded++
-				}
+			if option.DataCenter != "" && dn.GetDataCenter().Id() != NodeId(option.DataCenter) {
+				continue
+			}
+			if option.Rack != "" && dn.GetRack().Id() != NodeId(option.Rack) {
+				continue
+			}
+			if option.DataNode != "" && dn.Id() != NodeId(option.DataNode) {
+				continue
+			}
+			active++
+			info, _ := dn.GetVolumesById(v)
+			if float64(info.Size) > float64(vl.volumeSizeLimit)*VolumeGrowStrategy.Threshold {
+				crowded++
 			}
 		}
 	}
@@ -46,6 +46,16 @@ func (dnll *VolumeLocationList) Length() int {
 	return len(dnll.list)
 }
 
+// MatchTags checks whether all the locations have the tags
+func (dnll *VolumeLocationList) MatchTags(tags map[string]string) bool {
+	for _, dnl := range dnll.list {
+		if !dnl.MatchTags(tags) {
+			return false
+		}
+	}
+	return true
+}
+
 func (dnll *VolumeLocationList) Set(loc *DataNode) {
 	for i := 0; i < len(dnll.list); i++ {
 		if loc.Ip == dnll.list[i].Ip && loc.Port == dnll.list[i].Port {
@@ -0,0 +1,216 @@
+package chunk_cache
+
+import (
+	"container/list"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/seaweedfs/seaweedfs/weed/glog"
+)
+
+type flatFileCacheItem struct {
+	key  string
+	size int64
+}
+
+// FlatFileChunkCache keeps each chunk in its own file, named by the hash of the file id,
+// and evicts the least recently accessed chunks when over the capacity.
+// The access time is kept as the file modification time, to restore the order after restarts.
+type FlatFileChunkCache struct {
+	sync.Mutex
+	dir          string
+	capacity     int64
+	maxChunkSize int64
+	usedSize     int64
+	lru          *list.List // front is the most recently accessed
+	items        map[string]*list.Element
+}
+
+var _ ChunkCache = &FlatFileChunkCache{}
+
+func NewFlatFileChunkCache(dir string, capacity int64) (*FlatFileChunkCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	c := &FlatFileChunkCache{
+		dir:          dir,
+		capacity:     capacity,
+		maxChunkSize: capacity / 8,
+		lru:          list.New(),
+		items:        make(map[string]*list.Element),
+	}
+	if err := c.loadExisting(); err != nil {
+		return nil, err
+	}
+	glog.V(0).Infof("read cache %s: %d chunks, %d of %d bytes", dir, len(c.items), c.usedSize, capacity)
+	return c, nil
+}
+
+func (c *FlatFileChunkCache) loadExisting() error {
+	type existingFile struct {
+		key     string
+		size    int64
+		modTime time.Time
+	}
+	var files []existingFile
+	err := filepath.Walk(c.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		key := info.Name()
+		if len(key) != 2*sha1.Size || filepath.Base(filepath.Dir(path)) != key[:2] {
+			// leftover temporary files
+			os.Remove(path)
+			return nil
+		}
+		files = append(files, existingFile{key: key, size: info.Size(), modTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.After(files[j].modTime)
+	})
+	for _, f := range files {
+		c.items[f.key] = c.lru.PushBack(&flatFileCacheItem{key: f.key, size: f.size})
+		c.usedSize += f.size
+	}
+	c.evict()
+	return nil
+}
+
+func flatFileCacheKey(fileId string) string {
+	h := sha1.Sum([]byte(fileId))
+	return hex.EncodeToString(h[:])
+}
+
+func (c *FlatFileChunkCache) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key)
+}
+
+// MaxChunkSize is the size of the largest chunk to cache
+func (c *FlatFileChunkCache) MaxChunkSize() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.maxChunkSize
+}
+
+func (c *FlatFileChunkCache) ReadChunkAt(data []byte, fileId string, offset uint64) (n int, err error) {
+	if c == nil {
+		return 0, nil
+	}
+	key := flatFileCacheKey(fileId)
+
+	c.Lock()
+	element, found := c.items[key]
+	if found {
+		c.lru.MoveToFront(element)
+	}
+	c.Unlock()
+	if !found {
+		return 0, nil
+	}
+
+	p := c.path(key)
+	f, err := os.Open(p)
+	if err != nil {
+		c.remove(key)
+		return 0, nil
+	}
+	defer f.Close()
+	n, err = f.ReadAt(data, int64(offset))
+	if err == io.EOF {
+		err = nil
+	}
+	now := time.Now()
+	os.Chtimes(p, now, now)
+	return n, err
+}
+
+func (c *FlatFileChunkCache) SetChunk(fileId string, data []byte) {
+	if c == nil || int64(len(data)) > c.maxChunkSize {
+		return
+	}
+	key := flatFileCacheKey(fileId)
+
+	c.Lock()
+	_, found := c.items[key]
+	c.Unlock()
+	if found {
+		return
+	}
+
+	p := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		glog.Errorf("read cache mkdir %s: %v", filepath.Dir(p), err)
+		return
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(p), key+".tmp")
+	if err != nil {
+		glog.Errorf("read cache create %s: %v", p, err)
+		return
+	}
+	_, err = tmpFile.Write(data)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpFile.Name(), p)
+	}
+	if err != nil {
+		glog.Errorf("read cache write %s: %v", p, err)
+		os.Remove(tmpFile.Name())
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	if _, found = c.items[key]; found {
+		return
+	}
+	c.items[key] = c.lru.PushFront(&flatFileCacheItem{key: key, size: int64(len(data))})
+	c.usedSize += int64(len(data))
+	c.evict()
+}
+
+// InvalidateChunk removes the chunk, e.g. after the chunk is deleted.
+func (c *FlatFileChunkCache) InvalidateChunk(fileId string) {
+	if c == nil {
+		return
+	}
+	c.remove(flatFileCacheKey(fileId))
+}
+
+func (c *FlatFileChunkCache) remove(key string) {
+	c.Lock()
+	defer c.Unlock()
+	if element, found := c.items[key]; found {
+		c.removeElement(element)
+	}
+}
+
+// evict needs the lock
+func (c *FlatFileChunkCache) evict() {
+	for c.usedSize > c.capacity && c.lru.Len() > 0 {
+		c.removeElement(c.lru.Back())
+	}
+}
+
+// removeElement needs the lock
+func (c *FlatFileChunkCache) removeElement(element *list.Element) {
+	item := element.Value.(*flatFileCacheItem)
+	c.lru.Remove(element)
+	delete(c.items, item.key)
+	c.usedSize -= item.size
+	if err := os.Remove(c.path(item.key)); err != nil && !os.IsNotExist(err) {
+		glog.Errorf("read cache remove %s: %v", c.path(item.key), err)
+	}
+}
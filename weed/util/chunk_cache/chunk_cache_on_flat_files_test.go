@@ -0,0 +1,57 @@
+package chunk_cache
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestFlatFileChunkCache(t *testing.T) {
+	dir := t.TempDir()
+
+	cache, err := NewFlatFileChunkCache(dir, 8*1024)
+	if err != nil {
+		t.Fatalf("new cache: %v", err)
+	}
+	chunk := func(i int) []byte {
+		return bytes.Repeat([]byte{byte(i)}, 1024)
+	}
+	for i := 0; i < 8; i++ {
+		cache.SetChunk(fmt.Sprintf("1,%x", i), chunk(i))
+	}
+
+	// access chunk 0, so chunk 1 is the least recently accessed
+	data := make([]byte, 100)
+	if n, err := cache.ReadChunkAt(data, "1,0", 10); err != nil || n != 100 || !bytes.Equal(data, chunk(0)[10:110]) {
+		t.Fatalf("read chunk 0: %d %v", n, err)
+	}
+	cache.SetChunk("1,8", chunk(8))
+	if n, _ := cache.ReadChunkAt(data, "1,1", 0); n != 0 {
+		t.Errorf("chunk 1 should be evicted")
+	}
+	if n, _ := cache.ReadChunkAt(data, "1,0", 0); n != 100 {
+		t.Errorf("chunk 0 should be kept")
+	}
+
+	cache.InvalidateChunk("1,8")
+	if n, _ := cache.ReadChunkAt(data, "1,8", 0); n != 0 {
+		t.Errorf("chunk 8 should be invalidated")
+	}
+
+	cache.SetChunk("1,big", make([]byte, 2*1024))
+	if n, _ := cache.ReadChunkAt(data, "1,big", 0); n != 0 {
+		t.Errorf("chunks larger than max chunk size should not be cached")
+	}
+
+	// reload from the files
+	reloaded, err := NewFlatFileChunkCache(dir, 8*1024)
+	if err != nil {
+		t.Fatalf("reload cache: %v", err)
+	}
+	if len(reloaded.items) != 7 || reloaded.usedSize != 7*1024 {
+		t.Errorf("reloaded %d chunks of %d bytes", len(reloaded.items), reloaded.usedSize)
+	}
+	if n, _ := reloaded.ReadChunkAt(data, "1,2", 0); n != 100 || !bytes.Equal(data, chunk(2)[:100]) {
+		t.Errorf("read reloaded chunk 2: %d", n)
+	}
+}
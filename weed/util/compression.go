@@ -4,9 +4,10 @@ import (
 	"bytes"
 	"fmt"
 	"strings"
+	"sync"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/seaweedfs/seaweedfs/weed/glog"
-	// "github.com/klauspost/compress/zstd"
 )
 
 var (
@@ -60,11 +61,9 @@ func DecompressData(input []byte) ([]byte, error) {
 	if IsGzippedContent(input) {
 		return ungzipData(input)
 	}
-	/*
-		if IsZstdContent(input) {
-			return unzstdData(input)
-		}
-	*/
+	if IsZstdContent(input) {
+		return unzstdData(input)
+	}
 	return input, UnsupportedCompression
 }
 
@@ -75,11 +74,27 @@ func IsGzippedContent(data []byte) bool {
 	return data[0] == 31 && data[1] == 139
 }
 
-/*
-var zstdEncoder, _ = zstd.NewWriter(nil)
+// zstdEncoders keeps one encoder per zstd.EncoderLevel, since the level is fixed when the encoder is created
+var zstdEncoders sync.Map
 
-func ZstdData(input []byte) ([]byte, error) {
-	return zstdEncoder.EncodeAll(input, nil), nil
+// ZstdData compresses with the zstd level, 1 for the fastest to 22 for the best compression, or 0 for the default
+func ZstdData(input []byte, level int) ([]byte, error) {
+	encoderLevel := zstd.SpeedDefault
+	if level > 0 {
+		encoderLevel = zstd.EncoderLevelFromZstd(level)
+	}
+	encoder, found := zstdEncoders.Load(encoderLevel)
+	if !found {
+		newEncoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(encoderLevel))
+		if err != nil {
+			return nil, err
+		}
+		var loaded bool
+		if encoder, loaded = zstdEncoders.LoadOrStore(encoderLevel, newEncoder); loaded {
+			newEncoder.Close()
+		}
+	}
+	return encoder.(*zstd.Encoder).EncodeAll(input, nil), nil
 }
 
 var decoder, _ = zstd.NewReader(nil)
@@ -94,7 +109,6 @@ func IsZstdContent(data []byte) bool {
 	}
 	return data[3] == 0xFD && data[2] == 0x2F && data[1] == 0xB5 && data[0] == 0x28
 }
-*/
 
 /*
 * Default not to compressed since compression can be done on client side.
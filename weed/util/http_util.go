@@ -26,7 +26,7 @@ func init() {
 		MaxIdleConnsPerHost: 1024,
 	}
 	client = &http.Client{
-		Transport: Transport,
+		Transport: NewInternalTransport(Transport),
 	}
 }
 
@@ -94,7 +94,16 @@ func GetAuthenticated(url, jwt string) ([]byte, bool, error) {
 }
 
 func Head(url string) (http.Header, error) {
-	r, err := client.Head(url)
+	return HeadAuthenticated(url, "")
+}
+
+func HeadAuthenticated(url, jwt string) (http.Header, error) {
+	req, err := http.NewRequest(http.MethodHead, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	maybeAddAuth(req, jwt)
+	r, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
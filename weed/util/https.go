@@ -0,0 +1,50 @@
+package util
+
+import (
+	"crypto/tls"
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	httpsConfig         atomic.Pointer[tls.Config]
+	internalTransports  []*http.Transport
+	internalTransportMu sync.Mutex
+)
+
+// UseHttps sends the requests of the internal transports over https with the tls config,
+// when the master, volume and filer http servers all serve https.
+func UseHttps(tlsConfig *tls.Config) {
+	internalTransportMu.Lock()
+	defer internalTransportMu.Unlock()
+	httpsConfig.Store(tlsConfig)
+	for _, t := range internalTransports {
+		t.TLSClientConfig = tlsConfig
+	}
+}
+
+// NewInternalTransport wraps the transport of the requests between the seaweedfs servers.
+// The urls of the servers are always http://, and are sent over https after UseHttps.
+func NewInternalTransport(t *http.Transport) http.RoundTripper {
+	internalTransportMu.Lock()
+	defer internalTransportMu.Unlock()
+	if tlsConfig := httpsConfig.Load(); tlsConfig != nil {
+		t.TLSClientConfig = tlsConfig
+	}
+	internalTransports = append(internalTransports, t)
+	return &internalTransport{Transport: t}
+}
+
+type internalTransport struct {
+	*http.Transport
+}
+
+func (t *internalTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "http" || httpsConfig.Load() == nil {
+		return t.Transport.RoundTrip(req)
+	}
+	httpsReq := req.Clone(req.Context())
+	httpsReq.URL.Scheme = "https"
+	return t.Transport.RoundTrip(httpsReq)
+}
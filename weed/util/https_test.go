@@ -0,0 +1,37 @@
+package util
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestInternalTransportUseHttps(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewInternalTransport(&http.Transport{})}
+	url := "http://" + server.Listener.Addr().String() + "/status"
+
+	if resp, err := client.Get(url); err == nil {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusNoContent {
+			t.Fatalf("http request should not be served by the https server")
+		}
+	}
+
+	UseHttps(&tls.Config{InsecureSkipVerify: true})
+	defer UseHttps(nil)
+
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("get %s over https: %v", url, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("status %d, expected %d", resp.StatusCode, http.StatusNoContent)
+	}
+}
@@ -66,3 +66,19 @@ func CanonicalizeETag(etag string) string {
 	canonicalETag := strings.TrimPrefix(etag, "\"")
 	return strings.TrimSuffix(canonicalETag, "\"")
 }
+
+// ParseTags parses comma separated key=value pairs, like "rack=r1,tier=hot"
+func ParseTags(text string) (map[string]string, error) {
+	if text == "" {
+		return nil, nil
+	}
+	tags := make(map[string]string)
+	for _, pair := range strings.Split(text, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+		if !found || key == "" {
+			return nil, fmt.Errorf("invalid tag %q, expecting key=value", pair)
+		}
+		tags[key] = value
+	}
+	return tags, nil
+}
@@ -0,0 +1,161 @@
+package wdclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"google.golang.org/grpc"
+
+	"github.com/seaweedfs/seaweedfs/weed/operation"
+	"github.com/seaweedfs/seaweedfs/weed/pb"
+	"github.com/seaweedfs/seaweedfs/weed/util"
+)
+
+// FileId is the "<volume id>,<needle id><cookie>" id of a file stored on the volume servers.
+type FileId string
+
+type PutOptions struct {
+	Collection  string
+	Replication string
+	Ttl         string
+	DiskType    string
+	DataCenter  string
+	FileName    string
+	MimeType    string
+}
+
+// Client reads and writes files directly on the volume servers, without a filer.
+// The volume locations are kept up to date by the connection to the master,
+// and the http connections to the volume servers are pooled.
+type Client struct {
+	masterClient   *MasterClient
+	grpcDialOption grpc.DialOption
+}
+
+// NewClient connects to the masters until the context is done, and waits for the first connection.
+func NewClient(ctx context.Context, grpcDialOption grpc.DialOption, masters pb.ServerDiscovery) *Client {
+	c := &Client{
+		masterClient:   NewMasterClient(grpcDialOption, "", "client", "", "", "", masters),
+		grpcDialOption: grpcDialOption,
+	}
+	go c.masterClient.KeepConnectedToMaster(ctx)
+	c.masterClient.WaitUntilConnected(ctx)
+	return c
+}
+
+// Put assigns a new file id, and uploads the content to its volume server.
+func (c *Client) Put(ctx context.Context, r io.Reader, opts PutOptions) (FileId, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	assignResult, err := operation.Assign(c.masterClient.GetMaster, c.grpcDialOption, &operation.VolumeAssignRequest{
+		Count:       1,
+		Collection:  opts.Collection,
+		Replication: opts.Replication,
+		Ttl:         opts.Ttl,
+		DiskType:    opts.DiskType,
+		DataCenter:  opts.DataCenter,
+	})
+	if err != nil {
+		return "", fmt.Errorf("assign: %v", err)
+	}
+	uploadResult, err, _ := operation.Upload(r, &operation.UploadOption{
+		UploadUrl: "http://" + assignResult.Url + "/" + assignResult.Fid,
+		Filename:  opts.FileName,
+		MimeType:  opts.MimeType,
+		Jwt:       assignResult.Auth,
+	})
+	if err != nil {
+		return "", fmt.Errorf("upload %s: %v", assignResult.Fid, err)
+	}
+	if uploadResult.Error != "" {
+		return "", fmt.Errorf("upload %s: %s", assignResult.Fid, uploadResult.Error)
+	}
+	return FileId(assignResult.Fid), nil
+}
+
+// Get writes the content of the file to w, trying each volume server with the file in turn.
+// If a volume server fails in the middle of the content, the next one resumes after the bytes already written.
+func (c *Client) Get(ctx context.Context, fileId FileId, w io.Writer) error {
+	urls, err := c.masterClient.LookupFileIdWithFallback(string(fileId))
+	if err != nil {
+		return fmt.Errorf("lookup %s: %v", fileId, err)
+	}
+	var jwt string
+	cw := &countingWriter{w: w}
+	for _, url := range urls {
+		var status int
+		status, err = c.get(ctx, url, jwt, cw)
+		if status == http.StatusUnauthorized && jwt == "" {
+			// the volume servers require signed reads
+			jwt = string(operation.LookupJwt(c.masterClient.GetMaster(ctx), c.grpcDialOption, string(fileId)))
+			status, err = c.get(ctx, url, jwt, cw)
+		}
+		if err == nil || status == http.StatusNotFound || cw.err != nil || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func (c *Client) get(ctx context.Context, url, jwt string, cw *countingWriter) (status int, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	if jwt != "" {
+		req.Header.Set("Authorization", "BEARER "+jwt)
+	}
+	if cw.written > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", cw.written))
+	}
+	resp, err := util.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer util.CloseResponse(resp)
+	switch {
+	case resp.StatusCode == http.StatusOK && cw.written == 0:
+	case resp.StatusCode == http.StatusPartialContent && cw.written > 0:
+	default:
+		return resp.StatusCode, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	_, err = io.Copy(cw, resp.Body)
+	return resp.StatusCode, err
+}
+
+// countingWriter counts the bytes written, to resume the content on another volume server.
+// The errors of w are kept, since another volume server can not fix them.
+type countingWriter struct {
+	w       io.Writer
+	written int64
+	err     error
+}
+
+func (cw *countingWriter) Write(p []byte) (n int, err error) {
+	n, err = cw.w.Write(p)
+	cw.written += int64(n)
+	if err != nil {
+		cw.err = err
+	}
+	return
+}
+
+// Delete deletes the file from all the volume servers with the file.
+func (c *Client) Delete(ctx context.Context, fileId FileId) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	results, err := operation.DeleteFiles(c.masterClient.GetMaster, false, c.grpcDialOption, []string{string(fileId)})
+	if err != nil {
+		return fmt.Errorf("delete %s: %v", fileId, err)
+	}
+	for _, result := range results {
+		if result.Error != "" {
+			return fmt.Errorf("delete %s: %s", fileId, result.Error)
+		}
+	}
+	return nil
+}
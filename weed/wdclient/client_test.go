@@ -0,0 +1,52 @@
+package wdclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/seaweedfs/seaweedfs/weed/pb"
+)
+
+func TestClientGetResumesOnAnotherReplica(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	// every replica fails after half of the content, unless it is asked for the rest
+	replica := func(w http.ResponseWriter, r *http.Request) {
+		if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+			offset, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rangeHeader, "bytes="), "-"))
+			if err != nil || offset >= len(content) {
+				w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+				return
+			}
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, len(content)-1, len(content)))
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(content[offset:])
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.WriteHeader(http.StatusOK)
+		w.Write(content[:len(content)/2])
+		w.(http.Flusher).Flush()
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}
+	c := &Client{masterClient: NewMasterClient(nil, "", "client", "", "", "", pb.ServerDiscovery{})}
+	for i := 0; i < 2; i++ {
+		server := httptest.NewServer(http.HandlerFunc(replica))
+		defer server.Close()
+		c.masterClient.addLocation(3, Location{Url: strings.TrimPrefix(server.URL, "http://")})
+	}
+
+	var buf bytes.Buffer
+	if err := c.Get(context.Background(), "3,01637037d6", &buf); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), content) {
+		t.Errorf("got %d bytes, expected the %d bytes of the content", buf.Len(), len(content))
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"unicode"
 	"unicode/utf8"
 
+	"github.com/seaweedfs/seaweedfs/weed/security"
 	weed_server "github.com/seaweedfs/seaweedfs/weed/server"
 	"github.com/seaweedfs/seaweedfs/weed/util"
 	flag "github.com/seaweedfs/seaweedfs/weed/util/fla9"
@@ -24,6 +25,15 @@ import (
 
 var IsDebug *bool
 
+// tlsFiles are the -tls-ca, -tls-cert and -tls-key flags, registered on each command
+type tlsFiles struct {
+	caFile   *string
+	certFile *string
+	keyFile  *string
+}
+
+var commandTlsFiles = make(map[*command.Command]tlsFiles)
+
 var commands = command.Commands
 
 var exitStatus = 0
@@ -44,6 +54,13 @@ func init() {
 	weed_server.StaticFS, _ = fs.Sub(static, "static")
 
 	flag.Var(&util.ConfigurationFileDirectory, "config_dir", "directory with toml configuration files")
+	for _, cmd := range commands {
+		commandTlsFiles[cmd] = tlsFiles{
+			caFile:   cmd.Flag.String("tls-ca", "", "ca file to verify the grpc and http certificates of all components, overriding security.toml"),
+			certFile: cmd.Flag.String("tls-cert", "", "certificate file of the grpc and http servers and clients of all components, reloaded on SIGHUP"),
+			keyFile:  cmd.Flag.String("tls-key", "", "private key file of the grpc and http servers and clients of all components, reloaded on SIGHUP"),
+		}
+	}
 }
 
 func main() {
@@ -92,6 +109,14 @@ func main() {
 			cmd.Flag.Parse(args[1:])
 			args = cmd.Flag.Args()
 			IsDebug = cmd.IsDebug
+			if tls := commandTlsFiles[cmd]; *tls.caFile != "" || *tls.certFile != "" || *tls.keyFile != "" {
+				if err := security.UseTlsFiles(*tls.caFile, *tls.certFile, *tls.keyFile); err != nil {
+					fmt.Fprintf(os.Stderr, "weed %s: %v\n", cmd.Name(), err)
+					setExitStatus(2)
+					exit()
+					return
+				}
+			}
 			if !cmd.Run(cmd, args) {
 				fmt.Fprintf(os.Stderr, "\n")
 				cmd.Flag.Usage()